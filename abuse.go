@@ -0,0 +1,250 @@
+package http
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================= Abuse Detection ==========================
+// ====================================================================
+
+// AbuseSignals request characteristics given to the AbuseDetector.
+type AbuseSignals struct {
+	IP          string  // Client IP
+	UserAgent   string  // User-Agent header
+	Method      string  // HTTP method
+	Path        string  // Request path
+	BodySize    int     // Body size in bytes
+	BodyEntropy float64 // Shannon entropy of the body in bits per byte (0-8), high for random or encrypted payloads
+	Fingerprint string  // Request fingerprint, see Fingerprint
+}
+
+// AbuseVerdict result of an AbuseDetector inspection.
+type AbuseVerdict struct {
+	Score  float64 // Suspicion score, 1 or more is considered abusive by the built-in detector
+	Deny   bool    // Reject the request
+	Reason string  // Logged reason of a denial
+}
+
+// AbuseDetector is an interface for request abuse heuristics, invoked by ProcessData, ProcessUpdateData,
+// ProcessFilter and ProcessFilterAs before processing, and informed of validation outcomes.
+type AbuseDetector interface {
+	// Inspect scores a request before it is processed; a Deny verdict rejects it with 403.
+	Inspect(signals AbuseSignals) AbuseVerdict
+
+	// Report records whether the request failed parsing or validation, for validation-failure streaks.
+	Report(signals AbuseSignals, failed bool)
+}
+
+// abuseDetector the detector used by the Process* pipeline, disabled when nil.
+var abuseDetector AbuseDetector
+
+// RegisterAbuseDetector registers the detector invoked by the Process* pipeline.
+//
+// Example Usage:
+//
+//	http.RegisterAbuseDetector(http.NewSlidingWindowDetector(time.Minute, 120, 20))
+func RegisterAbuseDetector(detector AbuseDetector) {
+	abuseDetector = detector
+}
+
+// RequestSignals builds the abuse signals of a request.
+func RequestSignals(c *core.Ctx) AbuseSignals {
+	body := c.Root().PostBody()
+
+	return AbuseSignals{
+		IP:          c.ClientIP(),
+		UserAgent:   c.GetHeader(core.HeaderUserAgent),
+		Method:      string(c.Root().Method()),
+		Path:        string(c.Root().Path()),
+		BodySize:    len(body),
+		BodyEntropy: shannonEntropy(body),
+		Fingerprint: Fingerprint(c),
+	}
+}
+
+// InspectRequest runs the registered AbuseDetector on the request and rejects it when denied.
+func InspectRequest(c *core.Ctx) error {
+	if abuseDetector == nil {
+		return nil
+	}
+
+	signals := RequestSignals(c)
+	verdict := abuseDetector.Inspect(signals)
+	if !verdict.Deny {
+		return nil
+	}
+
+	log.Warnf("Request denied for %s %s from %s: %s (score %.2f)",
+		signals.Method, signals.Path, signals.IP, verdict.Reason, verdict.Score)
+
+	return c.Error(&Error{
+		Code:    "REQUEST_DENIED",
+		Message: "Request denied",
+	}, core.StatusForbidden)
+}
+
+// reportRequest records the outcome of the request's parsing or validation for the access log, and
+// informs the registered AbuseDetector of it.
+func reportRequest(c *core.Ctx, failed bool) {
+	if c == nil {
+		return
+	}
+	c.SetData(ValidationKey, !failed)
+
+	if abuseDetector != nil {
+		abuseDetector.Report(RequestSignals(c), failed)
+	}
+}
+
+// shannonEntropy entropy of the data in bits per byte.
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+
+	entropy := 0.0
+	size := float64(len(data))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / size
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// ====================================================================
+// ===================== Sliding Window Detector ======================
+// ====================================================================
+
+// slidingWindowPurgeSize number of tracked clients above which idle clients are purged.
+const slidingWindowPurgeSize = 10000
+
+// slidingWindowClient state of a client IP in SlidingWindowDetector.
+type slidingWindowClient struct {
+	requests      []time.Time
+	failureStreak int
+}
+
+// SlidingWindowDetector in-memory AbuseDetector denying client IPs which send more than MaxRequests within
+// Window, fail validation MaxFailureStreak times in a row, or send bodies above MaxEntropy.
+type SlidingWindowDetector struct {
+	Window           time.Duration // Length of the sliding window
+	MaxRequests      int           // Requests allowed per client IP within the window
+	MaxFailureStreak int           // Consecutive parse/validation failures allowed, unlimited when zero
+	MaxEntropy       float64       // Maximum body entropy in bits per byte for bodies of 256 bytes or more, unchecked when zero
+
+	mu      sync.Mutex
+	clients map[string]*slidingWindowClient
+}
+
+// NewSlidingWindowDetector creates a SlidingWindowDetector.
+func NewSlidingWindowDetector(window time.Duration, maxRequests, maxFailureStreak int) *SlidingWindowDetector {
+	return &SlidingWindowDetector{
+		Window:           window,
+		MaxRequests:      maxRequests,
+		MaxFailureStreak: maxFailureStreak,
+		clients:          map[string]*slidingWindowClient{},
+	}
+}
+
+// Inspect records the request in the client's window and scores it.
+func (d *SlidingWindowDetector) Inspect(signals AbuseSignals) AbuseVerdict {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if len(d.clients) > slidingWindowPurgeSize {
+		d.purge(now)
+	}
+
+	client := d.client(signals.IP, now)
+	client.requests = append(client.requests, now)
+
+	verdict := AbuseVerdict{}
+	if d.MaxRequests > 0 {
+		verdict.Score = float64(len(client.requests)) / float64(d.MaxRequests)
+		if len(client.requests) > d.MaxRequests {
+			verdict.Deny, verdict.Reason = true, "too many requests"
+		}
+	}
+
+	if d.MaxFailureStreak > 0 {
+		verdict.Score = math.Max(verdict.Score, float64(client.failureStreak)/float64(d.MaxFailureStreak))
+		if client.failureStreak >= d.MaxFailureStreak {
+			verdict.Deny, verdict.Reason = true, "validation failure streak"
+		}
+	}
+
+	if d.MaxEntropy > 0 && signals.BodySize >= 256 && signals.BodyEntropy > d.MaxEntropy {
+		verdict.Score = math.Max(verdict.Score, 1)
+		verdict.Deny, verdict.Reason = true, "high entropy body"
+	}
+
+	return verdict
+}
+
+// Report updates the client's validation-failure streak.
+func (d *SlidingWindowDetector) Report(signals AbuseSignals, failed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	client := d.client(signals.IP, time.Now())
+	if failed {
+		client.failureStreak++
+	} else {
+		client.failureStreak = 0
+	}
+}
+
+// purge removes the clients without requests within the window. Must be called with mu held.
+func (d *SlidingWindowDetector) purge(now time.Time) {
+	start := now.Add(-d.Window)
+	for ip, client := range d.clients {
+		if len(client.requests) == 0 || !client.requests[len(client.requests)-1].After(start) {
+			delete(d.clients, ip)
+		}
+	}
+}
+
+// client returns the state of the IP with requests older than the window removed. Must be called with mu held.
+func (d *SlidingWindowDetector) client(ip string, now time.Time) *slidingWindowClient {
+	if d.clients == nil {
+		d.clients = map[string]*slidingWindowClient{}
+	}
+
+	client, ok := d.clients[ip]
+	if !ok {
+		client = &slidingWindowClient{}
+		d.clients[ip] = client
+	}
+
+	start := now.Add(-d.Window)
+	kept := client.requests[:0]
+	for _, at := range client.requests {
+		if at.After(start) {
+			kept = append(kept, at)
+		}
+	}
+	client.requests = kept
+
+	// A client idle for a whole window starts over
+	if len(kept) == 0 {
+		client.failureStreak = 0
+	}
+
+	return client
+}
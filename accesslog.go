@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ============================ Access Log ============================
+// ====================================================================
+
+// Validation results of AccessLog.
+const (
+	ValidationPassed  = "passed"
+	ValidationFailed  = "failed"
+	ValidationSkipped = "skipped"
+)
+
+// AccessLog structured access log record, the schema shared by all services.
+type AccessLog struct {
+	Time       time.Time `json:"time"`            // Request start time
+	RequestID  string    `json:"request_id"`      // X-Request-ID header, or the connection-scoped request ID
+	Method     string    `json:"method"`          // HTTP method
+	Path       string    `json:"path"`            // Request path
+	Route      string    `json:"route"`           // Route path template, e.g. "/users/{id}"
+	Status     int       `json:"status"`          // Response status
+	LatencyMs  float64   `json:"latency_ms"`      // Time since the request start, in milliseconds
+	BytesIn    int       `json:"bytes_in"`        // Request body size
+	BytesOut   int       `json:"bytes_out"`       // Response body size
+	Caller     string    `json:"caller"`          // CallerIdentity
+	IP         string    `json:"ip"`              // Client IP
+	UserAgent  string    `json:"user_agent"`      // User-Agent header
+	DTO        string    `json:"dto,omitempty"`   // Type of the processed request DTO
+	Validation string    `json:"validation"`      // ValidationPassed, ValidationFailed or ValidationSkipped
+	Error      string    `json:"error,omitempty"` // Outcome error, if not an error response
+}
+
+// AccessLogSink is an interface for the destinations of access log records (stdout, file, log shipper, ...).
+type AccessLogSink interface {
+	Write(entry AccessLog)
+}
+
+// accessLogSink destination of WriteAccessLog, access logs are disabled when nil.
+var accessLogSink AccessLogSink
+
+// RegisterAccessLogSink registers the destination of access log records.
+//
+// Example Usage:
+//
+//	http.RegisterAccessLogSink(http.NewJSONAccessLogSink(os.Stdout))
+func RegisterAccessLogSink(sink AccessLogSink) {
+	accessLogSink = sink
+}
+
+// RequestID returns the ID of the request: the X-Request-ID header, or the connection-scoped request ID.
+func RequestID(c *core.Ctx) string {
+	if requestID := c.GetHeader(core.HeaderXRequestID); requestID != "" {
+		return requestID
+	}
+
+	return strconv.FormatUint(c.Root().ID(), 10)
+}
+
+// BuildAccessLog builds the access log record of a request once its response is rendered.
+// The outcome is the error returned by Validate or Handle, nil on success.
+func BuildAccessLog(c *core.Ctx, outcome error) AccessLog {
+	root := c.Root()
+
+	validation := ValidationSkipped
+	if valid, ok := c.GetData(ValidationKey).(bool); ok {
+		validation = ValidationFailed
+		if valid {
+			validation = ValidationPassed
+		}
+	}
+
+	entry := AccessLog{
+		Time:       root.Time(),
+		RequestID:  RequestID(c),
+		Method:     string(root.Method()),
+		Path:       string(root.Path()),
+		Route:      RoutePath(c),
+		Status:     root.Response.StatusCode(),
+		BytesIn:    len(root.PostBody()),
+		BytesOut:   len(root.Response.Body()),
+		Caller:     CallerIdentity(c),
+		IP:         c.ClientIP(),
+		UserAgent:  c.GetHeader(core.HeaderUserAgent),
+		Validation: validation,
+	}
+
+	if !entry.Time.IsZero() {
+		entry.LatencyMs = float64(time.Since(entry.Time).Microseconds()) / 1000
+	}
+	if requestData := c.GetData(RequestKey); requestData != nil {
+		entry.DTO = reflect.TypeOf(requestData).String()
+	}
+	// errors.UnknownError only tells an error response was rendered
+	if outcome != nil && !errors.Is(outcome, errors.UnknownError) {
+		entry.Error = outcome.Error()
+	}
+
+	return entry
+}
+
+// WriteAccessLog builds the access log record of the request and hands it to the registered sink.
+// A panicking sink is handled by the SubsystemAccessLog policy: the response is kept, under FailClosed the
+// failure is reported by the SubsystemFailureHeader.
+func WriteAccessLog(c *core.Ctx, outcome error) {
+	if accessLogSink == nil {
+		return
+	}
+
+	entry := BuildAccessLog(c, outcome)
+	if err := guard(func() { accessLogSink.Write(entry) }); err != nil {
+		degradeCommitted(c, SubsystemAccessLog, err)
+	}
+}
+
+// accessLoggedHandler handler wrapper writing the access log of each request.
+type accessLoggedHandler struct {
+	core.IHandler
+}
+
+// AccessLogged wraps a handler so an access log record is written for each request, rejected by
+// Validate or handled.
+//
+// Example Usage:
+//
+//	router.POST("/users", http.AccessLogged(api.NewCreateUserApi()))
+func AccessLogged(handler core.IHandler) core.IHandler {
+	return &accessLoggedHandler{IHandler: handler}
+}
+
+// Validate runs the wrapped Validate, rejected requests are logged as Handle is not called.
+func (h *accessLoggedHandler) Validate(c *core.Ctx) error {
+	err := h.IHandler.Validate(c)
+	if err != nil {
+		WriteAccessLog(c, err)
+	}
+
+	return err
+}
+
+// Handle runs the wrapped handler and logs the request.
+func (h *accessLoggedHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	WriteAccessLog(c, err)
+
+	return err
+}
+
+// JSONAccessLogSink AccessLogSink writing records as JSON lines.
+type JSONAccessLogSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewJSONAccessLogSink creates an AccessLogSink writing JSON lines to the writer.
+func NewJSONAccessLogSink(writer io.Writer) *JSONAccessLogSink {
+	return &JSONAccessLogSink{writer: writer}
+}
+
+// Write writes the record as a JSON line.
+func (s *JSONAccessLogSink) Write(entry AccessLog) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Errorf("Access log encoding error: %v", err)
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		log.Errorf("Access log write error: %v", err)
+	}
+}
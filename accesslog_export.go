@@ -0,0 +1,197 @@
+package http
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================== Access Log Export =========================
+// ====================================================================
+
+// FormatEvents server-sent events format of access log exports, one access_log event per record.
+const FormatEvents = "events"
+
+// AccessLogFilter selects access log records, zero fields select all.
+type AccessLogFilter struct {
+	Since  time.Time // Records of requests started at or after
+	Until  time.Time // Records of requests started before
+	Caller string    // Records of the caller (CallerIdentity), the actor of the request
+}
+
+// matches checks the record is selected by the filter.
+func (f AccessLogFilter) matches(entry AccessLog) bool {
+	return (f.Since.IsZero() || !entry.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || entry.Time.Before(f.Until)) &&
+		(f.Caller == "" || entry.Caller == f.Caller)
+}
+
+// AccessLogRecorder AccessLogSink keeping the latest records in memory, so operators can pull recent activity
+// (see AccessLogExportApi) without a separate logging stack. Records are also handed to the next sink, if any.
+type AccessLogRecorder struct {
+	mu      sync.RWMutex
+	records []AccessLog // Ring buffer
+	next    int         // Index of the next record
+	full    bool        // The buffer wrapped around
+	forward AccessLogSink
+}
+
+// NewAccessLogRecorder creates a recorder of the latest capacity records, forwarding them to next (nil for none).
+//
+// Example Usage:
+//
+//	recorder := http.NewAccessLogRecorder(10000, http.NewJSONAccessLogSink(os.Stdout))
+//	http.RegisterAccessLogSink(recorder)
+func NewAccessLogRecorder(capacity int, next AccessLogSink) *AccessLogRecorder {
+	return &AccessLogRecorder{
+		records: make([]AccessLog, max(capacity, 1)),
+		forward: next,
+	}
+}
+
+// Write records the entry, the oldest record is dropped once the recorder is full.
+func (r *AccessLogRecorder) Write(entry AccessLog) {
+	r.mu.Lock()
+	r.records[r.next] = entry
+	r.next = (r.next + 1) % len(r.records)
+	r.full = r.full || r.next == 0
+	r.mu.Unlock()
+
+	if r.forward != nil {
+		r.forward.Write(entry)
+	}
+}
+
+// Records returns the records selected by the filter, oldest first.
+func (r *AccessLogRecorder) Records(filter AccessLogFilter) []AccessLog {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ordered := r.records[:r.next]
+	if r.full {
+		ordered = append(append([]AccessLog{}, r.records[r.next:]...), r.records[:r.next]...)
+	}
+
+	var records []AccessLog
+	for _, entry := range ordered {
+		if filter.matches(entry) {
+			records = append(records, entry)
+		}
+	}
+
+	return records
+}
+
+// AccessLogExportApi handler streaming the records of an AccessLogRecorder as NDJSON, or as server-sent events
+// when the client accepts text/event-stream (or `format=events`). Records are filtered by the `since` and `until`
+// query parameters (RFC 3339) and the `actor` parameter (CallerIdentity). Records hold client IPs and callers:
+// the route must be restricted to operators. With the SlowClientDrop policy (see StreamFlowControl), events are
+// dropped while the client is stalled, then a `dropped` event tells how many.
+type AccessLogExportApi struct {
+	core.Endpoint
+	recorder *AccessLogRecorder
+}
+
+// NewAccessLogExportApi creates the export handler of the recorder's records.
+//
+// Example Usage:
+//
+//	adminRouter.GET("/_access-logs", http.NewAccessLogExportApi(recorder))
+//	// GET /_access-logs?since=2025-06-01T00:00:00Z&actor=user-42
+func NewAccessLogExportApi(recorder *AccessLogRecorder) *AccessLogExportApi {
+	return &AccessLogExportApi{recorder: recorder}
+}
+
+// Validate parses the filter and the format.
+func (h *AccessLogExportApi) Validate(c *core.Ctx) error {
+	errorData := core.Data{}
+	filter := AccessLogFilter{Caller: c.QueryStr("actor")}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.QueryStr(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errorData[name] = []string{"must be an RFC 3339 time"}
+
+			continue
+		}
+		*target = parsed
+	}
+
+	format := c.QueryStr("format")
+	switch {
+	case format == "" && strings.Contains(c.GetHeader(core.HeaderAccept), "text/event-stream"):
+		format = FormatEvents
+	case format == "":
+		format = FormatNDJSON
+	case format != FormatNDJSON && format != FormatEvents:
+		errorData["format"] = []string{"must be one of ndjson, events"}
+	}
+
+	if len(errorData) > 0 {
+		return c.Error(&Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		})
+	}
+
+	c.SetData(FilterKey, filter)
+	c.SetData(DataKey, format)
+
+	return nil
+}
+
+// Handle streams the selected records.
+func (h *AccessLogExportApi) Handle(c *core.Ctx) error {
+	records := h.recorder.Records(c.GetData(FilterKey).(AccessLogFilter))
+	events := c.GetData(DataKey).(string) == FormatEvents
+
+	root := c.Root()
+	root.Response.SetStatusCode(core.StatusOK)
+	if events {
+		root.Response.Header.SetContentType("text/event-stream")
+		root.Response.Header.Set(core.HeaderCacheControl, "no-cache")
+	} else {
+		root.Response.Header.SetContentType(exportContentTypes[FormatNDJSON])
+	}
+
+	var notice func(dropped int) []byte
+	if events {
+		notice = func(dropped int) []byte {
+			return []byte("event: dropped\ndata: {\"dropped\":" + strconv.Itoa(dropped) + "}\n\n")
+		}
+	}
+
+	streamBody(c, "access_log", notice, func(flow *streamFlow) error {
+		for _, entry := range records {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				log.Errorf("Access log encoding error: %v", err)
+
+				return err
+			}
+
+			if events {
+				line = append(append([]byte("event: access_log\nid: "+entry.RequestID+"\ndata: "), line...), "\n\n"...)
+			} else {
+				line = append(line, '\n')
+			}
+			if err := flow.Send(line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
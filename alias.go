@@ -0,0 +1,163 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================== Field Aliases ===========================
+// ====================================================================
+
+var (
+	// aliasCache aliases by DTO type: legacy JSON name -> current JSON name.
+	aliasCache sync.Map
+
+	aliasUsageMu sync.Mutex
+	// aliasUsage number of requests using each alias, by "Type.alias" then client (User-Agent).
+	aliasUsage = map[string]map[string]uint64{}
+)
+
+// FieldAliasUsage returns the number of requests which used each legacy field name,
+// by "Type.alias" and then by client User-Agent.
+func FieldAliasUsage() map[string]map[string]uint64 {
+	aliasUsageMu.Lock()
+	defer aliasUsageMu.Unlock()
+
+	usage := make(map[string]map[string]uint64, len(aliasUsage))
+	for key, clients := range aliasUsage {
+		usage[key] = make(map[string]uint64, len(clients))
+		for client, count := range clients {
+			usage[key][client] = count
+		}
+	}
+
+	return usage
+}
+
+// fieldAliases returns the `json_alias` tags of the top-level fields of a struct type.
+func fieldAliases(typ reflect.Type) map[string]string {
+	if cached, ok := aliasCache.Load(typ); ok {
+		return cached.(map[string]string)
+	}
+
+	aliases := map[string]string{}
+	if typ.Kind() == reflect.Struct {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag := field.Tag.Get("json_alias")
+			if tag == "" {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			for _, alias := range strings.Split(tag, ",") {
+				aliases[strings.TrimSpace(alias)] = name
+			}
+		}
+	}
+
+	aliasCache.Store(typ, aliases)
+
+	return aliases
+}
+
+// applyAliases renames the legacy keys of the JSON body to the current names declared by `json_alias` tags,
+// so renamed fields keep accepting their old name. The request body is rewritten, later steps only see
+// the current names. A DEPRECATED warning is attached and usage is counted (see FieldAliasUsage).
+// A legacy key is ignored when the current name is sent as well.
+//
+// Example:
+//
+//	type CreateUserRequest struct {
+//		FullName string `json:"full_name" json_alias:"name" validate:"required"`
+//	}
+func applyAliases(c *core.Ctx, typ reflect.Type) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	body, used := renameAliases(c.Root().PostBody(), typ)
+	if len(used) == 0 {
+		return
+	}
+
+	for _, alias := range used {
+		recordAliasUse(c, typ, alias)
+	}
+
+	c.Root().Request.SetBody(body)
+}
+
+// recordAliasUse counts the use of a legacy field name of a struct type and attaches its DEPRECATED warning.
+func recordAliasUse(c *core.Ctx, typ reflect.Type, alias string) {
+	AddWarning(c, WarningDeprecated, fmt.Sprintf("Field '%s' is renamed to '%s'", alias, fieldAliases(typ)[alias]), alias)
+	if linting, _ := c.GetData(LintKey).(bool); linting {
+		// Payloads checked by Lint are not client usage
+		return
+	}
+
+	key := fmt.Sprintf("%s.%s", typ.Name(), alias)
+	client := c.GetHeader(core.HeaderUserAgent)
+	if client == "" {
+		client = "unknown"
+	}
+
+	aliasUsageMu.Lock()
+	if aliasUsage[key] == nil {
+		aliasUsage[key] = map[string]uint64{}
+	}
+	aliasUsage[key][client]++
+	aliasUsageMu.Unlock()
+
+	log.Infof("Legacy field %s used by %s", key, client)
+}
+
+// renameAliases renames the legacy keys of a JSON body to the current names of the struct type, and returns
+// the rewritten body with the legacy keys found in it, sorted.
+func renameAliases(body []byte, typ reflect.Type) ([]byte, []string) {
+	aliases := fieldAliases(typ)
+	if len(aliases) == 0 {
+		return body, nil
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil {
+		return body, nil
+	}
+
+	var used []string
+	for alias, name := range aliases {
+		value, ok := document[alias]
+		if !ok {
+			continue
+		}
+
+		delete(document, alias)
+		if _, exists := document[name]; !exists {
+			document[name] = value
+		}
+		used = append(used, alias)
+	}
+	if len(used) == 0 {
+		return body, nil
+	}
+
+	renamed, err := json.Marshal(document)
+	if err != nil {
+		return body, nil
+	}
+	sort.Strings(used)
+
+	return renamed, used
+}
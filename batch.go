@@ -0,0 +1,155 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================== Batch Requests ==========================
+// ====================================================================
+
+// BatchMaxRequests maximum number of sub-requests in a batch.
+var BatchMaxRequests = 20
+
+// BatchItem struct to describe a sub-request of a batch.
+// @Description Sub-request of a batch, routed through the normal request pipeline
+// @Method Method is the HTTP method of the sub-request
+// @Path Path is the path of the sub-request, query string included
+// @Body Body is the optional JSON body of the sub-request
+// @Tags Batch
+type BatchItem struct {
+	Method string          `json:"method" example:"GET" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	Path   string          `json:"path" example:"/api/v1/users/1" validate:"required,startswith=/"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchRequest struct to describe a batch envelope.
+// @Description Batch of sub-requests executed in order
+// @Tags Batch
+type BatchRequest struct {
+	Requests []BatchItem `json:"requests" validate:"required,min=1,dive"`
+}
+
+// BatchItemResponse struct to describe the response of a sub-request.
+// @Description Response of a sub-request of a batch
+// @Status Status is the HTTP status code of the sub-request
+// @Body Body is the Success, List or Error envelope returned by the sub-request
+// @Tags Batch
+type BatchItemResponse struct {
+	Status int             `json:"status" example:"200"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// BatchResponse struct to describe the responses of a batch, in the order of the sub-requests.
+// @Description Responses of a batch
+// @Tags Batch
+type BatchResponse struct {
+	Responses []BatchItemResponse `json:"responses"`
+}
+
+// batchDataKeys request-scoped Ctx's Data keys reset between sub-requests.
+var batchDataKeys = []string{
+	DataKey, PathIDKey, RequestKey, FilterKey, WarningsKey, DeltaSinceKey, FieldsKey, FieldMaskKey,
+	FingerprintKey, EventsKey, MirrorKey, ValidationKey, UploadsKey, StreamedUploadsKey,
+	DumpKey, SupportReferenceKey, TenantConfigKey, DryRunKey, PayloadKeyKey, QuotaKey, LintKey,
+}
+
+// BatchApi handler dispatching the sub-requests of a batch envelope through the router,
+// so each one runs its route's middlewares, Validate and Handle as a standalone request would.
+// Sub-requests share the headers (authentication included) of the batch request.
+type BatchApi struct {
+	core.Endpoint
+	router *core.Router
+}
+
+// NewBatchApi creates the batch handler for the application's router.
+//
+// Example Usage:
+//
+//	app.POST("/api/v1/batch", http.NewBatchApi(app.Router()))
+func NewBatchApi(router *core.Router) *BatchApi {
+	return &BatchApi{router: router}
+}
+
+// Validate parses and checks the batch envelope.
+func (h *BatchApi) Validate(c *core.Ctx) error {
+	var requestData BatchRequest
+	if errData := Parse(c, &requestData); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+
+	if errData := Validate(requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	if maxRequests := RequestConfig(c).BatchMaxRequests; len(requestData.Requests) > maxRequests {
+		return c.Error(&Error{
+			Message: fmt.Sprintf("A batch can not contain more than %d requests", maxRequests),
+		}, FailureStatus(c, FailureValidation))
+	}
+
+	batchPath := string(c.Root().Path())
+	for _, item := range requestData.Requests {
+		if path, _, _ := strings.Cut(item.Path, "?"); path == batchPath {
+			return c.Error(&Error{
+				Message: "Batch requests can not be nested",
+			}, FailureStatus(c, FailureBusinessRule))
+		}
+	}
+
+	c.SetData(RequestKey, requestData)
+
+	return nil
+}
+
+// Handle runs the sub-requests in order and responds with their responses.
+func (h *BatchApi) Handle(c *core.Ctx) error {
+	requestData := c.GetData(RequestKey).(BatchRequest)
+	root := c.Root()
+
+	// Keep the batch request to restore it once sub-requests are done
+	var batch fasthttp.Request
+	root.Request.CopyTo(&batch)
+
+	responses := make([]BatchItemResponse, 0, len(requestData.Requests))
+	for _, item := range requestData.Requests {
+		for _, key := range batchDataKeys {
+			c.SetData(key, nil)
+		}
+		root.ResetUserValues()
+		root.Response.Reset()
+
+		root.Request.Header.SetMethod(item.Method)
+		root.Request.SetRequestURI(item.Path)
+		root.Request.SetBody(item.Body)
+		root.Request.Header.SetContentType(core.MIMEApplicationJSON)
+
+		_ = h.router.Handler(c)
+
+		body := root.Response.Body()
+		if len(body) == 0 {
+			body = []byte("null")
+		} else if !json.Valid(body) {
+			body, _ = json.Marshal(string(body))
+		}
+
+		responses = append(responses, BatchItemResponse{
+			Status: root.Response.StatusCode(),
+			Body:   append(json.RawMessage(nil), body...),
+		})
+	}
+
+	batch.CopyTo(&root.Request)
+	root.ResetUserValues()
+	root.Response.Reset()
+	for _, key := range batchDataKeys {
+		c.SetData(key, nil)
+	}
+
+	return c.Success(BatchResponse{Responses: responses})
+}
@@ -0,0 +1,150 @@
+package http
+
+import "testing"
+
+// benchmarkCodecs codecs compared by the benchmarks. Other codecs are compared by adding them here, e.g.
+// sonic.ConfigStd or jsoniter.ConfigCompatibleWithStandardLibrary; they are not dependencies of the package.
+var benchmarkCodecs = map[string]Codec{
+	"std": StdCodec,
+}
+
+// benchmarkBody request body of the Parse benchmarks.
+const benchmarkBody = `{"id": 42, "small": 7, "count": 300, "price": 19.99, "name": "John Doe", "email": "john@example.com",
+	"tags": ["a", "b", "c"], "labels": {"team": "core", "tier": "gold"}, "at": "2024-05-01T10:00:00Z",
+	"optional": "set", "nested": {"slug": "Hello World", "ratio": 0.5}}`
+
+// withCodec runs a benchmark with each codec registered.
+func withCodec(b *testing.B, benchmarkFn func(b *testing.B)) {
+	defer RegisterCodec(codec)
+
+	for name, benchmarkCodec := range benchmarkCodecs {
+		RegisterCodec(benchmarkCodec)
+		b.Run(name, benchmarkFn)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	withCodec(b, func(b *testing.B) {
+		c := newFuzzCtx(b, "POST", "/bench", benchmarkBody)
+		b.ReportAllocs()
+
+		for b.Loop() {
+			var requestData fuzzDTO
+			if errData := Parse(c, &requestData); errData != nil {
+				b.Fatal(errData.Message)
+			}
+		}
+	})
+}
+
+func BenchmarkWriteSuccess(b *testing.B) {
+	withCodec(b, func(b *testing.B) {
+		c := newFuzzCtx(b, "GET", "/bench", "")
+		data := Success{Message: "User found", Data: map[string]any{"user": benchmarkRecord(1)}}
+		b.ReportAllocs()
+
+		for b.Loop() {
+			c.Root().Response.Reset()
+			if err := WriteSuccess(c, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkWriteList(b *testing.B) {
+	withCodec(b, func(b *testing.B) {
+		c := newFuzzCtx(b, "GET", "/bench", "")
+		records := make([]fuzzDTO, 100)
+		for i := range records {
+			records[i] = benchmarkRecord(i)
+		}
+		data := List[fuzzDTO]{Meta: Meta{Page: 1, PerPage: len(records), Total: 1000}, Data: records}
+		b.ReportAllocs()
+
+		for b.Loop() {
+			c.Root().Response.Reset()
+			if err := WriteList(c, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchmarkRecord returns a record of the response benchmarks.
+func benchmarkRecord(id int) fuzzDTO {
+	record := fuzzDTO{
+		ID:     int64(id),
+		Price:  19.99,
+		Name:   "John Doe",
+		Email:  "john@example.com",
+		Tags:   []string{"a", "b", "c"},
+		Labels: map[string]string{"team": "core"},
+	}
+	record.Nested.Slug = "hello-world"
+
+	return record
+}
+
+// benchmarkWideDTO DTO of 30 fields for the validation benchmarks.
+type benchmarkWideDTO struct {
+	Name01 string            `json:"name01" validate:"required,max=64"`
+	Name02 string            `json:"name02" validate:"required,max=64"`
+	Name03 string            `json:"name03" validate:"omitempty,max=64"`
+	Name04 string            `json:"name04" validate:"omitempty,max=64"`
+	Name05 string            `json:"name05" validate:"omitempty,max=64" sanitize:"lower"`
+	Email1 string            `json:"email1" validate:"required,email"`
+	Email2 string            `json:"email2" validate:"omitempty,email"`
+	URL1   string            `json:"url1" validate:"omitempty,url"`
+	URL2   string            `json:"url2" validate:"omitempty,url"`
+	Code1  string            `json:"code1" validate:"required,country"`
+	Code2  string            `json:"code2" validate:"omitempty,currency"`
+	Phone  string            `json:"phone" validate:"omitempty,phone"`
+	Slug   string            `json:"slug" sanitize:"slug=name01"`
+	Role   string            `json:"role" validate:"oneof=user admin"`
+	Status string            `json:"status" validate:"oneof=new paid shipped"`
+	Int01  int               `json:"int01" validate:"min=0,max=1000"`
+	Int02  int               `json:"int02" validate:"min=0,max=1000"`
+	Int03  int               `json:"int03" validate:"gte=0"`
+	Int04  int64             `json:"int04" validate:"gte=0"`
+	Int05  int64             `json:"int05"`
+	Flt01  float64           `json:"flt01" validate:"gte=0,lte=100"`
+	Flt02  float64           `json:"flt02" validate:"gte=0,lte=100"`
+	Flt03  float64           `json:"flt03"`
+	Bool1  bool              `json:"bool1"`
+	Bool2  bool              `json:"bool2"`
+	Tags   []string          `json:"tags" validate:"max=10,dive,max=20"`
+	Labels map[string]string `json:"labels" validate:"max=10"`
+	Note1  string            `json:"note1" validate:"max=500"`
+	Note2  string            `json:"note2" validate:"max=500"`
+	Note3  string            `json:"note3"`
+}
+
+// benchmarkWide returns a valid benchmarkWideDTO.
+func benchmarkWide() benchmarkWideDTO {
+	return benchmarkWideDTO{
+		Name01: "John", Name02: "Doe", Name05: "Mixed", Email1: "john@example.com", URL1: "https://example.com",
+		Code1: "VN", Code2: "USD", Phone: "+84901234567", Role: "user", Status: "paid", Int01: 10, Int02: 20,
+		Flt01: 1.5, Flt02: 99, Tags: []string{"a", "b"}, Labels: map[string]string{"k": "v"}, Note1: "note",
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	requestData := benchmarkWide()
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if errData := Validate(requestData); errData != nil {
+			b.Fatal(errData.Data)
+		}
+	}
+}
+
+func BenchmarkSanitizeStruct(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		requestData := benchmarkWide()
+		SanitizeStruct(&requestData)
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Query Binding ===========================
+// ====================================================================
+
+// BindQuery maps the query parameters into the fields of a struct tagged `query:"name"`, converting them to
+// ints, uints, floats, bools, times (RFC 3339, or the layout given with `query:"name,layout=2006-01-02"` or a
+// `timeFormat:"2006-01-02"` tag, in the location of a `timeZone:"Europe/Berlin"` tag),
+// encoding.TextUnmarshaler values, pointers and slices. Slices take each value of a repeated parameter
+// (`?status=new&status=paid`) or the comma-separated items of a single one (`?status=new,paid`). Parameters
+// absent from the query leave their field zero. Unconvertible values are returned as an error per parameter.
+// Parameters are read with QueryValues, normalized by the registered QueryOptions.
+//
+// The struct is neither sanitized nor validated, see SanitizeStruct and ValidateRequest.
+//
+// Example Usage:
+//
+//	type ListOrdersQuery struct {
+//		Status []string   `query:"status" validate:"dive,oneof=new paid shipped"`
+//		Since  *time.Time `query:"since,layout=2006-01-02"`
+//		Paid   *bool      `query:"paid"`
+//		Limit  int        `query:"limit" validate:"omitempty,max=100"`
+//	}
+//
+//	query, errData := http.BindQuery[ListOrdersQuery](c)
+//	if errData != nil {
+//		return c.Error(errData)
+//	}
+func BindQuery[T any](c *core.Ctx) (*T, *Error) {
+	return bindFields[T](SourceQuery, func(name string) []string {
+		return QueryValues(c, name)
+	})
+}
+
+// bindFields sets the fields of a new struct read from the source (SourceQuery or SourceHeader) from the raw
+// values returned by valuesFn.
+func bindFields[T any](source string, valuesFn func(name string) []string) (*T, *Error) {
+	var structData T
+
+	value := reflect.ValueOf(&structData).Elem()
+	if value.Kind() != reflect.Struct {
+		return &structData, nil
+	}
+
+	errorData := core.Data{}
+	for _, field := range sourceFields(value.Type()) {
+		if field.source != source {
+			continue
+		}
+
+		raws := valuesFn(field.name)
+		if len(raws) == 0 {
+			continue
+		}
+
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			// Field of a nil embedded struct pointer
+			continue
+		}
+
+		if field.layout != "" {
+			err = setTimeValue(fieldValue, raws[0], field.layout, field.location)
+		} else {
+			err = setSourceValue(fieldValue, raws, false)
+		}
+		if err != nil {
+			errorData[field.name] = []string{err.Error()}
+		}
+	}
+
+	if len(errorData) > 0 {
+		return nil, &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	return &structData, nil
+}
+
+// setTimeValue parses a raw value with a layout into a time or time pointer field, in the location when the
+// layout has no offset.
+func setTimeValue(value reflect.Value, raw, layout string, location *time.Location) error {
+	parsed, err := time.ParseInLocation(layout, raw, location)
+	if err != nil {
+		return errors.New("must be date time formatted as " + layout)
+	}
+
+	switch value.Type() {
+	case timeType:
+		value.Set(reflect.ValueOf(parsed))
+	case reflect.PointerTo(timeType):
+		value.Set(reflect.ValueOf(&parsed))
+	default:
+		return errors.New("has layout but is not a time")
+	}
+
+	return nil
+}
+
+// ====================================================================
+// ========================== Header Binding ==========================
+// ====================================================================
+
+// BindHeaders maps the request headers into the fields of a struct tagged `header:"X-Tenant-ID"`, with the
+// conversions of BindQuery (repeated headers and comma-separated values fill slices), then sanitizes and
+// validates the struct. Header names are case-insensitive. Unconvertible values are returned as an error per
+// header, invalid values as validation errors.
+//
+// Example Usage:
+//
+//	type ClientHeaders struct {
+//		TenantID      string   `header:"X-Tenant-ID" json:"tenant_id" validate:"required"`
+//		ClientVersion string   `header:"X-Client-Version" json:"client_version" validate:"omitempty,semver"`
+//		Languages     []string `header:"Accept-Language" json:"languages"`
+//	}
+//
+//	headers, errData := http.BindHeaders[ClientHeaders](c)
+//	if errData != nil {
+//		return c.Error(errData)
+//	}
+func BindHeaders[T any](c *core.Ctx) (*T, *Error) {
+	structData, errData := bindFields[T](SourceHeader, func(name string) []string {
+		var raws []string
+		for _, raw := range c.Root().Request.Header.PeekAll(name) {
+			raws = append(raws, string(raw))
+		}
+
+		return raws
+	})
+	if errData != nil {
+		return nil, errData
+	}
+
+	sanitizeRequest(c, structData)
+
+	if errData := ValidateRequest(c, *structData); errData != nil {
+		return nil, errData
+	}
+
+	return structData, nil
+}
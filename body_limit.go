@@ -0,0 +1,80 @@
+package http
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================ Body Limit ============================
+// ====================================================================
+
+// ErrorCodeBodyTooLarge code of the Error returned by Parse for bodies larger than ParseOptions.MaxBodySize.
+const ErrorCodeBodyTooLarge = "BODY_TOO_LARGE"
+
+// DefaultMaxBodySize maximum size of the bodies decoded by Parse when ParseOptions.MaxBodySize is zero, the
+// default MaxRequestBodySize of the server.
+const DefaultMaxBodySize = 4 << 20
+
+// ParseStatus returns the HTTP status of an Error returned by Parse: 413 for BODY_TOO_LARGE, else the status of
+// unknown fields or malformed requests of the current StatusPolicy (400 by default). The Process helpers apply
+// the StatusPolicy of the request's configuration instead (see FailureStatus).
+//
+// Example Usage:
+//
+//	if errData := http.Parse(c, &requestData); errData != nil {
+//		return c.Error(errData, http.ParseStatus(errData))
+//	}
+func ParseStatus(errData *Error) int {
+	return parseStatus(nil, errData)
+}
+
+// limitBody checks the request body does not exceed the maximum size (DefaultMaxBodySize when zero, unlimited
+// when negative). Declared lengths are checked before reading, and streamed bodies are read up to the limit
+// only, so oversized payloads are never held in memory.
+func limitBody(c *core.Ctx, maxSize int64) *Error {
+	if maxSize == 0 {
+		maxSize = DefaultMaxBodySize
+	}
+	if maxSize < 0 {
+		return nil
+	}
+
+	root := c.Root()
+	if int64(root.Request.Header.ContentLength()) > maxSize {
+		return bodyTooLarge(maxSize)
+	}
+
+	stream := root.RequestBodyStream()
+	if stream == nil {
+		// Chunked bodies have no declared length
+		if int64(len(root.PostBody())) > maxSize {
+			return bodyTooLarge(maxSize)
+		}
+
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(stream, maxSize+1))
+	if err != nil {
+		return &Error{
+			Message: err.Error(),
+		}
+	}
+	if int64(len(body)) > maxSize {
+		return bodyTooLarge(maxSize)
+	}
+	root.Request.SetBody(body)
+
+	return nil
+}
+
+// bodyTooLarge returns the BODY_TOO_LARGE Error of the maximum size.
+func bodyTooLarge(maxSize int64) *Error {
+	return &Error{
+		Code:    ErrorCodeBodyTooLarge,
+		Message: fmt.Sprintf("Request body must be at most %d bytes", maxSize),
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ====================================================================
+// =========================== Buffer Pools ===========================
+// ====================================================================
+
+// Pool metrics reported to the registered Metrics backend.
+const (
+	MetricBufferGets     = "http_buffer_pool_gets_total"     // Labels: class
+	MetricBufferMisses   = "http_buffer_pool_misses_total"   // Labels: class
+	MetricBufferDiscards = "http_buffer_pool_discards_total" // Labels: class
+)
+
+// bufferClasses capacities of the pooled buffers, a buffer is pooled in the largest class its capacity reaches.
+var bufferClasses = [...]int{4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// MaxPooledBuffer capacity above which response buffers are not pooled, so a few huge responses do not pin
+// their memory. Buffers are dropped to the garbage collector instead.
+var MaxPooledBuffer = 4 << 20
+
+// bufferClass pool of a size class and its statistics.
+type bufferClass struct {
+	pool     sync.Pool
+	labels   map[string]string
+	gets     atomic.Uint64
+	misses   atomic.Uint64
+	discards atomic.Uint64
+}
+
+// bufferPools pools of the classes of bufferClasses.
+var bufferPools = func() []*bufferClass {
+	pools := make([]*bufferClass, len(bufferClasses))
+	for i, size := range bufferClasses {
+		pool := &bufferClass{labels: map[string]string{"class": strconv.Itoa(size)}}
+		pool.pool.New = func() any {
+			pool.misses.Add(1)
+			incCounter(MetricBufferMisses, pool.labels)
+
+			return bytes.NewBuffer(make([]byte, 0, size))
+		}
+		pools[i] = pool
+	}
+
+	return pools
+}()
+
+// BufferClassStats statistics of a buffer size class.
+type BufferClassStats struct {
+	Size     int    `json:"size"`     // Capacity of the class's buffers
+	Gets     uint64 `json:"gets"`     // Buffers acquired
+	Misses   uint64 `json:"misses"`   // Buffers allocated as the pool was empty
+	Discards uint64 `json:"discards"` // Buffers released above MaxPooledBuffer and not pooled
+}
+
+// BufferStats returns the statistics of the response buffer pools by size class. A high ratio of misses to
+// gets tells responses of the class outgrow the pool, e.g. after a traffic spike.
+//
+// Example Usage:
+//
+//	for _, class := range http.BufferStats() {
+//		log.Infof("buffers of %d bytes: %d gets, %d misses", class.Size, class.Gets, class.Misses)
+//	}
+func BufferStats() []BufferClassStats {
+	stats := make([]BufferClassStats, len(bufferPools))
+	for i, pool := range bufferPools {
+		stats[i] = BufferClassStats{
+			Size:     bufferClasses[i],
+			Gets:     pool.gets.Load(),
+			Misses:   pool.misses.Load(),
+			Discards: pool.discards.Load(),
+		}
+	}
+
+	return stats
+}
+
+// acquireBuffer returns an empty buffer of at least the size hint's capacity, from the smallest fitting class.
+func acquireBuffer(sizeHint int) *bytes.Buffer {
+	for i, size := range bufferClasses {
+		if sizeHint <= size {
+			pool := bufferPools[i]
+			pool.gets.Add(1)
+			incCounter(MetricBufferGets, pool.labels)
+
+			return pool.pool.Get().(*bytes.Buffer)
+		}
+	}
+
+	return bytes.NewBuffer(make([]byte, 0, sizeHint))
+}
+
+// encodedSizes size of the last encoding by type, the size hint of the next encoding of the type.
+var encodedSizes sync.Map
+
+// encodedSize returns the size of the last encoding of a type.
+func encodedSize(typ reflect.Type) *atomic.Int64 {
+	if size, ok := encodedSizes.Load(typ); ok {
+		return size.(*atomic.Int64)
+	}
+
+	size, _ := encodedSizes.LoadOrStore(typ, new(atomic.Int64))
+
+	return size.(*atomic.Int64)
+}
+
+// releaseBuffer returns a buffer to the pool of the largest class its capacity reaches. The buffer must not
+// be used afterward, nor the slices returned by its Bytes.
+func releaseBuffer(buffer *bytes.Buffer) {
+	capacity := buffer.Cap()
+	if capacity < bufferClasses[0] {
+		return
+	}
+
+	class := len(bufferClasses) - 1
+	for class > 0 && capacity < bufferClasses[class] {
+		class--
+	}
+	pool := bufferPools[class]
+
+	if capacity > MaxPooledBuffer {
+		pool.discards.Add(1)
+		incCounter(MetricBufferDiscards, pool.labels)
+
+		return
+	}
+
+	buffer.Reset()
+	pool.pool.Put(buffer)
+}
@@ -0,0 +1,409 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================= Response Cache ===========================
+// ====================================================================
+
+// CacheStatusHeader response header reporting whether the response was served from the cache (HIT or MISS).
+const CacheStatusHeader = "X-Cache"
+
+// CacheStore is an interface for storages of rendered responses (memory, Redis, ...).
+type CacheStore interface {
+	// Get returns the value stored for the key, and false when it is missing or expired.
+	Get(key string) ([]byte, bool)
+
+	// Set stores the value for the given time-to-live.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// DeletePrefix removes all values whose key starts with the prefix.
+	DeletePrefix(prefix string) error
+}
+
+// cacheStore the store used by Cached handlers.
+var cacheStore CacheStore = NewMemoryCacheStore()
+
+// RegisterCacheStore registers the store used by Cached handlers. A MemoryCacheStore is used by default.
+func RegisterCacheStore(store CacheStore) {
+	cacheStore = store
+}
+
+// CacheOptions configuration of a Cached handler.
+type CacheOptions struct {
+	Resource  string                   // Resource name, used by InvalidateCache
+	TTL       time.Duration            // Time-to-live of cached responses
+	StaleTTL  time.Duration            // Window after TTL during which stale responses are served during a refresh
+	ScopeFn   func(c *core.Ctx) string // Caller scope (e.g. user or tenant ID), CallerIdentity when nil
+	Shared    bool                     // Responses shared by all callers when ScopeFn is nil, e.g. public catalogs
+	VersionFn func(c *core.Ctx) string // API version of the request, optional
+}
+
+// cachedHandler handler wrapper serving GET responses from the cache store.
+type cachedHandler struct {
+	core.IHandler
+	options CacheOptions
+}
+
+// cacheRefreshing keys of stale entries being refreshed.
+var cacheRefreshing sync.Map
+
+// Cached wraps a handler so its successful GET responses are cached. The key is built from the resource,
+// the caller scope, the API version, the path and the canonical query string (filter, field selection, ...),
+// so only callers sharing a scope share responses: without ScopeFn, each caller (see CallerIdentity) has its own
+// responses unless Shared is set. The wrapped handler's Validate still runs on every request.
+//
+// With a StaleTTL, entries older than TTL are still served during the StaleTTL window (stale-while-revalidate):
+// the first request reaching a stale entry runs the wrapped handler and refreshes it, while the requests arriving
+// during the refresh are sent the stale response immediately, with Age and Warning headers and `meta.stale` set.
+//
+// Example Usage:
+//
+//	router.GET("/products", http.Cached(api.NewListProductsApi(), http.CacheOptions{
+//		Resource: "products",
+//		TTL:      time.Minute,
+//		StaleTTL: 5 * time.Minute,
+//		ScopeFn:  func(c *core.Ctx) string { return tenantID(c) },
+//	}))
+func Cached(handler core.IHandler, options CacheOptions) core.IHandler {
+	return &cachedHandler{
+		IHandler: handler,
+		options:  options,
+	}
+}
+
+// Handle serves the cached response or runs the wrapped handler and stores its response.
+func (h *cachedHandler) Handle(c *core.Ctx) error {
+	if !c.Root().IsGet() {
+		return h.IHandler.Handle(c)
+	}
+
+	key := CacheKey(c, h.options)
+
+	var value []byte
+	var ok bool
+	if err := guard(func() { value, ok = cacheStore.Get(key) }); err != nil {
+		// Served uncached under FailOpen
+		if err := degrade(c, SubsystemCache, err); err != nil {
+			return err
+		}
+	}
+
+	if ok {
+		if entry, valid := decodeCacheEntry(value); valid {
+			age := time.Since(entry.storedAt)
+			if age <= h.options.TTL {
+				entry.write(&c.Root().Response, "HIT", age)
+				replayEncryption(c, &c.Root().Response)
+
+				return nil
+			}
+
+			if _, refreshing := cacheRefreshing.LoadOrStore(key, true); refreshing {
+				// Another request is already refreshing the entry
+				entry.write(&c.Root().Response, "STALE", age)
+				replayEncryption(c, &c.Root().Response)
+
+				return nil
+			}
+
+			// This request refreshes the entry, the concurrent ones are served the stale response meanwhile
+			defer cacheRefreshing.Delete(key)
+		}
+	}
+
+	if err := h.IHandler.Handle(c); err != nil {
+		return err
+	}
+
+	c.SetHeader(CacheStatusHeader, "MISS")
+	if err := h.store(c, key); err != nil {
+		return degrade(c, SubsystemCache, err)
+	}
+
+	return nil
+}
+
+// store saves the response of the request when it can be shared.
+func (h *cachedHandler) store(c *core.Ctx, key string) (err error) {
+	response := &c.Root().Response
+
+	// Only plain successful responses are shared
+	if response.StatusCode() != core.StatusOK || len(response.Header.Peek(core.HeaderSetCookie)) > 0 {
+		return nil
+	}
+
+	entry := cacheEntry{
+		storedAt:    time.Now(),
+		contentType: response.Header.ContentType(),
+		body:        response.Body(),
+	}
+	ttl := h.options.TTL + h.options.StaleTTL
+	if panicErr := guard(func() { err = cacheStore.Set(key, entry.encode(), ttl) }); panicErr != nil {
+		return panicErr
+	}
+
+	return err
+}
+
+// cacheEntry rendered response stored in the cache.
+type cacheEntry struct {
+	storedAt    time.Time
+	contentType []byte
+	body        []byte
+}
+
+// encode serializes the entry as "<stored at>\n<content type>\n<body>".
+func (e cacheEntry) encode() []byte {
+	value := strconv.AppendInt(nil, e.storedAt.UnixNano(), 10)
+	value = append(append(value, '\n'), e.contentType...)
+
+	return append(append(value, '\n'), e.body...)
+}
+
+func decodeCacheEntry(value []byte) (cacheEntry, bool) {
+	storedAt, rest, found := bytes.Cut(value, []byte("\n"))
+	if !found {
+		return cacheEntry{}, false
+	}
+	contentType, body, found := bytes.Cut(rest, []byte("\n"))
+	if !found {
+		return cacheEntry{}, false
+	}
+	nanos, err := strconv.ParseInt(string(storedAt), 10, 64)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	return cacheEntry{storedAt: time.Unix(0, nanos), contentType: contentType, body: body}, true
+}
+
+// write sends the entry. Stale entries get a Warning header and `meta.stale` in JSON envelopes.
+func (e cacheEntry) write(response *fasthttp.Response, status string, age time.Duration) {
+	body := e.body
+	if status == "STALE" {
+		response.Header.Set(core.HeaderWarning, `110 - "Response is Stale"`)
+		body = markStale(body)
+	}
+
+	response.Header.Set(CacheStatusHeader, status)
+	response.Header.Set(core.HeaderAge, strconv.Itoa(int(age.Seconds())))
+	response.SetStatusCode(core.StatusOK)
+	response.Header.SetContentTypeBytes(e.contentType)
+	response.SetBody(body)
+}
+
+// replayEncryption sets the headers of encrypted responses on a cached response, which was encrypted for the
+// key of the request as the key is part of CacheKey.
+func replayEncryption(c *core.Ctx, response *fasthttp.Response) {
+	if payloadKeyID(c) != "" {
+		setEncryptionHeaders(&response.Header)
+	}
+}
+
+// markStale sets `meta.stale` in a JSON envelope having a meta object (List responses).
+func markStale(body []byte) []byte {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body
+	}
+
+	var meta map[string]any
+	if err := json.Unmarshal(envelope["meta"], &meta); err != nil || meta == nil {
+		return body
+	}
+	meta["stale"] = true
+
+	var err error
+	if envelope["meta"], err = json.Marshal(meta); err != nil {
+		return body
+	}
+	marked, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+
+	return marked
+}
+
+// CacheKey builds the cache key of the request: resource, scope, version, path and canonical query string.
+// Query parameters are sorted, and the items of the `fields` parameter too, so equivalent requests share a key.
+// MessagePack responses (see PrefersMsgpack) and media codec ones (see RegisterMediaCodec) are cached apart from
+// JSON ones, and encrypted responses (see EncryptResponse) apart for each client key.
+func CacheKey(c *core.Ctx, options CacheOptions) string {
+	scope, version := "", ""
+	if options.ScopeFn != nil {
+		scope = options.ScopeFn(c)
+	} else if !options.Shared {
+		scope = CallerIdentity(c)
+	}
+	if options.VersionFn != nil {
+		version = options.VersionFn(c)
+	}
+
+	key := strings.Join([]string{
+		options.Resource,
+		scope,
+		version,
+		string(c.Root().Path()) + "?" + canonicalQuery(c),
+	}, "|")
+	if format := responseFormat(c); format != "" {
+		key += "#" + format
+	}
+	if keyID := payloadKeyID(c); keyID != "" {
+		key += "#jwe:" + keyID
+	}
+
+	return key
+}
+
+// InvalidateCache removes the cached responses of a resource, for all scopes or only the given one.
+// It is called from mutation flows once the change is committed.
+//
+// Example Usage:
+//
+//	if err := productRepository.Update(product); err != nil { ... }
+//	_ = http.InvalidateCache("products", tenantID(c))
+func InvalidateCache(resource string, scope ...string) error {
+	prefix := resource + "|"
+	if len(scope) > 0 {
+		prefix += scope[0] + "|"
+	}
+
+	return cacheStore.DeletePrefix(prefix)
+}
+
+// ====================================================================
+// ======================= Memory Cache Store =========================
+// ====================================================================
+
+// MemoryCacheMaxEntries default maximum number of entries of a MemoryCacheStore.
+var MemoryCacheMaxEntries = 10000
+
+// memoryCacheSweepInterval minimum time between two purges of the expired entries of a MemoryCacheStore.
+const memoryCacheSweepInterval = time.Minute
+
+// memoryCacheEntry a value of MemoryCacheStore.
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCacheStore in-process CacheStore. Expired entries are purged at most every minute when values are set,
+// and the least recently used entries are evicted beyond MaxEntries.
+type MemoryCacheStore struct {
+	// MaxEntries maximum number of entries, unlimited when zero. Set it before the store is used.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Elements of recency, holding *memoryCacheEntry
+	recency *list.List               // Most recently used first
+	sweptAt time.Time
+}
+
+// NewMemoryCacheStore creates an empty MemoryCacheStore holding up to MemoryCacheMaxEntries entries.
+//
+// Example Usage:
+//
+//	store := http.NewMemoryCacheStore()
+//	store.MaxEntries = 50000
+//	http.RegisterCacheStore(store)
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		MaxEntries: MemoryCacheMaxEntries,
+		entries:    map[string]*list.Element{},
+		recency:    list.New(),
+		sweptAt:    time.Now(),
+	}
+}
+
+// Get returns the value stored for the key, and false when it is missing or expired.
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.remove(element)
+
+		return nil, false
+	}
+	s.recency.MoveToFront(element)
+
+	return entry.value, true
+}
+
+// Set stores the value for the given time-to-live, evicting the least recently used entries beyond MaxEntries.
+func (s *MemoryCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.sweptAt) >= memoryCacheSweepInterval {
+		s.sweep(now)
+	}
+
+	entry := &memoryCacheEntry{key: key, value: value, expiresAt: now.Add(ttl)}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.recency.MoveToFront(element)
+	} else {
+		s.entries[key] = s.recency.PushFront(entry)
+	}
+
+	for s.MaxEntries > 0 && len(s.entries) > s.MaxEntries {
+		s.remove(s.recency.Back())
+	}
+
+	return nil
+}
+
+// sweep removes the expired entries.
+func (s *MemoryCacheStore) sweep(now time.Time) {
+	s.sweptAt = now
+
+	for element := s.recency.Front(); element != nil; {
+		next := element.Next()
+		if now.After(element.Value.(*memoryCacheEntry).expiresAt) {
+			s.remove(element)
+		}
+		element = next
+	}
+}
+
+// remove removes the entry of an element.
+func (s *MemoryCacheStore) remove(element *list.Element) {
+	s.recency.Remove(element)
+	delete(s.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// DeletePrefix removes all values whose key starts with the prefix.
+func (s *MemoryCacheStore) DeletePrefix(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================== Canary Comparison =========================
+// ====================================================================
+
+// ComparisonResult outcome of the comparison of a primary response with its shadow response.
+type ComparisonResult struct {
+	Route         string   // Method and route path template, e.g. "GET /users/{id}"
+	PrimaryStatus int      // Status of the primary response
+	ShadowStatus  int      // Status of the shadow response
+	Differences   []string // Differing field paths with both values, empty when the responses match
+}
+
+// Matched checks the shadow response matches the primary one.
+func (r ComparisonResult) Matched() bool {
+	return r.PrimaryStatus == r.ShadowStatus && len(r.Differences) == 0
+}
+
+// ResponseComparator compares the responses of mirrored requests (see MirrorOptions.Compare) after
+// normalizing them: JSON bodies are compared structurally, ignoring the volatile fields.
+type ResponseComparator struct {
+	// IgnoreFields volatile fields: a name ("updated_at") ignores the field at any depth, a path
+	// ("meta.request_id", "data[].created_at") ignores one field, "[]" standing for any array index.
+	IgnoreFields []string
+	// OnMismatch optional callback receiving mismatching results, they are logged otherwise.
+	OnMismatch func(result ComparisonResult)
+
+	matched    atomic.Uint64
+	mismatched atomic.Uint64
+
+	mu              sync.Mutex
+	routeMismatches map[string]uint64
+}
+
+// Compare compares a primary response with its shadow response and records the outcome.
+func (rc *ResponseComparator) Compare(route string, primaryStatus int, primaryBody []byte,
+	shadowStatus int, shadowBody []byte) ComparisonResult {
+	result := ComparisonResult{
+		Route:         route,
+		PrimaryStatus: primaryStatus,
+		ShadowStatus:  shadowStatus,
+		Differences:   DiffResponses(primaryBody, shadowBody, rc.IgnoreFields),
+	}
+
+	if result.Matched() {
+		rc.matched.Add(1)
+
+		return result
+	}
+
+	rc.mismatched.Add(1)
+	rc.mu.Lock()
+	if rc.routeMismatches == nil {
+		rc.routeMismatches = map[string]uint64{}
+	}
+	rc.routeMismatches[route]++
+	rc.mu.Unlock()
+
+	if rc.OnMismatch != nil {
+		rc.OnMismatch(result)
+	} else {
+		log.Warnf("Canary mismatch on %s: status %d/%d, %s", route, primaryStatus, shadowStatus,
+			strings.Join(result.Differences, "; "))
+	}
+
+	return result
+}
+
+// Stats returns the number of matching and mismatching comparisons.
+func (rc *ResponseComparator) Stats() (matched, mismatched uint64) {
+	return rc.matched.Load(), rc.mismatched.Load()
+}
+
+// RouteMismatches returns the number of mismatching comparisons by route.
+func (rc *ResponseComparator) RouteMismatches() map[string]uint64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	mismatches := make(map[string]uint64, len(rc.routeMismatches))
+	for route, count := range rc.routeMismatches {
+		mismatches[route] = count
+	}
+
+	return mismatches
+}
+
+// DiffResponses returns the differences between two response bodies, sorted by field path. JSON bodies are
+// compared structurally (key order and number formatting do not matter) without the ignored fields;
+// other bodies are compared byte for byte.
+func DiffResponses(primary, shadow []byte, ignoreFields []string) []string {
+	var primaryDocument, shadowDocument any
+	if !decodeJSONNumbers(primary, &primaryDocument) || !decodeJSONNumbers(shadow, &shadowDocument) {
+		if bytes.Equal(primary, shadow) {
+			return nil
+		}
+
+		return []string{"body differs"}
+	}
+
+	ignored := map[string]bool{}
+	for _, field := range ignoreFields {
+		ignored[field] = true
+	}
+
+	var differences []string
+	diffValues(primaryDocument, shadowDocument, "", "", ignored, &differences)
+	sort.Strings(differences)
+
+	return differences
+}
+
+// diffValues compares two decoded JSON values. path locates the value ("data[2].id"), pattern is the same
+// path with "[]" for array indexes ("data[].id"), matched against the ignored fields.
+func diffValues(primary, shadow any, path, pattern string, ignored map[string]bool, differences *[]string) {
+	primaryObject, primaryIsObject := primary.(map[string]any)
+	shadowObject, shadowIsObject := shadow.(map[string]any)
+	if primaryIsObject && shadowIsObject {
+		keys := map[string]bool{}
+		for key := range primaryObject {
+			keys[key] = true
+		}
+		for key := range shadowObject {
+			keys[key] = true
+		}
+
+		for key := range keys {
+			fieldPattern := joinPath(pattern, key)
+			if ignored[key] || ignored[fieldPattern] {
+				continue
+			}
+			diffValues(primaryObject[key], shadowObject[key], joinPath(path, key), fieldPattern, ignored, differences)
+		}
+
+		return
+	}
+
+	primaryArray, primaryIsArray := primary.([]any)
+	shadowArray, shadowIsArray := shadow.([]any)
+	if primaryIsArray && shadowIsArray {
+		if len(primaryArray) != len(shadowArray) {
+			*differences = append(*differences, fmt.Sprintf("%s: %d items, shadow %d items",
+				displayPath(path), len(primaryArray), len(shadowArray)))
+
+			return
+		}
+
+		for i := range primaryArray {
+			diffValues(primaryArray[i], shadowArray[i], fmt.Sprintf("%s[%d]", path, i), pattern+"[]", ignored, differences)
+		}
+
+		return
+	}
+
+	if primaryNumber, ok := primary.(json.Number); ok {
+		if shadowNumber, ok := shadow.(json.Number); ok && equalNumbers(primaryNumber, shadowNumber) {
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(primary, shadow) {
+		primaryJSON, _ := json.Marshal(primary)
+		shadowJSON, _ := json.Marshal(shadow)
+		*differences = append(*differences, fmt.Sprintf("%s: %s, shadow %s", displayPath(path), primaryJSON, shadowJSON))
+	}
+}
+
+// equalNumbers compares JSON numbers by value, so 1, 1.0 and 1e0 are equal.
+func equalNumbers(a, b json.Number) bool {
+	aValue, aOk := new(big.Rat).SetString(a.String())
+	bValue, bOk := new(big.Rat).SetString(b.String())
+
+	return aOk && bOk && aValue.Cmp(bValue) == 0
+}
+
+// decodeJSONNumbers decodes a JSON document keeping numbers as json.Number, it reports whether the body is JSON.
+func decodeJSONNumbers(body []byte, document *any) bool {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	return decoder.Decode(document) == nil && !decoder.More()
+}
+
+// displayPath returns the path of a difference, "(root)" for the document itself.
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"hash"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Payload Checksum =========================
+// ====================================================================
+
+// Checksum headers checked by VerifyChecksum.
+const (
+	HeaderContentMD5    = "Content-MD5"
+	HeaderDigest        = "Digest"
+	HeaderContentDigest = "Content-Digest"
+)
+
+// ErrorCodeIntegrity code of the Error returned when the body does not match its checksum.
+const ErrorCodeIntegrity = "INTEGRITY_ERROR"
+
+// checksumAlgorithms supported digest algorithms by lower-case name.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// VerifyChecksum verifies the request body against the checksum headers sent by the client:
+// Content-MD5 (base64 MD5), Digest (RFC 3230, e.g. "SHA-256=<base64>") and Content-Digest
+// (RFC 9530, e.g. "sha-256=:<base64>:"). Unsupported algorithms are ignored and requests without
+// checksum headers pass. It is called by Parse; upload endpoints can call it directly.
+//
+// Example Usage:
+//
+//	func (h UploadApi) Validate(c *core.Ctx) error {
+//		if errData := http.VerifyChecksum(c); errData != nil {
+//			return c.Error(errData)
+//		}
+//		...
+//	}
+func VerifyChecksum(c *core.Ctx) *Error {
+	body := c.Root().PostBody()
+
+	if value := c.GetHeader(HeaderContentMD5); value != "" {
+		if !matchChecksum(body, "md5", value) {
+			return integrityError(HeaderContentMD5)
+		}
+	}
+
+	for _, header := range []string{HeaderDigest, HeaderContentDigest} {
+		value := c.GetHeader(header)
+		if value == "" {
+			continue
+		}
+
+		for _, item := range strings.Split(value, ",") {
+			algorithm, encoded, found := strings.Cut(strings.TrimSpace(item), "=")
+			if !found {
+				continue
+			}
+
+			algorithm = strings.ToLower(algorithm)
+			if _, supported := checksumAlgorithms[algorithm]; !supported {
+				continue
+			}
+
+			// Content-Digest wraps values in colons (structured field byte sequence)
+			encoded = strings.Trim(encoded, ":")
+			if !matchChecksum(body, algorithm, encoded) {
+				return integrityError(header)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchChecksum checks the base64 checksum of the body computed with the algorithm.
+func matchChecksum(body []byte, algorithm, encoded string) bool {
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	hasher := checksumAlgorithms[algorithm]()
+	hasher.Write(body)
+
+	return subtle.ConstantTimeCompare(hasher.Sum(nil), expected) == 1
+}
+
+func integrityError(header string) *Error {
+	return &Error{
+		Code:    ErrorCodeIntegrity,
+		Message: "Request body does not match its " + header + " header",
+	}
+}
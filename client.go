@@ -0,0 +1,21 @@
+package http
+
+import (
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// =========================== HTTP Client ============================
+// ====================================================================
+
+// HTTPClient outgoing HTTP client of the package (request mirroring, ...). Replace it to change
+// timeouts, connection limits or TLS configuration.
+var HTTPClient = &fasthttp.Client{
+	Name:                "gfly-http",
+	ReadTimeout:         10 * time.Second,
+	WriteTimeout:        10 * time.Second,
+	MaxIdleConnDuration: time.Minute,
+	MaxConnsPerHost:     64,
+}
@@ -0,0 +1,93 @@
+// Command dtogen scaffolds Go DTOs and stub transformers from the schemas of an OpenAPI or JSON Schema document,
+// with the json, example, validate, sanitize and doc tags of github.com/gflydev/http (see http.GenerateDTOs).
+//
+// Usage:
+//
+//	go run github.com/gflydev/http/cmd/dtogen -in openapi.yaml -out internal/dto
+//	go run github.com/gflydev/http/cmd/dtogen -in openapi.yaml -out internal/dto -models github.com/acme/shop/internal/models
+//
+// The DTOs are written to dtos.go and the transformers to transformers.go in the output directory. Existing files
+// are kept unless -force is set.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gflydev/http"
+)
+
+func main() {
+	in := flag.String("in", "", "OpenAPI or JSON Schema document, JSON or YAML (required)")
+	out := flag.String("out", ".", "Output directory")
+	pkg := flag.String("package", "", "Package name of the generated files (default: name of the output directory)")
+	models := flag.String("models", "", "Import path of the models mapped by the transformers")
+	tag := flag.String("tag", "", "@Tags annotation of the generated types")
+	force := flag.Bool("force", false, "Overwrite existing files")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg, *models, *tag, *force); err != nil {
+		fmt.Fprintln(os.Stderr, "dtogen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg, models, tag string, force bool) error {
+	if in == "" {
+		flag.Usage()
+
+		return errors.New("-in is required")
+	}
+
+	document, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	if pkg == "" {
+		absolute, err := filepath.Abs(out)
+		if err != nil {
+			return err
+		}
+		pkg = filepath.Base(absolute)
+	}
+
+	generated, err := http.GenerateDTOs(document, http.DTOGeneratorOptions{
+		Package:      pkg,
+		ModelPackage: models,
+		Tag:          tag,
+		Source:       filepath.Base(in),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"dtos.go", generated.DTOs},
+		{"transformers.go", generated.Transformers},
+	}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(out, file.name)); err == nil && !force {
+			return fmt.Errorf("%s exists, use -force to overwrite it", filepath.Join(out, file.name))
+		}
+	}
+	for _, file := range files {
+		target := filepath.Join(out, file.name)
+		if err := os.WriteFile(target, file.content, 0o644); err != nil {
+			return err
+		}
+		fmt.Println("dtogen: wrote", target)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ======================= Request Coalescing =========================
+// ====================================================================
+
+// coalescedCall an in-flight execution shared by identical requests.
+type coalescedCall struct {
+	done        chan struct{}
+	status      int
+	contentType []byte
+	body        []byte
+	headers     [][2]string // Headers set by the execution (ETag, Cache-Control, ...)
+	err         error
+}
+
+// ErrCoalescedCallFailed returned to waiting requests when the shared execution panicked.
+var ErrCoalescedCallFailed = errors.New("coalesced request failed")
+
+var (
+	coalesceMu    sync.Mutex
+	coalesceCalls = map[string]*coalescedCall{}
+
+	coalesceExecutions atomic.Uint64
+	coalesceShared     atomic.Uint64
+)
+
+// CoalesceKeyFunc builds the key identifying identical requests. It must include everything the
+// response depends on (filter, field selection, caller scope, ...).
+type CoalesceKeyFunc func(c *core.Ctx) string
+
+// CoalesceURIKey default CoalesceKeyFunc: the request method and URI (path and query string).
+// Use a custom key including the caller scope for responses which depend on the authenticated user.
+func CoalesceURIKey(c *core.Ctx) string {
+	return string(c.Root().Method()) + " " + string(c.Root().RequestURI())
+}
+
+// Coalesce shares one execution of fetchFn between concurrent identical GET/HEAD requests.
+// The first request runs fetchFn and renders its response; requests with the same key arriving
+// while it runs wait and receive a copy of the rendered status, content type, body and of the headers set by
+// fetchFn (cookies excepted), error responses included, along with the error fetchFn returned.
+// Other methods always run fetchFn.
+//
+// Example Usage:
+//
+//	func (h ListProductsApi) Handle(c *core.Ctx) error {
+//		return http.Coalesce(c, http.CoalesceURIKey, func(c *core.Ctx) error {
+//			products, total, err := productRepository.List(http.FilterData(c))
+//			...
+//			return http.WriteList(c, http.NewListResponse(products, filter, total, toProductResponse))
+//		})
+//	}
+func Coalesce(c *core.Ctx, keyFn CoalesceKeyFunc, fetchFn func(c *core.Ctx) error) error {
+	if !c.Root().IsGet() && !c.Root().IsHead() {
+		return fetchFn(c)
+	}
+
+	key := keyFn(c)
+
+	coalesceMu.Lock()
+	if call, ok := coalesceCalls[key]; ok {
+		coalesceMu.Unlock()
+		coalesceShared.Add(1)
+
+		<-call.done
+		if call.status == 0 {
+			// The execution panicked
+			return call.err
+		}
+
+		response := &c.Root().Response
+		response.SetStatusCode(call.status)
+		response.Header.SetContentTypeBytes(call.contentType)
+		response.SetBody(call.body)
+		for _, header := range call.headers {
+			response.Header.Del(header[0])
+		}
+		for _, header := range call.headers {
+			response.Header.Add(header[0], header[1])
+		}
+
+		return call.err
+	}
+
+	call := &coalescedCall{done: make(chan struct{}), err: ErrCoalescedCallFailed}
+	coalesceCalls[key] = call
+	coalesceMu.Unlock()
+	coalesceExecutions.Add(1)
+
+	defer func() {
+		coalesceMu.Lock()
+		delete(coalesceCalls, key)
+		coalesceMu.Unlock()
+		close(call.done)
+	}()
+
+	response := &c.Root().Response
+	before := responseHeaders(response)
+
+	err := fetchFn(c)
+
+	call.contentType = append([]byte(nil), response.Header.ContentType()...)
+	call.body = append([]byte(nil), response.Body()...)
+	for _, header := range responseHeaders(response) {
+		if !slices.Contains(before, header) {
+			call.headers = append(call.headers, header)
+		}
+	}
+	call.status = response.StatusCode()
+	call.err = err
+
+	return err
+}
+
+// responseHeaders returns the headers of a response shared by coalesced requests, all but the cookies and the
+// ones set from the body.
+func responseHeaders(response *fasthttp.Response) [][2]string {
+	var headers [][2]string
+	response.Header.VisitAll(func(key, value []byte) {
+		switch string(key) {
+		case core.HeaderSetCookie, core.HeaderContentType, core.HeaderContentLength:
+		default:
+			headers = append(headers, [2]string{string(key), string(value)})
+		}
+	})
+
+	return headers
+}
+
+// CoalesceStats returns the number of executions of fetch functions by Coalesce and
+// the number of requests which received a shared response instead.
+func CoalesceStats() (executions, coalesced uint64) {
+	return coalesceExecutions.Load(), coalesceShared.Load()
+}
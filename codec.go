@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================ JSON Codec ============================
+// ====================================================================
+
+// Codec JSON encoding used by Parse and the response writers (WriteSuccess, WriteList, WriteError).
+// The configurations of sonic (sonic.ConfigStd) and jsoniter (jsoniter.ConfigCompatibleWithStandardLibrary)
+// implement it as is.
+type Codec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes the JSON data into v.
+	Unmarshal(data []byte, v any) error
+}
+
+// stdCodec Codec of the standard library.
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// StdCodec the encoding/json Codec, used by default.
+var StdCodec Codec = stdCodec{}
+
+// codec the registered Codec.
+var codec = StdCodec
+
+// RegisterCodec registers the JSON codec of Parse and the response writers. Bodies decoded with strict numbers
+// (see ParseOptions) keep using encoding/json, which reports the fields of type errors.
+//
+// Example Usage:
+//
+//	http.RegisterCodec(sonic.ConfigStd)
+func RegisterCodec(c Codec) {
+	codec = c
+}
+
+// writeJSON sends data encoded by the registered codec with a status. The standard codec encodes into a
+// pooled buffer (see BufferStats) sized after the previous response of the type, then copied to the
+// response's own pooled body. Values supported by the MediaCodec the client prefers are encoded by it instead.
+func writeJSON(c *core.Ctx, status int, data any) error {
+	if data != nil {
+		if mediaCodec, mediaType, ok := responseMediaCodec(c, reflect.TypeOf(data)); ok {
+			return writeMediaBody(c, status, mediaCodec, mediaType, data)
+		}
+	}
+
+	if _, ok := codec.(stdCodec); !ok {
+		encoded, err := codec.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		return setResponseBody(c, status, encoded)
+	}
+
+	size := encodedSize(reflect.TypeOf(data))
+	buffer := acquireBuffer(int(size.Load()))
+	defer releaseBuffer(buffer)
+
+	if err := json.NewEncoder(buffer).Encode(data); err != nil {
+		return err
+	}
+	// Encode terminates the value with a newline, Marshal does not
+	buffer.Truncate(buffer.Len() - 1)
+	size.Store(int64(buffer.Len()))
+
+	return setResponseBody(c, status, buffer.Bytes())
+}
+
+// setResponseBody sets the status and the JSON body of the response, transcoded to MessagePack when the
+// client prefers it (see PrefersMsgpack).
+func setResponseBody(c *core.Ctx, status int, body []byte) error {
+	setJSONResponse(c, status)
+	if PrefersMsgpack(c) {
+		return setMsgpackBody(c, body)
+	}
+	c.Root().Response.SetBody(body)
+
+	return nil
+}
+
+// setJSONResponse sets the status and the JSON content type of the response.
+func setJSONResponse(c *core.Ctx, status int) {
+	c.Root().Response.SetStatusCode(status)
+	c.Root().Response.Header.SetContentType(core.MIMEApplicationJSONCharsetUTF8)
+}
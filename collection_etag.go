@@ -0,0 +1,105 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================= Collection ETags =========================
+// ====================================================================
+
+// CollectionStats cheap summary of the records listed by a request, e.g. `SELECT COUNT(*), MAX(updated_at)`
+// with the request's filter. Creations and updates move UpdatedAt, deletions change Count.
+type CollectionStats struct {
+	Count     int       // Number of records matching the request's filter
+	UpdatedAt time.Time // Latest modification time of these records
+}
+
+// CollectionStatsFunc repository callback returning the stats of the records listed by a request, typically
+// from the Filter stored by ProcessFilter.
+type CollectionStatsFunc func(c *core.Ctx) (CollectionStats, error)
+
+// CollectionETag returns the weak entity tag of a list response from the stats of its records, the request
+// path, the query and the response format, without loading nor rendering the list.
+func CollectionETag(c *core.Ctx, stats CollectionStats) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		string(c.Root().Path()) + "?" + canonicalQuery(c),
+		responseFormat(c),
+		strconv.Itoa(stats.Count),
+		strconv.FormatInt(stats.UpdatedAt.UnixNano(), 10),
+	}, "|")))
+
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// CollectionNotModified calls the stats callback and sets the ETag header of the response from it (see
+// CollectionETag). When the request's If-None-Match header matches, the response becomes a 304 Not Modified and
+// true is returned: the handler must not load the list. Errors of the callback are logged, the list is then
+// served without ETag.
+//
+// Example Usage:
+//
+//	func (h ListOrdersApi) Handle(c *core.Ctx) error {
+//		if http.CollectionNotModified(c, orderRepository.Stats) {
+//			return nil
+//		}
+//
+//		orders, total, err := orderRepository.List(c.GetData(http.FilterKey).(http.Filter))
+//		...
+//	}
+func CollectionNotModified(c *core.Ctx, statsFn CollectionStatsFunc) bool {
+	stats, err := statsFn(c)
+	if err != nil {
+		log.Warnf("Collection stats error: %v", err)
+
+		return false
+	}
+
+	etag := CollectionETag(c, stats)
+	c.SetHeader(core.HeaderETag, etag)
+
+	if matchesETag(c.GetHeader(core.HeaderIfNoneMatch), etag) {
+		c.Status(core.StatusNotModified)
+
+		return true
+	}
+
+	return false
+}
+
+// collectionETagHandler handler wrapper answering unchanged list requests with 304 Not Modified.
+type collectionETagHandler struct {
+	core.IHandler
+	statsFn CollectionStatsFunc
+}
+
+// WithCollectionETag wraps a list handler with CollectionNotModified: the wrapped handler only runs when the
+// collection changed since the client's copy. The ETag is removed from unsuccessful responses.
+//
+// Example Usage:
+//
+//	apiRouter.GET("/orders", http.WithCollectionETag(api.NewListOrdersApi(), orderRepository.Stats))
+func WithCollectionETag(handler core.IHandler, statsFn CollectionStatsFunc) core.IHandler {
+	return &collectionETagHandler{IHandler: handler, statsFn: statsFn}
+}
+
+// Handle answers 304 Not Modified for unchanged collections, runs the wrapped handler otherwise.
+func (h *collectionETagHandler) Handle(c *core.Ctx) error {
+	if CollectionNotModified(c, h.statsFn) {
+		return nil
+	}
+
+	err := h.IHandler.Handle(c)
+	if c.Root().Response.StatusCode() != core.StatusOK {
+		c.Root().Response.Header.Del(core.HeaderETag)
+	}
+
+	return err
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ====================================================================
+// ======================= Configuration Reload =======================
+// ====================================================================
+
+// Config snapshot of the package's tunables read while serving requests. A snapshot is immutable once loaded:
+// ReloadConfig and UpdateConfig swap the whole snapshot atomically, so a request reads either the old or the
+// new values, never a mix of them, and long-running services adjust limits without restarts.
+//
+// Until the first reload, the package variables of the same names are read (e.g. MaxPageOffset); they configure
+// the service at startup and must not be assigned while serving. Once a snapshot is loaded, assigning them has
+// no effect: use UpdateConfig. Registries of named values (storage, rule overrides, patterns, examples,
+// quota plans) are synchronized instead, they may be registered while serving.
+type Config struct {
+	DefaultPerPage       int               // per_page of filters without a usable per_page
+	MaxPerPage           int               // Cap of the per_page of filters, zero disables it
+	MaxPageOffset        int               // Maximum offset accepted by ProcessFilter, zero disables the guard
+	BatchMaxRequests     int               // Maximum number of sub-requests in a batch
+	ExportPageSize       int               // Number of records fetched per page by exports
+	MaxThumbnailSize     int               // Maximum width and height of thumbnails
+	MirrorMaxInFlight    int               // Maximum number of shadow requests in flight
+	PollInterval         time.Duration     // Delay between two checks of a long-poll request
+	PollMaxTimeout       time.Duration     // Upper bound of the timeout accepted by Poll
+	WSMaxMessageSize     int64             // Maximum size in bytes of inbound WebSocket messages
+	QuotaExceededStatus  int               // Status of the Error returned when a quota is exhausted
+	AccessDeniedStatus   int               // Status of the Error returned when the OwnershipPolicy denies access
+	OperationRetention   time.Duration     // Lifetime of finished operations and of export/import download URLs
+	MaxPatternInput      int               // Maximum number of bytes of a value matched by a registered pattern
+	SafeRedirectFallback string            // Target of SafeRedirect when the requested target is not allowed
+	PatchMaxOperations   int               // Maximum number of operations of a JSON Patch document
+	PatchMaxNodes        int               // Maximum number of values of a document patched by ProcessJSONPatch
+	LintEnabled          bool              // Enables LintApi
+	Parse                ParseOptions      // Options applied by Parse, see RegisterParseOptions
+	Query                QueryOptions      // Normalization of query parameters, see RegisterQueryOptions
+	PasswordPolicy       PasswordPolicy    // Policy used to hash passwords, see RegisterPasswordPolicy (global, not per tenant)
+	Uploads              UploadLimits      // Limits of streamed uploads, see RegisterUploadLimits
+	Sanitize             SanitizePolicy    // Sanitization of request DTOs, see RegisterSanitizePolicy
+	Statuses             StatusPolicy      // Statuses of rejected requests, see RegisterStatusPolicy
+	Streams              StreamFlowControl // Back-pressure of streamed responses, see RegisterStreamFlowControl
+
+	// QuotaPlans plans replacing the registered ones (see RegisterQuota) by endpoint class, usually set per
+	// tenant (see TenantConfigResolver). The map is shared by the copies of the snapshot: assign a new map
+	// instead of modifying it.
+	QuotaPlans map[string]QuotaPlan
+}
+
+// configSnapshot the loaded snapshot, nil until the first reload.
+var configSnapshot atomic.Pointer[Config]
+
+// CurrentConfig returns the current configuration: the loaded snapshot, else the package variables.
+//
+// Example Usage:
+//
+//	log.Infof("per_page cap: %d", http.CurrentConfig().MaxPerPage)
+func CurrentConfig() Config {
+	if snapshot := configSnapshot.Load(); snapshot != nil {
+		return *snapshot
+	}
+
+	return Config{
+		DefaultPerPage:       DefaultPerPage,
+		MaxPerPage:           MaxPerPage,
+		MaxPageOffset:        MaxPageOffset,
+		BatchMaxRequests:     BatchMaxRequests,
+		ExportPageSize:       ExportPageSize,
+		MaxThumbnailSize:     MaxThumbnailSize,
+		MirrorMaxInFlight:    MirrorMaxInFlight,
+		PollInterval:         PollInterval,
+		PollMaxTimeout:       PollMaxTimeout,
+		WSMaxMessageSize:     WSMaxMessageSize,
+		QuotaExceededStatus:  QuotaExceededStatus,
+		AccessDeniedStatus:   AccessDeniedStatus,
+		OperationRetention:   OperationRetention,
+		MaxPatternInput:      MaxPatternInput,
+		SafeRedirectFallback: SafeRedirectFallback,
+		PatchMaxOperations:   PatchMaxOperations,
+		PatchMaxNodes:        PatchMaxNodes,
+		LintEnabled:          LintEnabled,
+		Parse:                parseOptions,
+		Query:                queryOptions,
+		PasswordPolicy:       passwordPolicy,
+		Uploads:              uploadLimits,
+		Sanitize:             sanitizePolicy,
+		Statuses:             statusPolicy,
+		Streams:              streamFlowControl,
+	}
+}
+
+// ReloadConfig checks and loads a configuration snapshot. Invalid configurations are rejected and the current
+// one is kept.
+//
+// Example Usage:
+//
+//	config := http.CurrentConfig()
+//	config.MaxPerPage = settings.MaxPerPage
+//	config.MaxPageOffset = settings.MaxPageOffset
+//	if err := http.ReloadConfig(config); err != nil {
+//		log.Errorf("Invalid configuration: %v", err)
+//	}
+func ReloadConfig(snapshot Config) error {
+	if err := snapshot.check(); err != nil {
+		return err
+	}
+
+	configSnapshot.Store(&snapshot)
+
+	return nil
+}
+
+// UpdateConfig changes the current configuration with updateFn, applied to a copy of it. Concurrent updates
+// do not overwrite each other: updateFn is applied again when another update was loaded meanwhile.
+//
+// Example Usage:
+//
+//	err := http.UpdateConfig(func(config *http.Config) {
+//		config.MaxPerPage = 50
+//	})
+func UpdateConfig(updateFn func(config *Config)) error {
+	for {
+		current := configSnapshot.Load()
+
+		snapshot := CurrentConfig()
+		if current != nil {
+			snapshot = *current
+		}
+		updateFn(&snapshot)
+
+		if err := snapshot.check(); err != nil {
+			return err
+		}
+		if configSnapshot.CompareAndSwap(current, &snapshot) {
+			return nil
+		}
+	}
+}
+
+// check checks the values of a configuration.
+func (config Config) check() error {
+	var errs []error
+	for _, value := range []struct {
+		name  string
+		value int64
+	}{
+		{"DefaultPerPage", int64(config.DefaultPerPage)},
+		{"BatchMaxRequests", int64(config.BatchMaxRequests)},
+		{"ExportPageSize", int64(config.ExportPageSize)},
+		{"MaxThumbnailSize", int64(config.MaxThumbnailSize)},
+		{"PollInterval", int64(config.PollInterval)},
+		{"PollMaxTimeout", int64(config.PollMaxTimeout)},
+		{"WSMaxMessageSize", config.WSMaxMessageSize},
+		{"OperationRetention", int64(config.OperationRetention)},
+		{"MaxPatternInput", int64(config.MaxPatternInput)},
+		{"PatchMaxOperations", int64(config.PatchMaxOperations)},
+		{"PatchMaxNodes", int64(config.PatchMaxNodes)},
+		{"Uploads.MemoryThreshold", config.Uploads.MemoryThreshold},
+	} {
+		if value.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", value.name))
+		}
+	}
+
+	if config.MaxPerPage < 0 || config.MaxPageOffset < 0 || config.MirrorMaxInFlight < 0 {
+		errs = append(errs, errors.New("MaxPerPage, MaxPageOffset and MirrorMaxInFlight must not be negative"))
+	}
+	if config.Streams.QueueSize < 0 || config.Streams.StallThreshold < 0 || config.Streams.MaxStall < 0 {
+		errs = append(errs, errors.New("stream flow control must not be negative"))
+	}
+	if config.Uploads.MaxPartSize < 0 || config.Uploads.MaxTotalSize < 0 || config.Uploads.MaxParts < 0 {
+		errs = append(errs, errors.New("upload limits must not be negative"))
+	}
+	if config.MaxPerPage > 0 && config.DefaultPerPage > config.MaxPerPage {
+		errs = append(errs, fmt.Errorf("DefaultPerPage %d exceeds MaxPerPage %d", config.DefaultPerPage, config.MaxPerPage))
+	}
+	if config.QuotaExceededStatus < 400 || config.QuotaExceededStatus > 599 {
+		errs = append(errs, fmt.Errorf("QuotaExceededStatus %d is not an error status", config.QuotaExceededStatus))
+	}
+	if config.AccessDeniedStatus != 403 && config.AccessDeniedStatus != 404 {
+		errs = append(errs, fmt.Errorf("AccessDeniedStatus %d is neither 403 nor 404", config.AccessDeniedStatus))
+	}
+	for name, status := range map[string]int{
+		"Statuses.Malformed":    config.Statuses.Malformed,
+		"Statuses.UnknownField": config.Statuses.UnknownField,
+		"Statuses.Validation":   config.Statuses.Validation,
+		"Statuses.BusinessRule": config.Statuses.BusinessRule,
+	} {
+		if status != 0 && (status < 400 || status > 499) {
+			errs = append(errs, fmt.Errorf("%s %d is not a client error status", name, status))
+		}
+	}
+	if config.Sanitize != SanitizeStrict && config.Sanitize != SanitizeTagsOnly {
+		errs = append(errs, fmt.Errorf("unknown sanitize policy %d", config.Sanitize))
+	}
+	for class, plan := range config.QuotaPlans {
+		if plan.Limit <= 0 || plan.Period <= 0 {
+			errs = append(errs, fmt.Errorf("quota plan %q must have a positive limit and period", class))
+		}
+		if plan.WarnRatio < 0 || plan.WarnRatio > 1 {
+			errs = append(errs, fmt.Errorf("quota plan %q must have a WarnRatio between 0 and 1", class))
+		}
+	}
+	if config.PasswordPolicy.Algorithm != PasswordBcrypt && config.PasswordPolicy.Algorithm != PasswordArgon2id {
+		errs = append(errs, fmt.Errorf("unknown password algorithm %q", config.PasswordPolicy.Algorithm))
+	}
+
+	return errors.Join(errs...)
+}
@@ -21,4 +21,60 @@ const (
 	RequestKey string = "__request__"
 	// FilterKey key in Context's Data for filtering parameters
 	FilterKey string = "__filter__"
+	// WarningsKey key in Context's Data for non-fatal warnings collected while processing the request
+	WarningsKey string = "__warnings__"
+	// DeltaSinceKey key in Context's Data for the changes-since time of collection sync requests
+	DeltaSinceKey string = "__delta_since__"
+	// FieldsKey key in Context's Data for the response field selection
+	FieldsKey string = "__fields__"
+	// FieldMaskKey key in Context's Data for the fields present in an update request body
+	FieldMaskKey string = "__field_mask__"
+	// QuotaKey key in Context's Data for the caller's quota consumed by the request
+	QuotaKey string = "__quota__"
+	// FingerprintKey key in Context's Data for the request fingerprint computed by Fingerprint
+	FingerprintKey string = "__fingerprint__"
+	// EventsKey key in Context's Data for the events recorded by the request and not emitted yet
+	EventsKey string = "__events__"
+	// LocaleKey key in Context's Data for the locale of the request
+	LocaleKey string = "__locale__"
+	// MirrorKey key in Context's Data for the request captured for the shadow target
+	MirrorKey string = "__mirror__"
+	// PayloadKeyKey key in Context's Data for the client public key the response's Data is encrypted with
+	PayloadKeyKey string = "__payload_key__"
+	// ValidationKey key in Context's Data for the outcome of the request's parsing and validation (true when valid)
+	ValidationKey string = "__validation__"
+	// SessionKey key in Context's Data for the session loaded by ProcessSession
+	SessionKey string = "__session__"
+	// UploadsKey key in Context's Data for the files received by ProcessUpload
+	UploadsKey string = "__uploads__"
+	// StreamedUploadsKey key in Context's Data for the form received by ProcessStreamedUpload
+	StreamedUploadsKey string = "__streamed_uploads__"
+	// SupportReferenceKey key in Context's Data for the support reference of the request
+	SupportReferenceKey string = "__support_reference__"
+	// TenantConfigKey key in Context's Data for the configuration of the request resolved by RequestConfig
+	TenantConfigKey string = "__tenant_config__"
+	// DumpKey key in Context's Data for the request captured by Dumped
+	DumpKey string = "__dump__"
+	// DryRunKey key in Context's Data for the dry-run flag of the request, see IsDryRun
+	DryRunKey string = "__dry_run__"
+	// LintKey key in Context's Data for the flag of requests linting a payload (see Lint), whose usage is not counted
+	LintKey string = "__lint__"
+
+	// ====================================================================
+	// ========================= Warning Codes ============================
+	// ====================================================================
+
+	// WarningDeprecated code for notices about deprecated fields, parameters or endpoints
+	WarningDeprecated string = "DEPRECATED"
+	// WarningPartialFailure code for notices about a part of the operation that could not be completed
+	WarningPartialFailure string = "PARTIAL_FAILURE"
+	// WarningAutoCorrected code for notices about input values that were adjusted automatically
+	WarningAutoCorrected string = "AUTO_CORRECTED"
+	// WarningTruncated code for notices about list responses cut to the response size budget
+	WarningTruncated string = "TRUNCATED"
+	// WarningQuotaLow code for notices about a quota close to exhaustion, see QuotaPlan.WarnRatio
+	WarningQuotaLow string = "QUOTA_LOW"
+	// WarningFeatureDisabled code for notices about fields ignored because their feature is disabled, see
+	// RegisterFeatureFlagProvider
+	WarningFeatureDisabled string = "FEATURE_DISABLED"
 )
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ====================================================================
+// ========================= Response Contracts =======================
+// ====================================================================
+
+// Kinds of ContractChange.
+const (
+	ContractFieldAdded   = "added"
+	ContractFieldRemoved = "removed"
+	ContractTypeChanged  = "type_changed"
+)
+
+// ResponseShape JSON types of a serialized response by field path, e.g. {"id": "number", "tags[]": "string"}.
+type ResponseShape map[string]string
+
+// ContractChange a difference between the baseline and the current shape of a contract.
+type ContractChange struct {
+	Contract string // Contract name
+	Field    string // Field path
+	Kind     string // ContractFieldAdded, ContractFieldRemoved or ContractTypeChanged
+	Before   string // Baseline JSON type, empty for added fields
+	After    string // Current JSON type, empty for removed fields
+	Breaking bool   // Removed fields and type changes break consumers
+}
+
+// String describes the change.
+func (change ContractChange) String() string {
+	switch change.Kind {
+	case ContractFieldAdded:
+		return fmt.Sprintf("%s: field %q added (%s)", change.Contract, change.Field, change.After)
+	case ContractFieldRemoved:
+		return fmt.Sprintf("%s: field %q removed (was %s)", change.Contract, change.Field, change.Before)
+	default:
+		return fmt.Sprintf("%s: field %q changed from %s to %s", change.Contract, change.Field, change.Before, change.After)
+	}
+}
+
+var (
+	contractsMu sync.RWMutex
+	// contracts sample response builders by contract name.
+	contracts = map[string]func() any{}
+)
+
+// RegisterContract registers a DTO/transformer pair whose response shape is protected by CheckContracts.
+// The sample should fill optional fields, so they are part of the shape.
+//
+// Example Usage:
+//
+//	http.RegisterContract("user", models.User{ID: 1, Email: "john@example.com"}, transformers.ToUserResponse)
+func RegisterContract[T any, R any](name string, sample T, transformerFn func(T) R) {
+	contractsMu.Lock()
+	defer contractsMu.Unlock()
+
+	contracts[name] = func() any { return transformerFn(sample) }
+}
+
+// ContractShapes serializes the sample response of each registered contract and returns their shapes by name.
+func ContractShapes() (map[string]ResponseShape, error) {
+	contractsMu.RLock()
+	defer contractsMu.RUnlock()
+
+	shapes := make(map[string]ResponseShape, len(contracts))
+	for name, sampleFn := range contracts {
+		encoded, err := json.Marshal(sampleFn())
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %w", name, err)
+		}
+
+		var document any
+		if err := json.Unmarshal(encoded, &document); err != nil {
+			return nil, fmt.Errorf("contract %s: %w", name, err)
+		}
+
+		shape := ResponseShape{}
+		collectShape(document, "", shape)
+		shapes[name] = shape
+	}
+
+	return shapes, nil
+}
+
+// CheckContracts diffs the shapes of the registered contracts against the baseline JSON file. The baseline is
+// written when it does not exist or update is true (e.g. after an intended breaking change). Changes are sorted
+// by contract and field; tests fail on the Breaking ones.
+//
+// Example Usage:
+//
+//	func TestResponseContracts(t *testing.T) {
+//		changes, err := http.CheckContracts("testdata/contracts.json", os.Getenv("UPDATE_CONTRACTS") != "")
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		for _, change := range changes {
+//			if change.Breaking {
+//				t.Error(change)
+//			}
+//		}
+//	}
+func CheckContracts(baselinePath string, update bool) ([]ContractChange, error) {
+	current, err := ContractShapes()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(baselinePath)
+	if errors.Is(err, os.ErrNotExist) || update {
+		return nil, writeContracts(baselinePath, current)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline map[string]ResponseShape
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("invalid contract baseline %s: %w", baselinePath, err)
+	}
+
+	var changes []ContractChange
+	for name, before := range baseline {
+		after, ok := current[name]
+		if !ok {
+			// Unregistered contract, the endpoint may have been removed on purpose
+			continue
+		}
+		changes = append(changes, DiffShapes(name, before, after)...)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Contract != changes[j].Contract {
+			return changes[i].Contract < changes[j].Contract
+		}
+
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+// DiffShapes returns the changes from the before shape to the after shape. Type changes from or to null
+// are not breaking, as a null sample value does not tell the field's type.
+func DiffShapes(contract string, before, after ResponseShape) []ContractChange {
+	var changes []ContractChange
+
+	for field, beforeType := range before {
+		afterType, ok := after[field]
+		switch {
+		case !ok:
+			changes = append(changes, ContractChange{
+				Contract: contract, Field: field, Kind: ContractFieldRemoved, Before: beforeType, Breaking: true,
+			})
+		case afterType != beforeType:
+			changes = append(changes, ContractChange{
+				Contract: contract, Field: field, Kind: ContractTypeChanged, Before: beforeType, After: afterType,
+				Breaking: beforeType != "null" && afterType != "null",
+			})
+		default:
+		}
+	}
+
+	for field, afterType := range after {
+		if _, ok := before[field]; !ok {
+			changes = append(changes, ContractChange{
+				Contract: contract, Field: field, Kind: ContractFieldAdded, After: afterType,
+			})
+		}
+	}
+
+	return changes
+}
+
+// collectShape records the JSON type of each field of a decoded document. Array items are recorded under
+// "path[]", merged across items.
+func collectShape(value any, path string, shape ResponseShape) {
+	switch typed := value.(type) {
+	case map[string]any:
+		if path != "" {
+			shape[path] = "object"
+		}
+		for name, item := range typed {
+			collectShape(item, joinPath(path, name), shape)
+		}
+	case []any:
+		shape[path] = "array"
+		for _, item := range typed {
+			collectShape(item, path+"[]", shape)
+		}
+	case string:
+		shape[path] = "string"
+	case float64:
+		shape[path] = "number"
+	case bool:
+		shape[path] = "boolean"
+	default:
+		if _, ok := shape[path]; !ok {
+			shape[path] = "null"
+		}
+	}
+}
+
+// writeContracts writes the shapes as an indented JSON baseline.
+func writeContracts(baselinePath string, shapes map[string]ResponseShape) error {
+	content, err := json.MarshalIndent(shapes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(baselinePath), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(baselinePath, append(content, '\n'), 0o644)
+}
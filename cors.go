@@ -0,0 +1,196 @@
+package http
+
+import (
+	stderrors "errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// =============================== CORS ===============================
+// ====================================================================
+
+// defaultCORSMethods methods allowed by a CORSPolicy without AllowMethods.
+var defaultCORSMethods = []string{
+	fasthttp.MethodGet, fasthttp.MethodHead, fasthttp.MethodPost, fasthttp.MethodPut,
+	fasthttp.MethodPatch, fasthttp.MethodDelete,
+}
+
+// CORSPolicy cross-origin resource sharing policy of an API.
+type CORSPolicy struct {
+	AllowOrigins        []string         // "*", exact origins or wildcard subdomains ("https://*.example.com")
+	AllowOriginPatterns []*regexp.Regexp // Origins matching a pattern are allowed too
+	AllowMethods        []string         // Methods allowed by preflights, GET, HEAD, POST, PUT, PATCH and DELETE by default
+	AllowHeaders        []string         // Request headers allowed by preflights, the requested ones by default
+	ExposeHeaders       []string         // Response headers readable by the client
+	AllowCredentials    bool             // Allows cookies and Authorization, the origin is echoed; excludes "*"
+	MaxAge              time.Duration    // Caching duration of preflight responses, not sent when zero
+}
+
+// ErrCORSWildcardCredentials returned by NewCORSPolicy for policies allowing credentials from any origin.
+var ErrCORSWildcardCredentials = stderrors.New(`CORS policy can not allow credentials from origin "*"`)
+
+// NewCORSPolicy checks a policy and returns it. Allowing credentials from any origin ("*" with AllowCredentials)
+// would let every site make credentialed reads and is rejected with ErrCORSWildcardCredentials; policies built
+// without NewCORSPolicy ignore "*" when AllowCredentials is set.
+//
+// Example Usage:
+//
+//	cors, err := http.NewCORSPolicy(http.CORSPolicy{
+//		AllowOrigins:     []string{"https://app.example.com"},
+//		AllowCredentials: true,
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func NewCORSPolicy(policy CORSPolicy) (*CORSPolicy, error) {
+	if policy.AllowCredentials && slices.Contains(policy.AllowOrigins, "*") {
+		return nil, ErrCORSWildcardCredentials
+	}
+
+	return &policy, nil
+}
+
+// AllowsOrigin checks the origin is allowed by the policy. "*" allows any origin, unless credentials are allowed.
+func (p *CORSPolicy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	for _, allowed := range p.AllowOrigins {
+		if allowed == "*" {
+			if !p.AllowCredentials {
+				return true
+			}
+
+			continue
+		}
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		// Wildcard subdomain: "https://*.example.com" matches "https://api.example.com"
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			lowered := strings.ToLower(origin)
+			if len(lowered) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(lowered, strings.ToLower(prefix)) && strings.HasSuffix(lowered, strings.ToLower(suffix)) &&
+				!strings.Contains(lowered[len(prefix):len(lowered)-len(suffix)], "/") {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range p.AllowOriginPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply sets the CORS headers of a response to a cross-origin request from an allowed origin.
+// It reports whether the request's origin is allowed; same-origin requests (no Origin header) are not.
+func (p *CORSPolicy) Apply(c *core.Ctx) bool {
+	origin := c.GetHeader(core.HeaderOrigin)
+
+	// Responses depend on the origin, unless every origin gets "*"
+	if !slices.Contains(p.AllowOrigins, "*") || p.AllowCredentials {
+		c.Root().Response.Header.Add(core.HeaderVary, core.HeaderOrigin)
+	}
+
+	if !p.AllowsOrigin(origin) {
+		return false
+	}
+
+	// Credentials are never allowed for any origin, see NewCORSPolicy
+	if slices.Contains(p.AllowOrigins, "*") && !p.AllowCredentials {
+		c.SetHeader(core.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		c.SetHeader(core.HeaderAccessControlAllowOrigin, origin)
+	}
+
+	if p.AllowCredentials {
+		c.SetHeader(core.HeaderAccessControlAllowCredentials, "true")
+	}
+	if len(p.ExposeHeaders) > 0 {
+		c.SetHeader(core.HeaderAccessControlExposeHeaders, strings.Join(p.ExposeHeaders, ", "))
+	}
+
+	return true
+}
+
+// IsPreflight checks the request is a CORS preflight request.
+func IsPreflight(c *core.Ctx) bool {
+	return c.Root().IsOptions() && c.GetHeader(core.HeaderOrigin) != "" &&
+		c.GetHeader(core.HeaderAccessControlRequestMethod) != ""
+}
+
+// Preflight answers a preflight request: 204 with the allowed methods and headers, or 403 FORBIDDEN_ORIGIN
+// when the origin or the requested method is not allowed.
+func (p *CORSPolicy) Preflight(c *core.Ctx) error {
+	methods := p.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+
+	requestMethod := strings.ToUpper(c.GetHeader(core.HeaderAccessControlRequestMethod))
+	if !p.Apply(c) || !slices.Contains(methods, requestMethod) {
+		return c.Error(&Error{
+			Code:    "FORBIDDEN_ORIGIN",
+			Message: "Cross-origin request is not allowed",
+		}, core.StatusForbidden)
+	}
+
+	c.Root().Response.Header.Add(core.HeaderVary, core.HeaderAccessControlRequestMethod)
+	c.Root().Response.Header.Add(core.HeaderVary, core.HeaderAccessControlRequestHeaders)
+
+	c.SetHeader(core.HeaderAccessControlAllowMethods, strings.Join(methods, ", "))
+	if len(p.AllowHeaders) > 0 {
+		c.SetHeader(core.HeaderAccessControlAllowHeaders, strings.Join(p.AllowHeaders, ", "))
+	} else if requested := c.GetHeader(core.HeaderAccessControlRequestHeaders); requested != "" {
+		c.SetHeader(core.HeaderAccessControlAllowHeaders, requested)
+	}
+	if p.MaxAge > 0 {
+		c.SetHeader(core.HeaderAccessControlMaxAge, strconv.Itoa(int(p.MaxAge.Seconds())))
+	}
+
+	return c.NoContent()
+}
+
+// Middleware applies the policy to every request and short-circuits preflight requests, so they never
+// reach the handlers.
+//
+// Example Usage:
+//
+//	cors, err := http.NewCORSPolicy(http.CORSPolicy{
+//		AllowOrigins:     []string{"https://app.example.com", "https://*.example.com"},
+//		AllowCredentials: true,
+//		MaxAge:           10 * time.Minute,
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	apiRouter.Use(cors.Middleware)
+//	app.Router().GlobalOPTIONS = cors.Preflight
+func (p *CORSPolicy) Middleware(c *core.Ctx) error {
+	if !IsPreflight(c) {
+		p.Apply(c)
+
+		return nil
+	}
+
+	if err := p.Preflight(c); err != nil {
+		return err
+	}
+
+	// Stop the chain, the preflight response is complete
+	return errors.UnknownError
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ====================================================================
+// ============================== Date ================================
+// ====================================================================
+
+// DateLayout JSON and string layout of Date.
+const DateLayout = "2006-01-02"
+
+// Date struct to describe a calendar date without time and timezone, e.g. a birthday.
+// JSON representation is "2024-01-31", the zero Date is encoded as null.
+// @Description Calendar date in YYYY-MM-DD format
+// @Tags Common
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a date in "2006-01-02" layout.
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+
+	return DateOf(t), nil
+}
+
+// DateOf returns the date of the time in its own location.
+// Use DateOf(t.In(loc)) to get the date seen from another timezone.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the current date in the location (time.Local when nil).
+func Today(loc *time.Location) Date {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	return DateOf(time.Now().In(loc))
+}
+
+// In returns the time at midnight of the date in the location (UTC when nil).
+func (d Date) In(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// String returns the date in "2006-01-02" layout.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero checks the date is not set.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before checks the date is before the other date.
+func (d Date) Before(other Date) bool {
+	return d.In(nil).Before(other.In(nil))
+}
+
+// After checks the date is after the other date.
+func (d Date) After(other Date) bool {
+	return d.In(nil).After(other.In(nil))
+}
+
+// AddDays returns the date shifted by a number of days.
+func (d Date) AddDays(days int) Date {
+	return DateOf(d.In(nil).AddDate(0, 0, days))
+}
+
+// MarshalJSON encodes the date as "2006-01-02", or null when zero.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes a "2006-01-02" string or null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	value, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid date %s, expected YYYY-MM-DD", data)
+	}
+	if value == "" {
+		*d = Date{}
+
+		return nil
+	}
+
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+
+	return nil
+}
+
+// ====================================================================
+// =========================== Time Of Day ============================
+// ====================================================================
+
+// TimeOfDay struct to describe a wall clock time without date and timezone, e.g. opening hours.
+// JSON representation is "14:30" or "14:30:15" when seconds are set.
+// @Description Wall clock time in HH:MM or HH:MM:SS format
+// @Tags Common
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+// ParseTimeOfDay parses a time in "15:04" or "15:04:05" layout.
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return TimeOfDayOf(t), nil
+		}
+	}
+
+	return TimeOfDay{}, fmt.Errorf("invalid time %q, expected HH:MM or HH:MM:SS", value)
+}
+
+// TimeOfDayOf returns the wall clock time of the time in its own location.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()}
+}
+
+// On returns the time at the wall clock time of the date in the location (UTC when nil).
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, 0, loc)
+}
+
+// Seconds returns the number of seconds since midnight.
+func (t TimeOfDay) Seconds() int {
+	return t.Hour*3600 + t.Minute*60 + t.Second
+}
+
+// Before checks the time is before the other time.
+func (t TimeOfDay) Before(other TimeOfDay) bool {
+	return t.Seconds() < other.Seconds()
+}
+
+// After checks the time is after the other time.
+func (t TimeOfDay) After(other TimeOfDay) bool {
+	return t.Seconds() > other.Seconds()
+}
+
+// String returns the time in "15:04" layout, or "15:04:05" when seconds are set.
+func (t TimeOfDay) String() string {
+	if t.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// MarshalJSON encodes the time as "15:04" or "15:04:05".
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON decodes a "15:04" or "15:04:05" string or null.
+func (t *TimeOfDay) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	value, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid time %s, expected HH:MM or HH:MM:SS", data)
+	}
+
+	parsed, err := ParseTimeOfDay(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+
+	return nil
+}
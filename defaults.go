@@ -0,0 +1,192 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================== Default Values ==========================
+// ====================================================================
+
+// defaultField field with a `default` tag and its parsed value.
+type defaultField struct {
+	index   int
+	value   reflect.Value // Value of the field's type, or of its element type for pointer fields
+	pointer bool          // The field is a pointer, a new one is allocated for each request
+}
+
+// parseDefaults returns the fields of a struct type with a valid `default` tag, and the exported struct fields
+// (pointers to structs, slices and arrays of them) defaults are looked for in. Invalid tags are logged and
+// ignored.
+func parseDefaults(typ reflect.Type) (defaults []defaultField, nested []int) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("default")
+		if !ok {
+			elem := derefType(field.Type)
+			if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+				elem = derefType(elem.Elem())
+			}
+			if elem.Kind() == reflect.Struct && elem != typ && elem != timeType {
+				nested = append(nested, i)
+			}
+
+			continue
+		}
+
+		// An explicit false is the zero value, it would always be replaced by the default
+		if field.Type.Kind() == reflect.Bool {
+			log.Errorf("Invalid default of %s.%s: bool fields can not be turned off, use *bool", typ, field.Name)
+
+			continue
+		}
+
+		pointer := field.Type.Kind() == reflect.Pointer
+		valueType := field.Type
+		if pointer {
+			valueType = field.Type.Elem()
+		}
+
+		value, err := parseDefault(tag, valueType)
+		if err != nil {
+			log.Errorf("Invalid default of %s.%s: %v", typ, field.Name, err)
+
+			continue
+		}
+		defaults = append(defaults, defaultField{index: i, value: value, pointer: pointer})
+	}
+
+	return defaults, nested
+}
+
+// derefType returns the type pointed to by pointer types.
+func derefType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// parseDefault parses the `default` tag of a field: strings, booleans, numbers and durations ("30s").
+func parseDefault(tag string, typ reflect.Type) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString(tag)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(tag)
+		if err != nil {
+			return value, err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == reflect.TypeFor[time.Duration]() {
+			parsed, err := time.ParseDuration(tag)
+			if err != nil {
+				return value, err
+			}
+			value.SetInt(int64(parsed))
+
+			break
+		}
+
+		parsed, err := strconv.ParseInt(tag, 10, typ.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(tag, 10, typ.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(tag, typ.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetFloat(parsed)
+	default:
+		return value, fmt.Errorf("unsupported type %s", typ)
+	}
+
+	return value, nil
+}
+
+// ApplyDefaults sets the zero-valued fields of a struct with a `default` tag to their default, in nested structs
+// and in the items of slices of structs too. Strings, numbers, durations ("30s") and pointers to them are
+// supported, and pointers to booleans; nil pointers are set to a new value. An explicit zero ("", 0) can not be
+// told from an omitted value and gets the default too: use a pointer field when zero is a meaningful value.
+// `default` tags of bool fields are rejected, as false could never be sent. ProcessData, ProcessUpdateData and
+// ProcessRequest apply defaults after parsing, before validation.
+//
+// Example Usage:
+//
+//	type ListOrdersRequest struct {
+//		Status   string `json:"status" default:"open" validate:"oneof=open paid shipped"`
+//		Currency string `json:"currency" default:"USD" validate:"currency"`
+//		PerPage  int    `json:"per_page" default:"20" validate:"max=100"`
+//	}
+func ApplyDefaults(target any) {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return
+	}
+
+	applyDefaults(val.Elem())
+}
+
+// applyDefaults applies the defaults of a struct value.
+func applyDefaults(val reflect.Value) {
+	if val.Kind() != reflect.Struct {
+		return
+	}
+
+	plan := planOf(val.Type())
+	for _, defaulted := range plan.defaults {
+		field := val.Field(defaulted.index)
+		if !field.CanSet() || !field.IsZero() {
+			continue
+		}
+
+		if defaulted.pointer {
+			pointer := reflect.New(defaulted.value.Type())
+			pointer.Elem().Set(defaulted.value)
+			field.Set(pointer)
+		} else {
+			field.Set(defaulted.value)
+		}
+	}
+
+	for _, index := range plan.nested {
+		field := val.Field(index)
+		for field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		if field.Kind() != reflect.Slice && field.Kind() != reflect.Array {
+			applyDefaults(field)
+
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			item := field.Index(i)
+			for item.Kind() == reflect.Pointer && !item.IsNil() {
+				item = item.Elem()
+			}
+			applyDefaults(item)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================= Graceful Degradation =======================
+// ====================================================================
+
+// Optional subsystems whose runtime failures are handled by their FailurePolicy.
+const (
+	SubsystemCache     = "cache"      // CacheStore of Cached handlers
+	SubsystemMetrics   = "metrics"    // Metrics backend
+	SubsystemAccessLog = "access_log" // AccessLogSink, the audit trail of requests
+	SubsystemEvents    = "events"     // EventEmitter of EmitEvents
+	SubsystemScanner   = "scanner"    // Scanner of uploads, see ScannerOptions
+)
+
+// MetricSubsystemFailures counter of the runtime failures of optional subsystems.
+const MetricSubsystemFailures = "http_subsystem_failures_total" // Labels: subsystem, policy
+
+// ErrorCodeSubsystemUnavailable code of the Error of requests failed by a FailClosed subsystem.
+const ErrorCodeSubsystemUnavailable = "SUBSYSTEM_UNAVAILABLE"
+
+// SubsystemFailureHeader response header naming the FailClosed subsystems which failed once the response was
+// committed (access log, events), one value per subsystem.
+const SubsystemFailureHeader = "X-Subsystem-Failure"
+
+// FailurePolicy tells how requests are handled when an optional subsystem fails at runtime (error or panic).
+type FailurePolicy int
+
+// Failure policies.
+const (
+	FailOpen   FailurePolicy = iota // The subsystem is skipped, the failure is logged and counted (default)
+	FailClosed                      // The request fails with 503 SUBSYSTEM_UNAVAILABLE, or reports the failure
+)
+
+// String returns the name of the policy, as reported in metric labels.
+func (p FailurePolicy) String() string {
+	if p == FailClosed {
+		return "closed"
+	}
+
+	return "open"
+}
+
+var (
+	failurePoliciesMu sync.RWMutex
+	// failurePolicies policies by subsystem, FailOpen when missing
+	failurePolicies = map[string]FailurePolicy{SubsystemScanner: FailClosed}
+
+	// subsystemFailures failure counters by subsystem, kept even when the metrics backend is failing
+	subsystemFailures sync.Map
+)
+
+// RegisterFailurePolicy registers the policy of a subsystem. Subsystems fail open by default, except the
+// scanner whose policy is also set by RegisterScanner.
+//
+// Failures outside of a request (background cache refreshes) are only logged and counted, and so are metrics
+// failures, as counters are also incremented outside of request handling. The access log and the events are
+// written once the handler has committed its work: a 503 would invite clients to retry and repeat it, so
+// FailClosed keeps the response and names the subsystem in the SubsystemFailureHeader instead.
+//
+// Example Usage:
+//
+//	// Requests must not be served from a failing cache
+//	http.RegisterFailurePolicy(http.SubsystemCache, http.FailClosed)
+func RegisterFailurePolicy(subsystem string, policy FailurePolicy) {
+	failurePoliciesMu.Lock()
+	defer failurePoliciesMu.Unlock()
+
+	failurePolicies[subsystem] = policy
+}
+
+// failurePolicy returns the policy of a subsystem.
+func failurePolicy(subsystem string) FailurePolicy {
+	failurePoliciesMu.RLock()
+	defer failurePoliciesMu.RUnlock()
+
+	return failurePolicies[subsystem]
+}
+
+// SubsystemFailures returns the number of runtime failures by subsystem since the start, e.g. for health checks.
+//
+// Example Usage:
+//
+//	if failures := http.SubsystemFailures()[http.SubsystemCache]; failures > 0 {
+//		health.Warn("cache", fmt.Sprintf("%d failures", failures))
+//	}
+func SubsystemFailures() map[string]uint64 {
+	failures := map[string]uint64{}
+	subsystemFailures.Range(func(subsystem, count any) bool {
+		failures[subsystem.(string)] = count.(*atomic.Uint64).Load()
+
+		return true
+	})
+
+	return failures
+}
+
+// subsystemFailed logs and counts a runtime failure of a subsystem, and returns the subsystem's policy.
+func subsystemFailed(subsystem string, err error) FailurePolicy {
+	policy := failurePolicy(subsystem)
+
+	count, _ := subsystemFailures.LoadOrStore(subsystem, new(atomic.Uint64))
+	count.(*atomic.Uint64).Add(1)
+
+	// A failing metrics backend is not asked to count its own failures
+	if subsystem != SubsystemMetrics {
+		incCounter(MetricSubsystemFailures, map[string]string{"subsystem": subsystem, "policy": policy.String()})
+	}
+
+	if policy == FailOpen {
+		log.Warnf("Subsystem %s skipped: %v", subsystem, err)
+	} else {
+		log.Errorf("Subsystem %s failure: %v", subsystem, err)
+	}
+
+	return policy
+}
+
+// degrade handles a runtime failure of a subsystem while serving a request: nil under FailOpen so the request
+// goes on without the subsystem, the written 503 SUBSYSTEM_UNAVAILABLE response under FailClosed.
+func degrade(c *core.Ctx, subsystem string, err error) error {
+	if subsystemFailed(subsystem, err) == FailOpen {
+		return nil
+	}
+
+	return WriteError(c, &Error{
+		Code:    ErrorCodeSubsystemUnavailable,
+		Message: "Service temporarily unavailable, try again later",
+	}, core.StatusServiceUnavailable)
+}
+
+// degradeCommitted handles a runtime failure of a subsystem once the request's work is committed: the response
+// is kept under both policies, FailClosed adds the subsystem to the SubsystemFailureHeader.
+func degradeCommitted(c *core.Ctx, subsystem string, err error) {
+	if subsystemFailed(subsystem, err) == FailClosed {
+		c.Root().Response.Header.Add(SubsystemFailureHeader, subsystem)
+	}
+}
+
+// guard runs a call to a subsystem, its panic is returned as an error.
+func guard(call func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	call()
+
+	return nil
+}
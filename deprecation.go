@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================= Deprecated Fields ==========================
+// ====================================================================
+
+var (
+	deprecationsMu sync.RWMutex
+	// deprecations deprecation messages by DTO type and JSON field name.
+	deprecations = map[reflect.Type]map[string]string{}
+
+	deprecationUsageMu sync.Mutex
+	// deprecationUsage number of requests using each deprecated field, by "Type.field".
+	deprecationUsage = map[string]uint64{}
+)
+
+// DeprecateField registers a deprecated request field of the DTO T. The field may already be removed from T:
+// requests still sending it are accepted, and get a DEPRECATED warning with the message. Usage is logged
+// and counted (see DeprecatedFieldUsage) to follow client migrations.
+//
+// Example Usage:
+//
+//	http.DeprecateField[dto.CreateUser]("name", "Use 'full_name' instead, 'name' will be removed on 2025-06-01")
+func DeprecateField[T any](field, message string) {
+	typ := reflect.TypeFor[T]()
+
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+
+	if deprecations[typ] == nil {
+		deprecations[typ] = map[string]string{}
+	}
+	deprecations[typ][field] = message
+}
+
+// DeprecatedFieldUsage returns the number of requests which used each deprecated field, by "Type.field".
+func DeprecatedFieldUsage() map[string]uint64 {
+	deprecationUsageMu.Lock()
+	defer deprecationUsageMu.Unlock()
+
+	usage := make(map[string]uint64, len(deprecationUsage))
+	for key, count := range deprecationUsage {
+		usage[key] = count
+	}
+
+	return usage
+}
+
+// isDeprecatedField checks the JSON field of the DTO type is registered with DeprecateField, so bodies still
+// sending it are accepted when unknown fields are rejected.
+func isDeprecatedField(typ reflect.Type, field string) bool {
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+
+	_, ok := deprecations[typ][field]
+
+	return ok
+}
+
+// checkDeprecatedFields adds a warning for each deprecated field of T present in the request body.
+// It is called by ProcessData and ProcessUpdateData.
+func checkDeprecatedFields[T any](c *core.Ctx) {
+	typ := reflect.TypeFor[T]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	deprecationsMu.RLock()
+	fields := deprecations[typ]
+	deprecationsMu.RUnlock()
+
+	if len(fields) == 0 {
+		return
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(c.Root().PostBody(), &document); err != nil {
+		return
+	}
+
+	for field, message := range fields {
+		if _, ok := document[field]; !ok {
+			continue
+		}
+
+		key := fmt.Sprintf("%s.%s", typ.Name(), field)
+
+		deprecationUsageMu.Lock()
+		deprecationUsage[key]++
+		deprecationUsageMu.Unlock()
+
+		log.Warnf("Deprecated field %s used by %s (%s)", key, c.ClientIP(), c.GetHeader(core.HeaderUserAgent))
+		AddWarning(c, WarningDeprecated, message, field)
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================= Dry Runs =============================
+// ====================================================================
+
+const (
+	// DryRunParam query parameter requesting a dry run, e.g. `?dry_run=1`.
+	DryRunParam = "dry_run"
+	// HeaderPreferenceApplied response header acknowledging the preferences of the Prefer header (RFC 7240).
+	HeaderPreferenceApplied = "Preference-Applied"
+)
+
+// ErrorCodeDryRunUnsupported code of the Error returned for dry-run requests to endpoints not honoring them.
+const ErrorCodeDryRunUnsupported = "DRY_RUN_UNSUPPORTED"
+
+// preferDryRun preference of the Prefer header requesting a dry run.
+const preferDryRun = "dry-run"
+
+// DryRunResult struct to describe what a dry-run request would have done.
+// @Description Outcome of a dry run: the request is valid and nothing was committed
+// @Action Action is the action which would have been performed.
+// @Affected Affected is the number of records which would have been changed.
+// @Fields Fields are the fields which would have been changed, updates only.
+// @Data Data is the resource as it would have been stored.
+// @Tags Dry Runs
+type DryRunResult struct {
+	DryRun   bool      `json:"dry_run" example:"true" doc:"Always true, nothing was committed"`
+	Action   string    `json:"action" example:"update" doc:"Action which would have been performed"`
+	Affected int       `json:"affected" example:"1" doc:"Number of records which would have been changed"`
+	Fields   []string  `json:"fields,omitempty" example:"name,email" doc:"Fields which would have been changed"`
+	Data     any       `json:"data,omitempty" doc:"Resource as it would have been stored"`
+	Warnings []Warning `json:"warnings,omitempty" doc:"Non-fatal notices about the request"`
+}
+
+// dryRunHandler handler wrapper declaring the endpoint honors dry runs.
+type dryRunHandler struct {
+	core.IHandler
+}
+
+// AllowDryRun wraps a mutation handler which honors dry runs, requested with a `Prefer: dry-run` header or a
+// `dry_run=1` query parameter: the request is parsed and validated as usual (ProcessData, ProcessUpdateData,
+// ProcessPatchData, ProcessJSONPatch, ProcessRequest), then the handler checks IsDryRun and must not commit
+// anything. The Process helpers reject dry-run requests to endpoints not wrapped with 400 DRY_RUN_UNSUPPORTED,
+// so a handler unaware of dry runs never commits a request the client believes was only simulated.
+//
+// Example Usage:
+//
+//	router.POST("/orders", http.AllowDryRun(api.NewCreateOrderApi()))
+func AllowDryRun(handler core.IHandler) core.IHandler {
+	return &dryRunHandler{IHandler: handler}
+}
+
+// Validate flags dry runs before the wrapped Validate runs the Process helpers.
+func (h *dryRunHandler) Validate(c *core.Ctx) error {
+	if requestsDryRun(c) {
+		confirmDryRun(c)
+	} else {
+		c.SetData(DryRunKey, false)
+	}
+
+	return h.IHandler.Validate(c)
+}
+
+// IsDryRun checks the request is a dry run of an endpoint honoring them (see AllowDryRun): the handler must not
+// commit anything. Events recorded by dry runs are not emitted.
+//
+// Example Usage:
+//
+//	func (h CreateOrderApi) Handle(c *core.Ctx) error {
+//		requestData := c.GetData(http.RequestKey).(dto.CreateOrder)
+//		if http.IsDryRun(c) {
+//			return http.WriteDryRun(c, http.DryRunResult{Affected: len(requestData.Items)})
+//		}
+//		...
+//	}
+func IsDryRun(c *core.Ctx) bool {
+	dryRun, _ := c.GetData(DryRunKey).(bool)
+
+	return dryRun
+}
+
+// checkDryRun rejects the dry-run requests of endpoints not wrapped with AllowDryRun, their handler would
+// commit them.
+func checkDryRun(c *core.Ctx) error {
+	if _, allowed := c.GetData(DryRunKey).(bool); allowed || !requestsDryRun(c) {
+		return nil
+	}
+
+	return c.Error(&Error{
+		Code:    ErrorCodeDryRunUnsupported,
+		Message: "Dry runs are not supported by this endpoint",
+	}, FailureStatus(c, FailureMalformed))
+}
+
+// confirmDryRun flags the request as a dry run and acknowledges a `Prefer: dry-run` header.
+func confirmDryRun(c *core.Ctx) {
+	c.SetData(DryRunKey, true)
+
+	if preferred(c.GetHeader("Prefer"), preferDryRun) {
+		c.Root().Response.Header.Set(HeaderPreferenceApplied, preferDryRun)
+	}
+}
+
+// requestsDryRun checks the Prefer header and the query parameter of the request.
+func requestsDryRun(c *core.Ctx) bool {
+	if preferred(c.GetHeader("Prefer"), preferDryRun) {
+		return true
+	}
+
+	dryRun, err := strconv.ParseBool(c.QueryStr(DryRunParam))
+
+	return err == nil && dryRun
+}
+
+// preferred checks a Prefer header holds the preference name.
+func preferred(prefer, name string) bool {
+	for _, preference := range strings.Split(prefer, ",") {
+		token, _, _ := strings.Cut(preference, ";")
+		token, _, _ = strings.Cut(token, "=")
+		if strings.EqualFold(strings.TrimSpace(token), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteDryRun responds 200 OK with the outcome of a dry run, confirming the request committed nothing: its events
+// are not emitted and a `Prefer: dry-run` header is acknowledged. Empty members are filled from the request:
+// the action from the method (create, update, delete), the fields from the field mask of updates (see
+// GetFieldMask) and the data from the request DTO stored by the Process helpers.
+//
+// Example Usage:
+//
+//	if http.IsDryRun(c) {
+//		return http.WriteDryRun(c, http.DryRunResult{})
+//	}
+//
+//	// PATCH /users/42?dry_run=1 {"name":"An"}
+//	// {"dry_run":true,"action":"update","affected":1,"fields":["name"],"data":{"id":42,"name":"An",...}}
+func WriteDryRun(c *core.Ctx, result DryRunResult) error {
+	confirmDryRun(c)
+
+	result.DryRun = true
+	if result.Action == "" {
+		result.Action = dryRunAction(string(c.Root().Method()))
+	}
+	if result.Fields == nil {
+		result.Fields = GetFieldMask(c).Fields
+	}
+	if result.Data == nil {
+		result.Data = c.GetData(RequestKey)
+	}
+	if result.Affected == 0 && result.Data != nil {
+		result.Affected = 1
+	}
+	result.Warnings = append(result.Warnings, GetWarnings(c)...)
+
+	return writeJSON(c, core.StatusOK, result)
+}
+
+// dryRunAction returns the action of a method.
+func dryRunAction(method string) string {
+	switch method {
+	case core.MethodPost:
+		return "create"
+	case core.MethodPut, core.MethodPatch:
+		return "update"
+	case core.MethodDelete:
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
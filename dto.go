@@ -10,10 +10,25 @@ package http
 // @PerPage PerPage is the number of items to display per page (optional)
 // @Keyword Keyword is used for searching/filtering records by text content
 // @OrderBy OrderBy specifies the field to sort by, prefix with '-' for descending order
+// @Conditions Conditions are the filter conditions checked against the resource descriptor (ProcessFilterAs)
 // @Tags Request Filters
 type Filter struct {
 	Page    int    `json:"page" example:"1" validate:"number" doc:"Current page number for pagination"`
 	PerPage int    `json:"per_page" example:"10" validate:"number" doc:"Number of items to display per page"`
 	Keyword string `json:"keyword" example:"search term" validate:"" doc:"Search keyword for filtering records"`
 	OrderBy string `json:"order_by" example:"-created_at" validate:"" doc:"Field to order by, prefix with '-' for descending order"`
+
+	Conditions []FilterCondition `json:"conditions,omitempty" doc:"Filter conditions checked against the resource descriptor"`
+}
+
+// FilterCondition struct to describe a filter condition such as `filter[price][gte]=10`.
+// @Description Filter condition on a field of the resource
+// @Field Field is the filtered field name
+// @Operator Operator is the comparison operator (eq, ne, gt, gte, lt, lte, in, like)
+// @Value Value is the typed value, a list for the `in` operator
+// @Tags Request Filters
+type FilterCondition struct {
+	Field    string `json:"field" example:"price" doc:"Filtered field name"`
+	Operator string `json:"operator" example:"gte" doc:"Comparison operator"`
+	Value    any    `json:"value" example:"10" doc:"Typed value of the condition"`
 }
@@ -0,0 +1,854 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ====================================================================
+// ========================== DTO Generator ===========================
+// ====================================================================
+
+// DTOGeneratorOptions options of GenerateDTOs.
+type DTOGeneratorOptions struct {
+	Package      string // Package name of the generated files, "dto" when empty
+	ModelPackage string // Import path of the models mapped by transformers, stubs taking any when empty
+	Tag          string // @Tags annotation of the generated types, none when empty
+	Source       string // Name of the schema document, mentioned in the file headers
+}
+
+// GeneratedDTOs formatted Go files generated by GenerateDTOs.
+type GeneratedDTOs struct {
+	DTOs         []byte // Types of the schemas
+	Transformers []byte // Stub transformers of the object schemas
+}
+
+// GenerateDTOs scaffolds the Go DTOs of the schemas of an OpenAPI document (components.schemas, or definitions
+// for Swagger 2) or a JSON Schema ($defs, definitions, or a root object schema with a title), in JSON or YAML.
+// Object schemas become structs whose fields carry the package's tags: `json`, `example` (example or first of
+// examples), `validate` (required properties, lengths, bounds, enums, formats, `x-validate`), `sanitize`
+// (`x-sanitize`) and `doc` (description), mirroring what OpenAPIDocument generates. Stub transformers map the
+// models of ModelPackage to the structs. The code is a starting point to review, see cmd/dtogen.
+//
+// Example Usage:
+//
+//	document, _ := os.ReadFile("openapi.yaml")
+//	generated, err := http.GenerateDTOs(document, http.DTOGeneratorOptions{Package: "dto", Source: "openapi.yaml"})
+func GenerateDTOs(document []byte, options DTOGeneratorOptions) (GeneratedDTOs, error) {
+	var generated GeneratedDTOs
+	if options.Package == "" {
+		options.Package = "dto"
+	}
+
+	// Compact JSON documents, YAML does not allow their tab indentation
+	if json.Valid(document) {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, document); err == nil {
+			document = compacted.Bytes()
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return generated, fmt.Errorf("invalid schema document: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return generated, errors.New("invalid schema document: not an object")
+	}
+
+	generator := &dtoGenerator{
+		options:  options,
+		names:    map[string]string{},
+		declared: map[string]bool{},
+		imports:  map[string]bool{},
+		fields:   map[string][]string{},
+	}
+	if err := generator.collect(root.Content[0]); err != nil {
+		return generated, err
+	}
+	if err := generator.generate(); err != nil {
+		return generated, err
+	}
+
+	var err error
+	if generated.DTOs, err = generator.dtoFile(); err != nil {
+		return generated, err
+	}
+	generated.Transformers, err = generator.transformerFile()
+
+	return generated, err
+}
+
+// genSchema JSON schema read by the generator.
+type genSchema struct {
+	ref, format, description, title, pattern string
+	types                                    []string
+	nullable                                 bool
+	properties                               []genProperty
+	required                                 []string
+	items                                    *genSchema
+	additional                               *genSchema // additionalProperties
+	enum                                     []any
+	example                                  any
+	hasExample                               bool
+	minLength, maxLength, minItems, maxItems *float64
+	minimum, maximum                         *float64
+	exclusiveMinimum, exclusiveMaximum       bool
+	allOf, oneOf, anyOf                      []*genSchema
+	sanitize, validate                       string // x-sanitize and x-validate extensions
+}
+
+// genProperty property of an object schema.
+type genProperty struct {
+	name   string
+	schema *genSchema
+}
+
+// namedSchema schema declared in the document.
+type namedSchema struct {
+	name   string
+	schema *genSchema
+}
+
+// dtoGenerator state of GenerateDTOs.
+type dtoGenerator struct {
+	options  DTOGeneratorOptions
+	schemas  []namedSchema
+	byName   map[string]*genSchema
+	names    map[string]string // Go type names by schema name
+	declared map[string]bool   // Declared Go type names
+	pending  []namedSchema     // Inline object schemas to declare, by Go type name
+	imports  map[string]bool
+	body     bytes.Buffer
+	structs  []string            // Go names of the named struct types, for transformers
+	fields   map[string][]string // Go field names by struct type
+}
+
+// collect reads the named schemas of the document.
+func (g *dtoGenerator) collect(root *yaml.Node) error {
+	var nodes []*yaml.Node
+	if components := mappingValue(root, "components"); components != nil {
+		nodes = append(nodes, mappingValue(components, "schemas"))
+	}
+	nodes = append(nodes, mappingValue(root, "definitions"), mappingValue(root, "$defs"))
+
+	g.byName = map[string]*genSchema{}
+	for _, node := range nodes {
+		if node == nil || node.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			schema, err := parseGenSchema(node.Content[i+1])
+			if err != nil {
+				return fmt.Errorf("schema %s: %w", node.Content[i].Value, err)
+			}
+			g.schemas = append(g.schemas, namedSchema{name: node.Content[i].Value, schema: schema})
+		}
+	}
+
+	// JSON Schema documents describing a single object
+	if mappingValue(root, "properties") != nil && mappingValue(root, "openapi") == nil {
+		schema, err := parseGenSchema(root)
+		if err != nil {
+			return err
+		}
+		if schema.title == "" {
+			return errors.New("root schema has no title to name its type")
+		}
+		g.schemas = append(g.schemas, namedSchema{name: schema.title, schema: schema})
+	}
+
+	if len(g.schemas) == 0 {
+		return errors.New("no schemas found in the document")
+	}
+
+	for _, named := range g.schemas {
+		goName := goIdentifier(named.name)
+		if g.declared[goName] {
+			return fmt.Errorf("schemas %s and another one are both named %s in Go", named.name, goName)
+		}
+		g.declared[goName] = true
+		g.names[named.name] = goName
+		g.byName[named.name] = named.schema
+	}
+
+	return nil
+}
+
+// generate declares the types of the named schemas, then the inline object schemas they use.
+func (g *dtoGenerator) generate() error {
+	for _, named := range g.schemas {
+		if err := g.declare(g.names[named.name], named.schema, true); err != nil {
+			return fmt.Errorf("schema %s: %w", named.name, err)
+		}
+	}
+
+	for len(g.pending) > 0 {
+		inline := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.declare(inline.name, inline.schema, false); err != nil {
+			return fmt.Errorf("type %s: %w", inline.name, err)
+		}
+	}
+
+	return nil
+}
+
+// declare writes the declaration of a type.
+func (g *dtoGenerator) declare(name string, schema *genSchema, named bool) error {
+	if !isObjectSchema(schema) {
+		typ, err := g.goType(schema, name+"Value")
+		if err != nil {
+			return err
+		}
+
+		g.comment(name, "type", schema)
+		if len(schema.enum) > 0 {
+			fmt.Fprintf(&g.body, "// Values: %s\n", enumList(schema.enum, ", "))
+		}
+		fmt.Fprintf(&g.body, "type %s %s\n\n", name, typ)
+
+		return nil
+	}
+
+	var lines []string
+	var docs []string
+	for _, member := range schema.allOf {
+		if member.ref != "" {
+			embedded, err := g.refName(member.ref)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, "\t"+embedded)
+
+			continue
+		}
+
+		memberLines, memberDocs, err := g.structFields(name, member)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, memberLines...)
+		docs = append(docs, memberDocs...)
+	}
+
+	fieldLines, fieldDocs, err := g.structFields(name, schema)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, fieldLines...)
+	docs = append(docs, fieldDocs...)
+
+	g.comment(name, "struct", schema)
+	for _, doc := range docs {
+		g.body.WriteString(doc)
+	}
+	if g.options.Tag != "" {
+		fmt.Fprintf(&g.body, "// @Tags %s\n", g.options.Tag)
+	}
+	fmt.Fprintf(&g.body, "type %s struct {\n%s\n}\n\n", name, strings.Join(lines, "\n"))
+
+	if named {
+		g.structs = append(g.structs, name)
+	}
+
+	return nil
+}
+
+// comment writes the doc comment of a type, kind being "struct" or "type".
+func (g *dtoGenerator) comment(name, kind string, schema *genSchema) {
+	fmt.Fprintf(&g.body, "// %s %s to describe the %s schema.\n", name, kind, name)
+	if description := oneLine(schema.description); description != "" {
+		fmt.Fprintf(&g.body, "// @Description %s\n", description)
+	}
+}
+
+// structFields returns the field lines of the properties of an object schema, and their doc comment lines.
+func (g *dtoGenerator) structFields(typeName string, schema *genSchema) (lines, docs []string, err error) {
+	for _, property := range schema.properties {
+		fieldName := goIdentifier(property.name)
+		typ, err := g.goType(property.schema, typeName+fieldName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("property %s: %w", property.name, err)
+		}
+		if isNullable(property.schema) && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "any" {
+			typ = "*" + typ
+		}
+
+		tags := []string{`json:"` + property.name + `"`}
+		if example, ok := exampleTag(property.schema); ok {
+			tags = append(tags, "example:"+quoteTag(example))
+		}
+		required := slices.Contains(schema.required, property.name)
+		if rules := g.rules(property.schema, required); rules != "" {
+			tags = append(tags, "validate:"+quoteTag(rules))
+		}
+		if property.schema.sanitize != "" {
+			tags = append(tags, "sanitize:"+quoteTag(property.schema.sanitize))
+		}
+		description := oneLine(property.schema.description)
+		if description != "" {
+			tags = append(tags, "doc:"+quoteTag(strings.TrimSuffix(description, ".")))
+			docs = append(docs, fmt.Sprintf("// @%s %s is %s\n", fieldName, fieldName, lowerFirst(description)))
+		}
+
+		line := fmt.Sprintf("\t%s %s `%s`", fieldName, typ, strings.Join(tags, " "))
+		if pattern := g.resolve(property.schema).pattern; pattern != "" {
+			// Patterns are validated by name, see RegisterPattern
+			line += " // pattern " + pattern
+		}
+		lines = append(lines, line)
+		g.fields[typeName] = append(g.fields[typeName], fieldName)
+	}
+
+	return lines, docs, nil
+}
+
+// goType returns the Go type of a schema. Inline object schemas are declared as context.
+func (g *dtoGenerator) goType(schema *genSchema, context string) (string, error) {
+	if schema.ref != "" {
+		return g.refName(schema.ref)
+	}
+	if len(schema.allOf) == 1 && len(schema.properties) == 0 {
+		return g.goType(schema.allOf[0], context)
+	}
+	if len(schema.oneOf) > 0 || len(schema.anyOf) > 0 {
+		return "any", nil
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		switch schema.format {
+		case "date-time":
+			g.imports["time"] = true
+
+			return "time.Time", nil
+		case "date":
+			g.imports["github.com/gflydev/http"] = true
+
+			return "http.Date", nil
+		case "time":
+			g.imports["github.com/gflydev/http"] = true
+
+			return "http.TimeOfDay", nil
+		case "byte", "binary":
+			return "[]byte", nil
+		default:
+			return "string", nil
+		}
+	case "integer":
+		switch schema.format {
+		case "int64":
+			return "int64", nil
+		case "int32":
+			return "int32", nil
+		default:
+			return "int", nil
+		}
+	case "number":
+		if schema.format == "float" {
+			return "float32", nil
+		}
+
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if schema.items == nil {
+			return "[]any", nil
+		}
+		item, err := g.goType(schema.items, context+"Item")
+
+		return "[]" + item, err
+	case "object":
+		if isObjectSchema(schema) {
+			return g.inline(context, schema), nil
+		}
+		if schema.additional != nil {
+			value, err := g.goType(schema.additional, context+"Value")
+
+			return "map[string]" + value, err
+		}
+
+		return "map[string]any", nil
+	default:
+		return "any", nil
+	}
+}
+
+// inline queues the declaration of an inline object schema and returns its unique Go type name.
+func (g *dtoGenerator) inline(name string, schema *genSchema) string {
+	unique := name
+	for i := 2; g.declared[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.declared[unique] = true
+	g.pending = append(g.pending, namedSchema{name: unique, schema: schema})
+
+	return unique
+}
+
+// refName returns the Go type name of a local reference, e.g. "#/components/schemas/User".
+func (g *dtoGenerator) refName(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return "", fmt.Errorf("external reference %s is not supported", ref)
+	}
+
+	name := path.Base(ref)
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+	if goName, ok := g.names[name]; ok {
+		return goName, nil
+	}
+
+	return "", fmt.Errorf("unresolved reference %s", ref)
+}
+
+// resolve returns the schema referenced by a schema, the schema itself when it is not a reference.
+func (g *dtoGenerator) resolve(schema *genSchema) *genSchema {
+	if schema.ref != "" {
+		if referenced, ok := g.byName[path.Base(schema.ref)]; ok {
+			return referenced
+		}
+	}
+
+	return schema
+}
+
+// rules returns the `validate` tag of a property.
+func (g *dtoGenerator) rules(schema *genSchema, required bool) string {
+	var rules []string
+
+	resolved := g.resolve(schema)
+	switch schemaType(resolved) {
+	case "string":
+		rules = appendBound(rules, "min", resolved.minLength, false)
+		rules = appendBound(rules, "max", resolved.maxLength, false)
+		if rule, ok := formatRules[resolved.format]; ok {
+			rules = append(rules, rule)
+		}
+	case "integer", "number":
+		rules = appendBound(rules, "gte", resolved.minimum, resolved.exclusiveMinimum)
+		rules = appendBound(rules, "lte", resolved.maximum, resolved.exclusiveMaximum)
+	case "array":
+		rules = appendBound(rules, "min", resolved.minItems, false)
+		rules = appendBound(rules, "max", resolved.maxItems, false)
+		if resolved.items != nil && isObjectSchema(g.resolve(resolved.items)) {
+			rules = append(rules, "dive")
+		}
+	default:
+	}
+	if len(resolved.enum) > 0 {
+		rules = append(rules, "oneof="+enumList(resolved.enum, " "))
+	}
+	if schema.validate != "" {
+		rules = append(rules, schema.validate)
+	}
+
+	switch {
+	case required:
+		rules = append([]string{"required"}, rules...)
+	case len(rules) > 0 && rules[0] != "dive":
+		rules = append([]string{"omitempty"}, rules...)
+	}
+
+	return strings.Join(rules, ",")
+}
+
+// formatRules validation rules of string formats.
+var formatRules = map[string]string{
+	"email":    "email",
+	"uri":      "url",
+	"url":      "url",
+	"uuid":     "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+// appendBound appends the rule of a bound, the exclusive variant (gt, lt) when exclusive.
+func appendBound(rules []string, rule string, bound *float64, exclusive bool) []string {
+	if bound == nil {
+		return rules
+	}
+	if exclusive {
+		rule = strings.TrimSuffix(rule, "e")
+	}
+
+	return append(rules, rule+"="+strconv.FormatFloat(*bound, 'f', -1, 64))
+}
+
+// dtoFile returns the formatted file of the types.
+func (g *dtoGenerator) dtoFile() ([]byte, error) {
+	var file bytes.Buffer
+	g.header(&file)
+
+	// Standard library imports first, then modules
+	var standard, modules []string
+	for importPath := range g.imports {
+		if strings.Contains(importPath, ".") {
+			modules = append(modules, strconv.Quote(importPath))
+		} else {
+			standard = append(standard, strconv.Quote(importPath))
+		}
+	}
+	slices.Sort(standard)
+	slices.Sort(modules)
+	if len(standard) > 0 && len(modules) > 0 {
+		standard = append(standard, "")
+	}
+	if imports := append(standard, modules...); len(imports) > 0 {
+		fmt.Fprintf(&file, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	file.Write(g.body.Bytes())
+
+	return formatSource(file.Bytes())
+}
+
+// transformerFile returns the formatted file of the stub transformers.
+func (g *dtoGenerator) transformerFile() ([]byte, error) {
+	var file bytes.Buffer
+	g.header(&file)
+
+	model := ""
+	if g.options.ModelPackage != "" {
+		model = path.Base(g.options.ModelPackage)
+		fmt.Fprintf(&file, "import %q\n\n", g.options.ModelPackage)
+	}
+
+	for _, name := range g.structs {
+		if model == "" {
+			fmt.Fprintf(&file, "// To%s transforms a record into the %s DTO.\n", name, name)
+			file.WriteString("// TODO: replace any with the record type and map its fields.\n")
+			fmt.Fprintf(&file, "func To%s(record any) %s {\n\treturn %s{\n", name, name, name)
+			for _, field := range g.fields[name] {
+				fmt.Fprintf(&file, "\t\t// %s: record.%s,\n", field, field)
+			}
+		} else {
+			fmt.Fprintf(&file, "// To%s transforms a %s.%s into the %s DTO.\n", name, model, name, name)
+			fmt.Fprintf(&file, "func To%s(record %s.%s) %s {\n\treturn %s{\n", name, model, name, name, name)
+			for _, field := range g.fields[name] {
+				fmt.Fprintf(&file, "\t\t%s: record.%s,\n", field, field)
+			}
+		}
+		file.WriteString("\t}\n}\n\n")
+	}
+
+	return formatSource(file.Bytes())
+}
+
+// header writes the header and the package clause of a generated file.
+func (g *dtoGenerator) header(file *bytes.Buffer) {
+	source := ""
+	if g.options.Source != "" {
+		source = " from " + g.options.Source
+	}
+	fmt.Fprintf(file, "// Scaffolded by dtogen%s: review the types and rules, then edit as needed.\n\n", source)
+	fmt.Fprintf(file, "package %s\n\n", g.options.Package)
+}
+
+// formatSource formats generated Go code.
+func formatSource(source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return source, fmt.Errorf("generated code is invalid: %w", err)
+	}
+
+	return formatted, nil
+}
+
+// parseGenSchema reads a schema node.
+func parseGenSchema(node *yaml.Node) (*genSchema, error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	schema := &genSchema{}
+	if node.Kind != yaml.MappingNode {
+		// Boolean schemas
+		return schema, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		var err error
+		switch key {
+		case "$ref":
+			schema.ref = value.Value
+		case "type":
+			if value.Kind == yaml.SequenceNode {
+				err = value.Decode(&schema.types)
+			} else {
+				schema.types = []string{value.Value}
+			}
+		case "format":
+			schema.format = value.Value
+		case "description":
+			schema.description = value.Value
+		case "title":
+			schema.title = value.Value
+		case "pattern":
+			schema.pattern = value.Value
+		case "nullable":
+			err = value.Decode(&schema.nullable)
+		case "required":
+			if value.Kind == yaml.SequenceNode {
+				err = value.Decode(&schema.required)
+			}
+		case "properties":
+			for j := 0; j+1 < len(value.Content) && err == nil; j += 2 {
+				var property *genSchema
+				if property, err = parseGenSchema(value.Content[j+1]); err == nil {
+					schema.properties = append(schema.properties, genProperty{name: value.Content[j].Value, schema: property})
+				}
+			}
+		case "items":
+			schema.items, err = parseGenSchema(value)
+		case "additionalProperties":
+			if value.Kind == yaml.MappingNode {
+				schema.additional, err = parseGenSchema(value)
+			}
+		case "enum":
+			err = value.Decode(&schema.enum)
+		case "example":
+			schema.hasExample = true
+			err = value.Decode(&schema.example)
+		case "examples":
+			var examples []any
+			if value.Kind == yaml.SequenceNode {
+				err = value.Decode(&examples)
+			}
+			if len(examples) > 0 {
+				schema.example, schema.hasExample = examples[0], true
+			}
+		case "minLength":
+			schema.minLength, err = decodeBound(value)
+		case "maxLength":
+			schema.maxLength, err = decodeBound(value)
+		case "minItems":
+			schema.minItems, err = decodeBound(value)
+		case "maxItems":
+			schema.maxItems, err = decodeBound(value)
+		case "minimum":
+			schema.minimum, err = decodeBound(value)
+		case "maximum":
+			schema.maximum, err = decodeBound(value)
+		case "exclusiveMinimum":
+			schema.exclusiveMinimum, err = decodeExclusive(value, &schema.minimum)
+		case "exclusiveMaximum":
+			schema.exclusiveMaximum, err = decodeExclusive(value, &schema.maximum)
+		case "allOf", "oneOf", "anyOf":
+			var members []*genSchema
+			for _, item := range value.Content {
+				member, memberErr := parseGenSchema(item)
+				if memberErr != nil {
+					err = memberErr
+					break
+				}
+				members = append(members, member)
+			}
+			switch key {
+			case "allOf":
+				schema.allOf = members
+			case "oneOf":
+				schema.oneOf = members
+			default:
+				schema.anyOf = members
+			}
+		case "x-sanitize":
+			schema.sanitize = value.Value
+		case "x-validate":
+			schema.validate = value.Value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return schema, nil
+}
+
+// decodeBound decodes a numeric keyword.
+func decodeBound(node *yaml.Node) (*float64, error) {
+	var bound float64
+	if err := node.Decode(&bound); err != nil {
+		return nil, err
+	}
+
+	return &bound, nil
+}
+
+// decodeExclusive decodes exclusiveMinimum and exclusiveMaximum: a flag of the bound (OpenAPI 3.0, JSON Schema
+// draft 4) or the bound itself (OpenAPI 3.1, later drafts).
+func decodeExclusive(node *yaml.Node, bound **float64) (bool, error) {
+	if node.Tag == "!!bool" {
+		var exclusive bool
+		err := node.Decode(&exclusive)
+
+		return exclusive, err
+	}
+
+	decoded, err := decodeBound(node)
+	if err != nil {
+		return false, err
+	}
+	*bound = decoded
+
+	return true, nil
+}
+
+// mappingValue returns the value of a key of a mapping node, nil when absent.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// schemaType returns the type of a schema other than null, "object" for schemas with properties only.
+func schemaType(schema *genSchema) string {
+	for _, typ := range schema.types {
+		if typ != "null" {
+			return typ
+		}
+	}
+	if len(schema.properties) > 0 || len(schema.allOf) > 0 || schema.additional != nil {
+		return "object"
+	}
+
+	return ""
+}
+
+// isObjectSchema checks a schema declares a struct: an object with properties, or a composition.
+func isObjectSchema(schema *genSchema) bool {
+	if schema.ref != "" || schemaType(schema) != "object" {
+		return false
+	}
+
+	return len(schema.properties) > 0 || len(schema.allOf) > 1 || len(schema.allOf) == 1 && schema.allOf[0].ref == ""
+}
+
+// isNullable checks a schema accepts null.
+func isNullable(schema *genSchema) bool {
+	return schema.nullable || slices.Contains(schema.types, "null")
+}
+
+// exampleTag returns the `example` tag of a schema: strings as is, other values as JSON.
+func exampleTag(schema *genSchema) (string, bool) {
+	if !schema.hasExample {
+		return "", false
+	}
+	if text, ok := schema.example.(string); ok {
+		return text, true
+	}
+
+	encoded, err := json.Marshal(schema.example)
+	if err != nil {
+		return "", false
+	}
+
+	return string(encoded), true
+}
+
+// enumList returns the values of an enum separated by sep, values with spaces being quoted for oneof.
+func enumList(values []any, sep string) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = fmt.Sprint(value)
+		if strings.ContainsRune(items[i], ' ') {
+			items[i] = "'" + items[i] + "'"
+		}
+	}
+
+	return strings.Join(items, sep)
+}
+
+// quoteTag quotes the value of a struct tag, backquotes can not appear in struct tags.
+func quoteTag(value string) string {
+	return strconv.Quote(strings.ReplaceAll(value, "`", "'"))
+}
+
+// oneLine returns a description on a single line.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// lowerFirst lowers the first letter of a sentence, unless it starts an acronym.
+func lowerFirst(text string) string {
+	runes := []rune(text)
+	if len(runes) > 1 && unicode.IsUpper(runes[1]) {
+		return text
+	}
+	if len(runes) > 0 {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+
+	return string(runes)
+}
+
+// goInitialisms words written in capitals in Go identifiers.
+var goInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true, "json": true, "sku": true,
+	"sql": true, "ssl": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goIdentifier converts a schema or property name (snake_case, camelCase, kebab-case, dotted) to an exported
+// Go identifier, e.g. "user_id" -> "UserID".
+func goIdentifier(name string) string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words, word = appendWord(words, word), nil
+		case unicode.IsUpper(r) && len(word) > 0 &&
+			(unicode.IsLower(word[len(word)-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			words, word = appendWord(words, word), []rune{r}
+		default:
+			word = append(word, r)
+		}
+	}
+	words = appendWord(words, word)
+
+	var identifier strings.Builder
+	for _, item := range words {
+		if goInitialisms[strings.ToLower(item)] {
+			identifier.WriteString(strings.ToUpper(item))
+		} else {
+			runes := []rune(item)
+			runes[0] = unicode.ToUpper(runes[0])
+			identifier.WriteString(string(runes))
+		}
+	}
+
+	result := identifier.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+
+	return result
+}
+
+// appendWord appends a non-empty word.
+func appendWord(words []string, word []rune) []string {
+	if len(word) == 0 {
+		return words
+	}
+
+	return append(words, string(word))
+}
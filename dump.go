@@ -0,0 +1,225 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ============================ Debug Dumps ===========================
+// ====================================================================
+
+// DebugDump redacted capture of a failed request and its response. Dumps are JSON serializable, so
+// production failures can be stored and replayed in tests with the replay package.
+type DebugDump struct {
+	RequestID  string              `json:"request_id"`            // RequestID of the captured request
+	Route      string              `json:"route"`                 // Method and route path template, e.g. "POST /orders"
+	Method     string              `json:"method"`                // Request method
+	URI        string              `json:"uri"`                   // Request URI: path and query, redacted
+	Headers    map[string][]string `json:"headers"`               // Request headers, redacted
+	Body       string              `json:"body,omitempty"`        // Request body as received, redacted
+	PathParams map[string]string   `json:"path_params,omitempty"` // Path parameters of the route
+	Status     int                 `json:"status"`                // Response status
+	Response   string              `json:"response,omitempty"`    // Response body, redacted
+	CapturedAt time.Time           `json:"captured_at"`           // Capture time
+}
+
+// DumpOptions configuration of Dumped.
+type DumpOptions struct {
+	MinStatus     int             // Status from which requests are dumped, 500 by default
+	RedactHeaders []string        // Headers replaced by RedactedValue, Authorization and Cookie by default
+	RedactFields  []string        // Query parameters and JSON or form body fields replaced by RedactedValue
+	Sink          func(DebugDump) // Receiver of the dumps, e.g. writing them to a bucket
+}
+
+// dumpedHandler handler wrapper dumping failed requests.
+type dumpedHandler struct {
+	core.IHandler
+	options DumpOptions
+}
+
+// Dumped wraps a handler so requests answered with a status of at least MinStatus are captured as a
+// redacted DebugDump and handed to the sink.
+//
+// Example Usage:
+//
+//	dumpOptions := http.DumpOptions{
+//		RedactFields: []string{"password", "card_number"},
+//		Sink: func(dump http.DebugDump) {
+//			encoded, _ := json.Marshal(dump)
+//			log.Errorf("Request dump: %s", encoded)
+//		},
+//	}
+//	router.POST("/orders", http.Dumped(api.NewCreateOrderApi(), dumpOptions))
+func Dumped(handler core.IHandler, options DumpOptions) core.IHandler {
+	if options.MinStatus == 0 {
+		options.MinStatus = core.StatusInternalServerError
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = []string{core.HeaderAuthorization, core.HeaderCookie}
+	}
+
+	return &dumpedHandler{IHandler: handler, options: options}
+}
+
+// Validate captures the request before the wrapped Validate (Parse may rewrite the body).
+func (h *dumpedHandler) Validate(c *core.Ctx) error {
+	if h.options.Sink != nil {
+		request := fasthttp.AcquireRequest()
+		c.Root().Request.CopyTo(request)
+		c.SetData(DumpKey, request)
+	}
+
+	err := h.IHandler.Validate(c)
+	if err != nil {
+		// Handle is not called for rejected requests
+		h.dump(c, err, core.StatusBadRequest)
+	}
+
+	return err
+}
+
+// Handle runs the wrapped handler, then dumps the request when it failed.
+func (h *dumpedHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	h.dump(c, err, core.StatusInternalServerError)
+
+	return err
+}
+
+// dump hands the dump of the captured request to the sink when the response status is high enough. The
+// router sets errorStatus to responses of errors still being 200 OK.
+func (h *dumpedHandler) dump(c *core.Ctx, err error, errorStatus int) {
+	request, ok := c.GetData(DumpKey).(*fasthttp.Request)
+	if !ok {
+		return
+	}
+	c.SetData(DumpKey, nil)
+	defer fasthttp.ReleaseRequest(request)
+
+	status := c.Root().Response.StatusCode()
+	if err != nil && status == core.StatusOK {
+		status = errorStatus
+	}
+	if status < h.options.MinStatus {
+		return
+	}
+
+	dump := captureDump(c, request, h.options)
+	dump.Status = status
+	h.options.Sink(dump)
+}
+
+// captureDump builds the redacted dump of a request with the current response.
+func captureDump(c *core.Ctx, request *fasthttp.Request, options DumpOptions) DebugDump {
+	dump := DebugDump{
+		RequestID:  RequestID(c),
+		Route:      string(request.Header.Method()) + " " + RoutePath(c),
+		Method:     string(request.Header.Method()),
+		URI:        redactQuery(request, options.RedactFields),
+		Headers:    map[string][]string{},
+		Status:     c.Root().Response.StatusCode(),
+		CapturedAt: time.Now().UTC(),
+	}
+
+	for header, value := range request.Header.All() {
+		dump.Headers[string(header)] = append(dump.Headers[string(header)], string(value))
+	}
+	for key, values := range dump.Headers {
+		for _, header := range options.RedactHeaders {
+			if strings.EqualFold(key, header) {
+				for i := range values {
+					values[i] = RedactedValue
+				}
+			}
+		}
+	}
+
+	body := request.Body()
+	response := c.Root().Response.Body()
+	if len(options.RedactFields) > 0 {
+		// Bodies which can not be redacted are omitted
+		var ok bool
+		if body, ok = RedactBody(request.Header.ContentType(), body, options.RedactFields); !ok {
+			body = nil
+		}
+		if response, ok = RedactBody(c.Root().Response.Header.ContentType(), response, options.RedactFields); !ok {
+			response = nil
+		}
+	}
+	dump.Body = string(body)
+	dump.Response = string(response)
+
+	c.Root().VisitUserValues(func(key []byte, value any) {
+		if param, ok := value.(string); ok {
+			if dump.PathParams == nil {
+				dump.PathParams = map[string]string{}
+			}
+			dump.PathParams[string(key)] = param
+		}
+	})
+
+	return dump
+}
+
+// redactQuery returns the URI of a request with the values of the query parameters named like the fields
+// (case-insensitive, `filter[password]` matching "password") replaced by RedactedValue.
+func redactQuery(request *fasthttp.Request, fields []string) string {
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+	request.URI().CopyTo(uri)
+
+	if !redactArgs(uri.QueryArgs(), fields) {
+		return string(request.RequestURI())
+	}
+
+	return string(uri.RequestURI())
+}
+
+// redactArgs replaces the values of the query or form arguments named like the fields (case-insensitive,
+// `filter[password]` matching "password") by RedactedValue, it reports whether any was.
+func redactArgs(args *fasthttp.Args, fields []string) bool {
+	var redacted []string
+	for key := range args.All() {
+		name := string(key)
+		if open := strings.LastIndexByte(name, '['); open >= 0 && strings.HasSuffix(name, "]") {
+			name = name[open+1 : len(name)-1]
+		}
+		for _, field := range fields {
+			if strings.EqualFold(name, field) {
+				redacted = append(redacted, string(key))
+			}
+		}
+	}
+
+	for _, key := range redacted {
+		args.Set(key, RedactedValue)
+	}
+
+	return len(redacted) > 0
+}
+
+// ====================================================================
+// ============================ Dump Replay ===========================
+// ====================================================================
+
+// LoadDump reads a DebugDump from a JSON file, e.g. a dump stored in a test's testdata directory.
+func LoadDump(path string) (DebugDump, error) {
+	var dump DebugDump
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dump, err
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return dump, fmt.Errorf("invalid dump %s: %w", path, err)
+	}
+
+	return dump, nil
+}
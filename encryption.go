@@ -0,0 +1,278 @@
+package http
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ====================================================================
+// ======================= Envelope Encryption ========================
+// ====================================================================
+
+// encryptedPrefix prefix of values produced by EncryptField.
+const encryptedPrefix = "enc:v1:"
+
+// EncryptedMask replaces encrypted fields for readers who are not authorized to see them.
+const EncryptedMask = "********"
+
+var (
+	// ErrNoKeyProvider returned when encryption is used without a registered KeyProvider.
+	ErrNoKeyProvider = errors.New("no key provider registered")
+	// ErrInvalidCiphertext returned when a value is not a valid envelope produced by EncryptField.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// KeyProvider is an interface for key management services (AWS KMS, GCP KMS, Vault, ...)
+// used for envelope encryption: each value is encrypted with a fresh data key, which is itself
+// encrypted (wrapped) by a master key held by the provider.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit data key in plaintext and wrapped form, and the master key ID.
+	GenerateDataKey() (plaintext, wrapped []byte, keyID string, err error)
+
+	// DecryptDataKey unwraps a data key wrapped by the master key keyID.
+	DecryptDataKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// keyProvider the provider used by EncryptField and DecryptField.
+var keyProvider KeyProvider
+
+// RegisterKeyProvider registers the key provider used by the `transform:"encrypt"` tag.
+func RegisterKeyProvider(provider KeyProvider) {
+	keyProvider = provider
+}
+
+// EncryptField envelope-encrypts a value with AES-256-GCM.
+// The result has the form "enc:v1:<keyID>:<wrapped key>:<nonce+ciphertext>" (base64 parts).
+func EncryptField(plaintext string) (string, error) {
+	if keyProvider == nil {
+		return "", ErrNoKeyProvider
+	}
+
+	dataKey, wrapped, keyID, err := keyProvider.GenerateDataKey()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := sealAESGCM(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(keyID)),
+		base64.RawURLEncoding.EncodeToString(wrapped),
+		base64.RawURLEncoding.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// DecryptField decrypts a value produced by EncryptField.
+func DecryptField(value string) (string, error) {
+	if keyProvider == nil {
+		return "", ErrNoKeyProvider
+	}
+	if !IsEncrypted(value) {
+		return "", ErrInvalidCiphertext
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", ErrInvalidCiphertext
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		data, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return "", ErrInvalidCiphertext
+		}
+		decoded[i] = data
+	}
+
+	dataKey, err := keyProvider.DecryptDataKey(string(decoded[0]), decoded[1])
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAESGCM(dataKey, decoded[2])
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted checks the value was produced by EncryptField.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// DecryptStruct is the response-side counterpart of `transform:"encrypt"`: string fields of the target
+// tagged with `transform:"encrypt"` are decrypted when the reader is authorized, and replaced by
+// EncryptedMask otherwise.
+//
+// Example Usage:
+//
+//	resp := CustomerResponse{SSN: customer.SSN} // SSN string `json:"ssn" transform:"encrypt"`
+//	if err := http.DecryptStruct(&resp, principal.HasRole("compliance")); err != nil {
+//		return err
+//	}
+func DecryptStruct(target any, authorized bool) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return nil
+	}
+
+	return decryptValue(val.Elem(), authorized)
+}
+
+func decryptValue(val reflect.Value, authorized bool) error {
+	switch val.Kind() {
+	case reflect.Pointer:
+		if !val.IsNil() {
+			return decryptValue(val.Elem(), authorized)
+		}
+	case reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			if !hasTagRule(typ.Field(i).Tag.Get("transform"), "encrypt") {
+				if err := decryptValue(field, authorized); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if field.Kind() == reflect.Pointer && !field.IsNil() {
+				field = field.Elem()
+			}
+			if field.Kind() != reflect.String || field.String() == "" {
+				continue
+			}
+
+			if !authorized {
+				field.SetString(EncryptedMask)
+
+				continue
+			}
+
+			plaintext, err := DecryptField(field.String())
+			if err != nil {
+				return fmt.Errorf("%s: %w", typ.Field(i).Name, err)
+			}
+			field.SetString(plaintext)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			if err := decryptValue(val.Index(i), authorized); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	return nil
+}
+
+// hasTagRule checks a comma-separated tag value contains the rule.
+func hasTagRule(tag, rule string) bool {
+	for _, item := range strings.Split(tag, ",") {
+		if strings.TrimSpace(item) == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ====================================================================
+// ======================== Local Key Provider ========================
+// ====================================================================
+
+// LocalKeyProvider KeyProvider wrapping data keys with a local 256-bit master key.
+// Suitable for development or when the master key comes from a secret manager at startup.
+type LocalKeyProvider struct {
+	keyID     string
+	masterKey []byte
+}
+
+// NewLocalKeyProvider creates a LocalKeyProvider; masterKey must be 32 bytes.
+func NewLocalKeyProvider(keyID string, masterKey []byte) (*LocalKeyProvider, error) {
+	if len(masterKey) != 32 {
+		return nil, errors.New("master key must be 32 bytes")
+	}
+
+	return &LocalKeyProvider{keyID: keyID, masterKey: masterKey}, nil
+}
+
+// GenerateDataKey creates a random data key wrapped by the master key.
+func (p *LocalKeyProvider) GenerateDataKey() (plaintext, wrapped []byte, keyID string, err error) {
+	plaintext = make([]byte, 32)
+	if _, err = rand.Read(plaintext); err != nil {
+		return nil, nil, "", err
+	}
+
+	wrapped, err = sealAESGCM(p.masterKey, plaintext)
+
+	return plaintext, wrapped, p.keyID, err
+}
+
+// DecryptDataKey unwraps a data key wrapped by the master key.
+func (p *LocalKeyProvider) DecryptDataKey(keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("unknown master key %q", keyID)
+	}
+
+	return openAESGCM(p.masterKey, wrapped)
+}
+
+// sealAESGCM encrypts with AES-GCM and prepends the random nonce.
+func sealAESGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openAESGCM decrypts a value produced by sealAESGCM.
+func openAESGCM(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
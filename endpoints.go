@@ -0,0 +1,284 @@
+package http
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ======================= Endpoint Descriptions ======================
+// ====================================================================
+
+// EndpointError an error response documented for an endpoint.
+type EndpointError struct {
+	Status  int    // HTTP status, e.g. 404
+	Code    string // Error code, e.g. "NOT_FOUND"
+	Message string // Error message, e.g. "Resource not found"
+}
+
+// EndpointDescription metadata of an endpoint registered with Describe.
+type EndpointDescription struct {
+	Method   string          // HTTP method
+	Path     string          // Route path template, e.g. "/users/{id}"
+	Request  reflect.Type    // Request DTO, nil when the endpoint has none
+	Response reflect.Type    // Response body, nil for 204 No Content
+	Errors   []EndpointError // Documented error responses
+	Tags     []string        // Groups of the endpoint in the documentation
+}
+
+// SuccessStatus returns the status of the endpoint's success response: 204 without response body, else 200.
+func (d EndpointDescription) SuccessStatus() int {
+	if d.Response == nil {
+		return core.StatusNoContent
+	}
+
+	return core.StatusOK
+}
+
+var (
+	describedMu sync.RWMutex
+	// described endpoint descriptions, in registration order.
+	described []EndpointDescription
+)
+
+// Describe registers the metadata of an endpoint, the single source of truth of the OpenAPI document
+// (OpenAPIDocument), the mock server (NewMockHandler), the error catalog (ErrorCatalog) and the Allow header
+// of OPTIONS responses (DescribedRoutes). The route is "METHOD /path" with the router's path template;
+// request and response are zero values of their types, nil when the endpoint has none. Describing a route
+// again replaces it.
+//
+// Example Usage:
+//
+//	http.Describe("PUT /users/{id}", dto.UpdateUser{}, response.User{}, []http.EndpointError{
+//		{Status: 404, Code: "NOT_FOUND", Message: "Resource not found"},
+//		{Status: 400, Code: "BAD_REQUEST", Message: "Invalid input"},
+//	}, "Users")
+func Describe(route string, request, response any, errors []EndpointError, tags ...string) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		log.Errorf("Invalid described route %q, expected \"METHOD /path\"", route)
+
+		return
+	}
+
+	description := EndpointDescription{
+		Method: strings.ToUpper(method),
+		Path:   path,
+		Errors: slices.Clone(errors),
+		Tags:   slices.Clone(tags),
+	}
+	if request != nil {
+		description.Request = reflect.TypeOf(request)
+	}
+	if response != nil {
+		description.Response = reflect.TypeOf(response)
+	}
+
+	describedMu.Lock()
+	defer describedMu.Unlock()
+
+	for i, existing := range described {
+		if existing.Method == description.Method && existing.Path == description.Path {
+			described[i] = description
+
+			return
+		}
+	}
+	described = append(described, description)
+}
+
+// DescribedEndpoints returns the described endpoints sorted by path, then method.
+func DescribedEndpoints() []EndpointDescription {
+	describedMu.RLock()
+	endpoints := slices.Clone(described)
+	describedMu.RUnlock()
+
+	slices.SortFunc(endpoints, func(a, b EndpointDescription) int {
+		if byPath := strings.Compare(a.Path, b.Path); byPath != 0 {
+			return byPath
+		}
+
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return endpoints
+}
+
+// describedEndpoint returns the described endpoint matching a request method and path.
+func describedEndpoint(method, path string) (EndpointDescription, bool) {
+	describedMu.RLock()
+	defer describedMu.RUnlock()
+
+	for _, endpoint := range described {
+		if endpoint.Method == method && matchRoute(endpoint.Path, path) {
+			return endpoint, true
+		}
+	}
+
+	return EndpointDescription{}, false
+}
+
+// describedRoutes RouteLister of the described endpoints.
+type describedRoutes struct{}
+
+// List returns the described route paths by method.
+func (describedRoutes) List() map[string][]string {
+	describedMu.RLock()
+	defer describedMu.RUnlock()
+
+	routes := map[string][]string{}
+	for _, endpoint := range described {
+		routes[endpoint.Method] = append(routes[endpoint.Method], endpoint.Path)
+	}
+
+	return routes
+}
+
+// DescribedRoutes returns the described endpoints as a RouteLister, so OPTIONS responses (see WriteOptions)
+// allow the documented methods.
+//
+// Example Usage:
+//
+//	app.Router().GlobalOPTIONS = http.NewOptionsHandler(http.DescribedRoutes())
+func DescribedRoutes() RouteLister {
+	return describedRoutes{}
+}
+
+// ====================================================================
+// =========================== Error Catalog ==========================
+// ====================================================================
+
+// CatalogError struct to describe an error code of the API.
+// @Description Error code returned by the API, with the endpoints returning it
+// @Code Code is the machine-readable error code.
+// @Status Status is the HTTP status of the error.
+// @Message Message is the error message.
+// @Endpoints Endpoints are the routes returning the error, as "METHOD /path".
+// @Tags Info Responses
+type CatalogError struct {
+	Code      string   `json:"code" example:"NOT_FOUND" doc:"Machine-readable error code"`
+	Status    int      `json:"status" example:"404" doc:"HTTP status of the error"`
+	Message   string   `json:"message" example:"Resource not found" doc:"Error message"`
+	Endpoints []string `json:"endpoints" example:"[\"GET /users/{id}\"]" doc:"Routes returning the error"`
+}
+
+// ErrorCatalog returns the errors of the described endpoints, one per code and status, sorted by code then
+// status. It is meant to be exported for client developers, or served by an endpoint.
+//
+// Example Usage:
+//
+//	func (h ErrorCatalogApi) Handle(c *core.Ctx) error {
+//		return c.Success(http.ErrorCatalog())
+//	}
+func ErrorCatalog() []CatalogError {
+	catalog := map[string]*CatalogError{}
+	for _, endpoint := range DescribedEndpoints() {
+		route := endpoint.Method + " " + endpoint.Path
+		for _, endpointError := range endpoint.Errors {
+			key := endpointError.Code + " " + strconv.Itoa(endpointError.Status)
+			entry, ok := catalog[key]
+			if !ok {
+				entry = &CatalogError{
+					Code:    endpointError.Code,
+					Status:  endpointError.Status,
+					Message: endpointError.Message,
+				}
+				catalog[key] = entry
+			}
+			if !slices.Contains(entry.Endpoints, route) {
+				entry.Endpoints = append(entry.Endpoints, route)
+			}
+		}
+	}
+
+	errors := make([]CatalogError, 0, len(catalog))
+	for _, entry := range catalog {
+		errors = append(errors, *entry)
+	}
+	slices.SortFunc(errors, func(a, b CatalogError) int {
+		if byCode := strings.Compare(a.Code, b.Code); byCode != 0 {
+			return byCode
+		}
+
+		return a.Status - b.Status
+	})
+
+	return errors
+}
+
+// ====================================================================
+// ============================ Mock Server ===========================
+// ====================================================================
+
+// NewMockHandler returns a handler answering requests of the described endpoints with an example of their
+// response: its registered example (see RegisterExample), else one built from the `example` tags of the
+// response type. The `Prefer: status=404` request header selects a documented error response instead.
+// Undescribed routes get a 404. It is meant as the router's NotFound handler of a mock server, letting clients
+// be developed before the API.
+//
+// Example Usage:
+//
+//	app.Router().NotFound = http.NewMockHandler()
+func NewMockHandler() core.RequestHandler {
+	return func(c *core.Ctx) error {
+		method := string(c.Root().Method())
+		if method == fasthttp.MethodHead {
+			method = fasthttp.MethodGet
+		}
+
+		endpoint, ok := describedEndpoint(method, string(c.Root().Path()))
+		if !ok {
+			if method == fasthttp.MethodOptions {
+				return WriteOptions(c, DescribedRoutes())
+			}
+
+			return WriteError(c, &Error{
+				Code:    "NOT_FOUND",
+				Message: "Resource not found",
+			}, core.StatusNotFound)
+		}
+
+		if preferred, ok := preferredStatus(c.GetHeader("Prefer")); ok {
+			for _, endpointError := range endpoint.Errors {
+				if endpointError.Status == preferred {
+					return c.Error(&Error{
+						Code:    endpointError.Code,
+						Message: endpointError.Message,
+					}, endpointError.Status)
+				}
+			}
+		}
+
+		if endpoint.Response == nil {
+			return c.NoContent()
+		}
+
+		if example, ok := validExample(endpoint.Response); ok {
+			return c.Status(endpoint.SuccessStatus()).JSON(example)
+		}
+
+		return c.Status(endpoint.SuccessStatus()).JSON(exampleValue(endpoint.Response, nil))
+	}
+}
+
+// preferredStatus returns the status of a `Prefer: status=<code>` header.
+func preferredStatus(prefer string) (int, bool) {
+	for _, preference := range strings.Split(prefer, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(preference), "=")
+		if strings.EqualFold(name, "status") {
+			status, err := strconv.Atoi(strings.TrimSpace(value))
+
+			return status, err == nil
+		}
+	}
+
+	return 0, false
+}
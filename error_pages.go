@@ -0,0 +1,134 @@
+package http
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================= HTML Error Pages =========================
+// ====================================================================
+
+// ErrorPageOptions configuration of the HTML error pages rendered by WriteError.
+type ErrorPageOptions struct {
+	Templates       map[int]string // View templates by HTTP status, e.g. {404: "errors/404"}
+	DefaultTemplate string         // View template of the other statuses, the built-in page when empty
+}
+
+// errorPages HTML error pages configuration, JSON errors only when nil.
+var errorPages *ErrorPageOptions
+
+// defaultErrorPage built-in page of statuses without template.
+var defaultErrorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.status}} {{.title}}</title></head>
+<body>
+<h1>{{.status}} {{.title}}</h1>
+<p>{{.message}}</p>
+</body>
+</html>
+`))
+
+// RegisterErrorPages enables HTML error pages: WriteError renders the status's view template
+// (see core.Ctx.View) when the client prefers text/html over JSON, e.g. browsers on web routes.
+// Templates receive "status", "title", "code", "message" and "data", and need a view engine registered
+// with core.RegisterView; statuses without template use a minimal built-in page.
+//
+// Example Usage:
+//
+//	http.RegisterErrorPages(http.ErrorPageOptions{
+//		Templates:       map[int]string{404: "errors/404", 403: "errors/403"},
+//		DefaultTemplate: "errors/default",
+//	})
+func RegisterErrorPages(options ErrorPageOptions) {
+	errorPages = &options
+}
+
+// PrefersHTML checks the Accept header ranks text/html above application/json, as browsers do.
+// Clients sending no Accept header, `*/*` or JSON get JSON.
+func PrefersHTML(c *core.Ctx) bool {
+	htmlQuality, jsonQuality := 0.0, 0.0
+
+	for _, item := range parseAccept(c.GetHeader(core.HeaderAccept)) {
+		switch item.mediaType {
+		case core.MIMETextHTML:
+			htmlQuality = max(htmlQuality, item.quality)
+		case core.MIMEApplicationJSON:
+			jsonQuality = max(jsonQuality, item.quality)
+		case "*/*", "application/*":
+			jsonQuality = max(jsonQuality, item.quality)
+		default:
+		}
+	}
+
+	return htmlQuality > jsonQuality
+}
+
+// acceptRange media range of an Accept header with its quality.
+type acceptRange struct {
+	mediaType string // Lower-cased media range, e.g. "image/*"
+	quality   float64
+}
+
+// parseAccept returns the media ranges of an Accept header.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		if mediaType == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				quality, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: strings.ToLower(strings.TrimSpace(mediaType)), quality: quality})
+	}
+
+	return ranges
+}
+
+// writeErrorPage renders the HTML error page of the status.
+func writeErrorPage(c *core.Ctx, data *Error, status int) error {
+	c.Status(status)
+
+	pageData := core.Data{
+		"status":  status,
+		"title":   fasthttp.StatusMessage(status),
+		"code":    data.Code,
+		"message": data.Message,
+		"data":    data.Data,
+	}
+
+	name, ok := errorPages.Templates[status]
+	if !ok {
+		name = errorPages.DefaultTemplate
+	}
+
+	if name != "" {
+		err := c.View(name, pageData)
+		if err == nil {
+			return errors.UnknownError
+		}
+
+		log.Errorf("Error page %s rendering error: %v", name, err)
+		c.Root().Response.ResetBody()
+	}
+
+	c.Root().Response.Header.SetContentType(core.MIMETextHTMLCharsetUTF8)
+	if err := defaultErrorPage.Execute(c.Root().Response.BodyWriter(), pageData); err != nil {
+		log.Errorf("Error page rendering error: %v", err)
+	}
+
+	return errors.UnknownError
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================== Events ==============================
+// ====================================================================
+
+// Event a domain event recorded by a mutation, e.g. "user.created" with the validated DTO as payload.
+type Event struct {
+	ID         string    `json:"id"`                // UUIDv7, usable as idempotency key by consumers
+	Name       string    `json:"name"`              // Event name, e.g. "user.created"
+	Payload    any       `json:"payload"`           // Validated DTO or any event data
+	Caller     string    `json:"caller"`            // CallerIdentity of the request
+	Summary    string    `json:"summary,omitempty"` // Summarize of the request, for events recorded for DTOs
+	OccurredAt time.Time `json:"occurred_at"`       // Recording time
+}
+
+// EventEmitter is an interface for event sinks (transactional outbox table, message bus, ...).
+type EventEmitter interface {
+	// Emit delivers the events recorded by a request, in order.
+	Emit(events []Event) error
+}
+
+var (
+	// eventEmitter the sink of FlushEvents, events are dropped when nil.
+	eventEmitter EventEmitter
+
+	eventNamesMu sync.RWMutex
+	// eventNames event names by DTO type, recorded by ProcessData and ProcessUpdateData.
+	eventNames = map[reflect.Type]string{}
+)
+
+// RegisterEventEmitter registers the sink of recorded events.
+func RegisterEventEmitter(emitter EventEmitter) {
+	eventEmitter = emitter
+}
+
+// RegisterEvent registers the event recorded by ProcessData and ProcessUpdateData with the validated DTO T.
+// The event is only emitted once the mutation succeeds (see EmitEvents and FlushEvents).
+//
+// Example Usage:
+//
+//	http.RegisterEvent[dto.CreateUser]("user.created")
+//	http.RegisterEvent[dto.UpdateUser]("user.updated")
+func RegisterEvent[T any](name string) {
+	eventNamesMu.Lock()
+	defer eventNamesMu.Unlock()
+
+	eventNames[reflect.TypeFor[T]()] = name
+}
+
+// RecordEvent records an event of the request, emitted once the mutation succeeds.
+//
+// Example Usage:
+//
+//	http.RecordEvent(c, "order.shipped", core.Data{"order_id": order.ID})
+func RecordEvent(c *core.Ctx, name string, payload any) {
+	events, _ := c.GetData(EventsKey).([]Event)
+	c.SetData(EventsKey, append(events, Event{
+		ID:         NewUUIDv7(),
+		Name:       name,
+		Payload:    payload,
+		Caller:     CallerIdentity(c),
+		OccurredAt: time.Now(),
+	}))
+}
+
+// PendingEvents returns the events recorded by the request and not emitted yet.
+func PendingEvents(c *core.Ctx) []Event {
+	events, _ := c.GetData(EventsKey).([]Event)
+
+	return events
+}
+
+// FlushEvents emits the pending events of the request through the registered EventEmitter.
+// Handlers writing an outbox table call it inside their transaction, so events are stored atomically
+// with the mutation; otherwise EmitEvents calls it after the handler succeeds.
+//
+// Example Usage:
+//
+//	err := db.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Create(&user).Error; err != nil {
+//			return err
+//		}
+//		return http.FlushEvents(c)
+//	})
+func FlushEvents(c *core.Ctx) error {
+	events := PendingEvents(c)
+	if len(events) == 0 || eventEmitter == nil {
+		return nil
+	}
+
+	var err error
+	if panicErr := guard(func() { err = eventEmitter.Emit(events) }); panicErr != nil {
+		err = panicErr
+	}
+	if err != nil {
+		return err
+	}
+	c.SetData(EventsKey, nil)
+
+	return nil
+}
+
+// eventsHandler handler wrapper emitting the recorded events after successful handling.
+type eventsHandler struct {
+	core.IHandler
+}
+
+// EmitEvents wraps a mutation handler so the events recorded while processing the request (RegisterEvent,
+// RecordEvent) are emitted when Handle succeeds with a non-error status. Failed requests and dry runs (see
+// IsDryRun) emit nothing.
+// Emitter failures are handled by the SubsystemEvents policy (see RegisterFailurePolicy).
+//
+// Example Usage:
+//
+//	router.POST("/users", http.EmitEvents(api.NewCreateUserApi()))
+func EmitEvents(handler core.IHandler) core.IHandler {
+	return &eventsHandler{IHandler: handler}
+}
+
+// Handle runs the wrapped handler and emits the pending events on success.
+func (h *eventsHandler) Handle(c *core.Ctx) error {
+	if err := h.IHandler.Handle(c); err != nil {
+		return err
+	}
+
+	if c.Root().Response.StatusCode() >= core.StatusBadRequest {
+		return nil
+	}
+
+	// Nothing was committed
+	if IsDryRun(c) {
+		c.SetData(EventsKey, nil)
+
+		return nil
+	}
+
+	// The mutation is done, the response is kept and only reports the failure under FailClosed
+	if err := FlushEvents(c); err != nil {
+		degradeCommitted(c, SubsystemEvents, err)
+	}
+
+	return nil
+}
+
+// recordDataEvent records the event registered for the DTO T with the validated data.
+// It is called by ProcessData and ProcessUpdateData, dry runs record nothing.
+func recordDataEvent[T any](c *core.Ctx, requestData T) {
+	if IsDryRun(c) {
+		return
+	}
+
+	eventNamesMu.RLock()
+	name, ok := eventNames[reflect.TypeFor[T]()]
+	eventNamesMu.RUnlock()
+
+	if ok {
+		RecordEvent(c, name, requestData)
+
+		events := PendingEvents(c)
+		events[len(events)-1].Summary = Summarize(c, requestData)
+	}
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================== DTO Examples ============================
+// ====================================================================
+
+// Conventional example names. Names starting with ExampleInvalid (e.g. "invalid_email") are expected to fail
+// validation, all others to pass it.
+const (
+	ExampleValid   = "valid"
+	ExampleMinimal = "minimal"
+	ExampleInvalid = "invalid"
+)
+
+// namedExample example payload registered for a DTO.
+type namedExample struct {
+	name  string
+	value any
+}
+
+var (
+	examplesMu sync.RWMutex
+	// examples named examples by DTO type, pointers dereferenced, in registration order.
+	examples = map[reflect.Type][]namedExample{}
+)
+
+// RegisterExample registers a named example payload of a DTO. Examples are values of the DTO itself, so they
+// can not drift from the struct: they are shown in the OpenAPI document (see OpenAPIDocument), answered by
+// the mock server (see NewMockHandler), used by Fake and checked by CheckExamples. Registering a name again
+// replaces it. Examples of a DTO and of its pointer type are the same, whichever one Describe is given.
+//
+// Example Usage:
+//
+//	http.RegisterExample(http.ExampleValid, dto.CreateUser{Email: "john@example.com", Name: "John", Age: 32})
+//	http.RegisterExample(http.ExampleMinimal, dto.CreateUser{Email: "john@example.com"})
+//	http.RegisterExample("invalid_email", dto.CreateUser{Email: "john"})
+func RegisterExample[T any](name string, example T) {
+	value := reflect.ValueOf(&example).Elem()
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			log.Errorf("Example %s of %s is a nil pointer", name, reflect.TypeFor[T]())
+
+			return
+		}
+		value = value.Elem()
+	}
+	typ := value.Type()
+
+	examplesMu.Lock()
+	defer examplesMu.Unlock()
+
+	for i, existing := range examples[typ] {
+		if existing.name == name {
+			examples[typ][i].value = value.Interface()
+
+			return
+		}
+	}
+	examples[typ] = append(examples[typ], namedExample{name: name, value: value.Interface()})
+}
+
+// ExampleOf returns the named example of a DTO.
+//
+// Example Usage:
+//
+//	payload, _ := http.ExampleOf[dto.CreateUser](http.ExampleValid)
+func ExampleOf[T any](name string) (T, bool) {
+	for _, example := range examplesOf(reflect.TypeFor[T]()) {
+		if example.name == name {
+			return exampleAs[T](example.value), true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// ExamplesOf returns the examples of a DTO by name, e.g. for table-driven tests.
+//
+// Example Usage:
+//
+//	for name, payload := range http.ExamplesOf[dto.CreateUser]() {
+//		t.Run(name, func(t *testing.T) { ... })
+//	}
+func ExamplesOf[T any]() map[string]T {
+	registered := examplesOf(reflect.TypeFor[T]())
+	values := make(map[string]T, len(registered))
+	for _, example := range registered {
+		values[example.name] = exampleAs[T](example.value)
+	}
+
+	return values
+}
+
+// Fake returns a payload of a DTO for tests and seeds: a copy of its ExampleValid example, else of its first
+// valid one, else a value built from the `example` tags of its fields (see OpenAPIDocument), the fields without
+// tag holding placeholders. The overrides are applied in order to the payload, so tests only spell out the fields
+// they are about.
+//
+// Example Usage:
+//
+//	payload := http.Fake[dto.CreateUser](func(user *dto.CreateUser) {
+//		user.Email = "taken@example.com"
+//	})
+func Fake[T any](overrides ...func(payload *T)) T {
+	var payload T
+	if example, ok := validExample(reflect.TypeFor[T]()); ok {
+		payload = exampleAs[T](example)
+	} else if data, err := json.Marshal(exampleValue(reflect.TypeFor[T](), nil)); err == nil {
+		// Placeholders that the field types refuse leave them zero
+		_ = json.Unmarshal(data, &payload)
+	}
+
+	for _, override := range overrides {
+		override(&payload)
+	}
+
+	return payload
+}
+
+// CheckExamples sanitizes and validates every registered example like a request, and returns an error for
+// each example contradicting its name: invalid examples passing validation, or others failing it.
+//
+// Example Usage:
+//
+//	func TestExamples(t *testing.T) {
+//		for _, err := range http.CheckExamples() {
+//			t.Error(err)
+//		}
+//	}
+func CheckExamples() []error {
+	examplesMu.RLock()
+	types := make([]reflect.Type, 0, len(examples))
+	for typ := range examples {
+		types = append(types, typ)
+	}
+	examplesMu.RUnlock()
+	slices.SortFunc(types, func(a, b reflect.Type) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	var errs []error
+	for _, typ := range types {
+		for _, example := range examplesOf(typ) {
+			// Sanitized on a copy, examples are kept as registered
+			payload := reflect.New(typ)
+			payload.Elem().Set(reflect.ValueOf(example.value))
+			SanitizeStruct(payload.Interface())
+
+			errData := Validate(payload.Elem().Interface())
+			switch {
+			case isInvalidExample(example.name) && errData == nil:
+				errs = append(errs, fmt.Errorf("example %s of %s passes validation", example.name, typ))
+			case !isInvalidExample(example.name) && errData != nil:
+				errs = append(errs, fmt.Errorf("example %s of %s fails validation: %v", example.name, typ, errData.Data))
+			}
+		}
+	}
+
+	return errs
+}
+
+// examplesOf returns the examples registered for a type or the type it points to.
+func examplesOf(typ reflect.Type) []namedExample {
+	examplesMu.RLock()
+	defer examplesMu.RUnlock()
+
+	return slices.Clone(examples[derefType(typ)])
+}
+
+// exampleAs returns a registered example as a T, a pointer to a copy of it when T is a pointer type.
+func exampleAs[T any](value any) T {
+	var result T
+	target := reflect.ValueOf(&result).Elem()
+	example := reflect.ValueOf(value)
+	for target.Type() != example.Type() && target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	target.Set(example)
+
+	return result
+}
+
+// isInvalidExample checks an example name denotes an invalid payload.
+func isInvalidExample(name string) bool {
+	return strings.HasPrefix(name, ExampleInvalid)
+}
+
+// validExample returns the example answered for a type: the ExampleValid one, else the first valid example.
+func validExample(typ reflect.Type) (any, bool) {
+	var first *namedExample
+	for _, example := range examplesOf(typ) {
+		if example.name == ExampleValid {
+			return example.value, true
+		}
+		if first == nil && !isInvalidExample(example.name) {
+			first = &example
+		}
+	}
+	if first == nil {
+		return nil, false
+	}
+
+	return first.value, true
+}
+
+// openAPIExamples returns the OpenAPI examples of a type, invalid ones excluded; nil when there is none.
+func openAPIExamples(typ reflect.Type) map[string]any {
+	var values map[string]any
+	for _, example := range examplesOf(typ) {
+		if isInvalidExample(example.name) {
+			continue
+		}
+		if values == nil {
+			values = map[string]any{}
+		}
+		values[example.name] = map[string]any{"value": example.value}
+	}
+
+	return values
+}
@@ -0,0 +1,323 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================= Exports ==============================
+// ====================================================================
+
+// FormatNDJSON newline-delimited JSON file format of exports.
+const FormatNDJSON = "ndjson"
+
+// exportContentTypes content types of the export formats.
+var exportContentTypes = map[string]string{
+	FormatCSV:    "text/csv",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// ExportPageSize number of records fetched per page by exports.
+var ExportPageSize = 1000
+
+// ExportResult struct to describe the outcome of an export.
+// @Description Outcome of an export, the Result of its Operation; the file is downloaded from the operation's result_url
+// @Records Records is the number of exported records.
+// @Format Format is the file format (csv, xlsx, ndjson).
+// @Tags Exports
+type ExportResult struct {
+	Records int64  `json:"records" example:"250000" doc:"Number of exported records"`
+	Format  string `json:"format" example:"csv" doc:"File format: csv, xlsx or ndjson"`
+}
+
+// ExportPageFunc fetches a page of the exported records with the request's Filter, Page and PerPage set by
+// the export. The export ends with the first page shorter than PerPage; total is the number of records to
+// export, 0 when unknown.
+type ExportPageFunc[T any] func(ctx context.Context, filter Filter) (records []T, total int, err error)
+
+// StartExport exports the records matching the request's Filter (see ProcessFilter) as an Operation (see
+// StartOperation): the response is 202 Accepted, the file is generated in the background, page by page, then
+// put to the registered Storage. Once the operation succeeded, its result_url is a signed download URL and its
+// Result an ExportResult. Columns are the JSON names of T's fields.
+//
+// Example Usage:
+//
+//	func (h ExportOrdersApi) Validate(c *core.Ctx) error {
+//		return http.ProcessFilterAs(c, "orders")
+//	}
+//
+//	func (h ExportOrdersApi) Handle(c *core.Ctx) error {
+//		return http.StartExport(c, c.QueryStr("format"), func(ctx context.Context, filter http.Filter) ([]dto.Order, int, error) {
+//			return orderService.List(ctx, filter)
+//		})
+//	}
+func StartExport[T any](c *core.Ctx, format string, fetch ExportPageFunc[T]) error {
+	if format == "" {
+		format = FormatCSV
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		return c.Error(&Error{
+			Message: "Invalid input",
+			Data:    core.Data{"format": []string{"must be one of csv, xlsx, ndjson"}},
+		}, FailureStatus(c, FailureValidation))
+	}
+
+	filter, _ := c.GetData(FilterKey).(Filter)
+	config := RequestConfig(c)
+
+	return StartOperation(c, "export", func(ctx context.Context, progress *OperationProgress) (any, error) {
+		return exportRecords(ctx, progress, config, filter, format, fetch)
+	})
+}
+
+// exportRecords writes the records to a temporary file, then puts it to the storage.
+func exportRecords[T any](ctx context.Context, progress *OperationProgress, config Config, filter Filter,
+	format string, fetch ExportPageFunc[T]) (ExportResult, error) {
+	storage := GetStorage()
+	result := ExportResult{Format: format}
+	if storage == nil {
+		return result, errors.New("storage is not registered")
+	}
+
+	file, err := os.CreateTemp(core.TempDir, "export-*."+format)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	buffered := bufio.NewWriter(file)
+	writer, err := newExportWriter[T](buffered, format)
+	if err != nil {
+		return result, err
+	}
+
+	filter.PerPage = config.ExportPageSize
+	for filter.Page = 1; ; filter.Page++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		records, total, err := fetch(ctx, filter)
+		if err != nil {
+			return result, &OperationError{Message: "Unable to fetch the records", Err: err}
+		}
+		if filter.Page == 1 && total > 0 {
+			progress.SetTotal(int64(total))
+		}
+
+		for _, record := range records {
+			if err := writer.Write(record); err != nil {
+				return result, err
+			}
+		}
+		result.Records += int64(len(records))
+		progress.Add(int64(len(records)))
+
+		if len(records) < filter.PerPage {
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return result, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return result, err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return result, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return result, err
+	}
+
+	key := "exports/" + progress.ID() + "." + format
+	if err := storage.Put(ctx, key, file, size, exportContentTypes[format]); err != nil {
+		return result, &OperationError{Message: "Unable to store the export", Err: err}
+	}
+
+	downloadURL, err := storage.SignedURL(ctx, key, config.OperationRetention)
+	if err != nil {
+		return result, &OperationError{Message: "Unable to sign the export URL", Err: err}
+	}
+	progress.SetResultURL(downloadURL)
+
+	return result, nil
+}
+
+// exportWriter writer of exported records.
+type exportWriter[T any] struct {
+	columns []exportColumn
+	csv     *csv.Writer
+	xlsx    *xlsxWriter
+	json    *json.Encoder
+}
+
+// exportColumn column of an export.
+type exportColumn struct {
+	index int
+	name  string
+}
+
+// newExportWriter creates the writer of a format and writes the header row of tabular formats.
+func newExportWriter[T any](w io.Writer, format string) (*exportWriter[T], error) {
+	writer := &exportWriter[T]{columns: exportColumns(reflect.TypeFor[T]())}
+
+	header := make([]string, len(writer.columns))
+	for i, column := range writer.columns {
+		header[i] = column.name
+	}
+
+	var err error
+	switch format {
+	case FormatCSV:
+		writer.csv = csv.NewWriter(w)
+		err = writer.csv.Write(header)
+	case FormatXLSX:
+		writer.xlsx, err = newXLSXWriter(w)
+		if err == nil {
+			err = writer.xlsx.WriteRow(header, nil)
+		}
+	default:
+		writer.json = json.NewEncoder(w)
+	}
+
+	return writer, err
+}
+
+// Write writes a record.
+func (w *exportWriter[T]) Write(record T) error {
+	if w.json != nil {
+		return w.json.Encode(record)
+	}
+
+	value := reflect.ValueOf(record)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	cells := make([]string, len(w.columns))
+	numeric := make([]bool, len(w.columns))
+	if value.Kind() == reflect.Struct {
+		for i, column := range w.columns {
+			cells[i], numeric[i] = exportCell(value.Field(column.index))
+		}
+	}
+
+	if w.xlsx != nil {
+		return w.xlsx.WriteRow(cells, numeric)
+	}
+
+	for i := range cells {
+		if !numeric[i] {
+			cells[i] = csvCell(cells[i])
+		}
+	}
+
+	return w.csv.Write(cells)
+}
+
+// Close ends the file.
+func (w *exportWriter[T]) Close() error {
+	switch {
+	case w.csv != nil:
+		w.csv.Flush()
+
+		return w.csv.Error()
+	case w.xlsx != nil:
+		return w.xlsx.Close()
+	default:
+		return nil
+	}
+}
+
+// exportColumns returns the exported fields of a struct type with their JSON names.
+func exportColumns(typ reflect.Type) []exportColumn {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []exportColumn
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		columns = append(columns, exportColumn{index: i, name: name})
+	}
+
+	return columns
+}
+
+// exportCell formats a field value as a cell, and reports whether it is a number.
+func exportCell(value reflect.Value) (string, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format(time.RFC3339), false
+	}
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", false
+		}
+
+		return string(text), false
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), false
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), true
+	default:
+		encoded, err := json.Marshal(value.Interface())
+		if err != nil {
+			return "", false
+		}
+
+		return string(encoded), false
+	}
+}
@@ -0,0 +1,338 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// =========================== Feature Flags ==========================
+// ====================================================================
+
+// FeatureFlagProvider tells whether features are enabled for the caller or the tenant of a request.
+type FeatureFlagProvider interface {
+	// FeatureEnabled checks the feature is enabled for the request.
+	FeatureEnabled(c *core.Ctx, feature string) bool
+}
+
+// FeatureFlagProviderFunc adapter to use a function as a FeatureFlagProvider.
+type FeatureFlagProviderFunc func(c *core.Ctx, feature string) bool
+
+// FeatureEnabled calls f(c, feature).
+func (f FeatureFlagProviderFunc) FeatureEnabled(c *core.Ctx, feature string) bool {
+	return f(c, feature)
+}
+
+// featureFlagProvider provider of FeatureEnabled, every feature is disabled when nil.
+var featureFlagProvider FeatureFlagProvider
+
+// RegisterFeatureFlagProvider registers the provider of feature flags. DTO fields tagged `feature:"<name>"` are
+// experimental: Parse drops them from the body, and BindSources ignores their sources, with a FEATURE_DISABLED
+// warning, unless the feature is enabled for the request, so fields can be rolled out caller by caller. Providers are called for each tagged
+// field sent, they should cache their flags.
+//
+// Example Usage:
+//
+//	http.RegisterFeatureFlagProvider(http.FeatureFlagProviderFunc(func(c *core.Ctx, feature string) bool {
+//		return flags.Enabled(feature, c.GetHeader("X-Tenant-ID"))
+//	}))
+//
+//	type CreateOrderRequest struct {
+//		Items       []OrderItem `json:"items" validate:"required,min=1"`
+//		PricingPlan string      `json:"pricing_plan" feature:"new_pricing" validate:"omitempty,oneof=flat tiered"`
+//	}
+func RegisterFeatureFlagProvider(provider FeatureFlagProvider) {
+	featureFlagProvider = provider
+}
+
+// FeatureEnabled checks a feature is enabled for the request, false when no provider is registered.
+//
+// Example Usage:
+//
+//	if http.FeatureEnabled(c, "new_pricing") {
+//		total = pricing.Tiered(order)
+//	}
+func FeatureEnabled(c *core.Ctx, feature string) bool {
+	return featureFlagProvider != nil && featureFlagProvider.FeatureEnabled(c, feature)
+}
+
+// featureFieldsCache whether struct types have fields with a `feature` tag, nested ones included.
+var featureFieldsCache sync.Map
+
+// hasFeatureFields checks a type holds fields with a `feature` tag, so bodies of other DTOs are not rewritten.
+func hasFeatureFields(typ reflect.Type) bool {
+	typ = derefType(typ)
+	if cached, ok := featureFieldsCache.Load(typ); ok {
+		return cached.(bool)
+	}
+
+	found := findFeatureFields(typ, map[reflect.Type]bool{})
+	featureFieldsCache.Store(typ, found)
+
+	return found
+}
+
+func findFeatureFields(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return findFeatureFields(typ.Elem(), seen)
+	case reflect.Struct:
+		if seen[typ] || typ == timeType {
+			return false
+		}
+		seen[typ] = true
+
+		for _, field := range reflect.VisibleFields(typ) {
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("feature") != "" || findFeatureFields(field.Type, seen) {
+				return true
+			}
+		}
+	default:
+	}
+
+	return false
+}
+
+// droppedField field of a disabled feature removed from a request.
+type droppedField struct {
+	path    string
+	feature string
+}
+
+// dropDisabledFeatures removes the fields of disabled features from the JSON body, with a warning per field.
+func dropDisabledFeatures(c *core.Ctx, typ reflect.Type) {
+	if !hasFeatureFields(typ) {
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(c.Root().PostBody()))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		// Syntax errors are reported by the decoding itself
+		return
+	}
+
+	enabled := map[string]bool{}
+	var dropped []droppedField
+	document = dropFeatureFields(c, document, derefType(typ), "", enabled, &dropped)
+	if len(dropped) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return
+	}
+	c.Root().Request.SetBody(body)
+
+	sort.Slice(dropped, func(i, j int) bool {
+		return dropped[i].path < dropped[j].path
+	})
+	for _, field := range dropped {
+		warnFeatureDisabled(c, field)
+	}
+}
+
+// clearDisabledFeatures zeroes the fields of disabled features set in a decoded DTO, with a warning per field,
+// for bodies which are not decoded from JSON (media codecs, XML).
+func clearDisabledFeatures(c *core.Ctx, target any) {
+	if !hasFeatureFields(reflect.TypeOf(target)) {
+		return
+	}
+
+	enabled := map[string]bool{}
+	var dropped []droppedField
+	clearFeatureFields(c, reflect.ValueOf(target), "", enabled, &dropped)
+
+	for _, field := range dropped {
+		warnFeatureDisabled(c, field)
+	}
+}
+
+// clearFeatureFields zeroes the fields of disabled features of a decoded value. enabled caches the flags of the
+// request.
+func clearFeatureFields(c *core.Ctx, value reflect.Value, path string, enabled map[string]bool,
+	dropped *[]droppedField) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return
+		}
+
+		for _, field := range reflect.VisibleFields(value.Type()) {
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			fieldValue, err := value.FieldByIndexErr(field.Index)
+			if err != nil || !fieldValue.CanSet() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				name = field.Name
+			}
+
+			if feature := field.Tag.Get("feature"); feature != "" && !featureEnabled(c, feature, enabled) {
+				if !fieldValue.IsZero() {
+					fieldValue.SetZero()
+					*dropped = append(*dropped, droppedField{path: joinPath(path, name), feature: feature})
+				}
+
+				continue
+			}
+			clearFeatureFields(c, fieldValue, joinPath(path, name), enabled, dropped)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			clearFeatureFields(c, value.Index(i), fmt.Sprintf("%s[%d]", path, i), enabled, dropped)
+		}
+	case reflect.Map:
+		if !hasFeatureFields(value.Type().Elem()) {
+			return
+		}
+
+		// Map values are not addressable, they are cleared on a copy
+		iter := value.MapRange()
+		for iter.Next() {
+			item := reflect.New(iter.Value().Type()).Elem()
+			item.Set(iter.Value())
+			clearFeatureFields(c, item, joinPath(path, fmt.Sprint(iter.Key().Interface())), enabled, dropped)
+			value.SetMapIndex(iter.Key(), item)
+		}
+	default:
+	}
+}
+
+// dropFeatureFields removes the fields of disabled features from a decoded JSON value of type typ. enabled caches
+// the flags of the request.
+func dropFeatureFields(c *core.Ctx, value any, typ reflect.Type, path string, enabled map[string]bool,
+	dropped *[]droppedField) any {
+	typ = derefType(typ)
+	if typ == timeType {
+		return value
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for name, item := range typed {
+				field, ok := jsonField(typ, name)
+				if !ok {
+					continue
+				}
+
+				if feature := field.Tag.Get("feature"); feature != "" && !featureEnabled(c, feature, enabled) {
+					delete(typed, name)
+					*dropped = append(*dropped, droppedField{path: joinPath(path, name), feature: feature})
+
+					continue
+				}
+
+				typed[name] = dropFeatureFields(c, item, field.Type, joinPath(path, name), enabled, dropped)
+			}
+		case reflect.Map:
+			for name, item := range typed {
+				typed[name] = dropFeatureFields(c, item, typ.Elem(), joinPath(path, name), enabled, dropped)
+			}
+		default:
+		}
+	case []any:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, item := range typed {
+				typed[i] = dropFeatureFields(c, item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), enabled, dropped)
+			}
+		}
+	default:
+	}
+
+	return value
+}
+
+// featureEnabled checks a feature once per request.
+func featureEnabled(c *core.Ctx, feature string, enabled map[string]bool) bool {
+	isEnabled, ok := enabled[feature]
+	if !ok {
+		isEnabled = FeatureEnabled(c, feature)
+		enabled[feature] = isEnabled
+	}
+
+	return isEnabled
+}
+
+// dropFeatureOperations removes the JSON Patch operations touching fields of disabled features of typ, with a
+// warning per operation.
+func dropFeatureOperations(c *core.Ctx, typ reflect.Type, operations []JSONPatchOperation) []JSONPatchOperation {
+	if !hasFeatureFields(typ) {
+		return operations
+	}
+
+	enabled := map[string]bool{}
+	kept := make([]JSONPatchOperation, 0, len(operations))
+	for _, operation := range operations {
+		feature := pointerFeature(typ, operation.Path)
+		if feature == "" {
+			feature = pointerFeature(typ, operation.From)
+		}
+		if feature != "" && !featureEnabled(c, feature, enabled) {
+			warnFeatureDisabled(c, droppedField{path: operation.Path, feature: feature})
+
+			continue
+		}
+		kept = append(kept, operation)
+	}
+
+	return kept
+}
+
+// pointerFeature returns the first `feature` tag of the fields along a JSON pointer into typ.
+func pointerFeature(typ reflect.Type, pointer string) string {
+	tokens, _ := parsePointer(pointer)
+	for _, token := range tokens {
+		typ = derefType(typ)
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(typ, token)
+			if !ok {
+				return ""
+			}
+			if feature := field.Tag.Get("feature"); feature != "" {
+				return feature
+			}
+			typ = field.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// warnFeatureDisabled adds the warning of a dropped field.
+func warnFeatureDisabled(c *core.Ctx, field droppedField) {
+	AddWarning(c, WarningFeatureDisabled,
+		fmt.Sprintf("Field '%s' requires the '%s' feature, it was ignored", field.path, field.feature), field.path)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Field Selection ==========================
+// ====================================================================
+
+// FieldsParam query parameter listing the response fields requested by the client.
+const FieldsParam = "fields"
+
+// FieldSet tree of selected field paths. A nil child selects the whole field,
+// a non-nil child selects only the listed sub-fields.
+type FieldSet map[string]FieldSet
+
+// ParseFieldSet parses a comma-separated list of dotted paths such as "id,author.name,comments.id".
+// Selecting a field and one of its sub-fields ("author,author.name") selects the whole field.
+func ParseFieldSet(fields string) FieldSet {
+	fieldSet := FieldSet{}
+
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		node := fieldSet
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			if part == "" {
+				break
+			}
+
+			child, exists := node[part]
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if exists && child == nil {
+				// Whole field already selected
+				break
+			}
+			if child == nil {
+				child = FieldSet{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+
+	return fieldSet
+}
+
+// SelectedFields returns the field selection of the `fields` query parameter, nil when absent.
+func SelectedFields(c *core.Ctx) FieldSet {
+	if fieldSet, ok := c.GetData(FieldsKey).(FieldSet); ok {
+		return fieldSet
+	}
+
+	var fieldSet FieldSet
+	if fields := c.QueryStr(FieldsParam); fields != "" {
+		fieldSet = ParseFieldSet(fields)
+	}
+	c.SetData(FieldsKey, fieldSet)
+
+	return fieldSet
+}
+
+// Select returns the JSON representation of value reduced to the selected fields.
+// Structs and maps are converted through their JSON encoding, so field names are JSON names;
+// arrays are reduced item by item and scalars are returned unchanged. Unknown fields are ignored.
+//
+// Example Usage:
+//
+//	fields := http.ParseFieldSet("id,author.name")
+//	data, err := fields.Select(transformers.ToPostResponse(post))
+func (fs FieldSet) Select(value any) (any, error) {
+	if len(fs) == 0 {
+		return value, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as json.Number, int64 IDs beyond 2^53 would be rounded by float64
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var decoded any
+	if err = decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return fs.prune(decoded), nil
+}
+
+func (fs FieldSet) prune(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		selected := make(map[string]any, len(fs))
+		for name, child := range fs {
+			item, ok := typed[name]
+			if !ok {
+				continue
+			}
+			if child != nil {
+				item = child.prune(item)
+			}
+			selected[name] = item
+		}
+
+		return selected
+	case []any:
+		for i, item := range typed {
+			typed[i] = fs.prune(item)
+		}
+
+		return typed
+	default:
+		return value
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFieldSetSelectKeepsLargeIntegers(t *testing.T) {
+	type record struct {
+		ID    int64   `json:"id"`
+		Price float64 `json:"price"`
+		Name  string  `json:"name"`
+	}
+
+	for _, tc := range []struct {
+		name   string
+		fields string
+		value  any
+		want   string
+	}{
+		{"int64 beyond 2^53", "id", record{ID: 9007199254740993, Name: "a"}, `{"id":9007199254740993}`},
+		{"float", "price", record{Price: 12.5}, `{"price":12.5}`},
+		{"list", "id", []record{{ID: 9007199254740993}, {ID: -9223372036854775808}},
+			`[{"id":9007199254740993},{"id":-9223372036854775808}]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := ParseFieldSet(tc.fields).Select(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encoded, err := json.Marshal(selected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(encoded) != tc.want {
+				t.Errorf("Select() = %s, want %s", encoded, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================ Fingerprint ===========================
+// ====================================================================
+
+// callerIdentityFunc identifies the caller of a request, the client IP by default.
+var callerIdentityFunc = func(c *core.Ctx) string { return c.ClientIP() }
+
+// RegisterCallerIdentity registers the function identifying callers (API key, user or tenant ID, ...).
+// It is used by Fingerprint, and by ConsumeQuota unless RegisterQuotaCaller overrides it.
+//
+// Example Usage:
+//
+//	http.RegisterCallerIdentity(func(c *core.Ctx) string {
+//		if user := c.GetData(constants.User); user != nil {
+//			return strconv.Itoa(user.(*models.User).ID)
+//		}
+//		return c.ClientIP()
+//	})
+func RegisterCallerIdentity(identityFn func(c *core.Ctx) string) {
+	callerIdentityFunc = identityFn
+}
+
+// CallerIdentity returns the identity of the request's caller.
+func CallerIdentity(c *core.Ctx) string {
+	return callerIdentityFunc(c)
+}
+
+// Fingerprint returns a stable hex SHA-256 of the request over its method, route path template, sorted query,
+// caller identity and canonical body, so deduplication, idempotency, caching and abuse detection share one
+// definition of "the same request". JSON bodies are canonicalized (keys sorted, whitespace removed), so
+// `{"a":1, "b":2}` and `{"b":2,"a":1}` give the same fingerprint. The value is computed once per request.
+//
+// Example Usage:
+//
+//	if seen, _ := store.Get("dedupe:" + http.Fingerprint(c)); seen != nil {
+//		return c.Error(&http.Error{Code: "DUPLICATE_REQUEST", Message: "Request already processed"}, core.StatusConflict)
+//	}
+func Fingerprint(c *core.Ctx) string {
+	if fingerprint, ok := c.GetData(FingerprintKey).(string); ok {
+		return fingerprint
+	}
+
+	hasher := sha256.New()
+	for _, part := range []string{
+		string(c.Root().Method()),
+		RoutePath(c),
+		canonicalQuery(c),
+		CallerIdentity(c),
+	} {
+		hasher.Write([]byte(part))
+		hasher.Write([]byte{0})
+	}
+	hasher.Write(canonicalBody(c.Root().PostBody()))
+
+	fingerprint := hex.EncodeToString(hasher.Sum(nil))
+	c.SetData(FingerprintKey, fingerprint)
+
+	return fingerprint
+}
+
+// RoutePath returns the request path with the values of path parameters replaced by their names,
+// e.g. "/users/{id}" for "/users/42", so requests to the same route share a path template.
+func RoutePath(c *core.Ctx) string {
+	segments := strings.Split(string(c.Root().Path()), "/")
+
+	c.Root().VisitUserValues(func(key []byte, value any) {
+		name := string(key)
+		param, ok := value.(string)
+		if !ok || param == "" || strings.HasPrefix(name, "__") {
+			return
+		}
+
+		for i, segment := range segments {
+			if segment == param {
+				segments[i] = "{" + name + "}"
+			}
+		}
+	})
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query parameters sorted by key and value, with the items of the fields
+// parameter sorted too.
+func canonicalQuery(c *core.Ctx) string {
+	var params []string
+	c.Root().QueryArgs().VisitAll(func(key, value []byte) {
+		if string(key) == FieldsParam {
+			items := strings.Split(string(value), ",")
+			sort.Strings(items)
+			value = []byte(strings.Join(items, ","))
+		}
+		params = append(params, string(key)+"="+string(value))
+	})
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// canonicalBody re-encodes a JSON body with sorted keys and no whitespace, keeping numbers as sent.
+// Other bodies are returned unchanged.
+func canonicalBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return body
+	}
+
+	canonical, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+
+	return canonical
+}
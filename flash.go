@@ -0,0 +1,155 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================== Flash Messages ==========================
+// ====================================================================
+
+// Flash message levels.
+const (
+	FlashSuccess = "success"
+	FlashInfo    = "info"
+	FlashWarning = "warning"
+	FlashError   = "error"
+)
+
+// FlashCookie name of the cookie holding flash messages when no session store is registered.
+const FlashCookie = "flash"
+
+// flashSessionKey session key of flash messages.
+const flashSessionKey = "_flashes"
+
+// flashCookieTTL lifetime of the flash cookie, flashes are meant for the next request.
+const flashCookieTTL = 5 * time.Minute
+
+// Flash struct to describe a message displayed once, typically after a redirect.
+// @Description One-time message to display, e.g. a banner after a redirect
+// @Level Level is the message level (success, info, warning, error).
+// @Message Message is the text to display.
+// @Tags Info Responses
+type Flash struct {
+	Level   string `json:"level" example:"success" doc:"Message level: success, info, warning or error"`
+	Message string `json:"message" example:"Profile updated" doc:"Text to display"`
+}
+
+// AddFlash adds a message displayed once by the next response rendering flashes, typically the page the client
+// is redirected to. Flashes are kept in the session when a session store is registered (see WithSession),
+// in the FlashCookie otherwise.
+//
+// Example Usage:
+//
+//	http.AddFlash(c, http.FlashSuccess, "Profile updated")
+//	return http.RedirectSeeOther(c, "/profile")
+func AddFlash(c *core.Ctx, level, message string) {
+	storeFlashes(c, append(loadFlashes(c), Flash{Level: level, Message: message}))
+}
+
+// ConsumeFlashes returns the pending flash messages and removes them. WriteSuccess and WriteList call it,
+// templates of server-rendered pages can call it too.
+func ConsumeFlashes(c *core.Ctx) []Flash {
+	if !hasFlashes(c) {
+		return nil
+	}
+
+	flashes := loadFlashes(c)
+	if len(flashes) > 0 {
+		storeFlashes(c, nil)
+	}
+
+	return flashes
+}
+
+// hasFlashes checks flashes may be pending, without loading a session the request does not use.
+func hasFlashes(c *core.Ctx) bool {
+	if sessionStore != nil {
+		_, loaded := c.GetData(SessionKey).(*Session)
+
+		return loaded
+	}
+
+	return c.GetCookie(FlashCookie) != "" || len(c.Root().Response.Header.PeekCookie(FlashCookie)) > 0
+}
+
+// loadFlashes returns the pending flash messages.
+func loadFlashes(c *core.Ctx) []Flash {
+	if sessionStore != nil {
+		flashes, _ := GetSession[[]Flash](c, flashSessionKey)
+
+		return flashes
+	}
+
+	// Flashes added by this request, then flashes of the request's cookie
+	value := c.GetCookie(FlashCookie)
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+	cookie.SetKey(FlashCookie)
+	if c.Root().Response.Header.Cookie(cookie) {
+		value = string(cookie.Value())
+	}
+	if value == "" {
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+
+	var flashes []Flash
+	if err := json.Unmarshal(decoded, &flashes); err != nil {
+		return nil
+	}
+
+	// Cookies are client-controlled
+	for i := range flashes {
+		flashes[i].Level = SanitizeString(flashes[i].Level)
+		flashes[i].Message = SanitizeString(flashes[i].Message)
+	}
+
+	return flashes
+}
+
+// storeFlashes replaces the pending flash messages.
+func storeFlashes(c *core.Ctx, flashes []Flash) {
+	if sessionStore != nil {
+		if len(flashes) == 0 {
+			DeleteSession(c, flashSessionKey)
+		} else if err := SetSession(c, flashSessionKey, flashes); err != nil {
+			log.Errorf("Flash store error: %v", err)
+		}
+
+		return
+	}
+
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+	cookie.SetKey(FlashCookie)
+	cookie.SetPath("/")
+	cookie.SetHTTPOnly(true)
+	cookie.SetSameSite(fasthttp.CookieSameSiteLaxMode)
+
+	if len(flashes) == 0 {
+		// Empty value with an expiration in the past deletes the cookie, and marks the flashes consumed
+		cookie.SetExpire(fasthttp.CookieExpireDelete)
+	} else {
+		encoded, err := json.Marshal(flashes)
+		if err != nil {
+			log.Errorf("Flash encoding error: %v", err)
+
+			return
+		}
+		cookie.SetValue(base64.RawURLEncoding.EncodeToString(encoded))
+		cookie.SetMaxAge(int(flashCookieTTL.Seconds()))
+	}
+
+	c.Root().Response.Header.SetCookie(cookie)
+}
@@ -0,0 +1,297 @@
+package http
+
+import (
+	"encoding/json"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// =========================== Form Binding ===========================
+// ====================================================================
+
+// formField struct field bound to a form field.
+type formField struct {
+	name     string        // Form field name
+	jsonName string        // JSON name the value is decoded from
+	slice    bool          // Field taking every value of the form field
+	kind     formFieldKind // Conversion of the raw values
+	nested   []formField   // Fields of nested structs, named `parent.child` in the form
+}
+
+// formFieldKind conversion of the raw values of a form field.
+type formFieldKind int
+
+const (
+	formString formFieldKind = iota
+	formBool
+	formNumber
+	formStruct
+	formSkip // Slices of structs, maps and files, not representable by flat forms
+)
+
+// formFieldsCache form fields by struct type.
+var formFieldsCache sync.Map
+
+// optionalValueType type implemented by Optional values.
+var optionalValueType = reflect.TypeFor[optionalValue]()
+
+// adaptForm converts url-encoded and multipart form bodies to a JSON object of the fields of typ, so form posts
+// go through the JSON steps of Parse (aliases, unknown fields, time formats, decoding). The request body is
+// rewritten as JSON, which releases the files of multipart bodies: declare UploadedFile fields (see ProcessData) or
+// call ProcessUpload before Parse to keep them.
+// It is called by Parse after the payload adapters, registered adapters of the form media types take precedence.
+//
+// Form fields are named like the `form` tag of the fields, else their JSON name. Repeated fields (and `name[]`
+// fields) fill slices, nested structs take the fields named `parent.child`, checkboxes (`on`) set bools, and
+// empty values leave non-string fields zero. Fields not declared by the DTO are ignored.
+//
+// Example:
+//
+//	type SignupForm struct {
+//		Email     string   `json:"email" validate:"required,email"`
+//		Age       int      `json:"age" validate:"omitempty,min=18"`
+//		Interests []string `json:"interests" form:"interest"`
+//		Terms     bool     `json:"terms" validate:"required"`
+//		Address   struct {
+//			City string `json:"city"`
+//		} `json:"address"`
+//	}
+//
+//	// email=an%40x.io&age=30&interest=go&interest=sql&terms=on&address.city=Hanoi
+func adaptForm(c *core.Ctx, typ reflect.Type) *Error {
+	mediaType, _, err := mime.ParseMediaType(string(c.Root().Request.Header.ContentType()))
+	if err != nil {
+		return nil
+	}
+
+	var valuesFn func(name string) []string
+	switch mediaType {
+	case core.MIMEApplicationForm:
+		args := c.Root().PostArgs()
+		valuesFn = func(name string) []string {
+			var raws []string
+			for _, raw := range args.PeekMulti(name) {
+				raws = append(raws, string(raw))
+			}
+
+			return raws
+		}
+	case core.MIMEMultipartForm:
+		form, err := c.Root().MultipartForm()
+		if err != nil {
+			return &Error{
+				Code:    "INVALID_PAYLOAD",
+				Message: "Invalid " + mediaType + " payload: " + err.Error(),
+			}
+		}
+		valuesFn = func(name string) []string {
+			return form.Value[name]
+		}
+	default:
+		return nil
+	}
+
+	typ = derefType(typ)
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errorData := core.Data{}
+	document := formDocument(formFields(typ), valuesFn, errorData)
+	if len(errorData) > 0 {
+		return &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return &Error{
+			Message: err.Error(),
+		}
+	}
+
+	c.Root().Request.SetBody(body)
+	c.Root().Request.Header.SetContentType(core.MIMEApplicationJSON)
+
+	return nil
+}
+
+// formFields returns the form fields of a struct type, including the fields of embedded structs.
+func formFields(typ reflect.Type) []formField {
+	if cached, ok := formFieldsCache.Load(typ); ok {
+		return cached.([]formField)
+	}
+
+	fields := collectFormFields(typ, "", map[reflect.Type]bool{})
+	formFieldsCache.Store(typ, fields)
+
+	return fields
+}
+
+func collectFormFields(typ reflect.Type, prefix string, seen map[reflect.Type]bool) []formField {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	var fields []formField
+	for _, field := range reflect.VisibleFields(typ) {
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || jsonName == "-" || field.Anonymous && jsonName == "" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = jsonName
+		}
+
+		bound := formField{name: prefix + name, jsonName: jsonName}
+		var elem reflect.Type
+		elem, bound.slice, bound.kind = formKind(field.Type)
+		switch bound.kind {
+		case formSkip:
+			continue
+		case formStruct:
+			bound.nested = collectFormFields(elem, bound.name+".", seen)
+		default:
+		}
+		fields = append(fields, bound)
+	}
+
+	return fields
+}
+
+// formKind returns the type converted for a field type (the element of slices, the value of Optional),
+// whether the field is a slice and the conversion of its values.
+func formKind(typ reflect.Type) (reflect.Type, bool, formFieldKind) {
+	typ = derefType(typ)
+	slice := typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8
+	if slice {
+		typ = derefType(typ.Elem())
+	}
+	if typ.Kind() == reflect.Struct && typ.Implements(optionalValueType) {
+		typ = derefType(typ.Field(0).Type)
+	}
+
+	if typ == uploadedFileType {
+		return typ, slice, formSkip
+	}
+	if typ == timeType || reflect.PointerTo(typ).Implements(textUnmarshalerType) ||
+		reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return typ, slice, formString
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return typ, slice, formBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return typ, slice, formNumber
+	case reflect.Struct:
+		if slice {
+			return typ, slice, formSkip
+		}
+
+		return typ, slice, formStruct
+	case reflect.Map, reflect.Interface, reflect.Slice, reflect.Array:
+		return typ, slice, formSkip
+	default:
+		return typ, slice, formString
+	}
+}
+
+// formDocument builds the JSON object of the form fields present, with conversion errors by form field name.
+func formDocument(fields []formField, valuesFn func(name string) []string, errorData core.Data) map[string]any {
+	document := map[string]any{}
+
+	for _, field := range fields {
+		if field.kind == formStruct {
+			if nested := formDocument(field.nested, valuesFn, errorData); len(nested) > 0 {
+				document[field.jsonName] = nested
+			}
+
+			continue
+		}
+
+		raws := valuesFn(field.name)
+		if len(raws) == 0 {
+			raws = valuesFn(field.name + "[]")
+		}
+		if len(raws) == 0 {
+			continue
+		}
+
+		if !field.slice {
+			raws = raws[:1]
+		}
+
+		values := make([]any, 0, len(raws))
+		for _, raw := range raws {
+			value, ok, message := formValue(field.kind, raw)
+			if message != "" {
+				errorData[field.name] = []string{message}
+
+				break
+			}
+			if ok {
+				values = append(values, value)
+			}
+		}
+
+		switch {
+		case field.slice:
+			document[field.jsonName] = values
+		case len(values) > 0:
+			document[field.jsonName] = values[0]
+		default:
+		}
+	}
+
+	return document
+}
+
+// formValue converts a raw form value. ok is false for empty values of non-string fields, which are left zero.
+func formValue(kind formFieldKind, raw string) (any, bool, string) {
+	if kind != formString && strings.TrimSpace(raw) == "" {
+		return nil, false, ""
+	}
+
+	switch kind {
+	case formBool:
+		if strings.EqualFold(raw, "on") {
+			return true, true, ""
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false, "must be a boolean"
+		}
+
+		return value, true, ""
+	case formNumber:
+		raw = strings.TrimSpace(raw)
+		if _, err := strconv.ParseFloat(raw, 64); err != nil || !json.Valid([]byte(raw)) {
+			return nil, false, "must be a number"
+		}
+
+		return json.Number(raw), true, ""
+	default:
+		return raw, true, ""
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// fuzzDTO request DTO covering the kinds of fields decoded by Parse.
+type fuzzDTO struct {
+	ID       int64             `json:"id"`
+	Small    int8              `json:"small"`
+	Count    uint16            `json:"count"`
+	Price    float64           `json:"price"`
+	Name     string            `json:"name" validate:"required,max=255"`
+	Email    string            `json:"email" validate:"omitempty,email"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	At       time.Time         `json:"at"`
+	Optional *string           `json:"optional"`
+	Nested   struct {
+		Slug  string  `json:"slug" sanitize:"slug"`
+		Ratio float32 `json:"ratio"`
+	} `json:"nested"`
+}
+
+// newFuzzCtx creates the Ctx of a JSON request, setting its unexported fields as the gFly server does (see the
+// replay package, which can not be imported by the tests of this package).
+func newFuzzCtx(t testing.TB, method, uri, body string) *core.Ctx {
+	root := &fasthttp.RequestCtx{}
+	root.Request.Header.SetMethod(method)
+	root.Request.SetRequestURI(uri)
+	root.Request.Header.SetContentType(core.MIMEApplicationJSON)
+	root.Request.SetBodyString(body)
+
+	c := &core.Ctx{}
+	ctx := reflect.ValueOf(c).Elem()
+	for name, value := range map[string]any{"root": root, "data": core.Data{}} {
+		field := ctx.FieldByName(name)
+		if !field.IsValid() || field.Type() != reflect.TypeOf(value) {
+			t.Fatalf("unsupported core.Ctx layout, field %s", name)
+		}
+		reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(value))
+	}
+
+	return c
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		`{"id": 1, "name": "John", "email": "john@example.com", "tags": ["a"], "nested": {"slug": "Hello World"}}`,
+		`{"id": 99999999999999999999999999, "small": 128, "count": -1}`,
+		`{"price": 1e999999, "nested": {"ratio": 1e39}}`,
+		`{"id": 9007199254740993, "price": 9007199254740993}`,
+		`{"name": "\xff\xfe\xfd", "labels": {"\xc3\x28": "\xe2\x82"}}`,
+		`{"name": "<script>alert(1)</script>", "optional": null, "at": "2024-13-45T99:99:99Z"}`,
+		`[` + strings.Repeat(`[`, 1000) + `]`,
+		`{"id": "1", "tags": "a,b"}`,
+		``,
+	} {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+
+	defer RegisterParseOptions(parseOptions)
+
+	f.Fuzz(func(t *testing.T, body string, strictNumbers bool) {
+		RegisterParseOptions(ParseOptions{StrictNumbers: strictNumbers})
+
+		c := newFuzzCtx(t, "POST", "/fuzz", body)
+
+		var requestData fuzzDTO
+		if errData := Parse(c, &requestData); errData != nil {
+			if errData.Message == "" {
+				t.Errorf("error without message for body %q", body)
+			}
+
+			return
+		}
+
+		SanitizeStruct(&requestData)
+		for _, value := range append([]string{requestData.Name, requestData.Email, requestData.Nested.Slug}, requestData.Tags...) {
+			if !utf8.ValidString(value) || strings.ContainsRune(value, 0) {
+				t.Errorf("sanitized value %q is not clean", value)
+			}
+		}
+		_ = Validate(requestData)
+	})
+}
+
+func FuzzSanitizeString(f *testing.F) {
+	for _, seed := range []string{
+		"hello",
+		"  spaced  ",
+		"<script>alert(1)</script>text",
+		"<SCRIPT src=x>\n</SCRIPT>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"<scr<script></script>ipt>alert(1)</script>",
+		"<scr\x00ipt>alert(1)</script>",
+		"\xff\xfe<script>\xc3\x28</script>",
+		"&#0;&#xD800;&amp;amp;",
+		strings.Repeat("<script>", 1000) + strings.Repeat("</script>", 1000),
+		strings.Repeat("<script", 5000),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		clean := SanitizeString(input)
+
+		if !utf8.ValidString(clean) {
+			t.Errorf("invalid UTF-8 in %q", clean)
+		}
+		if strings.ContainsRune(clean, 0) {
+			t.Errorf("NUL byte in %q", clean)
+		}
+		if scriptTagPattern.MatchString(clean) {
+			t.Errorf("script tag left in %q", clean)
+		}
+	})
+}
+
+func FuzzFilterData(f *testing.F) {
+	for _, seed := range []string{
+		"page=2&per_page=20&keyword=shoes&order_by=-price",
+		"page=-1&per_page=0",
+		"page=9223372036854775807&per_page=9223372036854775807",
+		"page=99999999999999999999&per_page=1e9",
+		"page=4611686018427387904&per_page=4",
+		"keyword=%ff%fe&order_by=%00",
+		"page=1&page=2&per_page=&per_page=x",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		c := newFuzzCtx(t, "GET", "/fuzz?"+query, "")
+
+		filter := FilterData(c)
+		if filter.Page < 1 || filter.PerPage < 1 {
+			t.Fatalf("page %d and per_page %d must be positive", filter.Page, filter.PerPage)
+		}
+
+		errData := CheckOffset(c, filter)
+		fits := filter.Page-1 <= math.MaxInt/filter.PerPage && (filter.Page-1)*filter.PerPage < RequestConfig(c).MaxPageOffset
+		if fits != (errData == nil) {
+			t.Errorf("offset of page %d and per_page %d checked as fitting: %v", filter.Page, filter.PerPage, errData == nil)
+		}
+	})
+}
@@ -11,31 +11,65 @@ import "github.com/gflydev/core"
 // @Page Page is the current page number (optional, starts from 1)
 // @PerPage PerPage is the number of items displayed per page (optional)
 // @Total Total is the total number of records available
+// @FailedCount FailedCount is the number of records skipped in partial-success mode (optional)
+// @FailedIDs FailedIDs are the IDs of records skipped in partial-success mode (optional)
+// @DeltaToken DeltaToken is the token for the next collection sync (optional)
+// @DeletedIDs DeletedIDs are the IDs of records deleted since the previous sync (optional)
+// @Stale Stale is set when the response is served from the cache while it is being refreshed (optional)
+// @Quota Quota is the remaining quota of the caller for metered endpoints (optional)
+// @Truncated Truncated is set when records were cut to fit the response size budget (optional)
+// @Flashes Flashes are the flash messages to display, set by a previous request (optional)
 // @Tags Info Responses
 type Meta struct {
-	Page    int `json:"page,omitempty" example:"1" doc:"Current page number"`
-	PerPage int `json:"per_page,omitempty" example:"10" doc:"Number of items per page"`
-	Total   int `json:"total" example:"1354" doc:"Total number of records"`
+	Page        int     `json:"page,omitempty" example:"1" doc:"Current page number"`
+	PerPage     int     `json:"per_page,omitempty" example:"10" doc:"Number of items per page"`
+	Total       int     `json:"total" example:"1354" doc:"Total number of records"`
+	FailedCount int     `json:"failed_count,omitempty" example:"1" doc:"Number of records skipped because their transformation failed"`
+	FailedIDs   []any   `json:"failed_ids,omitempty" example:"[42]" doc:"IDs of records skipped because their transformation failed"`
+	DeltaToken  string  `json:"delta_token,omitempty" example:"eyJzIjoiMjAyNC0wMS0zMVQxMDowMDowMFoifQ" doc:"Token to send back as delta_token to fetch only later changes"`
+	DeletedIDs  []any   `json:"deleted_ids,omitempty" example:"[7]" doc:"IDs of records deleted since the previous sync"`
+	Stale       bool    `json:"stale,omitempty" example:"false" doc:"Response served from the cache while being refreshed"`
+	Quota       *Quota  `json:"quota,omitempty" doc:"Remaining quota of the caller for metered endpoints"`
+	Truncated   bool    `json:"truncated,omitempty" example:"false" doc:"Records were cut to fit the response size budget"`
+	Flashes     []Flash `json:"flashes,omitempty" doc:"Flash messages to display, set by a previous request"`
+}
+
+// Warning struct to describe a non-fatal notice attached to a success response.
+// @Description Non-fatal information such as deprecation notices, partial failures or auto-corrections
+// @Code Code is a machine-readable warning code.
+// @Message Message is a human-readable description of the warning.
+// @Field Field is the optional request field the warning relates to.
+// @Tags Info Responses
+type Warning struct {
+	Code    string `json:"code" example:"DEPRECATED" doc:"Machine-readable warning code"`
+	Message string `json:"message" example:"Field 'name' is deprecated" doc:"Warning message description"`
+	Field   string `json:"field,omitempty" example:"name" doc:"Request field related to the warning"`
 }
 
 // List struct to describe a generic list response.
 // @Description Generic list response structure
 // @Meta Meta contains metadata information for pagination.
 // @Data Data is a slice of type T, which can be any data type.
+// @Warnings Warnings is optional and contains non-fatal notices about the request.
 // @Tags Success Responses
 type List[T any] struct {
-	Meta Meta `json:"meta" example:"{\"page\":1,\"per_page\":10,\"total\":100}" doc:"Metadata information for pagination"`
-	Data []T  `json:"data" example:"[]" doc:"List of category data"`
+	Meta     Meta      `json:"meta" example:"{\"page\":1,\"per_page\":10,\"total\":100}" doc:"Metadata information for pagination"`
+	Data     []T       `json:"data" example:"[]" doc:"List of category data"`
+	Warnings []Warning `json:"warnings,omitempty" doc:"Non-fatal notices about the request"`
 }
 
 // Success struct to describe a generic success response.
 // @Description Generic success response structure
 // @Data Data is optional and can be used to return additional information related to the operation.
 // @Message Message is a success message that describes the operation.
+// @Warnings Warnings is optional and contains non-fatal notices about the operation.
+// @Flashes Flashes is optional and contains the flash messages to display.
 // @Tags Success Responses
 type Success struct {
-	Message string    `json:"message" example:"Operation completed successfully"`  // Success message description
-	Data    core.Data `json:"data" doc:"Additional data related to the operation"` // Optional data related to the success operation
+	Message  string    `json:"message" example:"Operation completed successfully"`             // Success message description
+	Data     core.Data `json:"data" doc:"Additional data related to the operation"`            // Optional data related to the success operation
+	Warnings []Warning `json:"warnings,omitempty" doc:"Non-fatal notices about the operation"` // Optional non-fatal notices
+	Flashes  []Flash   `json:"flashes,omitempty" doc:"Flash messages to display"`              // Optional flash messages
 }
 
 // ====================================================================
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"fmt"
+
+	"github.com/gflydev/core/log"
 	"github.com/gflydev/utils/fn"
 )
 
@@ -9,3 +12,66 @@ import (
 func ToListResponse[T any, R any](records []T, transformerFn func(T) R) []R {
 	return fn.TransformList(records, transformerFn)
 }
+
+// ToPartialListResponse generic function takes a list of records, a fallible transformer function
+// and an ID function; records failing the transformation are skipped instead of failing the whole list.
+// Returns the transformed data and the IDs of the skipped records.
+func ToPartialListResponse[T any, R any](records []T, transformerFn func(T) (R, error), idFn func(T) any) ([]R, []any) {
+	data := make([]R, 0, len(records))
+	var failedIDs []any
+
+	for _, record := range records {
+		item, err := transformerFn(record)
+		if err != nil {
+			id := idFn(record)
+			log.Errorf("Skip record %v from list response: %v", id, err)
+			failedIDs = append(failedIDs, id)
+
+			continue
+		}
+
+		data = append(data, item)
+	}
+
+	return data, failedIDs
+}
+
+// NewListResponse generic function builds a List response from records, the filter used to query them,
+// and the total number of available records.
+func NewListResponse[T any, R any](records []T, filter Filter, total int, transformerFn func(T) R) List[R] {
+	return List[R]{
+		Meta: Meta{
+			Page:    filter.Page,
+			PerPage: filter.PerPage,
+			Total:   total,
+		},
+		Data: ToListResponse(records, transformerFn),
+	}
+}
+
+// NewPartialListResponse generic function builds a List response like NewListResponse but in partial-success mode:
+// records failing the transformation are skipped, counted in Meta.FailedCount / Meta.FailedIDs
+// and reported with a WarningPartialFailure warning.
+func NewPartialListResponse[T any, R any](records []T, filter Filter, total int, transformerFn func(T) (R, error), idFn func(T) any) List[R] {
+	data, failedIDs := ToPartialListResponse(records, transformerFn, idFn)
+
+	list := List[R]{
+		Meta: Meta{
+			Page:        filter.Page,
+			PerPage:     filter.PerPage,
+			Total:       total,
+			FailedCount: len(failedIDs),
+			FailedIDs:   failedIDs,
+		},
+		Data: data,
+	}
+
+	if len(failedIDs) > 0 {
+		list.Warnings = append(list.Warnings, Warning{
+			Code:    WarningPartialFailure,
+			Message: fmt.Sprintf("%d record(s) could not be transformed and were skipped", len(failedIDs)),
+		})
+	}
+
+	return list
+}
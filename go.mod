@@ -3,9 +3,15 @@ module github.com/gflydev/http
 go 1.24.0
 
 require (
+	github.com/fasthttp/websocket v1.5.12
 	github.com/gflydev/core v1.17.11
 	github.com/gflydev/utils v1.1.0
 	github.com/gflydev/validation v1.2.1
+	github.com/go-playground/validator/v10 v10.28.0
+	github.com/valyala/fasthttp v1.67.0
+	golang.org/x/crypto v0.43.0
+	golang.org/x/text v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -15,7 +21,6 @@ require (
 	github.com/gflydev/db v1.12.4 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/go-playground/validator/v10 v10.28.0 // indirect
 	github.com/jivegroup/fluentsql v1.5.4 // indirect
 	github.com/jmoiron/sqlx v1.4.0 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
@@ -23,9 +28,8 @@ require (
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/savsgio/gotils v0.0.0-20240704082632-aef3928b8a38 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.67.0 // indirect
-	golang.org/x/crypto v0.43.0 // indirect
+	golang.org/x/net v0.45.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
 )
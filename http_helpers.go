@@ -1,15 +1,23 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
 	"github.com/gflydev/core"
 	"github.com/gflydev/validation"
-	"strconv"
+	"github.com/go-playground/validator/v10"
 )
 
 // ---------------------- Path data ------------------------
 
-// PathID get ID from path request
+// PathID get ID from path request.
+// When an IDCodec is registered, the path value is decoded with it (see RegisterIDCodec).
 func PathID(c *core.Ctx, idName ...string) (int, *Error) {
 	// Path name
 	name := "id"
@@ -18,23 +26,217 @@ func PathID(c *core.Ctx, idName ...string) (int, *Error) {
 	}
 
 	// Parse path parameter
-	id, err := strconv.Atoi(c.PathVal(name))
+	id, err := DecodeID(c.PathVal(name))
 	if err != nil || id < 1 {
+		message := fmt.Sprintf("%s must be positive integer", name)
+		if idCodec != nil {
+			message = fmt.Sprintf("%s must be valid identifier", name)
+		}
+
 		return id, &Error{
-			Message: fmt.Sprintf("%s must be positive integer", name),
+			Message: message,
 		}
 	}
 
 	return id, nil
 }
 
+// PathParam get a string parameter from path request and check it against validation rules.
+//
+// Example Usage:
+//
+//	id, errData := http.PathParam(c, "id", "uuid7")
+func PathParam(c *core.Ctx, name, rules string) (string, *Error) {
+	value := c.PathVal(name)
+
+	// Check path parameter
+	if err := validation.ValidatorInstance().Var(value, rules); err != nil {
+		message := fmt.Sprintf("%s is invalid", name)
+
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) && len(ve) > 0 {
+			message = fmt.Sprintf("%s %s", name, MsgForTag(ve[0]))
+		}
+
+		return value, &Error{
+			Message: message,
+		}
+	}
+
+	return value, nil
+}
+
 // ---------------------- Parse data ------------------------
 
-// Parse get body data from request
+// ParseOptions options applied by Parse.
+type ParseOptions struct {
+	// StrictNumbers rejects numbers out of the range of their field's type, and integers which can not be
+	// represented exactly by float fields, with an error per field. Numbers decoded into interface values
+	// are kept as json.Number instead of float64.
+	StrictNumbers bool
+	// DisallowUnknownFields rejects bodies holding fields the DTO does not declare (e.g. "per_pages" for
+	// "per_page") with an UNKNOWN_FIELDS error listing them, instead of ignoring them.
+	DisallowUnknownFields bool
+	// MaxBodySize maximum size in bytes of the bodies, DefaultMaxBodySize when zero, unlimited when negative.
+	// Larger bodies are rejected with a BODY_TOO_LARGE error (413, see ParseStatus) without being decoded.
+	MaxBodySize int64
+}
+
+// parseOptions options used by Parse.
+var parseOptions ParseOptions
+
+// RegisterParseOptions registers the options applied by Parse.
+// Once a configuration snapshot is loaded (see ReloadConfig), the options of the snapshot are replaced.
+func RegisterParseOptions(options ParseOptions) {
+	parseOptions = options
+	if configSnapshot.Load() != nil {
+		_ = UpdateConfig(func(config *Config) { config.Parse = options })
+	}
+}
+
+// Parse get body data from request.
+// The body is first verified against its checksum headers (see VerifyChecksum), a mismatch returns an
+// INTEGRITY_ERROR. Bodies of legacy formats are converted by their PayloadAdapter (see RegisterPayloadAdapter),
+// and legacy names declared by `json_alias` tags are accepted for renamed fields. YAML bodies are converted to
+// JSON by a built-in adapter, and so are form posts (application/x-www-form-urlencoded, multipart/form-data),
+// their fields named by the `form` tags of the DTO or its JSON names.
+// XML bodies (application/xml, text/xml, +xml media types) without an adapter are decoded with encoding/xml,
+// matching the `xml` tags of the DTO; other bodies are decoded as JSON.
+// Bodies of a media type with a MediaCodec supporting *T (see RegisterMediaCodec) are decoded by the codec first.
+// JSON bodies are decoded with the ParseOptions of the request's configuration (see RegisterParseOptions).
 func Parse[T any](c *core.Ctx, structData *T) *Error {
+	return ParseWith(c, structData, RequestConfig(c).Parse)
+}
+
+// ParseWith gets body data from request like Parse, with the options of the call instead of the registered ones.
+//
+// Example Usage:
+//
+//	// Accept large imports on this endpoint only
+//	if errData := http.ParseWith(c, &requestData, http.ParseOptions{MaxBodySize: 64 << 20}); errData != nil {
+//		return c.Error(errData, http.ParseStatus(errData))
+//	}
+//
+//	// Reject typos in the fields of this endpoint only
+//	if errData := http.ParseWith(c, &requestData, http.ParseOptions{DisallowUnknownFields: true}); errData != nil {
+//		return c.Error(errData)
+//	}
+func ParseWith[T any](c *core.Ctx, structData *T, options ParseOptions) *Error {
+	// Reject oversized bodies before reading them
+	if errData := limitBody(c, options.MaxBodySize); errData != nil {
+		return errData
+	}
+
+	// Verify body integrity
+	if errData := VerifyChecksum(c); errData != nil {
+		reportRequest(c, true)
+
+		return errData
+	}
+
+	if errData := decodeBody(c, reflect.TypeFor[T](), structData, options); errData != nil {
+		reportRequest(c, true)
+
+		return errData
+	}
+
+	return nil
+}
+
+// decodeBody runs the body pipeline of Parse on the request body: media codecs, payload and form adapters, XML,
+// legacy aliases, disabled features, unknown fields, `timeFormat` layouts, then the JSON decoding into target, a
+// pointer to typ. It is shared by ParseWith and Lint.
+func decodeBody(c *core.Ctx, typ reflect.Type, target any, options ParseOptions) *Error {
+	// Decode bodies of registered media codecs (protobuf, ...), the JSON-only steps below do not apply to them
+	if mediaCodec, ok := requestMediaCodec(c, reflect.PointerTo(typ)); ok {
+		if err := mediaCodec.Unmarshal(c.Root().PostBody(), target); err != nil {
+			return &Error{
+				Message: err.Error(),
+			}
+		}
+
+		// Drop the fields of disabled features
+		clearDisabledFeatures(c, target)
+
+		return nil
+	}
+
+	// Convert legacy payload formats to JSON
+	if errData := adaptPayload(c, typ); errData != nil {
+		return errData
+	}
+
+	// Convert form posts to JSON
+	if errData := adaptForm(c, typ); errData != nil {
+		return errData
+	}
+
+	// Decode XML bodies, the JSON-only steps below do not apply to them
+	if isXMLBody(c) {
+		if errData := parseXML(c, typ, target); errData != nil {
+			return errData
+		}
+
+		// Drop the fields of disabled features
+		clearDisabledFeatures(c, target)
+
+		return nil
+	}
+
+	// Rename legacy fields
+	applyAliases(c, typ)
+
+	// Drop the fields of disabled features
+	dropDisabledFeatures(c, typ)
+
+	// Reject unknown fields
+	if options.DisallowUnknownFields {
+		if errData := checkUnknownFields(c.Root().PostBody(), typ); errData != nil {
+			return errData
+		}
+	}
+
+	// Convert times sent in the layouts of `timeFormat` tags
+	if errData := applyTimeFormats(c, typ); errData != nil {
+		return errData
+	}
+
+	if options.StrictNumbers {
+		return decodeStrict(c.Root().PostBody(), typ, target)
+	}
+
 	// Parse request body
-	err := c.ParseBody(structData)
-	if err != nil {
+	if err := codec.Unmarshal(c.Root().PostBody(), target); err != nil {
+		return &Error{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// decodeStrict decodes a JSON body into structData, a pointer to typ, checking its numbers fit the fields.
+func decodeStrict(body []byte, typ reflect.Type, structData any) *Error {
+	if errorData := checkNumbers(body, typ); len(errorData) > 0 {
+		return &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(structData); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) && typeError.Field != "" {
+			return &Error{
+				Message: "Invalid input",
+				Data: core.Data{
+					typeError.Field: []string{fmt.Sprintf("must be %s", typeError.Type)},
+				},
+			}
+		}
+
 		return &Error{
 			Message: err.Error(),
 		}
@@ -45,43 +247,151 @@ func Parse[T any](c *core.Ctx, structData *T) *Error {
 
 // ---------------------- Filters ------------------------
 
+var (
+	// DefaultPerPage per_page of filters without a usable per_page.
+	DefaultPerPage = 10
+	// MaxPerPage cap of the per_page of filters, larger values are lowered with a warning; zero disables it.
+	MaxPerPage = 0
+	// MaxPageOffset maximum offset ((page-1) * per_page) accepted by ProcessFilter; zero disables the guard.
+	// Deep OFFSET queries scan and discard all skipped rows, cursor pagination should be used beyond it.
+	MaxPageOffset = 10000
+)
+
+// FilterData reads the page, per_page, keyword and order_by query parameters, normalized with the QueryOptions
+// of the request (see RegisterQueryOptions).
 func FilterData(c *core.Ctx) Filter {
 	// Receive request parameters
-	page, _ := c.QueryInt("page")
-	limit, _ := c.QueryInt("per_page")
+	page, _ := strconv.Atoi(QueryValue(c, "page"))
+	limit, _ := strconv.Atoi(QueryValue(c, "per_page"))
 
-	// Set default values.
+	// Set default values. Values sent by the client but unusable are reported as warnings.
 	if page < 1 {
+		if QueryValue(c, "page") != "" {
+			AddWarning(c, WarningAutoCorrected, "page must be positive integer, defaulted to 1", "page")
+		}
 		page = 1
 	}
 
+	config := RequestConfig(c)
 	if limit < 1 {
-		limit = 10
+		if QueryValue(c, "per_page") != "" {
+			AddWarning(c, WarningAutoCorrected,
+				fmt.Sprintf("per_page must be positive integer, defaulted to %d", config.DefaultPerPage), "per_page")
+		}
+		limit = config.DefaultPerPage
+	}
+	if config.MaxPerPage > 0 && limit > config.MaxPerPage {
+		AddWarning(c, WarningAutoCorrected, fmt.Sprintf("per_page must not exceed %d, lowered", config.MaxPerPage),
+			"per_page")
+		limit = config.MaxPerPage
 	}
 
 	// Create DTO
 	filterDto := Filter{}
-	filterDto.Keyword = c.QueryStr("keyword")
-	filterDto.OrderBy = c.QueryStr("order_by")
+	filterDto.Keyword = QueryValue(c, "keyword")
+	filterDto.OrderBy = QueryValue(c, "order_by")
 	filterDto.Page = page
 	filterDto.PerPage = limit
 
 	return filterDto
 }
 
+// CheckOffset checks the filter's offset does not exceed the MaxPageOffset of the request's configuration.
+func CheckOffset(c *core.Ctx, filter Filter) *Error {
+	maxOffset := RequestConfig(c).MaxPageOffset
+	if maxOffset <= 0 || filter.Page <= 1 || filter.PerPage <= 0 {
+		return nil
+	}
+
+	// Compared by division, the product of huge values overflows
+	if filter.Page-1 < maxOffset/filter.PerPage ||
+		(filter.Page-1 == maxOffset/filter.PerPage && maxOffset%filter.PerPage != 0) {
+		return nil
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+	if filter.Page-1 > math.MaxInt/filter.PerPage {
+		offset = math.MaxInt
+	}
+
+	return &Error{
+		Code:    "OFFSET_TOO_LARGE",
+		Message: fmt.Sprintf("page * per_page must not exceed %d, use cursor pagination to go further", maxOffset),
+		Data: core.Data{
+			"offset":     offset,
+			"max_offset": maxOffset,
+			"suggestion": "cursor",
+		},
+	}
+}
+
 // ---------------------- Validations ------------------------
 
 // Validate perform data input checking.
+// Messages are built by MsgForTag unless a custom function is given.
 func Validate(structData any, msgForTagFunc ...validation.MsgForTagFunc) *Error {
-	errorData, err := validation.Check(structData, msgForTagFunc...)
+	return ValidateRequest(nil, structData, msgForTagFunc...)
+}
+
+// ValidateRequest works as Validate, and ignores the failed rules relaxed for the request by the
+// registered RuleOverride (see RegisterRuleOverride). It is used by ProcessData and ProcessUpdateData.
+// The outcome is reported to the registered AbuseDetector, and failed rules are counted (see ValidationStats).
+func ValidateRequest(c *core.Ctx, structData any, msgForTagFunc ...validation.MsgForTagFunc) *Error {
+	msgFn := validation.MsgForTagFunc(MsgForTag)
+	if len(msgForTagFunc) > 0 {
+		msgFn = msgForTagFunc[0]
+	}
+
+	errorData, failed, ok := validateStruct(c, structData, msgFn)
+	if !ok {
+		reportRequest(c, true)
 
-	if err != nil {
-		// Response validation error
 		return &Error{
 			Message: "Invalid input",
-			Data:    errorData,
 		}
 	}
 
-	return nil
+	recordValidation(structData, failed)
+	reportRequest(c, len(errorData) > 0)
+	if len(errorData) == 0 {
+		return nil
+	}
+
+	// Response validation error
+	return &Error{
+		Message: "Invalid input",
+		Data:    errorData,
+	}
+}
+
+// validateStruct checks the validation rules of a struct, without recording nor reporting the outcome. It
+// returns the messages by field and the failed rules, those relaxed for the request excluded; ok is false
+// when the struct can not be validated.
+func validateStruct(c *core.Ctx, structData any, msgFn validation.MsgForTagFunc) (core.Data, []validator.FieldError, bool) {
+	// Let rules check the values of Optional fields
+	registerOptionalTypes()
+
+	errorData := core.Data{}
+	var failed []validator.FieldError
+	if err := validation.ValidatorInstance().Struct(structData); err != nil {
+		var ve validator.ValidationErrors
+		if !errors.As(err, &ve) {
+			return nil, nil, false
+		}
+
+		for _, fe := range ve {
+			if relaxRule(c, fieldPath(fe.Namespace()), fe.Tag()) {
+				continue
+			}
+			failed = append(failed, fe)
+
+			messages, _ := errorData[fe.Field()].([]string)
+			errorData[fe.Field()] = append(messages, msgFn(fe))
+		}
+	}
+
+	// Values the `transform` tags can not process (passwords too long for bcrypt)
+	checkTransformInputs(reflect.ValueOf(structData), errorData)
+
+	return errorData, failed, true
 }
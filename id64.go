@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ====================================================================
+// ========================== 64-bit IDs ==============================
+// ====================================================================
+
+// MaxSafeInteger largest integer exactly represented by a JavaScript number (2^53 - 1).
+const MaxSafeInteger = 1<<53 - 1
+
+// IDStringMode tells when ID64 and PublicID values are serialized as JSON strings.
+type IDStringMode int
+
+// ID string modes.
+const (
+	IDStringUnsafe IDStringMode = iota // Strings for IDs beyond ±MaxSafeInteger only (default)
+	IDStringAlways                     // Strings for all IDs, so clients handle a single type
+	IDStringNever                      // Numbers for all IDs
+)
+
+// idStringMode the mode used by ID64 and PublicID.
+var idStringMode = IDStringUnsafe
+
+// errInvalidID64 returned when a JSON value is neither an integer nor a decimal string.
+var errInvalidID64 = errors.New("ID must be integer or decimal string")
+
+// RegisterIDStringMode registers when IDs are serialized as strings. JavaScript clients silently
+// round integers beyond 2^53, e.g. snowflake IDs, which breaks lookups with the received ID.
+//
+// Example Usage:
+//
+//	http.RegisterIDStringMode(http.IDStringAlways)
+func RegisterIDStringMode(mode IDStringMode) {
+	idStringMode = mode
+}
+
+// ID64 64-bit ID serialized as a JSON string when the registered IDStringMode requires it, and
+// accepting both numbers and decimal strings when unmarshaled. Use it in request and response DTOs
+// for IDs which may exceed MaxSafeInteger.
+type ID64 int64
+
+// String returns the decimal representation of the ID.
+func (id ID64) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON encodes the ID as a number, or as a string per the registered IDStringMode.
+func (id ID64) MarshalJSON() ([]byte, error) {
+	return marshalID(int64(id)), nil
+}
+
+// UnmarshalJSON accepts either a number or a decimal string. null leaves the ID unchanged, as for other types.
+func (id *ID64) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return errInvalidID64
+	}
+
+	*id = ID64(parsed)
+
+	return nil
+}
+
+// marshalID encodes a numeric ID per the registered IDStringMode.
+func marshalID(id int64) []byte {
+	encoded := strconv.FormatInt(id, 10)
+
+	if idStringMode == IDStringAlways ||
+		(idStringMode == IDStringUnsafe && (id > MaxSafeInteger || id < -MaxSafeInteger)) {
+		return []byte(strconv.Quote(encoded))
+	}
+
+	return []byte(encoded)
+}
@@ -0,0 +1,329 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ====================================================================
+// ============================ ID Codec ==============================
+// ====================================================================
+
+// IDCodec is an interface for types that obfuscate numeric IDs exposed in paths and responses.
+type IDCodec interface {
+	// EncodeID converts a numeric ID to its public representation.
+	EncodeID(id int) (string, error)
+
+	// DecodeID converts a public representation back to the numeric ID.
+	DecodeID(value string) (int, error)
+}
+
+// idCodec the codec used by PathID, EncodeID and PublicID. Nil means IDs are exposed as-is.
+var idCodec IDCodec
+
+// RegisterIDCodec registers the codec used to decode path IDs and encode response IDs.
+// Pass nil to expose sequential IDs again.
+//
+// Example Usage:
+//
+//	http.RegisterIDCodec(http.NewHashIDCodec(os.Getenv("HASHID_SALT"), 8))
+func RegisterIDCodec(codec IDCodec) {
+	idCodec = codec
+}
+
+// EncodeID converts a numeric ID to its public form using the registered codec.
+// Without codec, the decimal representation is returned.
+func EncodeID(id int) string {
+	if idCodec == nil {
+		return strconv.Itoa(id)
+	}
+
+	encoded, err := idCodec.EncodeID(id)
+	if err != nil {
+		return strconv.Itoa(id)
+	}
+
+	return encoded
+}
+
+// DecodeID converts a public ID to its numeric form using the registered codec.
+// Without codec, the value is parsed as a decimal integer.
+func DecodeID(value string) (int, error) {
+	if idCodec == nil {
+		return strconv.Atoi(value)
+	}
+
+	return idCodec.DecodeID(value)
+}
+
+// PublicID numeric ID which is encoded with the registered codec when marshaled to JSON
+// and decoded when unmarshaled. Use it in response DTOs instead of int.
+type PublicID int
+
+// MarshalJSON encodes the ID with the registered codec. Without codec, the ID is serialized per the
+// registered IDStringMode.
+func (id PublicID) MarshalJSON() ([]byte, error) {
+	if idCodec == nil {
+		return marshalID(int64(id)), nil
+	}
+
+	return []byte(strconv.Quote(EncodeID(int(id)))), nil
+}
+
+// UnmarshalJSON accepts either an encoded string or a plain number.
+func (id *PublicID) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	decoded, err := DecodeID(value)
+	if err != nil {
+		return err
+	}
+
+	*id = PublicID(decoded)
+
+	return nil
+}
+
+// ====================================================================
+// ========================== Hashids Codec ===========================
+// ====================================================================
+
+const (
+	hashIDAlphabet   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+	hashIDSeparators = "cfhistuCFHISTU"
+	hashIDSepDiv     = 3.5
+	hashIDGuardDiv   = 12.0
+)
+
+// errInvalidHashID returned when a value can not be decoded by HashIDCodec.
+var errInvalidHashID = errors.New("invalid hash ID")
+
+// HashIDCodec IDCodec implementation of the Hashids algorithm (https://hashids.org).
+// Encoded values are compatible with other Hashids implementations using the default alphabet.
+type HashIDCodec struct {
+	salt      []rune
+	minLength int
+	alphabet  []rune
+	seps      []rune
+	guards    []rune
+}
+
+// NewHashIDCodec creates a Hashids codec with the project salt and minimum length of encoded values.
+func NewHashIDCodec(salt string, minLength int) *HashIDCodec {
+	h := &HashIDCodec{
+		salt:      []rune(salt),
+		minLength: minLength,
+	}
+
+	// Separators must be part of the alphabet and are removed from it
+	alphabet := []rune(hashIDAlphabet)
+	var seps []rune
+	for _, r := range hashIDSeparators {
+		if idx := indexRune(alphabet, r); idx >= 0 {
+			seps = append(seps, r)
+			alphabet = append(alphabet[:idx], alphabet[idx+1:]...)
+		}
+	}
+	seps = hashIDShuffle(seps, h.salt)
+
+	if len(seps) == 0 || float64(len(alphabet))/float64(len(seps)) > hashIDSepDiv {
+		sepsLength := int(math.Ceil(float64(len(alphabet)) / hashIDSepDiv))
+		if sepsLength == 1 {
+			sepsLength++
+		}
+		if sepsLength > len(seps) {
+			diff := sepsLength - len(seps)
+			seps = append(seps, alphabet[:diff]...)
+			alphabet = alphabet[diff:]
+		} else {
+			seps = seps[:sepsLength]
+		}
+	}
+	alphabet = hashIDShuffle(alphabet, h.salt)
+
+	guardCount := int(math.Ceil(float64(len(alphabet)) / hashIDGuardDiv))
+	if len(alphabet) < 3 {
+		h.guards = seps[:guardCount]
+		seps = seps[guardCount:]
+	} else {
+		h.guards = alphabet[:guardCount]
+		alphabet = alphabet[guardCount:]
+	}
+
+	h.alphabet = alphabet
+	h.seps = seps
+
+	return h
+}
+
+// EncodeID encodes a non-negative ID.
+func (h *HashIDCodec) EncodeID(id int) (string, error) {
+	if id < 0 {
+		return "", errInvalidHashID
+	}
+
+	return h.encode([]int{id}), nil
+}
+
+// DecodeID decodes a value produced by EncodeID.
+func (h *HashIDCodec) DecodeID(value string) (int, error) {
+	numbers := h.decode(value)
+	if len(numbers) != 1 {
+		return 0, errInvalidHashID
+	}
+
+	return numbers[0], nil
+}
+
+func (h *HashIDCodec) encode(numbers []int) string {
+	alphabet := append([]rune(nil), h.alphabet...)
+
+	numbersHash := 0
+	for i, number := range numbers {
+		numbersHash += number % (i + 100)
+	}
+
+	lottery := alphabet[numbersHash%len(alphabet)]
+	result := []rune{lottery}
+
+	for i, number := range numbers {
+		buffer := append(append([]rune{lottery}, h.salt...), alphabet...)
+		alphabet = hashIDShuffle(alphabet, buffer[:len(alphabet)])
+		last := hashIDHash(number, alphabet)
+		result = append(result, last...)
+
+		if i+1 < len(numbers) {
+			number %= int(last[0]) + i
+			result = append(result, h.seps[number%len(h.seps)])
+		}
+	}
+
+	if len(result) < h.minLength {
+		guardIndex := (numbersHash + int(result[0])) % len(h.guards)
+		result = append([]rune{h.guards[guardIndex]}, result...)
+
+		if len(result) < h.minLength {
+			guardIndex = (numbersHash + int(result[2])) % len(h.guards)
+			result = append(result, h.guards[guardIndex])
+		}
+	}
+
+	halfLength := len(alphabet) / 2
+	for len(result) < h.minLength {
+		alphabet = hashIDShuffle(alphabet, append([]rune(nil), alphabet...))
+		result = append(append(append([]rune(nil), alphabet[halfLength:]...), result...), alphabet[:halfLength]...)
+
+		if excess := len(result) - h.minLength; excess > 0 {
+			start := excess / 2
+			result = result[start : start+h.minLength]
+		}
+	}
+
+	return string(result)
+}
+
+func (h *HashIDCodec) decode(value string) []int {
+	if value == "" {
+		return nil
+	}
+
+	parts := hashIDSplit([]rune(value), h.guards)
+	breakdown := parts[0]
+	if len(parts) == 2 || len(parts) == 3 {
+		breakdown = parts[1]
+	}
+	if breakdown == "" {
+		return nil
+	}
+
+	runes := []rune(breakdown)
+	lottery := runes[0]
+	alphabet := append([]rune(nil), h.alphabet...)
+
+	var numbers []int
+	for _, subID := range hashIDSplit(runes[1:], h.seps) {
+		buffer := append(append([]rune{lottery}, h.salt...), alphabet...)
+		alphabet = hashIDShuffle(alphabet, buffer[:len(alphabet)])
+
+		number, ok := hashIDUnhash([]rune(subID), alphabet)
+		if !ok {
+			return nil
+		}
+		numbers = append(numbers, number)
+	}
+
+	// Reject values which are not canonical encodings
+	if len(numbers) == 0 || h.encode(numbers) != value {
+		return nil
+	}
+
+	return numbers
+}
+
+// hashIDSplit splits the value on any of the separators, keeping empty parts.
+func hashIDSplit(value, separators []rune) []string {
+	return strings.Split(strings.Map(func(r rune) rune {
+		if indexRune(separators, r) >= 0 {
+			return ' '
+		}
+
+		return r
+	}, string(value)), " ")
+}
+
+// hashIDShuffle consistent shuffle of the alphabet driven by the salt.
+func hashIDShuffle(alphabet, salt []rune) []rune {
+	result := append([]rune(nil), alphabet...)
+	if len(salt) == 0 {
+		return result
+	}
+
+	for i, v, p := len(result)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		integer := int(salt[v])
+		p += integer
+		j := (integer + v + p) % i
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result
+}
+
+func hashIDHash(number int, alphabet []rune) []rune {
+	var hash []rune
+	for {
+		hash = append([]rune{alphabet[number%len(alphabet)]}, hash...)
+		number /= len(alphabet)
+		if number == 0 {
+			return hash
+		}
+	}
+}
+
+func hashIDUnhash(hash, alphabet []rune) (int, bool) {
+	number := 0
+	for _, r := range hash {
+		pos := indexRune(alphabet, r)
+		if pos < 0 || number > (math.MaxInt-pos)/len(alphabet) {
+			return 0, false
+		}
+		number = number*len(alphabet) + pos
+	}
+
+	return number, true
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, item := range runes {
+		if item == r {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"time"
+)
+
+// ====================================================================
+// ======================= Sortable Identifiers =======================
+// ====================================================================
+
+// crockfordAlphabet Crockford's Base32 alphabet used by ULIDs.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	uuidV7Pattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-7[0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`)
+	ulidPattern   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+)
+
+// NewUUIDv7 generates a time-ordered UUID version 7 (RFC 9562) such as "01890a5d-ac96-774b-bcce-b302099a8057".
+func NewUUIDv7() string {
+	var id [16]byte
+	_, _ = rand.Read(id[6:])
+	putTimestamp(id[:6], time.Now())
+
+	id[6] = (id[6] & 0x0f) | 0x70 // Version 7
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant RFC 9562
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+
+	return string(buf)
+}
+
+// NewULID generates a lexicographically sortable ULID such as "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+func NewULID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[6:])
+	putTimestamp(id[:6], time.Now())
+
+	// 128 bits encoded as 26 characters of 5 bits, the first character holds the 3 leading bits.
+	buf := make([]byte, 26)
+	bits, value := 0, uint(0)
+	pos := 25
+	for i := len(id) - 1; i >= 0; i-- {
+		value |= uint(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			buf[pos] = crockfordAlphabet[value&0x1f]
+			pos--
+			value >>= 5
+			bits -= 5
+		}
+	}
+	buf[0] = crockfordAlphabet[value&0x1f]
+
+	return string(buf)
+}
+
+// IsUUIDv7 checks the value is a UUID version 7.
+func IsUUIDv7(value string) bool {
+	return uuidV7Pattern.MatchString(value)
+}
+
+// IsULID checks the value is a ULID.
+func IsULID(value string) bool {
+	return ulidPattern.MatchString(value)
+}
+
+// putTimestamp writes the 48-bit big-endian Unix timestamp in milliseconds.
+func putTimestamp(dst []byte, t time.Time) {
+	ms := uint64(t.UnixMilli()) // #nosec G115 -- timestamps are positive
+	for i := 5; i >= 0; i-- {
+		dst[i] = byte(ms)
+		ms >>= 8
+	}
+}
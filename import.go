@@ -0,0 +1,369 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================= Imports ==============================
+// ====================================================================
+
+// File formats of imports.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ImportRowError struct to describe a rejected row of an import.
+// @Description Rejected row of an import, listed in the error report
+// @Row Row is the row number in the file, the header being row 1.
+// @Field Field is the column of the error, empty for errors of the whole row.
+// @Message Message is the error description.
+// @Tags Imports
+type ImportRowError struct {
+	Row     int    `json:"row" example:"42" doc:"Row number in the file, the header being row 1"`
+	Field   string `json:"field,omitempty" example:"email" doc:"Column of the error"`
+	Message string `json:"message" example:"email must be a valid email address" doc:"Error description"`
+}
+
+// ImportResult struct to describe the outcome of an import.
+// @Description Outcome of an import, the Result of its Operation
+// @Imported Imported is the number of imported rows.
+// @Failed Failed is the number of rejected rows, listed in the error report of the operation's result_url.
+// @DryRun DryRun tells the rows were only validated, Imported being the number of rows which would be imported.
+// @Tags Imports
+type ImportResult struct {
+	Imported int64 `json:"imported" example:"4980" doc:"Number of imported rows"`
+	Failed   int64 `json:"failed" example:"20" doc:"Number of rejected rows"`
+	DryRun   bool  `json:"dry_run,omitempty" example:"false" doc:"Rows were validated, nothing was imported"`
+}
+
+// ImportRowFunc stores an imported row. Its error rejects the row, reported with the error's message.
+type ImportRowFunc[T any] func(ctx context.Context, row T) error
+
+// StartImport imports a CSV or XLSX file received by ProcessUpload as an Operation (see StartOperation): the
+// response is 202 Accepted, rows are processed in the background. The header row names the columns, matched
+// to the JSON names of T's fields; each row is converted to a T, sanitized, validated and given to the handler.
+// Rejected rows are listed in a CSV error report put to the registered Storage, downloaded from the
+// operation's result_url. The Operation's Result is an ImportResult. Dry runs (`Prefer: dry-run`, `dry_run=1`)
+// validate the rows without handing them to the handler.
+//
+// Example Usage:
+//
+//	func (h ImportContactsApi) Validate(c *core.Ctx) error {
+//		return http.ProcessUpload(c, "file")
+//	}
+//
+//	func (h ImportContactsApi) Handle(c *core.Ctx) error {
+//		return http.StartImport(c, http.Uploads(c)[0], func(ctx context.Context, row dto.Contact) error {
+//			return contactService.Create(ctx, row)
+//		})
+//	}
+func StartImport[T any](c *core.Ctx, file core.UploadedFile, handler ImportRowFunc[T]) error {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Name)), ".")
+	if format != FormatCSV && format != FormatXLSX {
+		removeUploads([]core.UploadedFile{file})
+
+		return c.Error(&Error{
+			Code:    "UNSUPPORTED_FILE_TYPE",
+			Message: "File type is not allowed",
+			Data:    core.Data{file.Field: []string{"must be a CSV or XLSX file"}},
+		}, FailureStatus(c, FailureBusinessRule))
+	}
+
+	dryRun := requestsDryRun(c)
+	if dryRun {
+		confirmDryRun(c)
+		handler = func(context.Context, T) error { return nil }
+	}
+
+	return StartOperation(c, "import", func(ctx context.Context, progress *OperationProgress) (any, error) {
+		defer removeUploads([]core.UploadedFile{file})
+
+		result, err := importRows(ctx, progress, file.Path, format, handler)
+		result.DryRun = dryRun
+
+		return result, err
+	})
+}
+
+// importRows imports the rows of a file and puts the error report of rejected rows to the storage.
+func importRows[T any](ctx context.Context, progress *OperationProgress, filePath, format string,
+	handler ImportRowFunc[T]) (ImportResult, error) {
+	var result ImportResult
+
+	rows, err := openImportRows(filePath, format)
+	if err != nil {
+		return result, &OperationError{Message: "Unable to read the file", Err: err}
+	}
+	defer rows.Close()
+	progress.SetTotal(rows.Count())
+
+	header, err := rows.Next()
+	if err != nil {
+		return result, &OperationError{Message: "File has no header row", Err: err}
+	}
+	columns := importColumns[T](header)
+
+	var rowErrors []ImportRowError
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		record, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, &OperationError{Message: fmt.Sprintf("Unable to read row %d", line), Err: err}
+		}
+		progress.Add(1)
+		if isBlankRecord(record) {
+			continue
+		}
+
+		if errs := importRow(ctx, line, header, columns, record, handler); len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			result.Failed++
+		} else {
+			result.Imported++
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		reportURL, err := storeImportReport(ctx, progress.ID(), rowErrors)
+		if err != nil {
+			return result, &OperationError{Message: "Unable to store the error report", Err: err}
+		}
+		progress.SetResultURL(reportURL)
+	}
+
+	return result, nil
+}
+
+// importRow converts, validates and hands a row to the handler, returning its errors.
+func importRow[T any](ctx context.Context, line int, header []string, columns []int, record []string,
+	handler ImportRowFunc[T]) []ImportRowError {
+	var row T
+	value := reflect.ValueOf(&row).Elem()
+
+	var rowErrors []ImportRowError
+	for i, raw := range record {
+		raw = strings.TrimSpace(raw)
+		if i >= len(columns) || columns[i] < 0 || raw == "" {
+			continue
+		}
+
+		if err := setStringValue(value.Field(columns[i]), raw, false); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: line, Field: header[i], Message: header[i] + " " + err.Error()})
+		}
+	}
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	SanitizeStruct(&row)
+	if errData := Validate(row); errData != nil {
+		fields := make([]string, 0, len(errData.Data))
+		for field := range errData.Data {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			messages, _ := errData.Data[field].([]string)
+			for _, message := range messages {
+				rowErrors = append(rowErrors, ImportRowError{Row: line, Field: field, Message: message})
+			}
+		}
+
+		return rowErrors
+	}
+
+	if err := handler(ctx, row); err != nil {
+		return []ImportRowError{{Row: line, Message: err.Error()}}
+	}
+
+	return nil
+}
+
+// importColumns maps the columns of the header to the index of T's fields by JSON name (case-insensitive),
+// -1 for columns without field.
+func importColumns[T any](header []string) []int {
+	typ := reflect.TypeFor[T]()
+	columns := make([]int, len(header))
+	for i, name := range header {
+		columns[i] = -1
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+
+		for j := 0; j < typ.NumField(); j++ {
+			field := typ.Field(j)
+			if !field.IsExported() {
+				continue
+			}
+
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+
+			if strings.EqualFold(jsonName, name) {
+				columns[i] = j
+
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+// isBlankRecord checks all cells of a record are empty.
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// storeImportReport puts the CSV report of rejected rows to the storage and returns its download URL.
+func storeImportReport(ctx context.Context, operationID string, rowErrors []ImportRowError) (string, error) {
+	storage := GetStorage()
+	if storage == nil {
+		return "", errors.New("storage is not registered")
+	}
+
+	var report bytes.Buffer
+	writer := csv.NewWriter(&report)
+	_ = writer.Write([]string{"row", "field", "message"})
+	for _, rowError := range rowErrors {
+		_ = writer.Write([]string{strconv.Itoa(rowError.Row), csvCell(rowError.Field), csvCell(rowError.Message)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+
+	key := "imports/" + operationID + "-errors.csv"
+	if err := storage.Put(ctx, key, &report, int64(report.Len()), "text/csv"); err != nil {
+		return "", err
+	}
+
+	return storage.SignedURL(ctx, key, CurrentConfig().OperationRetention)
+}
+
+// csvCell neutralizes a value read as a formula by spreadsheets (CSV injection).
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// importRowReader reader of the rows of an imported file.
+type importRowReader interface {
+	// Next returns the next row, io.EOF after the last one.
+	Next() ([]string, error)
+
+	// Count returns the number of data rows, the header excluded.
+	Count() int64
+
+	// Close releases the file.
+	Close() error
+}
+
+// openImportRows opens the rows of a CSV or XLSX file.
+func openImportRows(filePath, format string) (importRowReader, error) {
+	if format == FormatXLSX {
+		return openXLSXSheet(filePath)
+	}
+
+	// Rows are counted first, so the progress has a total
+	count, err := countCSVRecords(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvRowReader{file: file, reader: newCSVReader(file), count: count - 1}, nil
+}
+
+// newCSVReader creates a lenient CSV reader: rows may have different lengths.
+func newCSVReader(reader io.Reader) *csv.Reader {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	return csvReader
+}
+
+// countCSVRecords returns the number of records of a CSV file.
+func countCSVRecords(filePath string) (int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := newCSVReader(file)
+	reader.ReuseRecord = true
+
+	var count int64
+	for {
+		if _, err := reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			return 0, err
+		}
+		count++
+	}
+}
+
+// csvRowReader importRowReader of a CSV file.
+type csvRowReader struct {
+	file   *os.File
+	reader *csv.Reader
+	count  int64
+}
+
+// Next returns the next row.
+func (r *csvRowReader) Next() ([]string, error) {
+	return r.reader.Read()
+}
+
+// Count returns the number of data rows.
+func (r *csvRowReader) Count() int64 {
+	return max(r.count, 0)
+}
+
+// Close closes the file.
+func (r *csvRowReader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,836 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================ JSON Patch ============================
+// ====================================================================
+
+// MIMEApplicationJSONPatch media type of JSON Patch (RFC 6902) bodies.
+const MIMEApplicationJSONPatch = "application/json-patch+json"
+
+// ErrorCodePatchTooLarge code of the Error returned for JSON Patch documents over the operation or size limits.
+const ErrorCodePatchTooLarge = "PATCH_TOO_LARGE"
+
+var (
+	// PatchMaxOperations maximum number of operations of a JSON Patch document.
+	PatchMaxOperations = 100
+	// PatchMaxNodes maximum number of values (objects, arrays and scalars) of a patched document, checked after
+	// each operation so copies can not grow it exponentially. Resources already larger can not grow.
+	PatchMaxNodes = 10000
+)
+
+// JSONPatchOperation struct to describe an operation of a JSON Patch document.
+// @Description Operation of a JSON Patch (RFC 6902) document
+// @Op Op is the operation: add, remove, replace, move, copy or test.
+// @Path Path is the JSON pointer of the target location.
+// @From From is the JSON pointer of the source location of move and copy.
+// @Value Value is the value of add, replace and test.
+// @Tags JSON Patch
+type JSONPatchOperation struct {
+	Op    string          `json:"op" example:"replace" doc:"Operation: add, remove, replace, move, copy or test"`
+	Path  string          `json:"path" example:"/address/city" doc:"JSON pointer of the target location"`
+	From  string          `json:"from,omitempty" example:"/billing_address/city" doc:"JSON pointer of the source location"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object" doc:"Value of add, replace and test"`
+}
+
+// ProcessJSONPatch validates and processes PATCH requests with a JSON Patch (RFC 6902) body: the operations are
+// applied in order to the current resource returned by loadFn, then the patched DTO is sanitized, validated as
+// a whole and stored in Ctx's Data like ProcessPatchData does. The paths (and the sources of move and copy) must
+// be one of allowedPaths or below one, `*` matching any single segment; without allowedPaths every path of the
+// DTO may be patched. Locations and values are checked against the DTO the way Parse checks bodies: legacy names
+// of `json_alias` tags are renamed, times of `timeFormat` fields are converted, locations which are not fields of
+// the DTO are rejected with UNKNOWN_FIELDS, as are unknown members of values with DisallowUnknownFields, and
+// numbers must fit their field with StrictNumbers (see ParseOptions).
+// Invalid documents are answered with 400 Bad Request, operations that can not be applied
+// to the current resource (missing location, failed test) with 409 Conflict, and nothing is stored when any
+// operation fails. The field mask (see GetFieldMask) lists the top-level fields touched by the operations.
+// Documents of more than PatchMaxOperations operations, or growing the resource beyond PatchMaxNodes values, are
+// answered with 413 PATCH_TOO_LARGE.
+//
+// Example Usage:
+//
+//	func (h PatchUserApi) Validate(c *core.Ctx) error {
+//		return http.ProcessJSONPatch[*dto.UpdateUser](c, loadUpdateUser, "/nickname", "/address", "/tags")
+//	}
+//
+//	// PATCH /users/42
+//	// [{"op":"test","path":"/address/city","value":"Hue"},
+//	//  {"op":"replace","path":"/address/city","value":"Hanoi"},
+//	//  {"op":"add","path":"/tags/-","value":"vip"}]
+func ProcessJSONPatch[T UpdateData](c *core.Ctx, loadFn PatchLoadFunc[T], allowedPaths ...string) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	// Reject dry runs the handler would commit, see AllowDryRun
+	if err := checkDryRun(c); err != nil {
+		return err
+	}
+
+	// Receive path parameter ID
+	itemID, errData := PathID(c)
+	if errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Check the caller may touch the row
+	if err := CheckOwnership(c, itemID); err != nil {
+		return err
+	}
+
+	// Receive and check the patch document
+	var operations []JSONPatchOperation
+	if errData := Parse(c, &operations); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+	config := RequestConfig(c)
+	if len(operations) > config.PatchMaxOperations {
+		return c.Error(&Error{
+			Code:    ErrorCodePatchTooLarge,
+			Message: fmt.Sprintf("JSON Patch must not have more than %d operations", config.PatchMaxOperations),
+		}, core.StatusRequestEntityTooLarge)
+	}
+
+	// Check the operations against the fields of the DTO, as Parse checks bodies
+	if errData := resolveJSONPatch(c, reflect.TypeFor[T](), operations, config.Parse); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+	if errData := checkJSONPatch(operations, allowedPaths); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Skip the operations touching fields of disabled features
+	operations = dropFeatureOperations(c, reflect.TypeFor[T](), operations)
+
+	// Load the current resource
+	current, err := loadFn(c, itemID)
+	if err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to load the resource",
+		}, core.StatusInternalServerError, "Patch load error: %v", err)
+	}
+	if value := reflect.ValueOf(current); !value.IsValid() || value.Kind() == reflect.Pointer && value.IsNil() {
+		return c.Error(&Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	// Apply the operations to the current resource
+	requestData, errData, err := patchInto(current, operations, config.PatchMaxNodes)
+	if errData != nil && errData.Code == ErrorCodePatchTooLarge {
+		return c.Error(errData, core.StatusRequestEntityTooLarge)
+	}
+	if errData != nil {
+		return c.Error(errData, core.StatusConflict)
+	}
+	if err != nil {
+		return c.Error(&Error{
+			Message: err.Error(),
+		}, FailureStatus(c, FailureMalformed))
+	}
+
+	// Sanitize request data
+	sanitizeRequest(c, &requestData)
+
+	// Set ID on the request body
+	requestData.SetID(itemID)
+
+	// Record touched and cleared fields
+	c.SetData(FieldMaskKey, buildFieldMask(patchedFields(operations), requestData))
+
+	// Validate DTO
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Transform validated data (e.g. hash passwords)
+	if err := TransformStruct(&requestData); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process request data",
+		}, core.StatusInternalServerError, "Transform request data error: %v", err)
+	}
+
+	// Store data into context
+	c.SetData(RequestKey, requestData)
+
+	// Record the DTO's event, emitted once the mutation succeeds
+	recordDataEvent(c, requestData)
+
+	return nil
+}
+
+// checkJSONPatch checks the members and the paths of the operations, with errors keyed by operation.
+func checkJSONPatch(operations []JSONPatchOperation, allowedPaths []string) *Error {
+	if len(operations) == 0 {
+		return &Error{
+			Message: "JSON Patch must be a non-empty array of operations",
+		}
+	}
+
+	errorData := core.Data{}
+	for i, operation := range operations {
+		var messages []string
+
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				messages = append(messages, "value is required")
+			}
+		case "move", "copy":
+			messages = append(messages, checkPatchPath("from", operation.From, allowedPaths)...)
+		case "remove":
+		default:
+			messages = append(messages, "op must be one of add remove replace move copy test")
+		}
+		messages = append(messages, checkPatchPath("path", operation.Path, allowedPaths)...)
+
+		if (operation.Op == "move" || operation.Op == "copy") && strings.HasPrefix(operation.Path, operation.From+"/") {
+			messages = append(messages, "path can not be a child of from")
+		}
+
+		if len(messages) > 0 {
+			errorData[fmt.Sprintf("operations[%d]", i)] = messages
+		}
+	}
+
+	if len(errorData) > 0 {
+		return &Error{
+			Message: "Invalid JSON Patch",
+			Data:    errorData,
+		}
+	}
+
+	return nil
+}
+
+// checkPatchPath checks a pointer member of an operation is valid and allowed.
+func checkPatchPath(member, pointer string, allowedPaths []string) []string {
+	if _, err := parsePointer(pointer); err != nil {
+		return []string{member + " " + err.Error()}
+	}
+
+	if len(allowedPaths) == 0 {
+		return nil
+	}
+	for _, allowed := range allowedPaths {
+		if matchPatchPath(allowed, pointer) {
+			return nil
+		}
+	}
+
+	return []string{member + " " + pointer + " is not allowed"}
+}
+
+// matchPatchPath checks a pointer is the allowed path or below it, `*` segments matching any segment.
+func matchPatchPath(allowed, pointer string) bool {
+	if allowed == "" {
+		return true
+	}
+	if pointer == "" {
+		return false
+	}
+
+	allowedTokens := strings.Split(allowed, "/")
+	tokens := strings.Split(pointer, "/")
+	if len(tokens) < len(allowedTokens) {
+		return false
+	}
+	for i, token := range allowedTokens {
+		if token != "*" && token != tokens[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped reference tokens, none for the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errors.New("must be a JSON pointer")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// pointerUnescaper unescapes reference tokens, `~1` before `~0` so `~01` is read as `~1`.
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// resolveJSONPatch checks the locations and values of the operations against the DTO type with the ParseOptions,
+// with errors keyed by operation. Locations are rewritten to the JSON names of the fields before checkJSONPatch
+// matches them with the allowed paths, and times of `timeFormat` fields in values to RFC 3339.
+func resolveJSONPatch(c *core.Ctx, typ reflect.Type, operations []JSONPatchOperation, options ParseOptions) *Error {
+	errorData := core.Data{}
+	unknown := false
+	for i := range operations {
+		operation := &operations[i]
+
+		var messages []string
+		path, target, field, message := resolvePointer(c, typ, operation.Path)
+		if message != "" {
+			messages = append(messages, "path "+message)
+		}
+		operation.Path = path
+		if operation.Op == "move" || operation.Op == "copy" {
+			from, _, _, message := resolvePointer(c, typ, operation.From)
+			if message != "" {
+				messages = append(messages, "from "+message)
+			}
+			operation.From = from
+		}
+		unknown = unknown || len(messages) > 0
+
+		if len(messages) == 0 && target != nil && operation.Value != nil {
+			value, valueMessages, unknownMembers := resolvePatchValue(operation.Value, target, field, options)
+			operation.Value = value
+			messages = append(messages, valueMessages...)
+			unknown = unknown || unknownMembers
+		}
+
+		if len(messages) > 0 {
+			errorData[fmt.Sprintf("operations[%d]", i)] = messages
+		}
+	}
+
+	if len(errorData) == 0 {
+		return nil
+	}
+	if unknown {
+		return &Error{
+			Code:    ErrorCodeUnknownFields,
+			Message: "JSON Patch targets unknown fields",
+			Data:    errorData,
+		}
+	}
+
+	return &Error{
+		Message: "Invalid JSON Patch",
+		Data:    errorData,
+	}
+}
+
+// resolvePointer walks a JSON pointer through the fields of typ, renaming legacy names of `json_alias` tags and
+// case variants to the JSON names. It returns the rewritten pointer, the type of the location (nil below values
+// decoded by their own UnmarshalJSON or of interface type) with its struct field, or why it is not a field.
+func resolvePointer(c *core.Ctx, typ reflect.Type,
+	pointer string) (string, reflect.Type, *reflect.StructField, string) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		// Reported by checkJSONPatch
+		return pointer, nil, nil, ""
+	}
+
+	var field *reflect.StructField
+	for i, token := range tokens {
+		typ = derefType(typ)
+		if reflect.PointerTo(typ).Implements(jsonUnmarshalerType) || typ.Kind() == reflect.Interface {
+			return escapePointer(tokens), nil, nil, ""
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			if name, ok := fieldAliases(typ)[token]; ok {
+				recordAliasUse(c, typ, token)
+				token = name
+			}
+			structField, ok := jsonField(typ, token)
+			if !ok {
+				return pointer, nil, nil, pointer + " is not a known field"
+			}
+			tokens[i], _, _ = strings.Cut(structField.Tag.Get("json"), ",")
+			if tokens[i] == "" {
+				tokens[i] = structField.Name
+			}
+			typ, field = structField.Type, &structField
+		case reflect.Slice, reflect.Array, reflect.Map:
+			typ, field = typ.Elem(), nil
+		default:
+			return pointer, nil, nil, pointer + " is not a known field"
+		}
+	}
+
+	return escapePointer(tokens), typ, field, ""
+}
+
+// escapePointer joins reference tokens into a JSON pointer (RFC 6901).
+func escapePointer(tokens []string) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteString("/")
+		pointer.WriteString(pointerEscaper.Replace(token))
+	}
+
+	return pointer.String()
+}
+
+// pointerEscaper escapes reference tokens, `~` before `/`.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// resolvePatchValue checks the value of an operation against the type of its location, the struct field of the
+// location when it is one, and returns the value with the times of `timeFormat` fields as RFC 3339, the error
+// messages and whether they are about unknown members.
+func resolvePatchValue(value json.RawMessage, typ reflect.Type, field *reflect.StructField,
+	options ParseOptions) (json.RawMessage, []string, bool) {
+	if options.DisallowUnknownFields {
+		if errData := checkUnknownFields(value, typ); errData != nil {
+			return value, valueMessages(errData.Data), true
+		}
+	}
+
+	if field != nil && field.Tag.Get("timeFormat") != "" && derefType(field.Type) == timeType {
+		var raw string
+		if err := json.Unmarshal(value, &raw); err == nil {
+			layout, location := fieldTimeFormat(*field, "")
+			parsed, err := time.ParseInLocation(layout, raw, location)
+			if err != nil {
+				return value, []string{"value must be date time formatted as " + layout}, false
+			}
+			value, _ = json.Marshal(parsed.Format(time.RFC3339Nano))
+		}
+	} else {
+		converted, errData := convertTimeFormats(value, typ)
+		if errData != nil {
+			return value, valueMessages(errData.Data), false
+		}
+		if converted != nil {
+			value = converted
+		}
+	}
+
+	if options.StrictNumbers {
+		if errorData := checkNumbers(value, typ); len(errorData) > 0 {
+			return value, valueMessages(errorData), false
+		}
+	}
+
+	return value, nil, false
+}
+
+// valueMessages returns the messages of error data keyed by paths inside an operation value, sorted.
+func valueMessages(errorData core.Data) []string {
+	var messages []string
+	for path, fieldMessages := range errorData {
+		prefix := "value"
+		if path != "" {
+			prefix += "." + path
+		}
+		list, _ := fieldMessages.([]string)
+		for _, message := range list {
+			messages = append(messages, prefix+" "+message)
+		}
+	}
+	sort.Strings(messages)
+
+	return messages
+}
+
+// patchInto applies the operations to the JSON of the current DTO and decodes the result into a new DTO.
+// Operations that can not be applied, or growing the document beyond maxNodes values, are returned as an error
+// keyed by operation.
+func patchInto[T any](current T, operations []JSONPatchOperation, maxNodes int) (T, *Error, error) {
+	var patched T
+
+	encoded, err := codec.Marshal(current)
+	if err != nil {
+		return patched, nil, err
+	}
+	document, err := decodeDocument(encoded)
+	if err != nil {
+		return patched, nil, err
+	}
+
+	maxNodes = max(maxNodes, countNodes(document, maxNodes))
+	for i, operation := range operations {
+		document, err = applyPatchOperation(document, operation)
+		if err != nil {
+			return patched, &Error{
+				Code:    "PATCH_CONFLICT",
+				Message: "JSON Patch can not be applied",
+				Data:    core.Data{fmt.Sprintf("operations[%d]", i): []string{err.Error()}},
+			}, nil
+		}
+
+		if countNodes(document, maxNodes+1) > maxNodes {
+			return patched, &Error{
+				Code:    ErrorCodePatchTooLarge,
+				Message: fmt.Sprintf("JSON Patch must not grow the resource beyond %d values", maxNodes),
+				Data:    core.Data{fmt.Sprintf("operations[%d]", i): []string{"document is too large"}},
+			}, nil
+		}
+	}
+
+	encoded, err = json.Marshal(dropNulls(document))
+	if err != nil {
+		return patched, nil, err
+	}
+	err = codec.Unmarshal(encoded, &patched)
+
+	return patched, nil, err
+}
+
+// applyPatchOperation applies an operation to a decoded JSON document and returns the result.
+func applyPatchOperation(document any, operation JSONPatchOperation) (any, error) {
+	// Pointers were checked by checkJSONPatch
+	path, _ := parsePointer(operation.Path)
+	from, _ := parsePointer(operation.From)
+
+	var value any
+	if operation.Value != nil {
+		var err error
+		if value, err = decodeDocument(operation.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return addValue(document, path, value)
+	case "remove":
+		if len(path) == 0 {
+			return nil, errors.New("the whole document can not be removed")
+		}
+
+		return updateParent(document, path, func(parent any, token string) (any, error) {
+			return removeChild(parent, token)
+		})
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+
+		return updateParent(document, path, func(parent any, token string) (any, error) {
+			return replaceChild(parent, token, value)
+		})
+	case "move":
+		moved, err := valueAt(document, from)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) > 0 {
+			if document, err = updateParent(document, from, func(parent any, token string) (any, error) {
+				return removeChild(parent, token)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		return addValue(document, path, moved)
+	case "copy":
+		copied, err := valueAt(document, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return addValue(document, path, copyDocument(copied))
+	case "test":
+		actual, err := valueAt(document, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalDocuments(actual, value) {
+			return nil, fmt.Errorf("test failed, %s does not hold the value", operation.Path)
+		}
+
+		return document, nil
+	default:
+		return nil, fmt.Errorf("unknown op %s", operation.Op)
+	}
+}
+
+// addValue adds a value at a location: object members are set, array items are inserted.
+func addValue(document any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(document, path, func(parent any, token string) (any, error) {
+		switch typed := parent.(type) {
+		case map[string]any:
+			typed[token] = value
+
+			return typed, nil
+		case []any:
+			index, err := arrayIndex(token, len(typed), true)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(typed[:index], append([]any{value}, typed[index:]...)...), nil
+		default:
+			return nil, fmt.Errorf("%s has no parent object or array", token)
+		}
+	})
+}
+
+// removeChild removes an existing member or item of a container.
+func removeChild(parent any, token string) (any, error) {
+	switch typed := parent.(type) {
+	case map[string]any:
+		if _, ok := typed[token]; !ok {
+			return nil, fmt.Errorf("%s does not exist", token)
+		}
+		delete(typed, token)
+
+		return typed, nil
+	case []any:
+		index, err := arrayIndex(token, len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(typed[:index], typed[index+1:]...), nil
+	default:
+		return nil, fmt.Errorf("%s has no parent object or array", token)
+	}
+}
+
+// replaceChild replaces an existing member or item of a container.
+func replaceChild(parent any, token string, value any) (any, error) {
+	switch typed := parent.(type) {
+	case map[string]any:
+		if _, ok := typed[token]; !ok {
+			return nil, fmt.Errorf("%s does not exist", token)
+		}
+		typed[token] = value
+
+		return typed, nil
+	case []any:
+		index, err := arrayIndex(token, len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = value
+
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("%s has no parent object or array", token)
+	}
+}
+
+// updateParent replaces the parent container of the last token of path with the result of fn, rebuilding the
+// containers above it (arrays may be reallocated).
+func updateParent(node any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	child, err := childAt(node, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := updateParent(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typed := node.(type) {
+	case map[string]any:
+		typed[path[0]] = updated
+	case []any:
+		index, _ := arrayIndex(path[0], len(typed), false)
+		typed[index] = updated
+	default:
+	}
+
+	return node, nil
+}
+
+// valueAt returns the value at a location, which must exist.
+func valueAt(document any, path []string) (any, error) {
+	var err error
+	for _, token := range path {
+		if document, err = childAt(document, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return document, nil
+}
+
+// childAt returns an existing member or item of a container.
+func childAt(node any, token string) (any, error) {
+	switch typed := node.(type) {
+	case map[string]any:
+		child, ok := typed[token]
+		if !ok {
+			return nil, fmt.Errorf("%s does not exist", token)
+		}
+
+		return child, nil
+	case []any:
+		index, err := arrayIndex(token, len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+
+		return typed[index], nil
+	default:
+		return nil, fmt.Errorf("%s has no parent object or array", token)
+	}
+}
+
+// arrayIndex parses the array index of a token, `-` (the end of the array) being accepted for additions.
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if token == "-" && adding {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("%s is not an array index", token)
+	}
+	if index > length || index == length && !adding {
+		return 0, fmt.Errorf("index %d is out of bounds", index)
+	}
+
+	return index, nil
+}
+
+// countNodes counts the values of a decoded JSON document, up to limit.
+func countNodes(document any, limit int) int {
+	count := 1
+	switch typed := document.(type) {
+	case map[string]any:
+		for _, value := range typed {
+			if count >= limit {
+				break
+			}
+			count += countNodes(value, limit-count)
+		}
+	case []any:
+		for _, value := range typed {
+			if count >= limit {
+				break
+			}
+			count += countNodes(value, limit-count)
+		}
+	default:
+	}
+
+	return count
+}
+
+// copyDocument deep copies a decoded JSON value, so copied objects and arrays are not shared.
+func copyDocument(document any) any {
+	switch typed := document.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(typed))
+		for name, value := range typed {
+			copied[name] = copyDocument(value)
+		}
+
+		return copied
+	case []any:
+		copied := make([]any, len(typed))
+		for i, value := range typed {
+			copied[i] = copyDocument(value)
+		}
+
+		return copied
+	default:
+		return document
+	}
+}
+
+// equalDocuments compares decoded JSON values, numbers by value (1 equals 1.0).
+func equalDocuments(a, b any) bool {
+	switch typedA := a.(type) {
+	case map[string]any:
+		typedB, ok := b.(map[string]any)
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for name, value := range typedA {
+			other, ok := typedB[name]
+			if !ok || !equalDocuments(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		typedB, ok := b.([]any)
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for i := range typedA {
+			if !equalDocuments(typedA[i], typedB[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number:
+		typedB, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		numberA, okA := new(big.Float).SetString(typedA.String())
+		numberB, okB := new(big.Float).SetString(typedB.String())
+
+		return okA && okB && numberA.Cmp(numberB) == 0
+	default:
+		return a == b
+	}
+}
+
+// patchedFields returns a JSON object of the top-level fields touched by the operations for buildFieldMask,
+// fields removed or set to null as a whole being null.
+func patchedFields(operations []JSONPatchOperation) []byte {
+	fields := map[string]json.RawMessage{}
+	touch := func(pointer string, value json.RawMessage) {
+		tokens, _ := parsePointer(pointer)
+		if len(tokens) == 0 {
+			// The whole document is replaced, its members are touched
+			_ = json.Unmarshal(value, &fields)
+
+			return
+		}
+		if len(tokens) > 1 || value == nil {
+			value = json.RawMessage("{}")
+		}
+		fields[tokens[0]] = value
+	}
+
+	for _, operation := range operations {
+		switch operation.Op {
+		case "add", "replace":
+			touch(operation.Path, operation.Value)
+		case "remove":
+			touch(operation.Path, json.RawMessage("null"))
+		case "move":
+			touch(operation.From, json.RawMessage("null"))
+			touch(operation.Path, nil)
+		case "copy":
+			touch(operation.Path, nil)
+		default:
+		}
+	}
+
+	encoded, _ := json.Marshal(fields)
+
+	return encoded
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Request Linting =========================
+// ====================================================================
+
+// LintEnabled enables LintApi, disabled by default: it is a debug endpoint listing the request DTOs.
+var LintEnabled = false
+
+// LintRequest struct to describe a payload to lint.
+// @Description Payload checked against a request DTO, without calling any endpoint
+// @DTO DTO is the name of the request DTO, e.g. "CreateUser" or "dto.CreateUser".
+// @Payload Payload is the JSON body to check.
+// @Tags Debug
+type LintRequest struct {
+	DTO     string          `json:"dto" example:"CreateUser" doc:"Name of the request DTO" validate:"required"`
+	Payload json.RawMessage `json:"payload" example:"{\"email\":\"john\"}" doc:"JSON body to check" validate:"required"`
+}
+
+// LintReport struct to describe the outcome of a lint.
+// @Description Outcome of a payload lint: the error the endpoint would respond, and the sanitized payload
+// @DTO DTO is the full name of the request DTO.
+// @Valid Valid is set when the payload passes decoding and validation.
+// @Error Error is the error response the endpoint would send (optional).
+// @Sanitized Sanitized is the decoded and sanitized payload, as validated (optional).
+// @Tags Debug
+type LintReport struct {
+	DTO       string `json:"dto" example:"dto.CreateUser" doc:"Full name of the request DTO"`
+	Valid     bool   `json:"valid" example:"false" doc:"Payload passes decoding and validation"`
+	Error     *Error `json:"error,omitempty" doc:"Error response the endpoint would send"`
+	Sanitized any    `json:"sanitized,omitempty" doc:"Decoded and sanitized payload, as validated"`
+}
+
+// Lint runs the body pipeline of a request DTO on a payload, as the body of the request: the decoding steps of
+// Parse (legacy field aliases, disabled features, unknown fields and strict numbers checks when enabled by the
+// request's ParseOptions, `timeFormat` layouts), then sanitization, defaults and validation. The request body is
+// restored afterwards. Nothing is recorded: no alias usage, validation stats, abuse report nor event. Fields read
+// from the query, headers or path (see BindSources) are not set.
+//
+// Example Usage:
+//
+//	report := http.Lint(c, reflect.TypeFor[dto.CreateUser](), []byte(`{"email":"john"}`))
+func Lint(c *core.Ctx, typ reflect.Type, payload []byte) LintReport {
+	typ = derefType(typ)
+	report := LintReport{DTO: typ.String()}
+
+	request := &c.Root().Request
+	body, contentType := bytes.Clone(request.Body()), bytes.Clone(request.Header.ContentType())
+	request.SetBody(payload)
+	request.Header.SetContentType(core.MIMEApplicationJSON)
+	c.SetData(LintKey, true)
+	defer func() {
+		request.SetBody(body)
+		request.Header.SetContentTypeBytes(contentType)
+		c.SetData(LintKey, false)
+	}()
+
+	target := reflect.New(typ)
+	if report.Error = decodeBody(c, typ, target.Interface(), RequestConfig(c).Parse); report.Error != nil {
+		return report
+	}
+
+	SanitizeStruct(target.Interface())
+	ApplyDefaults(target.Interface())
+	report.Sanitized = target.Interface()
+
+	errorData, _, ok := validateStruct(nil, target.Elem().Interface(), MsgForTag)
+	switch {
+	case !ok:
+		report.Error = &Error{
+			Message: "Invalid input",
+		}
+	case len(errorData) > 0:
+		report.Error = &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	default:
+		report.Valid = true
+	}
+
+	return report
+}
+
+// lintTarget returns the request DTO named by its type name or full name, among the request types of the
+// described endpoints (see Describe) and the DTOs with examples (see RegisterExample).
+func lintTarget(name string) (reflect.Type, bool) {
+	for _, typ := range lintTargets() {
+		if typ.Name() == name || typ.String() == name {
+			return typ, true
+		}
+	}
+
+	return nil, false
+}
+
+// lintTargets returns the struct types which can be linted, sorted by full name.
+func lintTargets() []reflect.Type {
+	seen := map[reflect.Type]bool{}
+	add := func(typ reflect.Type) {
+		for typ != nil && typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ != nil && typ.Kind() == reflect.Struct && typ.Name() != "" {
+			seen[typ] = true
+		}
+	}
+
+	for _, endpoint := range DescribedEndpoints() {
+		add(endpoint.Request)
+	}
+	examplesMu.RLock()
+	for typ := range examples {
+		add(typ)
+	}
+	examplesMu.RUnlock()
+
+	targets := make([]reflect.Type, 0, len(seen))
+	for typ := range seen {
+		targets = append(targets, typ)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].String() < targets[j].String()
+	})
+
+	return targets
+}
+
+// LintApi debug handler linting payloads against request DTOs (see Lint), so client developers debug their
+// payloads without calling real endpoints. It answers 404 unless LintEnabled.
+type LintApi struct {
+	core.Endpoint
+}
+
+// NewLintApi creates the lint debug handler.
+//
+// Example Usage:
+//
+//	apiRouter.POST("/_lint", http.NewLintApi())
+func NewLintApi() *LintApi {
+	return &LintApi{}
+}
+
+// Validate parses the lint request and resolves its DTO; unknown DTOs are listed with the known ones.
+func (h *LintApi) Validate(c *core.Ctx) error {
+	if !CurrentConfig().LintEnabled {
+		return WriteError(c, &Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	var requestData LintRequest
+	if errData := Parse(c, &requestData); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	if _, ok := lintTarget(requestData.DTO); !ok {
+		targets := lintTargets()
+		known := make([]string, len(targets))
+		for i, typ := range targets {
+			known[i] = typ.String()
+		}
+
+		return c.Error(&Error{
+			Message: "Invalid input",
+			Data: core.Data{
+				"dto":   []string{"is not a known request DTO"},
+				"known": known,
+			},
+		}, FailureStatus(c, FailureValidation))
+	}
+
+	c.SetData(RequestKey, requestData)
+
+	return nil
+}
+
+// Handle responds with the lint report, 200 OK whether the payload is valid or not.
+func (h *LintApi) Handle(c *core.Ctx) error {
+	requestData := c.GetData(RequestKey).(LintRequest)
+	typ, _ := lintTarget(requestData.DTO)
+
+	return c.Success(Lint(c, typ, requestData.Payload))
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"math"
+	"sync"
+
+	"github.com/gflydev/core"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// ====================================================================
+// ========================= Locale Formatting ========================
+// ====================================================================
+
+// HeaderAcceptLanguage request header listing the client's preferred languages.
+const HeaderAcceptLanguage = "Accept-Language"
+
+var (
+	// supportedLocales locales the application renders, the first one is the default.
+	supportedLocales = []language.Tag{language.English}
+	localeMatcher    = language.NewMatcher(supportedLocales)
+
+	dateLayoutsMu sync.RWMutex
+	// dateLayouts Go time layouts of FormatDate by locale, falling back to the base language then DateLayout.
+	dateLayouts = map[string]string{
+		"en":    "Jan 2, 2006",
+		"en-GB": "2 Jan 2006",
+		"de":    "02.01.2006",
+		"fr":    "02/01/2006",
+		"es":    "02/01/2006",
+		"it":    "02/01/2006",
+		"pt":    "02/01/2006",
+		"nl":    "02-01-2006",
+		"ru":    "02.01.2006",
+		"vi":    "02/01/2006",
+		"ja":    "2006/01/02",
+		"zh":    "2006/01/02",
+		"ko":    "2006. 01. 02.",
+	}
+)
+
+// RegisterLocales registers the locales the application renders, the first one being the default.
+// Locale resolves the request's Accept-Language header against them.
+//
+// Example Usage:
+//
+//	http.RegisterLocales(language.English, language.German, language.Vietnamese)
+func RegisterLocales(locales ...language.Tag) {
+	if len(locales) == 0 {
+		return
+	}
+
+	supportedLocales = locales
+	localeMatcher = language.NewMatcher(locales)
+}
+
+// RegisterDateLayout registers the Go time layout of FormatDate for a locale (e.g. "en-US" or "de").
+//
+// Example Usage:
+//
+//	http.RegisterDateLayout("en-US", "01/02/2006")
+func RegisterDateLayout(locale, layout string) {
+	dateLayoutsMu.Lock()
+	defer dateLayoutsMu.Unlock()
+
+	dateLayouts[locale] = layout
+}
+
+// Locale returns the locale of the request: the one set by SetLocale (e.g. from the user's profile), otherwise
+// the best match of the Accept-Language header among the registered locales. It is resolved once per request.
+func Locale(c *core.Ctx) language.Tag {
+	if locale, ok := c.GetData(LocaleKey).(language.Tag); ok {
+		return locale
+	}
+
+	tags, _, _ := language.ParseAcceptLanguage(c.GetHeader(HeaderAcceptLanguage))
+	_, index, _ := localeMatcher.Match(tags...)
+	locale := supportedLocales[index]
+	c.SetData(LocaleKey, locale)
+
+	return locale
+}
+
+// SetLocale sets the locale of the request, overriding the Accept-Language header.
+func SetLocale(c *core.Ctx, locale language.Tag) {
+	c.SetData(LocaleKey, locale)
+}
+
+// FormatNumber formats a number with the grouping and decimal separators of the request's locale.
+//
+// Example Usage:
+//
+//	http.FormatNumber(c, 1234567.891, 2) // "1,234,567.89" (en), "1.234.567,89" (de)
+func FormatNumber(c *core.Ctx, value float64, decimals int) string {
+	return message.NewPrinter(Locale(c)).Sprint(number.Decimal(value, number.Scale(decimals)))
+}
+
+// FormatMoney formats an amount with its currency symbol and the separators of the request's locale.
+// It is meant for display-ready strings; APIs should return Money itself.
+//
+// Example Usage:
+//
+//	http.FormatMoney(c, http.NewMoney(123450, "EUR")) // "€ 1,234.50" (en), "€ 1.234,50" (de)
+func FormatMoney(c *core.Ctx, m Money) string {
+	exponent := CurrencyExponent(m.Currency)
+	value := float64(m.Amount) / math.Pow10(exponent)
+	printer := message.NewPrinter(Locale(c))
+
+	unit, err := currency.ParseISO(m.Currency)
+	if err != nil {
+		// Unknown currency, keep its code
+		return m.Currency + " " + printer.Sprint(number.Decimal(value, number.Scale(exponent)))
+	}
+
+	return printer.Sprint(currency.Symbol(unit.Amount(value)))
+}
+
+// FormatDate formats a date with the layout of the request's locale (see RegisterDateLayout),
+// DateLayout when the locale has none.
+//
+// Example Usage:
+//
+//	http.FormatDate(c, order.DeliveryDate) // "Jan 31, 2024" (en), "31.01.2024" (de)
+func FormatDate(c *core.Ctx, d Date) string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.In(nil).Format(dateLayout(Locale(c)))
+}
+
+// dateLayout returns the date layout of the locale, trying the full tag, then the language and region,
+// then the base language.
+func dateLayout(locale language.Tag) string {
+	dateLayoutsMu.RLock()
+	defer dateLayoutsMu.RUnlock()
+
+	if layout, ok := dateLayouts[locale.String()]; ok {
+		return layout
+	}
+
+	base, _ := locale.Base()
+	if region, confidence := locale.Region(); confidence == language.Exact {
+		if layout, ok := dateLayouts[base.String()+"-"+region.String()]; ok {
+			return layout
+		}
+	}
+	if layout, ok := dateLayouts[base.String()]; ok {
+		return layout
+	}
+
+	return DateLayout
+}
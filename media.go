@@ -0,0 +1,298 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ======================== Media Negotiation =========================
+// ====================================================================
+
+// Fit modes of thumbnails.
+const (
+	FitContain = "contain" // Fits inside the box, keeping the aspect ratio (default)
+	FitCover   = "cover"   // Covers the box, keeping the aspect ratio, cropping the overflow
+	FitFill    = "fill"    // Stretches to the box
+)
+
+// MaxThumbnailSize maximum width and height of thumbnails requested with `w` and `h`.
+var MaxThumbnailSize = 2048
+
+// ResizeOptions thumbnail requested by a client.
+type ResizeOptions struct {
+	Width  int    // Box width in pixels, 0 to follow the height
+	Height int    // Box height in pixels, 0 to follow the width
+	Fit    string // FitContain, FitCover or FitFill
+}
+
+// ImageProcessor is an interface for image libraries producing thumbnails (imaging, bimg/libvips, ...).
+type ImageProcessor interface {
+	// Resize returns the thumbnail of the image read from src, and its content type.
+	Resize(src io.Reader, contentType string, options ResizeOptions) (thumbnail []byte, thumbnailType string, err error)
+}
+
+// imageProcessor processor of thumbnails, thumbnails are unavailable when nil.
+var imageProcessor ImageProcessor
+
+// RegisterImageProcessor registers the processor producing thumbnails.
+//
+// Example Usage:
+//
+//	http.RegisterImageProcessor(imaging.NewProcessor())
+func RegisterImageProcessor(processor ImageProcessor) {
+	imageProcessor = processor
+}
+
+// MediaFile file served by WriteMedia.
+type MediaFile struct {
+	Name        string                        // File name, e.g. "avatar.png"
+	ContentType string                        // MIME type, e.g. "image/png"
+	Size        int64                         // Size in bytes
+	ModifiedAt  time.Time                     // Last modification time
+	Width       int                           // Image width in pixels, when known
+	Height      int                           // Image height in pixels, when known
+	Open        func() (io.ReadCloser, error) // Opens the content of the file
+}
+
+// MediaInfo struct to describe the metadata of a media file.
+// @Description Metadata of a media file
+// @Name Name is the file name.
+// @ContentType ContentType is the MIME type of the file.
+// @Size Size is the size in bytes.
+// @ModifiedAt ModifiedAt is the last modification time.
+// @Width Width is the image width in pixels (optional).
+// @Height Height is the image height in pixels (optional).
+// @Tags Info Responses
+type MediaInfo struct {
+	Name        string    `json:"name" example:"avatar.png" doc:"File name"`
+	ContentType string    `json:"content_type" example:"image/png" doc:"MIME type of the file"`
+	Size        int64     `json:"size" example:"48213" doc:"Size in bytes"`
+	ModifiedAt  time.Time `json:"modified_at" example:"2024-01-31T10:00:00Z" doc:"Last modification time"`
+	Width       int       `json:"width,omitempty" example:"512" doc:"Image width in pixels"`
+	Height      int       `json:"height,omitempty" example:"512" doc:"Image height in pixels"`
+}
+
+// LocalMedia returns the MediaFile of a file on disk, its content type guessed from the extension.
+func LocalMedia(path string) (MediaFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return MediaFile{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return MediaFile{}, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return MediaFile{
+		Name:        info.Name(),
+		ContentType: contentType,
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime(),
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}, nil
+}
+
+// WriteMedia answers a media request with the representation the client negotiates:
+//   - the JSON metadata (MediaInfo) when the Accept header ranks application/json above the file type,
+//   - a thumbnail when the `w` and/or `h` query parameters are set (with `fit`: contain, cover or fill),
+//     produced by the registered ImageProcessor,
+//   - the original file otherwise, with `X-Content-Type-Options: nosniff`; types browsers run scripts in
+//     (HTML, SVG, XML, JavaScript, PDF) are sent as attachments.
+//
+// Example Usage:
+//
+//	func (h GetAvatarApi) Handle(c *core.Ctx) error {
+//		file, err := http.LocalMedia(filepath.Join(avatarDir, c.PathVal("name")))
+//		if err != nil {
+//			return http.WriteError(c, &http.Error{Code: "NOT_FOUND", Message: "Avatar not found"}, core.StatusNotFound)
+//		}
+//		return http.WriteMedia(c, file) // /avatars/me.png?w=128&h=128&fit=cover
+//	}
+func WriteMedia(c *core.Ctx, file MediaFile) error {
+	c.Root().Response.Header.Add(core.HeaderVary, core.HeaderAccept)
+
+	if prefersMetadata(c.GetHeader(core.HeaderAccept), file.ContentType) {
+		return c.Success(MediaInfo{
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			ModifiedAt:  file.ModifiedAt,
+			Width:       file.Width,
+			Height:      file.Height,
+		})
+	}
+
+	if c.QueryStr("w") != "" || c.QueryStr("h") != "" {
+		options, errData := ParseResizeOptions(c)
+		if errData != nil {
+			return c.Error(errData, FailureStatus(c, FailureValidation))
+		}
+
+		return writeThumbnail(c, file, options)
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return writeMediaError(c, err)
+	}
+
+	c.SetHeader(core.HeaderContentType, file.ContentType)
+	c.SetHeader(core.HeaderXContentTypeOptions, "nosniff")
+	if activeContentType(file.ContentType) {
+		// Downloaded, not rendered on the API's origin
+		c.SetHeader(core.HeaderContentDisposition, "attachment")
+	}
+	if !file.ModifiedAt.IsZero() {
+		c.SetHeader(core.HeaderLastModified, file.ModifiedAt.UTC().Format(httpTimeFormat))
+	}
+	c.Root().SetBodyStream(content, int(file.Size))
+
+	return nil
+}
+
+// activeContentTypes types browsers render as documents running scripts.
+var activeContentTypes = []string{
+	"text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml",
+	"text/javascript", "application/javascript", "application/pdf",
+}
+
+// activeContentType checks a content type is rendered by browsers as a document running scripts.
+func activeContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	return slices.Contains(activeContentTypes, strings.ToLower(strings.TrimSpace(mediaType)))
+}
+
+// prefersMetadata checks the Accept header ranks application/json above the file's content type.
+// Wildcards only count for the file, so clients sending `*/*` get the file.
+func prefersMetadata(accept, contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	mainType, _, _ := strings.Cut(contentType, "/")
+	jsonQuality, fileQuality := 0.0, 0.0
+
+	for _, item := range parseAccept(accept) {
+		switch item.mediaType {
+		case core.MIMEApplicationJSON:
+			jsonQuality = max(jsonQuality, item.quality)
+			if contentType == core.MIMEApplicationJSON {
+				fileQuality = max(fileQuality, item.quality)
+			}
+		case contentType, mainType + "/*", "*/*":
+			fileQuality = max(fileQuality, item.quality)
+		default:
+		}
+	}
+
+	return jsonQuality > fileQuality
+}
+
+// ParseResizeOptions reads the thumbnail requested by the `w`, `h` and `fit` query parameters, with an error
+// per invalid parameter.
+func ParseResizeOptions(c *core.Ctx) (ResizeOptions, *Error) {
+	options := ResizeOptions{Fit: FitContain}
+	errorData := core.Data{}
+
+	maxSize := CurrentConfig().MaxThumbnailSize
+	for _, param := range []string{"w", "h"} {
+		value := c.QueryStr(param)
+		if value == "" {
+			continue
+		}
+
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxSize {
+			errorData[param] = []string{fmt.Sprintf("must be between 1 and %d", maxSize)}
+			continue
+		}
+
+		if param == "w" {
+			options.Width = size
+		} else {
+			options.Height = size
+		}
+	}
+
+	if fit := c.QueryStr("fit"); fit != "" {
+		switch fit {
+		case FitContain, FitCover, FitFill:
+			options.Fit = fit
+		default:
+			errorData["fit"] = []string{"must be one of contain, cover, fill"}
+		}
+	}
+
+	if len(errorData) > 0 {
+		return options, &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	return options, nil
+}
+
+// writeThumbnail sends the thumbnail of an image.
+func writeThumbnail(c *core.Ctx, file MediaFile, options ResizeOptions) error {
+	if !strings.HasPrefix(file.ContentType, "image/") {
+		return c.Error(&Error{
+			Code:    "NOT_AN_IMAGE",
+			Message: "Thumbnails are only available for images",
+		}, FailureStatus(c, FailureBusinessRule))
+	}
+
+	if imageProcessor == nil {
+		return WriteError(c, &Error{
+			Code:    "THUMBNAIL_UNAVAILABLE",
+			Message: "Thumbnails are not available",
+		}, core.StatusNotImplemented)
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return writeMediaError(c, err)
+	}
+	defer content.Close()
+
+	thumbnail, thumbnailType, err := imageProcessor.Resize(content, file.ContentType, options)
+	if err != nil {
+		return writeMediaError(c, err)
+	}
+
+	c.SetHeader(core.HeaderContentType, thumbnailType)
+	if !file.ModifiedAt.IsZero() {
+		c.SetHeader(core.HeaderLastModified, file.ModifiedAt.UTC().Format(httpTimeFormat))
+	}
+
+	return c.Raw(thumbnail)
+}
+
+// writeMediaError reports a media file which could not be read or processed.
+func writeMediaError(c *core.Ctx, err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return WriteError(c, &Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	return WriteServerError(c, &Error{
+		Message: "Unable to read media",
+	}, core.StatusInternalServerError, "Media error: %v", err)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// =========================== Media Codecs ===========================
+// ====================================================================
+
+// MediaCodec Codec of a media type other than JSON (protobuf, CBOR, ...), applied to the DTOs and response
+// values of the types it supports. Unlike payload adapters, bodies are decoded into the DTO directly.
+type MediaCodec interface {
+	Codec
+	// Supports reports whether values of the type are encoded by the codec. Parse asks for the pointer type
+	// of the DTO, the response writers for the type of the written value.
+	Supports(typ reflect.Type) bool
+}
+
+var (
+	mediaCodecsMu sync.RWMutex
+	// mediaCodecs codecs by lower-cased media type
+	mediaCodecs = map[string]MediaCodec{}
+)
+
+// RegisterMediaCodec registers the codec of the media type. Parse decodes bodies of the media type with it when
+// it supports the DTO, before payload adapters apply; sanitization and validation run as for JSON bodies.
+// Values written by WriteMessage and the response writers are encoded with it when it supports them and the
+// Accept header ranks the media type above JSON.
+//
+// Example Usage:
+//
+//	type protoCodec struct{}
+//
+//	func (protoCodec) Marshal(v any) ([]byte, error)      { return proto.Marshal(v.(proto.Message)) }
+//	func (protoCodec) Unmarshal(data []byte, v any) error { return proto.Unmarshal(data, v.(proto.Message)) }
+//	func (protoCodec) Supports(typ reflect.Type) bool {
+//		return typ.Implements(reflect.TypeFor[proto.Message]())
+//	}
+//
+//	http.RegisterMediaCodec("application/x-protobuf", protoCodec{})
+func RegisterMediaCodec(mediaType string, mediaCodec MediaCodec) {
+	mediaCodecsMu.Lock()
+	defer mediaCodecsMu.Unlock()
+
+	mediaCodecs[strings.ToLower(mediaType)] = mediaCodec
+}
+
+// WriteMessage sends a single value with the status, encoded by the MediaCodec the client prefers (see
+// RegisterMediaCodec) when it supports the value, as JSON otherwise. It answers clients of proto-based APIs,
+// whose messages can not be wrapped in Success.
+//
+// Example Usage:
+//
+//	func (h GetOrderApi) Handle(c *core.Ctx) error {
+//		return http.WriteMessage(c, core.StatusOK, &pb.Order{Id: order.ID, Total: order.Total})
+//	}
+func WriteMessage(c *core.Ctx, status int, value any) error {
+	return writeJSON(c, status, value)
+}
+
+// requestMediaCodec returns the codec of the request body's media type, when it supports the type.
+func requestMediaCodec(c *core.Ctx, typ reflect.Type) (MediaCodec, bool) {
+	mediaCodecsMu.RLock()
+	defer mediaCodecsMu.RUnlock()
+
+	if len(mediaCodecs) == 0 {
+		return nil, false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(string(c.Root().Request.Header.ContentType()))
+	if err != nil {
+		return nil, false
+	}
+
+	mediaCodec, ok := mediaCodecs[mediaType]
+	if !ok || !mediaCodec.Supports(typ) {
+		return nil, false
+	}
+
+	return mediaCodec, true
+}
+
+// responseMediaCodec returns the codec, and its media type, of the response value: the codec of the media type
+// ranked highest by the Accept header, when it is ranked above JSON and supports the type (any type when nil).
+func responseMediaCodec(c *core.Ctx, typ reflect.Type) (MediaCodec, string, bool) {
+	mediaCodecsMu.RLock()
+	defer mediaCodecsMu.RUnlock()
+
+	if len(mediaCodecs) == 0 {
+		return nil, "", false
+	}
+
+	var (
+		preferred  MediaCodec
+		mediaType  string
+		quality    float64
+		jsonRanked float64
+	)
+	for _, item := range parseAccept(c.GetHeader(core.HeaderAccept)) {
+		switch item.mediaType {
+		case core.MIMEApplicationJSON, "*/*", "application/*":
+			jsonRanked = max(jsonRanked, item.quality)
+		default:
+			if mediaCodec, ok := mediaCodecs[item.mediaType]; ok && item.quality > quality &&
+				(typ == nil || mediaCodec.Supports(typ)) {
+				preferred, mediaType, quality = mediaCodec, item.mediaType, item.quality
+			}
+		}
+	}
+
+	if preferred == nil || quality <= jsonRanked {
+		return nil, "", false
+	}
+
+	return preferred, mediaType, true
+}
+
+// writeMediaBody sets the status and the body of the response encoded by the media codec.
+func writeMediaBody(c *core.Ctx, status int, mediaCodec MediaCodec, mediaType string, value any) error {
+	encoded, err := mediaCodec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.Root().Response.SetStatusCode(status)
+	c.Root().Response.Header.SetContentType(mediaType)
+	c.Root().Response.SetBody(encoded)
+
+	return nil
+}
+
+// responseFormat returns the format of the responses the client prefers other than JSON: the media type of a
+// MediaCodec or "msgpack", empty for JSON.
+func responseFormat(c *core.Ctx) string {
+	if _, mediaType, ok := responseMediaCodec(c, nil); ok {
+		return mediaType
+	}
+	if PrefersMsgpack(c) {
+		return "msgpack"
+	}
+
+	return ""
+}
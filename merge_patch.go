@@ -0,0 +1,218 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ======================== JSON Merge Patch ==========================
+// ====================================================================
+
+// MIMEApplicationMergePatch media type of JSON Merge Patch (RFC 7386) bodies.
+const MIMEApplicationMergePatch = "application/merge-patch+json"
+
+// PatchLoadFunc repository callback returning the current resource of the ID as its update DTO (a pointer, as
+// for ProcessUpdateData), nil when it does not exist.
+type PatchLoadFunc[T any] func(c *core.Ctx, id int) (T, error)
+
+// ProcessPatchData validates and processes PATCH requests with JSON Merge Patch (RFC 7386) semantics: the body
+// is merged into the current resource returned by loadFn, so fields absent from the body keep their current
+// value, fields set to null are cleared and nested objects are merged. The merged DTO is sanitized, validated
+// as a whole and stored in Ctx's Data like ProcessUpdateData does; the field mask (see GetFieldMask) tells the
+// fields sent from the ones set to null. Fields tagged `json:"-"` are not carried over, except the ID.
+//
+// Example Usage:
+//
+//	func (h PatchUserApi) Validate(c *core.Ctx) error {
+//		return http.ProcessPatchData[*dto.UpdateUser](c, func(c *core.Ctx, id int) (*dto.UpdateUser, error) {
+//			user, err := userRepository.Find(id)
+//			if user == nil || err != nil {
+//				return nil, err
+//			}
+//			return transformers.ToUpdateUser(user), nil
+//		})
+//	}
+//
+//	// PATCH /users/42 {"nickname":null,"address":{"city":"Hanoi"}}
+//	// -> nickname cleared, address.city replaced, other fields unchanged
+func ProcessPatchData[T UpdateData](c *core.Ctx, loadFn PatchLoadFunc[T]) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	// Reject dry runs the handler would commit, see AllowDryRun
+	if err := checkDryRun(c); err != nil {
+		return err
+	}
+
+	// Receive path parameter ID
+	itemID, errData := PathID(c)
+	if errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Check the caller may touch the row
+	if err := CheckOwnership(c, itemID); err != nil {
+		return err
+	}
+
+	// Run the body checks and rewrites of Parse, the patch document is read from the rewritten body
+	var patchData T
+	if errData := Parse(c, &patchData); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+
+	patch, err := decodeDocument(c.Root().PostBody())
+	if _, isObject := patch.(map[string]any); err != nil || !isObject {
+		return c.Error(&Error{
+			Message: "Merge patch must be a JSON object",
+		}, FailureStatus(c, FailureMalformed))
+	}
+
+	// Load the current resource
+	current, err := loadFn(c, itemID)
+	if err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to load the resource",
+		}, core.StatusInternalServerError, "Patch load error: %v", err)
+	}
+	if value := reflect.ValueOf(current); !value.IsValid() || value.Kind() == reflect.Pointer && value.IsNil() {
+		return c.Error(&Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	// Merge the patch into the current resource
+	requestData, err := mergeInto(current, patch)
+	if err != nil {
+		return c.Error(&Error{
+			Message: err.Error(),
+		}, FailureStatus(c, FailureMalformed))
+	}
+
+	// Warn about deprecated fields
+	checkDeprecatedFields[T](c)
+
+	// Sanitize request data
+	sanitizeRequest(c, &requestData)
+
+	// Set ID on the request body
+	requestData.SetID(itemID)
+
+	// Record present and cleared fields
+	c.SetData(FieldMaskKey, buildFieldMask(c.Root().PostBody(), requestData))
+
+	// Validate DTO
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Transform validated data (e.g. hash passwords)
+	if err := TransformStruct(&requestData); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process request data",
+		}, core.StatusInternalServerError, "Transform request data error: %v", err)
+	}
+
+	// Store data into context
+	c.SetData(RequestKey, requestData)
+
+	// Record the DTO's event, emitted once the mutation succeeds
+	recordDataEvent(c, requestData)
+
+	return nil
+}
+
+// mergeInto applies a merge patch to the JSON of the current DTO and decodes the result into a new DTO.
+func mergeInto[T any](current T, patch any) (T, error) {
+	var merged T
+
+	encoded, err := codec.Marshal(current)
+	if err != nil {
+		return merged, err
+	}
+	document, err := decodeDocument(encoded)
+	if err != nil {
+		return merged, err
+	}
+
+	encoded, err = json.Marshal(MergePatch(dropNulls(document), patch))
+	if err != nil {
+		return merged, err
+	}
+	err = codec.Unmarshal(encoded, &merged)
+
+	return merged, err
+}
+
+// decodeDocument decodes a JSON document, keeping numbers as sent.
+func decodeDocument(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	err := decoder.Decode(&document)
+
+	return document, err
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to a decoded JSON document and returns the result: members
+// of patch objects set to null are removed from the target, other members are merged recursively, and patches
+// other than objects replace the target. The target is modified.
+//
+// Example Usage:
+//
+//	merged := http.MergePatch(
+//		map[string]any{"title": "Hello", "tags": []any{"a"}, "author": map[string]any{"name": "An", "email": "an@x.io"}},
+//		map[string]any{"tags": nil, "author": map[string]any{"email": "an@y.io"}},
+//	)
+//	// {"title":"Hello","author":{"name":"An","email":"an@y.io"}}
+func MergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = MergePatch(targetObject[name], value)
+		}
+	}
+
+	return targetObject
+}
+
+// dropNulls removes the null members of the objects of a document, so the current null values of a DTO are
+// not decoded as explicit nulls (see Optional).
+func dropNulls(document any) any {
+	switch typed := document.(type) {
+	case map[string]any:
+		for name, value := range typed {
+			if value == nil {
+				delete(typed, name)
+			} else {
+				typed[name] = dropNulls(value)
+			}
+		}
+	case []any:
+		for i, value := range typed {
+			typed[i] = dropNulls(value)
+		}
+	default:
+	}
+
+	return document
+}
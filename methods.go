@@ -0,0 +1,190 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================== HEAD & OPTIONS ==========================
+// ====================================================================
+
+// RouteRegistrar registers GET and HEAD routes, e.g. *core.Router and *core.Group.
+type RouteRegistrar interface {
+	GET(path string, handler core.IHandler)
+	HEAD(path string, handler core.IHandler)
+}
+
+// RouteLister lists the registered route paths by method, e.g. *core.Router.
+type RouteLister interface {
+	List() map[string][]string
+}
+
+// ETag returns the strong entity tag of a response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// SetETag sets the ETag header of a successful response from its body, unless the handler set one. When the
+// request's If-None-Match header matches, the response becomes a 304 Not Modified without body.
+func SetETag(c *core.Ctx) {
+	response := &c.Root().Response
+	if response.StatusCode() != core.StatusOK || response.IsBodyStream() {
+		return
+	}
+
+	etag := string(response.Header.Peek(core.HeaderETag))
+	if etag == "" {
+		etag = ETag(response.Body())
+		response.Header.Set(core.HeaderETag, etag)
+	}
+
+	if matchesETag(c.GetHeader(core.HeaderIfNoneMatch), etag) {
+		response.SetStatusCode(core.StatusNotModified)
+		response.ResetBody()
+	}
+}
+
+// matchesETag checks an If-None-Match header against an entity tag, with weak comparison.
+func matchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headHandler handler wrapper answering HEAD requests with a GET handler.
+type headHandler struct {
+	core.IHandler
+}
+
+// HeadOf wraps a GET handler to answer HEAD requests from the same code path: the response gets the headers,
+// Content-Length and ETag of the GET response, and the server sends no body.
+//
+// Example Usage:
+//
+//	router.HEAD("/users/{id}", http.HeadOf(api.NewGetUserApi()))
+func HeadOf(handler core.IHandler) core.IHandler {
+	return &headHandler{IHandler: handler}
+}
+
+// Handle runs the wrapped GET handler and sets the ETag of its response. The body is kept so the server
+// reports its Content-Length, fasthttp never writes the body of HEAD responses.
+func (h *headHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	SetETag(c)
+
+	return err
+}
+
+// GETWithHead registers a GET handler and its HEAD counterpart (see HeadOf).
+//
+// Example Usage:
+//
+//	http.GETWithHead(apiRouter, "/users/{id}", api.NewGetUserApi())
+func GETWithHead(routes RouteRegistrar, path string, handler core.IHandler) {
+	routes.GET(path, handler)
+	routes.HEAD(path, HeadOf(handler))
+}
+
+// AllowedMethods returns the sorted methods of the routes registered for a request path, OPTIONS included,
+// nil when no route matches.
+func AllowedMethods(routes RouteLister, path string) []string {
+	var allowed []string
+	for method, templates := range routes.List() {
+		if method == fasthttp.MethodOptions {
+			continue
+		}
+
+		for _, template := range templates {
+			if matchRoute(template, path) {
+				allowed = append(allowed, method)
+				break
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	allowed = append(allowed, fasthttp.MethodOptions)
+	slices.Sort(allowed)
+
+	return allowed
+}
+
+// matchRoute checks a request path against a route path template: `{name}` (with optional `:regex`) matches
+// one segment, `{name?}` an optional last segment and `{name:*}` the rest of the path.
+func matchRoute(template, path string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(pathSegments) == 1 && pathSegments[0] == "" {
+		pathSegments = nil
+	}
+
+	for i, segment := range templateSegments {
+		if segment == "" && i == 0 && len(templateSegments) == 1 {
+			return len(pathSegments) == 0
+		}
+
+		isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if isParam && strings.HasSuffix(segment, ":*}") {
+			return true
+		}
+
+		if i >= len(pathSegments) {
+			return isParam && strings.HasSuffix(segment, "?}") && i == len(templateSegments)-1
+		}
+
+		if !isParam && segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return len(pathSegments) == len(templateSegments)
+}
+
+// WriteOptions answers an OPTIONS request with the Allow header of the routes registered for its path,
+// and 204 No Content; 404 when no route matches.
+func WriteOptions(c *core.Ctx, routes RouteLister) error {
+	allowed := AllowedMethods(routes, string(c.Root().Path()))
+	if allowed == nil {
+		return WriteError(c, &Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	c.SetHeader(core.HeaderAllow, strings.Join(allowed, ", "))
+
+	return c.NoContent()
+}
+
+// NewOptionsHandler returns a handler answering OPTIONS requests with WriteOptions, meant as the router's
+// GlobalOPTIONS handler.
+//
+// Example Usage:
+//
+//	app.Router().GlobalOPTIONS = http.NewOptionsHandler(app.Router())
+func NewOptionsHandler(routes RouteLister) core.RequestHandler {
+	return func(c *core.Ctx) error {
+		return WriteOptions(c, routes)
+	}
+}
@@ -0,0 +1,42 @@
+package http
+
+import "fmt"
+
+// ====================================================================
+// ============================= Metrics ==============================
+// ====================================================================
+
+// Metrics is an interface for metrics backends (Prometheus, StatsD, OpenTelemetry, ...) the package
+// reports its counters to.
+type Metrics interface {
+	// IncCounter increments the counter of the name and labels by one.
+	IncCounter(name string, labels map[string]string)
+}
+
+// metrics the registered backend, counters are only kept in memory when nil.
+var metrics Metrics
+
+// RegisterMetrics registers the metrics backend.
+//
+// Example Usage:
+//
+//	http.RegisterMetrics(prometheusMetrics)
+func RegisterMetrics(backend Metrics) {
+	metrics = backend
+}
+
+// incCounter increments a counter of the registered backend, if any. A panicking backend is logged and
+// counted whatever its policy, as counters are also incremented outside of request handling.
+func incCounter(name string, labels map[string]string) {
+	if metrics == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			subsystemFailed(SubsystemMetrics, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	metrics.IncCounter(name, labels)
+}
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================= Request Mirroring ========================
+// ====================================================================
+
+// MirrorMaxInFlight maximum number of shadow requests in flight, requests above are not mirrored
+// so a slow shadow never piles up goroutines on the primary.
+var MirrorMaxInFlight = 100
+
+// RedactedValue replacement of redacted header and body values.
+const RedactedValue = "[REDACTED]"
+
+var (
+	mirrorInFlight atomic.Int64
+
+	mirrorSent    atomic.Uint64
+	mirrorDropped atomic.Uint64
+	mirrorFailed  atomic.Uint64
+)
+
+// MirrorOptions configuration of Mirrored.
+type MirrorOptions struct {
+	Target        string               // Base URL of the shadow service, e.g. "http://users-v2.internal:8080"
+	SampleRate    float64              // Fraction of requests mirrored, from 0 to 1
+	Timeout       time.Duration        // Shadow request timeout, 5 seconds by default
+	RedactHeaders []string             // Headers replaced by RedactedValue, Authorization and Cookie by default
+	RedactFields  []string             // Query parameters and JSON or form body fields replaced by RedactedValue
+	Filter        func(*core.Ctx) bool // Optional selection of mirrored requests
+	Compare       *ResponseComparator  // Optional comparison of shadow responses with primary responses
+}
+
+// mirroredRequest a request captured for the shadow target, with the primary response when compared.
+type mirroredRequest struct {
+	request       *fasthttp.Request
+	route         string
+	primaryStatus int
+	primaryBody   []byte
+}
+
+// mirroredHandler handler wrapper duplicating requests to a shadow service.
+type mirroredHandler struct {
+	core.IHandler
+	options MirrorOptions
+	target  *url.URL
+}
+
+// Mirrored wraps a handler so a sample of its requests is replayed, redacted, against a shadow target with
+// HTTPClient. The shadow request is sent in the background once the primary response is rendered;
+// its response and failures never affect the client. With a Compare comparator, shadow responses are
+// diffed against primary responses (canary validation).
+//
+// Example Usage:
+//
+//	router.GET("/users/{id}", http.Mirrored(api.NewGetUserApi(), http.MirrorOptions{
+//		Target:       "http://users-v2.internal:8080",
+//		SampleRate:   0.05,
+//		RedactFields: []string{"password", "card_number"},
+//	}))
+func Mirrored(handler core.IHandler, options MirrorOptions) core.IHandler {
+	target, err := url.Parse(options.Target)
+	if err != nil || target.Host == "" {
+		log.Errorf("Invalid mirror target %q, mirroring disabled", options.Target)
+		target = nil
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = 5 * time.Second
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = []string{core.HeaderAuthorization, core.HeaderCookie}
+	}
+
+	return &mirroredHandler{
+		IHandler: handler,
+		options:  options,
+		target:   target,
+	}
+}
+
+// Validate captures the request before the wrapped Validate (Parse may rewrite the body), when sampled.
+func (h *mirroredHandler) Validate(c *core.Ctx) error {
+	if h.target != nil && rand.Float64() < h.options.SampleRate &&
+		(h.options.Filter == nil || h.options.Filter(c)) {
+		shadow := fasthttp.AcquireRequest()
+		c.Root().Request.CopyTo(shadow)
+		c.SetData(MirrorKey, shadow)
+	}
+
+	err := h.IHandler.Validate(c)
+	if err != nil {
+		// Rejected requests are mirrored too, Handle is not called
+		h.dispatch(c)
+	}
+
+	return err
+}
+
+// Handle runs the wrapped handler, then sends the captured request to the shadow target.
+func (h *mirroredHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	h.dispatch(c)
+
+	return err
+}
+
+// dispatch sends the captured request of the request, if any, in the background.
+func (h *mirroredHandler) dispatch(c *core.Ctx) {
+	shadow, ok := c.GetData(MirrorKey).(*fasthttp.Request)
+	if !ok {
+		return
+	}
+	c.SetData(MirrorKey, nil)
+
+	if mirrorInFlight.Add(1) > int64(CurrentConfig().MirrorMaxInFlight) {
+		mirrorInFlight.Add(-1)
+		mirrorDropped.Add(1)
+		fasthttp.ReleaseRequest(shadow)
+
+		return
+	}
+
+	mirrored := mirroredRequest{request: shadow}
+	if h.options.Compare != nil {
+		mirrored.route = string(c.Root().Method()) + " " + RoutePath(c)
+		mirrored.primaryStatus = c.Root().Response.StatusCode()
+		mirrored.primaryBody = bytes.Clone(c.Root().Response.Body())
+	}
+
+	h.prepare(shadow)
+	go h.send(mirrored)
+}
+
+// prepare points the captured request to the shadow target and redacts it.
+func (h *mirroredHandler) prepare(shadow *fasthttp.Request) {
+	uri := shadow.URI()
+	uri.SetScheme(h.target.Scheme)
+	uri.SetHost(h.target.Host)
+	if basePath := strings.TrimSuffix(h.target.Path, "/"); basePath != "" {
+		uri.SetPath(basePath + string(uri.Path()))
+	}
+	shadow.Header.SetHost(h.target.Host)
+
+	for _, header := range h.options.RedactHeaders {
+		if len(shadow.Header.Peek(header)) > 0 {
+			shadow.Header.Set(header, RedactedValue)
+		}
+	}
+
+	if len(h.options.RedactFields) > 0 {
+		redactArgs(uri.QueryArgs(), h.options.RedactFields)
+
+		// Bodies which can not be redacted are not forwarded
+		body, ok := RedactBody(shadow.Header.ContentType(), shadow.Body(), h.options.RedactFields)
+		if !ok {
+			body = nil
+		}
+		shadow.SetBody(body)
+	}
+}
+
+// send sends the shadow request, and compares its response with the primary response when configured.
+func (h *mirroredHandler) send(mirrored mirroredRequest) {
+	response := fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseRequest(mirrored.request)
+		fasthttp.ReleaseResponse(response)
+		mirrorInFlight.Add(-1)
+	}()
+
+	if err := HTTPClient.DoTimeout(mirrored.request, response, h.options.Timeout); err != nil {
+		mirrorFailed.Add(1)
+		log.Debugf("Mirror request %s failed: %v", mirrored.request.URI().String(), err)
+
+		return
+	}
+	mirrorSent.Add(1)
+
+	if h.options.Compare != nil {
+		body, err := response.BodyUncompressed()
+		if err != nil {
+			body = response.Body()
+		}
+		h.options.Compare.Compare(mirrored.route, mirrored.primaryStatus, mirrored.primaryBody, response.StatusCode(), body)
+	}
+}
+
+// MirrorStats returns the number of shadow requests sent, dropped (MirrorMaxInFlight reached) and failed.
+func MirrorStats() (sent, dropped, failed uint64) {
+	return mirrorSent.Load(), mirrorDropped.Load(), mirrorFailed.Load()
+}
+
+// RedactBody replaces the values of the fields (case-insensitive) of a body by RedactedValue: fields at any depth
+// of JSON documents, and form-encoded values (`card[number]` matching "number"). It returns false for bodies it
+// can not redact (multipart forms, other types, malformed documents), which must not be sent or stored as is.
+//
+// Example Usage:
+//
+//	body, ok := http.RedactBody(request.Header.ContentType(), request.Body(), []string{"password"})
+//	if !ok {
+//		body = nil
+//	}
+func RedactBody(contentType, body []byte, fields []string) ([]byte, bool) {
+	if len(body) == 0 {
+		return body, true
+	}
+
+	mediaType, _, _ := strings.Cut(string(contentType), ";")
+	if strings.EqualFold(strings.TrimSpace(mediaType), core.MIMEApplicationForm) {
+		args := fasthttp.AcquireArgs()
+		defer fasthttp.ReleaseArgs(args)
+		args.ParseBytes(body)
+		if !redactArgs(args, fields) {
+			return body, true
+		}
+
+		return args.QueryString(), true
+	}
+
+	return redactJSON(body, fields)
+}
+
+// RedactJSON replaces the values of the fields (at any depth, case-insensitive) of a JSON body by
+// RedactedValue. Bodies which are not JSON are returned unchanged, see RedactBody for other types.
+func RedactJSON(body []byte, fields []string) []byte {
+	redacted, _ := redactJSON(body, fields)
+
+	return redacted
+}
+
+// redactJSON redacts a JSON body, it returns the body unchanged and false when it is not a JSON document.
+func redactJSON(body []byte, fields []string) ([]byte, bool) {
+	var document any
+	if !decodeJSONNumbers(body, &document) {
+		return body, false
+	}
+
+	redacted := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		redacted[strings.ToLower(field)] = true
+	}
+	redactValue(document, redacted)
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return body, false
+	}
+
+	return encoded, true
+}
+
+func redactValue(value any, redacted map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if redacted[strings.ToLower(key)] {
+				typed[key] = RedactedValue
+				continue
+			}
+			redactValue(item, redacted)
+		}
+	case []any:
+		for _, item := range typed {
+			redactValue(item, redacted)
+		}
+	default:
+	}
+}
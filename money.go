@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ====================================================================
+// ============================== Money ===============================
+// ====================================================================
+
+var (
+	// ErrInvalidAmount returned when an amount can not be parsed.
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrAmountPrecision returned when an amount has more decimals than its currency allows.
+	ErrAmountPrecision = errors.New("amount has too many decimal places for currency")
+	// ErrAmountOverflow returned when an amount or an arithmetic result exceeds the int64 range.
+	ErrAmountOverflow = errors.New("amount overflow")
+	// ErrCurrencyMismatch returned by arithmetic on amounts of different currencies.
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// currencyExponents number of decimal places of ISO 4217 currencies which do not use 2.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of decimal places of an ISO 4217 currency (2 by default).
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+
+	return 2
+}
+
+// Money struct to describe an amount in a currency, stored as an integer number of minor units
+// (e.g. cents) so arithmetic never suffers from floating point rounding.
+// @Description Monetary amount with ISO 4217 currency, amount is a decimal string
+// @Amount Amount is the decimal amount, e.g. "12.34"
+// @Currency Currency is the ISO 4217 currency code
+// @Tags Common
+type Money struct {
+	Amount   int64  `json:"amount" example:"12.34" doc:"Decimal amount, stored in minor units of the currency"`
+	Currency string `json:"currency" example:"USD" doc:"ISO 4217 currency code"`
+}
+
+// NewMoney creates Money from an amount in minor units.
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{Amount: minorUnits, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney creates Money from a decimal amount such as "12.34".
+// Amounts with more decimals than the currency allows are rejected instead of being rounded.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	minorUnits, err := ParseAmount(amount, CurrencyExponent(currency))
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// ParseAmount converts a decimal string to an integer number of minor units with the given exponent,
+// e.g. ParseAmount("12.3", 2) returns 1230.
+func ParseAmount(amount string, exponent int) (int64, error) {
+	amount = strings.TrimSpace(amount)
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return 0, ErrAmountPrecision
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	var result int64
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+		digit := int64(r - '0')
+		if result > (math.MaxInt64-digit)/10 {
+			return 0, ErrAmountOverflow
+		}
+		result = result*10 + digit
+	}
+
+	if negative {
+		result = -result
+	}
+
+	return result, nil
+}
+
+// FormatAmount converts an integer number of minor units to a decimal string, e.g. FormatAmount(1230, 2) returns "12.30".
+func FormatAmount(minorUnits int64, exponent int) string {
+	digits := strconv.FormatUint(absInt64(minorUnits), 10)
+	if exponent > 0 {
+		if len(digits) <= exponent {
+			digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+	}
+
+	if minorUnits < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// String returns the decimal amount followed by the currency, e.g. "12.30 USD".
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// Decimal returns the decimal amount, e.g. "12.30".
+func (m Money) Decimal() string {
+	return FormatAmount(m.Amount, CurrencyExponent(m.Currency))
+}
+
+// IsZero checks the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if !strings.EqualFold(m.Currency, other.Currency) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul returns the amount multiplied by an integer factor.
+func (m Money) Mul(factor int64) (Money, error) {
+	if factor != 0 && (m.Amount*factor/factor != m.Amount || (m.Amount == math.MinInt64 && factor == -1)) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: m.Amount * factor, Currency: m.Currency}, nil
+}
+
+// Sanitize normalizes the currency code. Called by SanitizeStruct.
+func (m *Money) Sanitize() {
+	m.Currency = strings.ToUpper(strings.TrimSpace(m.Currency))
+}
+
+// MarshalJSON encodes the amount as a decimal string: {"amount":"12.30","currency":"USD"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON accepts the amount as a decimal string ("12.30") or a JSON number (12.30).
+// Numbers are parsed from their text so no precision is lost through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	amount := string(raw.Amount)
+	if unquoted, err := strconv.Unquote(amount); err == nil {
+		amount = unquoted
+	}
+
+	money, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+
+	*m = money
+
+	return nil
+}
+
+func absInt64(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1 // #nosec G115 -- handles math.MinInt64
+	}
+
+	return uint64(value) // #nosec G115 -- value is positive
+}
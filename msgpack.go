@@ -0,0 +1,538 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// =========================== MessagePack ============================
+// ====================================================================
+
+// MIMEApplicationMsgpack media type of MessagePack bodies.
+const MIMEApplicationMsgpack = "application/msgpack"
+
+// msgpackMaxDepth maximum nesting of arrays and maps in MessagePack bodies.
+const msgpackMaxDepth = 1000
+
+// errMsgpackTruncated returned for MessagePack data ending inside a value.
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+// PrefersMsgpack checks the client accepts MessagePack (application/msgpack or application/x-msgpack) with a
+// higher quality than JSON. Success, list and error responses are then encoded as MessagePack.
+func PrefersMsgpack(c *core.Ctx) bool {
+	accept := c.GetHeader(core.HeaderAccept)
+	if !strings.Contains(accept, "msgpack") {
+		return false
+	}
+
+	msgpackQuality, jsonQuality := 0.0, 0.0
+	for _, item := range parseAccept(accept) {
+		switch item.mediaType {
+		case MIMEApplicationMsgpack, "application/x-msgpack":
+			msgpackQuality = max(msgpackQuality, item.quality)
+		case core.MIMEApplicationJSON, "*/*", "application/*":
+			jsonQuality = max(jsonQuality, item.quality)
+		default:
+		}
+	}
+
+	return msgpackQuality > jsonQuality
+}
+
+// setMsgpackBody replaces the JSON body of a response with its MessagePack encoding.
+func setMsgpackBody(c *core.Ctx, body []byte) error {
+	encoded, err := jsonToMsgpack(body)
+	if err != nil {
+		return err
+	}
+
+	c.Root().Response.Header.SetContentType(MIMEApplicationMsgpack)
+	c.Root().Response.SetBody(encoded)
+
+	return nil
+}
+
+// msgpackToJSON PayloadAdapter of MessagePack bodies. Map keys keep their order; binary values are encoded as
+// base64 strings (like []byte fields) and timestamps as RFC 3339 strings.
+func msgpackToJSON(_ *core.Ctx, body []byte) ([]byte, error) {
+	decoder := msgpackDecoder{data: body}
+
+	var encoded bytes.Buffer
+	if err := decoder.decode(&encoded, 0); err != nil {
+		return nil, err
+	}
+	if decoder.offset != len(body) {
+		return nil, errors.New("msgpack: trailing data after the value")
+	}
+
+	return encoded.Bytes(), nil
+}
+
+// ---------------------- JSON to MessagePack ------------------------
+
+// jsonToMsgpack transcodes a JSON document to MessagePack, keeping the order of object keys.
+func jsonToMsgpack(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	value, err := readJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded bytes.Buffer
+	encoded.Grow(len(body))
+	if err := writeMsgpack(&encoded, value); err != nil {
+		return nil, err
+	}
+
+	return encoded.Bytes(), nil
+}
+
+// jsonObject JSON object with its keys in document order.
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+// readJSONValue reads the next value of a decoder as nil, bool, json.Number, string, []any or *jsonObject.
+func readJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		values := []any{}
+		for decoder.More() {
+			value, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		_, err := decoder.Token()
+
+		return values, err
+	case json.Delim('{'):
+		object := &jsonObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err := decoder.Token()
+
+		return object, err
+	default:
+		return token, nil
+	}
+}
+
+// writeMsgpack encodes a value read by readJSONValue.
+func writeMsgpack(w *bytes.Buffer, value any) error {
+	switch value := value.(type) {
+	case nil:
+		w.WriteByte(0xc0)
+	case bool:
+		if value {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case json.Number:
+		writeMsgpackNumber(w, value)
+	case string:
+		writeMsgpackHeader(w, len(value), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		w.WriteString(value)
+	case []any:
+		writeMsgpackHeader(w, len(value), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range value {
+			if err := writeMsgpack(w, item); err != nil {
+				return err
+			}
+		}
+	case *jsonObject:
+		writeMsgpackHeader(w, len(value.keys), 0x80, 16, 0, 0xde, 0xdf)
+		for i, key := range value.keys {
+			writeMsgpackHeader(w, len(key), 0xa0, 32, 0xd9, 0xda, 0xdb)
+			w.WriteString(key)
+			if err := writeMsgpack(w, value.values[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", value)
+	}
+
+	return nil
+}
+
+// writeMsgpackNumber encodes a number with the smallest integer format, or as float64.
+func writeMsgpackNumber(w *bytes.Buffer, number json.Number) {
+	if integer, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+		switch {
+		case integer >= 0 && integer <= 0x7f:
+			w.WriteByte(byte(integer))
+		case integer >= -32 && integer < 0:
+			w.WriteByte(byte(int8(integer)))
+		case integer >= 0:
+			writeMsgpackUint(w, uint64(integer))
+		case integer >= math.MinInt8:
+			w.Write([]byte{0xd0, byte(int8(integer))})
+		case integer >= math.MinInt16:
+			w.WriteByte(0xd1)
+			w.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(integer))))
+		case integer >= math.MinInt32:
+			w.WriteByte(0xd2)
+			w.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(integer))))
+		default:
+			w.WriteByte(0xd3)
+			w.Write(binary.BigEndian.AppendUint64(nil, uint64(integer)))
+		}
+
+		return
+	}
+
+	if integer, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+		writeMsgpackUint(w, integer)
+
+		return
+	}
+
+	float, _ := number.Float64()
+	w.WriteByte(0xcb)
+	w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(float)))
+}
+
+// writeMsgpackUint encodes an unsigned integer above the positive fixint range.
+func writeMsgpackUint(w *bytes.Buffer, integer uint64) {
+	switch {
+	case integer <= math.MaxUint8:
+		w.Write([]byte{0xcc, byte(integer)})
+	case integer <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(integer)))
+	case integer <= math.MaxUint32:
+		w.WriteByte(0xce)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(integer)))
+	default:
+		w.WriteByte(0xcf)
+		w.Write(binary.BigEndian.AppendUint64(nil, integer))
+	}
+}
+
+// writeMsgpackHeader encodes the length of a string, array or map: in the fixed format below fixLimit, else
+// with the 8-bit (when the type has one), 16-bit or 32-bit format.
+func writeMsgpackHeader(w *bytes.Buffer, length int, fixPrefix byte, fixLimit int, prefix8, prefix16, prefix32 byte) {
+	switch {
+	case length < fixLimit:
+		w.WriteByte(fixPrefix | byte(length))
+	case prefix8 != 0 && length <= math.MaxUint8:
+		w.Write([]byte{prefix8, byte(length)})
+	case length <= math.MaxUint16:
+		w.WriteByte(prefix16)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(length)))
+	default:
+		w.WriteByte(prefix32)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(length)))
+	}
+}
+
+// ---------------------- MessagePack to JSON ------------------------
+
+// msgpackDecoder reader of MessagePack data.
+type msgpackDecoder struct {
+	data   []byte
+	offset int
+}
+
+// next returns the next n bytes.
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.offset {
+		return nil, errMsgpackTruncated
+	}
+	chunk := d.data[d.offset : d.offset+n]
+	d.offset += n
+
+	return chunk, nil
+}
+
+// length reads a big-endian length or number of size bytes.
+func (d *msgpackDecoder) length(size int) (int, error) {
+	chunk, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var length uint64
+	for _, b := range chunk {
+		length = length<<8 | uint64(b)
+	}
+	if length > uint64(len(d.data)) {
+		// Longer than the whole body, the data is truncated
+		return 0, errMsgpackTruncated
+	}
+
+	return int(length), nil
+}
+
+// decode transcodes the next value to JSON.
+func (d *msgpackDecoder) decode(w *bytes.Buffer, depth int) error {
+	if depth > msgpackMaxDepth {
+		return errors.New("msgpack: maximum nesting depth exceeded")
+	}
+
+	head, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	prefix := head[0]
+
+	// Fixed formats, the value or length is held by the prefix
+	switch {
+	case prefix <= 0x7f:
+		w.WriteString(strconv.Itoa(int(prefix)))
+
+		return nil
+	case prefix >= 0xe0:
+		w.WriteString(strconv.Itoa(int(int8(prefix))))
+
+		return nil
+	case prefix <= 0x8f:
+		return d.decodeMap(w, int(prefix&0x0f), depth)
+	case prefix <= 0x9f:
+		return d.decodeArray(w, int(prefix&0x0f), depth)
+	case prefix <= 0xbf:
+		return d.decodeString(w, int(prefix&0x1f))
+	default:
+	}
+
+	switch prefix {
+	case 0xc0:
+		w.WriteString("null")
+	case 0xc2:
+		w.WriteString("false")
+	case 0xc3:
+		w.WriteString("true")
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		chunk, err := d.next(1 << (prefix - 0xcc))
+		if err != nil {
+			return err
+		}
+		var integer uint64
+		for _, b := range chunk {
+			integer = integer<<8 | uint64(b)
+		}
+		w.WriteString(strconv.FormatUint(integer, 10))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (prefix - 0xd0)
+		chunk, err := d.next(size)
+		if err != nil {
+			return err
+		}
+		var integer uint64
+		for _, b := range chunk {
+			integer = integer<<8 | uint64(b)
+		}
+		// Sign extension of the size's two's complement
+		shift := 64 - 8*size
+		w.WriteString(strconv.FormatInt(int64(integer<<shift)>>shift, 10))
+	case 0xca, 0xcb:
+		return d.decodeFloat(w, prefix == 0xcb)
+	case 0xd9, 0xda, 0xdb:
+		length, err := d.length(1 << (prefix - 0xd9))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeString(w, length)
+	case 0xc4, 0xc5, 0xc6:
+		length, err := d.length(1 << (prefix - 0xc4))
+		if err != nil {
+			return err
+		}
+		chunk, err := d.next(length)
+		if err != nil {
+			return err
+		}
+		w.WriteByte('"')
+		w.WriteString(base64.StdEncoding.EncodeToString(chunk))
+		w.WriteByte('"')
+	case 0xdc, 0xdd:
+		length, err := d.length(2 << (prefix - 0xdc))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeArray(w, length, depth)
+	case 0xde, 0xdf:
+		length, err := d.length(2 << (prefix - 0xde))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeMap(w, length, depth)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(w, 1<<(prefix-0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		length, err := d.length(1 << (prefix - 0xc7))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeExt(w, length)
+	default:
+		return fmt.Errorf("msgpack: invalid prefix 0x%02x", prefix)
+	}
+
+	return nil
+}
+
+// decodeString transcodes a string of the length.
+func (d *msgpackDecoder) decodeString(w *bytes.Buffer, length int) error {
+	chunk, err := d.next(length)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(string(chunk))
+	if err != nil {
+		return err
+	}
+	w.Write(encoded)
+
+	return nil
+}
+
+// decodeFloat transcodes a float32 or float64, NaN and infinities have no JSON representation.
+func (d *msgpackDecoder) decodeFloat(w *bytes.Buffer, double bool) error {
+	var float float64
+	if double {
+		chunk, err := d.next(8)
+		if err != nil {
+			return err
+		}
+		float = math.Float64frombits(binary.BigEndian.Uint64(chunk))
+	} else {
+		chunk, err := d.next(4)
+		if err != nil {
+			return err
+		}
+		float = float64(math.Float32frombits(binary.BigEndian.Uint32(chunk)))
+	}
+
+	if math.IsNaN(float) || math.IsInf(float, 0) {
+		return errors.New("msgpack: NaN and infinite numbers are not supported")
+	}
+
+	bitSize := 64
+	if !double {
+		bitSize = 32
+	}
+	w.WriteString(strconv.FormatFloat(float, 'g', -1, bitSize))
+
+	return nil
+}
+
+// decodeArray transcodes an array of the length.
+func (d *msgpackDecoder) decodeArray(w *bytes.Buffer, length, depth int) error {
+	w.WriteByte('[')
+	for i := 0; i < length; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := d.decode(w, depth+1); err != nil {
+			return err
+		}
+	}
+	w.WriteByte(']')
+
+	return nil
+}
+
+// decodeMap transcodes a map of the length, keys must be strings or integers.
+func (d *msgpackDecoder) decodeMap(w *bytes.Buffer, length, depth int) error {
+	w.WriteByte('{')
+	for i := 0; i < length; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		var key bytes.Buffer
+		if err := d.decode(&key, depth+1); err != nil {
+			return err
+		}
+		switch first := key.Bytes()[0]; {
+		case first == '"':
+			w.Write(key.Bytes())
+		case first == '-' || (first >= '0' && first <= '9'):
+			w.WriteByte('"')
+			w.Write(key.Bytes())
+			w.WriteByte('"')
+		default:
+			return errors.New("msgpack: map keys must be strings or integers")
+		}
+
+		w.WriteByte(':')
+		if err := d.decode(w, depth+1); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('}')
+
+	return nil
+}
+
+// decodeExt transcodes an extension value of the length, only timestamps (type -1) are supported.
+func (d *msgpackDecoder) decodeExt(w *bytes.Buffer, length int) error {
+	kind, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	chunk, err := d.next(length)
+	if err != nil {
+		return err
+	}
+	if int8(kind[0]) != -1 {
+		return fmt.Errorf("msgpack: unsupported extension type %d", int8(kind[0]))
+	}
+
+	var timestamp time.Time
+	switch length {
+	case 4:
+		timestamp = time.Unix(int64(binary.BigEndian.Uint32(chunk)), 0)
+	case 8:
+		value := binary.BigEndian.Uint64(chunk)
+		timestamp = time.Unix(int64(value&0x3ffffffff), int64(value>>34))
+	case 12:
+		timestamp = time.Unix(int64(binary.BigEndian.Uint64(chunk[4:])), int64(binary.BigEndian.Uint32(chunk)))
+	default:
+		return fmt.Errorf("msgpack: invalid timestamp of %d bytes", length)
+	}
+
+	w.WriteByte('"')
+	w.WriteString(timestamp.UTC().Format(time.RFC3339Nano))
+	w.WriteByte('"')
+
+	return nil
+}
@@ -0,0 +1,459 @@
+package http
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================= OpenAPI Document =========================
+// ====================================================================
+
+// OpenAPIInfo general information of the OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string // API title
+	Version     string // API version, e.g. "1.0.0"
+	Description string // Optional API description
+}
+
+// textMarshalerType type of the encoding.TextMarshaler interface.
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// OpenAPIDocument generates the OpenAPI 3.1 document of the described endpoints (see Describe). Schemas are
+// built from the JSON names of the DTO fields, with their `doc` tags as descriptions, their `example` tags
+// as examples and `validate:"required"` as required properties; examples registered for request and response
+// types (see RegisterExample) are listed by name. Fields tagged `from` are parameters (see
+// BindSources), as are all fields of GET, HEAD and DELETE requests, bound from the query.
+//
+// Example Usage:
+//
+//	func (h OpenAPIApi) Handle(c *core.Ctx) error {
+//		return c.Success(http.OpenAPIDocument(http.OpenAPIInfo{Title: "Shop API", Version: "1.4.0"}))
+//	}
+func OpenAPIDocument(info OpenAPIInfo) map[string]any {
+	schemas := &openAPISchemas{components: map[string]any{}}
+	errorSchema := schemas.schema(reflect.TypeFor[Error]())
+
+	paths := map[string]any{}
+	for _, endpoint := range DescribedEndpoints() {
+		path, pathParams := openAPIPath(endpoint.Path)
+
+		operation := map[string]any{
+			"operationId": openAPIOperationID(endpoint.Method, path),
+			"responses":   openAPIResponses(endpoint, schemas, errorSchema),
+		}
+		if len(endpoint.Tags) > 0 {
+			operation["tags"] = endpoint.Tags
+		}
+
+		parameters := make([]map[string]any, 0, len(pathParams))
+		for _, name := range pathParams {
+			parameters = append(parameters, map[string]any{
+				"name": name, "in": SourcePath, "required": true, "schema": map[string]any{"type": "string"},
+			})
+		}
+		if endpoint.Request != nil {
+			requestParams, body := openAPIRequest(endpoint.Method, endpoint.Request, schemas)
+			for _, parameter := range requestParams {
+				// Path parameters of the template are replaced by the typed DTO field
+				parameters = slices.DeleteFunc(parameters, func(existing map[string]any) bool {
+					return existing["in"] == parameter["in"] && existing["name"] == parameter["name"]
+				})
+				parameters = append(parameters, parameter)
+			}
+			if body != nil {
+				media := map[string]any{"schema": body}
+				if named := openAPIExamples(endpoint.Request); named != nil {
+					media["examples"] = named
+				}
+				operation["requestBody"] = map[string]any{
+					"required": true,
+					"content":  map[string]any{core.MIMEApplicationJSON: media},
+				}
+			}
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(endpoint.Method)] = operation
+	}
+
+	infoObject := map[string]any{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoObject["description"] = info.Description
+	}
+
+	return map[string]any{
+		"openapi":    "3.1.0",
+		"info":       infoObject,
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas.components},
+	}
+}
+
+// openAPIPath converts a route path template to an OpenAPI path, returning its parameter names:
+// `{id:[0-9]+}`, `{name?}` and `{path:*}` become `{id}`, `{name}` and `{path}`.
+func openAPIPath(template string) (string, []string) {
+	segments := strings.Split(template, "/")
+	var params []string
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(strings.TrimSuffix(segment[1:len(segment)-1], "?"), ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// openAPIOperationID returns the operation ID of an endpoint, e.g. "get_users_id" for GET /users/{id}.
+func openAPIOperationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment != "" {
+			id += "_" + segment
+		}
+	}
+
+	return id
+}
+
+// openAPIRequest returns the parameters and the body schema of a request DTO, nil without body.
+func openAPIRequest(method string, typ reflect.Type, schemas *openAPISchemas) ([]map[string]any, map[string]any) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, schemas.schema(typ)
+	}
+
+	var parameters []map[string]any
+	for _, field := range sourceFields(typ) {
+		structField := typ.FieldByIndex(field.index)
+		parameters = append(parameters, openAPIParameter(field.name, field.source, structField, schemas))
+	}
+
+	if method != fasthttp.MethodGet && method != fasthttp.MethodHead && method != fasthttp.MethodDelete {
+		return parameters, schemas.schema(typ)
+	}
+
+	// Requests without body are bound from the query
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || field.Tag.Get("from") != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		parameters = append(parameters, openAPIParameter(name, SourceQuery, field, schemas))
+	}
+
+	return parameters, nil
+}
+
+// openAPIParameter returns the parameter of a DTO field.
+func openAPIParameter(name, source string, field reflect.StructField, schemas *openAPISchemas) map[string]any {
+	parameter := map[string]any{
+		"name":     name,
+		"in":       source,
+		"required": source == SourcePath || isRequiredField(field),
+		"schema":   schemas.fieldSchema(field),
+	}
+	if doc := field.Tag.Get("doc"); doc != "" {
+		parameter["description"] = doc
+		delete(parameter["schema"].(map[string]any), "description")
+	}
+
+	return parameter
+}
+
+// openAPIResponses returns the success and error responses of an endpoint.
+func openAPIResponses(endpoint EndpointDescription, schemas *openAPISchemas, errorSchema map[string]any) map[string]any {
+	responses := map[string]any{}
+
+	success := map[string]any{"description": "Success"}
+	if endpoint.Response != nil {
+		media := map[string]any{"schema": schemas.schema(endpoint.Response)}
+		if named := openAPIExamples(endpoint.Response); named != nil {
+			media["examples"] = named
+		}
+		success["content"] = map[string]any{core.MIMEApplicationJSON: media}
+	}
+	responses[strconv.Itoa(endpoint.SuccessStatus())] = success
+
+	for _, endpointError := range endpoint.Errors {
+		status := strconv.Itoa(endpointError.Status)
+		response, _ := responses[status].(map[string]any)
+		if response == nil {
+			response = map[string]any{
+				"description": endpointError.Message,
+				"content": map[string]any{core.MIMEApplicationJSON: map[string]any{
+					"schema":  errorSchema,
+					"example": map[string]any{"code": endpointError.Code, "message": endpointError.Message, "data": nil},
+				}},
+			}
+			responses[status] = response
+
+			continue
+		}
+
+		// Errors sharing a status are listed in the description
+		response["description"] = response["description"].(string) + "; " + endpointError.Message
+	}
+
+	return responses
+}
+
+// openAPISchemas builder of the JSON schemas of Go types, named structs being shared components.
+type openAPISchemas struct {
+	components map[string]any
+}
+
+// schema returns the JSON schema of a type, a reference for named structs.
+func (s *openAPISchemas) schema(typ reflect.Type) map[string]any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return s.objectSchema(typ)
+		}
+
+		name := openAPISchemaName(typ)
+		if _, ok := s.components[name]; !ok {
+			// Registered before its fields, so recursive types end
+			s.components[name] = map[string]any{}
+			s.components[name] = s.objectSchema(typ)
+		}
+
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+
+		return map[string]any{"type": "array", "items": s.schema(typ.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(typ.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// objectSchema returns the schema of a struct's JSON object. Embedded structs without JSON name are inlined.
+func (s *openAPISchemas) objectSchema(typ reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inlined := s.objectSchema(embedded)
+				for property, schema := range inlined["properties"].(map[string]any) {
+					properties[property] = schema
+				}
+				if names, ok := inlined["required"].([]string); ok {
+					required = append(required, names...)
+				}
+
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.fieldSchema(field)
+		if isRequiredField(field) {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// fieldSchema returns the schema of a struct field, with its `doc` and `example` tags.
+func (s *openAPISchemas) fieldSchema(field reflect.StructField) map[string]any {
+	schema := s.schema(field.Type)
+	if doc := field.Tag.Get("doc"); doc != "" {
+		schema["description"] = doc
+	}
+	if example, ok := field.Tag.Lookup("example"); ok {
+		schema["examples"] = []any{parseExample(example, field.Type)}
+	}
+
+	return schema
+}
+
+// openAPISchemaName returns the component name of a named type: generic arguments are reduced to their type
+// name, e.g. "List_User" for List[dto.User].
+func openAPISchemaName(typ reflect.Type) string {
+	name, arguments, generic := strings.Cut(typ.Name(), "[")
+	if !generic {
+		return name
+	}
+
+	for _, argument := range strings.Split(strings.TrimSuffix(arguments, "]"), ",") {
+		argument = argument[strings.LastIndexAny(argument, "./*")+1:]
+		name += "_" + argument
+	}
+
+	return name
+}
+
+// isRequiredField checks a field is validated as required.
+func isRequiredField(field reflect.StructField) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required")
+}
+
+// parseExample returns the value of an `example` tag: strings as is, other types decoded from JSON.
+func parseExample(example string, typ reflect.Type) any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.String || typ == timeType {
+		return example
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(example), &value); err != nil {
+		return example
+	}
+
+	return value
+}
+
+// exampleValue returns an example JSON value of a type, from the `example` tags of its fields. Types being
+// built are tracked in seen, so recursive types end.
+func exampleValue(typ reflect.Type, seen map[reflect.Type]bool) any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return "2024-01-31T10:00:00Z"
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		return "string"
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if seen[typ] {
+			return nil
+		}
+		if seen == nil {
+			seen = map[reflect.Type]bool{}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		object := map[string]any{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+
+			if field.Anonymous && name == "" {
+				if embedded, ok := exampleValue(field.Type, seen).(map[string]any); ok {
+					for property, value := range embedded {
+						object[property] = value
+					}
+
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			if example, ok := field.Tag.Lookup("example"); ok {
+				object[name] = parseExample(example, field.Type)
+			} else {
+				object[name] = exampleValue(field.Type, seen)
+			}
+		}
+
+		return object
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+
+		return []any{exampleValue(typ.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{}
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 0
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,346 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================== Long-Running Operations ===================
+// ====================================================================
+
+// Operation statuses.
+const (
+	OperationPending   = "pending"
+	OperationRunning   = "running"
+	OperationSucceeded = "succeeded"
+	OperationFailed    = "failed"
+)
+
+// ErrOperationNotFound returned by OperationStore when no operation has the ID, or it expired.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// OperationError error of an OperationFunc whose message is shown to clients in Operation.Error. Other errors
+// are only logged, clients read "Operation failed", so internal details (paths, hosts, queries) do not leak.
+//
+// Example Usage:
+//
+//	if err := searchIndex.Rebuild(ctx, progress.Add); err != nil {
+//		return nil, &http.OperationError{Message: "Unable to rebuild the index", Err: err}
+//	}
+type OperationError struct {
+	Message string // Message shown to clients
+	Err     error  // Cause, only logged
+}
+
+// Error returns the message and the cause.
+func (e *OperationError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause.
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// OperationsPath path of the operation endpoint (see OperationApi), the ID is appended for the Location
+	// header of accepted operations.
+	OperationsPath = "/api/v1/operations"
+	// OperationRetention lifetime of finished operations in a MemoryOperationStore.
+	OperationRetention = 24 * time.Hour
+	// operationSaveInterval minimum delay between two saves of an operation's progress.
+	operationSaveInterval = time.Second
+)
+
+// Operation struct to describe a long-running operation processed in the background.
+// @Description Long-running operation, polled until its status is succeeded or failed
+// @ID ID is the operation identifier.
+// @Kind Kind is the kind of operation (e.g. "import", "export").
+// @Status Status is the operation status (pending, running, succeeded, failed).
+// @Processed Processed is the number of items processed so far.
+// @Total Total is the number of items to process, 0 when unknown.
+// @Result Result is the outcome of a succeeded operation (optional).
+// @ResultURL ResultURL is the download URL of the operation's file (optional).
+// @Error Error is the failure reason of a failed operation (optional).
+// @Owner Owner is the identity of the caller which started the operation, never sent to clients.
+// @CreatedAt CreatedAt is the creation time.
+// @UpdatedAt UpdatedAt is the time of the last progress update.
+// @Tags Info Responses
+type Operation struct {
+	ID        string    `json:"id" example:"6f1c0e9b3a7d4c2e" doc:"Operation identifier"`
+	Kind      string    `json:"kind" example:"import" doc:"Kind of operation"`
+	Status    string    `json:"status" example:"running" doc:"Status: pending, running, succeeded or failed"`
+	Processed int64     `json:"processed" example:"1200" doc:"Number of items processed so far"`
+	Total     int64     `json:"total" example:"5000" doc:"Number of items to process, 0 when unknown"`
+	Result    any       `json:"result,omitempty" doc:"Outcome of a succeeded operation"`
+	ResultURL string    `json:"result_url,omitempty" example:"https://files.example.com/exports/6f1c.csv?signature=..." doc:"Download URL of the operation's file"`
+	Error     string    `json:"error,omitempty" example:"Unable to read the file" doc:"Failure reason of a failed operation"`
+	Owner     string    `json:"owner,omitempty" doc:"Identity of the caller which started the operation, never sent to clients"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-31T10:00:00Z" doc:"Creation time"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-31T10:00:05Z" doc:"Time of the last progress update"`
+}
+
+// Finished checks the operation succeeded or failed.
+func (o Operation) Finished() bool {
+	return o.Status == OperationSucceeded || o.Status == OperationFailed
+}
+
+// OperationStore is an interface for storages of operations (memory, Redis, database, ...). Stores must keep
+// all fields, Owner included: OperationApi only answers the caller which started the operation.
+type OperationStore interface {
+	// Save creates or replaces an operation.
+	Save(operation Operation) error
+
+	// Get returns an operation, ErrOperationNotFound when there is none.
+	Get(id string) (Operation, error)
+}
+
+// operationStore storage of operations.
+var operationStore OperationStore = NewMemoryOperationStore()
+
+// RegisterOperationStore registers the storage of operations. A MemoryOperationStore is used by default, which
+// only works when clients poll the instance running the operation.
+func RegisterOperationStore(store OperationStore) {
+	operationStore = store
+}
+
+// OperationProgress reports the progress of a running operation.
+type OperationProgress struct {
+	mu        sync.Mutex
+	operation Operation
+	savedAt   time.Time
+}
+
+// ID returns the operation ID.
+func (p *OperationProgress) ID() string {
+	return p.operation.ID
+}
+
+// SetTotal sets the number of items to process.
+func (p *OperationProgress) SetTotal(total int64) {
+	p.mu.Lock()
+	p.operation.Total = total
+	p.mu.Unlock()
+	p.save(true)
+}
+
+// Add counts processed items. Progress is saved at most once a second.
+func (p *OperationProgress) Add(processed int64) {
+	p.mu.Lock()
+	p.operation.Processed += processed
+	p.mu.Unlock()
+	p.save(false)
+}
+
+// SetResultURL sets the download URL of the operation's file.
+func (p *OperationProgress) SetResultURL(url string) {
+	p.mu.Lock()
+	p.operation.ResultURL = url
+	p.mu.Unlock()
+}
+
+// save stores the operation, unless it was stored less than operationSaveInterval ago and force is false.
+func (p *OperationProgress) save(force bool) {
+	p.mu.Lock()
+	now := time.Now()
+	if !force && now.Sub(p.savedAt) < operationSaveInterval {
+		p.mu.Unlock()
+
+		return
+	}
+	p.savedAt = now
+	p.operation.UpdatedAt = now.UTC()
+	operation := p.operation
+	p.mu.Unlock()
+
+	if err := operationStore.Save(operation); err != nil {
+		log.Errorf("Operation save error: %v", err)
+	}
+}
+
+// finish stores the final state of the operation.
+func (p *OperationProgress) finish(result any, err error) {
+	p.mu.Lock()
+	if err != nil {
+		log.Errorf("Operation %s %s failed: %v", p.operation.Kind, p.operation.ID, err)
+		p.operation.Status = OperationFailed
+		p.operation.Error = "Operation failed"
+		var operationError *OperationError
+		if errors.As(err, &operationError) {
+			p.operation.Error = operationError.Message
+		}
+	} else {
+		p.operation.Status = OperationSucceeded
+		p.operation.Result = result
+	}
+	p.mu.Unlock()
+
+	p.save(true)
+}
+
+// OperationFunc work of an operation. The context carries the snapshot of the request which started it
+// (see SnapshotFrom); the returned result is set to Operation.Result.
+type OperationFunc func(ctx context.Context, progress *OperationProgress) (result any, err error)
+
+// StartOperation runs the work in the background and responds 202 Accepted with the Operation, and its
+// endpoint in the Location header. Clients poll the operation (see OperationApi) until it is finished. The
+// operation belongs to the caller (see CallerIdentity) which started it. Errors of the work are shown to clients
+// as OperationError messages only.
+//
+// Example Usage:
+//
+//	func (h RebuildIndexApi) Handle(c *core.Ctx) error {
+//		return http.StartOperation(c, "reindex", func(ctx context.Context, progress *http.OperationProgress) (any, error) {
+//			return nil, searchIndex.Rebuild(ctx, progress.Add)
+//		})
+//	}
+func StartOperation(c *core.Ctx, kind string, work OperationFunc) error {
+	snapshot, err := SnapshotCtx(c)
+	if err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to start operation",
+		}, core.StatusInternalServerError, "Operation snapshot error: %v", err)
+	}
+
+	now := time.Now().UTC()
+	progress := &OperationProgress{operation: Operation{
+		ID:        newOperationID(),
+		Kind:      kind,
+		Status:    OperationPending,
+		Owner:     CallerIdentity(c),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+	if err := operationStore.Save(progress.operation); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to start operation",
+		}, core.StatusInternalServerError, "Operation save error: %v", err)
+	}
+	accepted := progress.operation
+	accepted.Owner = ""
+
+	go func() {
+		ctx := RestoreCtx(context.Background(), snapshot)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				progress.finish(nil, fmt.Errorf("panic: %v", recovered))
+			}
+		}()
+
+		progress.mu.Lock()
+		progress.operation.Status = OperationRunning
+		progress.mu.Unlock()
+		progress.save(true)
+
+		result, err := work(ctx, progress)
+		progress.finish(result, err)
+	}()
+
+	c.SetHeader(core.HeaderLocation, OperationsPath+"/"+accepted.ID)
+
+	return c.Status(core.StatusAccepted).JSON(accepted)
+}
+
+// newOperationID returns a random operation ID of 128 bits.
+func newOperationID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// OperationApi handler answering with the operation of the `id` path parameter.
+//
+// Example Usage:
+//
+//	apiRouter.GET("/operations/{id}", http.NewOperationApi())
+type OperationApi struct {
+	core.Endpoint
+}
+
+// NewOperationApi creates the handler answering with operations.
+func NewOperationApi() *OperationApi {
+	return &OperationApi{}
+}
+
+// Handle responds with the operation; 404 when there is none, or when it was started by another caller (see
+// CallerIdentity).
+func (h *OperationApi) Handle(c *core.Ctx) error {
+	operation, err := operationStore.Get(c.PathVal("id"))
+	if err == nil && operation.Owner != CallerIdentity(c) {
+		err = ErrOperationNotFound
+	}
+	if err != nil {
+		if !errors.Is(err, ErrOperationNotFound) {
+			log.Errorf("Operation load error: %v", err)
+		}
+
+		return WriteError(c, &Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+	operation.Owner = ""
+
+	return c.Success(operation)
+}
+
+// memoryOperationPurgeSize number of stored operations from which expired ones are purged on save.
+const memoryOperationPurgeSize = 1000
+
+// MemoryOperationStore in-memory OperationStore for development and single-instance deployments.
+type MemoryOperationStore struct {
+	mu         sync.Mutex
+	operations map[string]Operation
+}
+
+// NewMemoryOperationStore creates an in-memory OperationStore.
+func NewMemoryOperationStore() *MemoryOperationStore {
+	return &MemoryOperationStore{operations: map[string]Operation{}}
+}
+
+// Save stores an operation. Operations finished for longer than OperationRetention are purged once the
+// store is large.
+func (s *MemoryOperationStore) Save(operation Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.operations) >= memoryOperationPurgeSize {
+		expiredAt := time.Now().Add(-CurrentConfig().OperationRetention)
+		for id, stored := range s.operations {
+			if stored.Finished() && stored.UpdatedAt.Before(expiredAt) {
+				delete(s.operations, id)
+			}
+		}
+	}
+	s.operations[operation.ID] = operation
+
+	return nil
+}
+
+// Get returns an operation.
+func (s *MemoryOperationStore) Get(id string) (Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operation, ok := s.operations[id]
+	if !ok {
+		return Operation{}, ErrOperationNotFound
+	}
+
+	return operation, nil
+}
@@ -0,0 +1,275 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/validation"
+)
+
+// ====================================================================
+// ========================= Optional Values ==========================
+// ====================================================================
+
+// Optional is a value of a request field distinguishing three states: omitted (Set is false),
+// explicitly null (Null is true, "clear this column") and set to a value.
+//
+// Example:
+//
+//	type UpdateUserRequest struct {
+//		ID       int                    `json:"-"`
+//		Nickname http.Optional[string] `json:"nickname" validate:"omitempty,max=30"`
+//	}
+type Optional[T any] struct {
+	Value T    // Value sent by the client
+	Set   bool // Field present in the request
+	Null  bool // Field explicitly set to null
+}
+
+// Some creates an Optional holding a value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// Get returns the value and whether the field holds a non-null value.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set && !o.Null
+}
+
+// IsZero reports an omitted field, for the `omitzero` JSON option.
+func (o Optional[T]) IsZero() bool {
+	return !o.Set
+}
+
+// UnmarshalJSON is only called for fields present in the document, which marks them as Set.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	o.Value, o.Set, o.Null = zero, true, false
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON encodes omitted and null values as null.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.Value)
+}
+
+// validationValue returns the value checked by validation rules, nil when omitted or null.
+func (o Optional[T]) validationValue() any {
+	if !o.Set || o.Null {
+		return nil
+	}
+
+	return o.Value
+}
+
+// optionalValue implemented by all Optional types.
+type optionalValue interface {
+	validationValue() any
+}
+
+// registerOptionalTypes lets validation rules check the value of common Optional types instead of the struct.
+// It runs lazily, once applications have registered their own rules with validation.AddRule.
+var registerOptionalTypes = sync.OnceFunc(func() {
+	validation.ValidatorInstance().RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if optional, ok := field.Interface().(optionalValue); ok {
+			return optional.validationValue()
+		}
+
+		return nil
+	},
+		Optional[string]{}, Optional[int]{}, Optional[int64]{}, Optional[float64]{}, Optional[bool]{},
+		Optional[Date]{}, Optional[TimeOfDay]{}, Optional[Money]{}, Optional[PublicID]{},
+	)
+})
+
+// ====================================================================
+// ============================ Field Mask ============================
+// ====================================================================
+
+// FieldMask fields present in the body of an update request.
+type FieldMask struct {
+	Fields  []string // JSON names of all fields present in the body
+	Cleared []string // JSON names of nullable fields (pointers, slices, maps, Optional) explicitly set to null
+}
+
+// Has checks the field was sent.
+func (m FieldMask) Has(field string) bool {
+	return slices.Contains(m.Fields, field)
+}
+
+// IsCleared checks the field was explicitly set to null.
+func (m FieldMask) IsCleared(field string) bool {
+	return slices.Contains(m.Cleared, field)
+}
+
+// GetFieldMask returns the field mask stored by ProcessUpdateData, ProcessPatchData and ProcessJSONPatch.
+func GetFieldMask(c *core.Ctx) FieldMask {
+	mask, _ := c.GetData(FieldMaskKey).(FieldMask)
+
+	return mask
+}
+
+// ClearedFields returns the fields of the update request explicitly set to null, i.e. the columns to clear.
+//
+// Example Usage:
+//
+//	for _, field := range http.ClearedFields(c) {
+//		columns[field] = nil
+//	}
+func ClearedFields(c *core.Ctx) []string {
+	return GetFieldMask(c).Cleared
+}
+
+// ChangedFields returns the fields present in the body of the update request (ProcessUpdateData,
+// ProcessPatchData, ProcessJSONPatch), i.e. the only columns an UPDATE statement should touch. Omitted fields
+// keep their stored value, even when the DTO holds their zero value. Fields are named by their JSON name whatever
+// the case of the body's keys, and keys which are not fields of the DTO (`json:"-"` ones included) are ignored.
+//
+// Example Usage:
+//
+//	if !slices.Contains(http.ChangedFields(c), "email") {
+//		// Skip the e-mail verification
+//	}
+func ChangedFields(c *core.Ctx) []string {
+	return GetFieldMask(c).Fields
+}
+
+// ChangedValues returns the values of the changed fields (see ChangedFields) of the request DTO stored by the
+// Process helpers, keyed by JSON name. Optional values are unwrapped, cleared fields are nil.
+//
+// Example Usage:
+//
+//	requestData := c.GetData(http.RequestKey).(*dto.UpdateUser)
+//	err := userRepository.UpdateColumns(requestData.ID, http.ChangedValues(c))
+//	// PATCH /users/42 {"nickname":null,"age":30}
+//	// -> UPDATE users SET age = 30, nickname = NULL WHERE id = 42
+func ChangedValues(c *core.Ctx) map[string]any {
+	mask := GetFieldMask(c)
+	values := make(map[string]any, len(mask.Fields))
+
+	val := reflect.ValueOf(c.GetData(RequestKey))
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return values
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return values
+	}
+
+	for _, name := range mask.Fields {
+		field, _, ok := jsonFieldValue(val, name)
+		if !ok || !field.CanInterface() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			if field.IsNil() {
+				values[name] = nil
+
+				continue
+			}
+		default:
+		}
+
+		if optional, isOptional := field.Interface().(optionalValue); isOptional {
+			values[name] = optional.validationValue()
+
+			continue
+		}
+		values[name] = field.Interface()
+	}
+
+	return values
+}
+
+// buildFieldMask lists the top-level fields of a JSON object body and the nullable fields of target set to null.
+func buildFieldMask(body []byte, target any) FieldMask {
+	var mask FieldMask
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil {
+		return mask
+	}
+
+	val := reflect.ValueOf(target)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem()).Elem()
+			continue
+		}
+		val = val.Elem()
+	}
+
+	for name, raw := range document {
+		if val.Kind() != reflect.Struct {
+			mask.Fields = append(mask.Fields, name)
+
+			continue
+		}
+
+		// Keys are matched like encoding/json does, fields of the DTO only
+		field, jsonName, ok := jsonFieldValue(val, name)
+		if !ok || slices.Contains(mask.Fields, jsonName) {
+			continue
+		}
+		mask.Fields = append(mask.Fields, jsonName)
+
+		if !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) || !field.CanInterface() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			mask.Cleared = append(mask.Cleared, jsonName)
+		default:
+			if _, isOptional := field.Interface().(optionalValue); isOptional {
+				mask.Cleared = append(mask.Cleared, jsonName)
+			}
+		}
+	}
+
+	slices.Sort(mask.Fields)
+	slices.Sort(mask.Cleared)
+
+	return mask
+}
+
+// jsonFieldValue returns the field of a struct value a JSON key decodes into (see jsonField) with its JSON name,
+// false for unknown keys and fields of nil embedded struct pointers.
+func jsonFieldValue(val reflect.Value, key string) (reflect.Value, string, bool) {
+	field, ok := jsonField(val.Type(), key)
+	if !ok {
+		return reflect.Value{}, "", false
+	}
+
+	value, err := val.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return value, name, true
+}
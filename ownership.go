@@ -0,0 +1,86 @@
+package http
+
+import (
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================= Ownership ============================
+// ====================================================================
+
+// AccessDeniedStatus HTTP status of the Error returned when the OwnershipPolicy denies access: 403 FORBIDDEN,
+// or 404 NOT_FOUND so callers can not tell the rows of others from missing ones.
+var AccessDeniedStatus = core.StatusForbidden
+
+// OwnershipPolicy decides whether the principal of a request may touch the row of the path ID, centralizing the
+// "can this user modify this row" checks of update and delete endpoints.
+type OwnershipPolicy interface {
+	// Owns reports whether the principal (see CallerIdentity) may access the resource of the ID. The route of
+	// the request (see RoutePath) tells the resource apart.
+	Owns(c *core.Ctx, principal string, id int) (bool, error)
+}
+
+// OwnershipPolicyFunc adapter to use a function as an OwnershipPolicy.
+type OwnershipPolicyFunc func(c *core.Ctx, principal string, id int) (bool, error)
+
+// Owns calls f(c, principal, id).
+func (f OwnershipPolicyFunc) Owns(c *core.Ctx, principal string, id int) (bool, error) {
+	return f(c, principal, id)
+}
+
+// ownershipPolicy policy checked by ProcessPathID, ProcessUpdateData and ProcessRequest, no check when nil.
+var ownershipPolicy OwnershipPolicy
+
+// RegisterOwnershipPolicy registers the policy checked by ProcessPathID, ProcessUpdateData and ProcessRequest
+// (for DTOs binding the `id` path parameter) once the path ID is extracted. Denied requests are answered with AccessDeniedStatus, before the body is parsed.
+//
+// Example Usage:
+//
+//	http.RegisterOwnershipPolicy(http.OwnershipPolicyFunc(func(c *core.Ctx, principal string, id int) (bool, error) {
+//		switch http.RoutePath(c) {
+//		case "/api/v1/orders/{id}":
+//			return orderRepository.IsOwnedBy(id, principal)
+//		default:
+//			return true, nil
+//		}
+//	}))
+func RegisterOwnershipPolicy(policy OwnershipPolicy) {
+	ownershipPolicy = policy
+}
+
+// CheckOwnership checks the registered OwnershipPolicy allows the request to access the resource of the ID, and
+// writes the error response when it does not: AccessDeniedStatus when denied, 500 when the policy fails.
+//
+// Example Usage:
+//
+//	func (h ArchiveOrderApi) Validate(c *core.Ctx) error {
+//		id, _ := http.PathID(c, "order_id")
+//		return http.CheckOwnership(c, id)
+//	}
+func CheckOwnership(c *core.Ctx, id int) error {
+	if ownershipPolicy == nil {
+		return nil
+	}
+
+	owned, err := ownershipPolicy.Owns(c, CallerIdentity(c), id)
+	if err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to check access",
+		}, core.StatusInternalServerError, "Ownership policy error: %v", err)
+	}
+	if owned {
+		return nil
+	}
+
+	if status := RequestConfig(c).AccessDeniedStatus; status == core.StatusNotFound {
+		return c.Error(&Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, status)
+	}
+
+	return c.Error(&Error{
+		Code:    "FORBIDDEN",
+		Message: "Access to the resource is not allowed",
+	}, core.StatusForbidden)
+}
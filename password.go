@@ -0,0 +1,174 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gflydev/core/log"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ====================================================================
+// ========================= Password Hashing =========================
+// ====================================================================
+
+const (
+	// PasswordBcrypt bcrypt password hashing algorithm.
+	PasswordBcrypt = "bcrypt"
+	// PasswordArgon2id argon2id password hashing algorithm, hashes are encoded in PHC string format.
+	PasswordArgon2id = "argon2id"
+)
+
+// ErrUnknownPasswordHash returned when a hash was not produced by a supported algorithm.
+var ErrUnknownPasswordHash = errors.New("unknown password hash format")
+
+// PasswordPolicy parameters used to hash passwords.
+// Hashes produced with other parameters or another algorithm are upgraded by VerifyPassword.
+type PasswordPolicy struct {
+	Algorithm     string // Preferred algorithm: PasswordBcrypt or PasswordArgon2id
+	BcryptCost    int    // bcrypt cost factor
+	Argon2Memory  uint32 // argon2id memory in KiB
+	Argon2Time    uint32 // argon2id number of iterations
+	Argon2Threads uint8  // argon2id degree of parallelism
+	Argon2KeyLen  uint32 // argon2id length of the derived key in bytes
+	Argon2SaltLen uint32 // argon2id length of the random salt in bytes
+}
+
+// DefaultPasswordPolicy bcrypt with the default cost, argon2id with the RFC 9106 second recommended option.
+var DefaultPasswordPolicy = PasswordPolicy{
+	Algorithm:     PasswordBcrypt,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Memory:  64 * 1024,
+	Argon2Time:    3,
+	Argon2Threads: 4,
+	Argon2KeyLen:  32,
+	Argon2SaltLen: 16,
+}
+
+// passwordPolicy the policy used by HashPassword and VerifyPassword.
+var passwordPolicy = DefaultPasswordPolicy
+
+// RegisterPasswordPolicy registers the policy used to hash and upgrade passwords.
+// Once a configuration snapshot is loaded (see ReloadConfig), the policy of the snapshot is replaced.
+func RegisterPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy = policy
+	if configSnapshot.Load() != nil {
+		if err := UpdateConfig(func(config *Config) { config.PasswordPolicy = policy }); err != nil {
+			log.Errorf("Password policy not registered: %v", err)
+		}
+	}
+}
+
+// HashPassword hashes a password with the algorithm (the policy's preferred algorithm when empty).
+func HashPassword(password string, algorithm ...string) (string, error) {
+	policy := CurrentConfig().PasswordPolicy
+	algo := policy.Algorithm
+	if len(algorithm) > 0 && algorithm[0] != "" {
+		algo = algorithm[0]
+	}
+
+	switch algo {
+	case PasswordBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), policy.BcryptCost)
+
+		return string(hash), err
+	case PasswordArgon2id:
+		salt := make([]byte, policy.Argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, policy.Argon2Time, policy.Argon2Memory,
+			policy.Argon2Threads, policy.Argon2KeyLen)
+
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			policy.Argon2Memory, policy.Argon2Time, policy.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+
+	return "", fmt.Errorf("unsupported password algorithm %q", algo)
+}
+
+// VerifyPassword checks a password against a bcrypt or argon2id hash.
+// When the password is valid but the hash does not match the current policy (algorithm, cost or parameters),
+// a new hash is returned so the caller can store it; otherwise newHash is empty.
+//
+// Example Usage:
+//
+//	valid, newHash, err := http.VerifyPassword(req.Password, user.Password)
+//	if valid && newHash != "" {
+//		user.Password = newHash // Upgrade-on-verify
+//	}
+func VerifyPassword(password, hash string) (valid bool, newHash string, err error) {
+	var outdated bool
+	policy := CurrentConfig().PasswordPolicy
+
+	switch {
+	case strings.HasPrefix(hash, "$2"):
+		if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, "", nil
+			}
+
+			return false, "", err
+		}
+
+		cost, _ := bcrypt.Cost([]byte(hash))
+		outdated = policy.Algorithm != PasswordBcrypt || cost != policy.BcryptCost
+	case strings.HasPrefix(hash, "$argon2id$"):
+		var params string
+		if valid, params, err = verifyArgon2id(password, hash); !valid || err != nil {
+			return false, "", err
+		}
+
+		outdated = policy.Algorithm != PasswordArgon2id || params != fmt.Sprintf("m=%d,t=%d,p=%d",
+			policy.Argon2Memory, policy.Argon2Time, policy.Argon2Threads)
+	default:
+		return false, "", ErrUnknownPasswordHash
+	}
+
+	if outdated {
+		if newHash, err = HashPassword(password); err != nil {
+			// The password is valid, failing to upgrade the hash must not fail the verification
+			return true, "", nil
+		}
+	}
+
+	return true, newHash, nil
+}
+
+// verifyArgon2id checks a password against a PHC encoded argon2id hash and returns its parameters.
+func verifyArgon2id(password, hash string) (bool, string, error) {
+	// $argon2id$v=19$m=65536,t=3,p=4$salt$key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, "", ErrUnknownPasswordHash
+	}
+
+	var version int
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, "", ErrUnknownPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, "", ErrUnknownPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, "", ErrUnknownPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, "", ErrUnknownPasswordHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key))) // #nosec G115
+
+	return subtle.ConstantTimeCompare(key, computed) == 1, parts[3], nil
+}
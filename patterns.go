@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ====================================================================
+// ========================= Guarded Patterns =========================
+// ====================================================================
+
+// Go's regexp package is RE2-based: matching runs in time linear in the input, without backtracking, so the
+// catastrophic patterns of backtracking engines (e.g. "(a+)+$") can not hang a request. What remains bounded
+// by the pattern is the cost per input byte and the memory of the compiled program, which the limits below
+// cap for patterns registered by projects.
+var (
+	// MaxPatternLength maximum length of a registered pattern's source.
+	MaxPatternLength = 512
+	// MaxPatternInstructions maximum size of a registered pattern's compiled program. Counted repetitions
+	// are expanded by the compiler, e.g. "[a-z]{1,1000}" compiles to 2,001 instructions.
+	MaxPatternInstructions = 2000
+	// MaxPatternInput maximum number of bytes of a value matched by a registered pattern.
+	MaxPatternInput = 64 << 10
+)
+
+// ErrUnsafePattern is returned by CompilePattern for patterns exceeding the limits.
+var ErrUnsafePattern = errors.New("unsafe pattern")
+
+// CompilePattern compiles a regular expression like regexp.Compile, and rejects patterns longer than
+// MaxPatternLength or compiling to more than MaxPatternInstructions instructions. Use it for any pattern
+// coming from configuration, e.g. CORS AllowOriginPatterns.
+//
+// Example Usage:
+//
+//	pattern, err := http.CompilePattern(os.Getenv("CORS_ORIGIN_PATTERN"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrUnsafePattern, len(pattern), MaxPatternLength)
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	program, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	if len(program.Inst) > MaxPatternInstructions {
+		return nil, fmt.Errorf("%w: %q compiles to %d instructions, at most %d allowed", ErrUnsafePattern, pattern,
+			len(program.Inst), MaxPatternInstructions)
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// sanitizePattern pattern replacement applied by a `sanitize` tag rule.
+type sanitizePattern struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+var (
+	patternsMu sync.RWMutex
+	// patterns named patterns checked by the "pattern" validation rule.
+	patterns = map[string]*regexp.Regexp{}
+	// sanitizePatterns named replacements applied by `sanitize` tag rules.
+	sanitizePatterns = map[string]sanitizePattern{}
+)
+
+// RegisterPattern registers a named pattern checked by the "pattern" validation rule. The pattern is compiled
+// with CompilePattern, values longer than MaxPatternInput fail the rule without being matched.
+//
+// Example Usage:
+//
+//	if err := http.RegisterPattern("sku", `^[A-Z]{3}-\d{4,8}$`); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	SKU string `json:"sku" validate:"required,pattern=sku"`
+func RegisterPattern(name, pattern string) error {
+	compiled, err := CompilePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+
+	patterns[name] = compiled
+
+	return nil
+}
+
+// RegisterSanitizePattern registers a `sanitize` tag rule replacing the matches of a pattern, with
+// regexp.ReplaceAllString's expansion of the replacement ($1, ${name}). The pattern is compiled with
+// CompilePattern; values longer than MaxPatternInput are cut to that length before being matched.
+// Rules of SanitizeStruct (slug, upper, phone, ...) can not be replaced.
+//
+// Example Usage:
+//
+//	if err := http.RegisterSanitizePattern("no_urls", `(?i)https?://\S+`, ""); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	Bio string `json:"bio" sanitize:"no_urls"`
+func RegisterSanitizePattern(name, pattern, replacement string) error {
+	if _, ok := normalizers[name]; ok || name == "slug" {
+		return fmt.Errorf("sanitize rule %s is built in", name)
+	}
+
+	compiled, err := CompilePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+
+	sanitizePatterns[name] = sanitizePattern{pattern: compiled, replacement: replacement}
+
+	return nil
+}
+
+// applySanitizePattern applies the registered sanitize pattern of a rule to a value.
+func applySanitizePattern(name, value string) (string, bool) {
+	patternsMu.RLock()
+	rule, ok := sanitizePatterns[name]
+	patternsMu.RUnlock()
+	if !ok {
+		return value, false
+	}
+
+	return rule.pattern.ReplaceAllString(truncateInput(value), rule.replacement), true
+}
+
+// truncateInput cuts a value to MaxPatternInput bytes, without leaving a partial rune.
+func truncateInput(value string) string {
+	maxInput := CurrentConfig().MaxPatternInput
+	if len(value) <= maxInput {
+		return value
+	}
+
+	return strings.ToValidUTF8(value[:maxInput], "")
+}
+
+// PatternRule custom validation rule checking a string field matches a pattern registered with RegisterPattern.
+// Unknown pattern names fail the rule.
+//
+//	SKU string `json:"sku" validate:"required,pattern=sku"`
+type PatternRule string
+
+func (v PatternRule) GetTag() string {
+	return string(v)
+}
+
+func (v PatternRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		patternsMu.RLock()
+		pattern, ok := patterns[fl.Param()]
+		patternsMu.RUnlock()
+
+		value := fl.Field().String()
+
+		return ok && len(value) <= CurrentConfig().MaxPatternInput && pattern.MatchString(value)
+	}
+}
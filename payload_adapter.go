@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+	"gopkg.in/yaml.v3"
+)
+
+// ====================================================================
+// ========================= Payload Adapters =========================
+// ====================================================================
+
+// PayloadAdapter converts a request body of a legacy format (XML, CSV, form, ...) into the canonical JSON
+// of the DTO, so it flows through the usual decoding, sanitization and validation.
+type PayloadAdapter func(c *core.Ctx, body []byte) ([]byte, error)
+
+var (
+	payloadAdaptersMu sync.RWMutex
+	// payloadAdapters adapters by DTO type (any for every DTO) and media type. YAML and MessagePack bodies are
+	// converted by default.
+	payloadAdapters = map[reflect.Type]map[string]PayloadAdapter{
+		reflect.TypeFor[any](): {
+			"application/yaml":   yamlToJSON,
+			"application/x-yaml": yamlToJSON,
+			"text/yaml":          yamlToJSON,
+			"text/x-yaml":        yamlToJSON,
+
+			MIMEApplicationMsgpack:  msgpackToJSON,
+			"application/x-msgpack": msgpackToJSON,
+		},
+	}
+)
+
+// RegisterPayloadAdapter registers the adapter of bodies of the media type for the DTO T. Adapters
+// registered for T = any apply to every DTO without a specific one.
+//
+// Example Usage:
+//
+//	http.RegisterPayloadAdapter[dto.CreateOrder]("application/xml", func(c *core.Ctx, body []byte) ([]byte, error) {
+//		var legacy legacyOrderXML
+//		if err := xml.Unmarshal(body, &legacy); err != nil {
+//			return nil, err
+//		}
+//		return json.Marshal(legacy.toCreateOrder())
+//	})
+func RegisterPayloadAdapter[T any](mediaType string, adapter PayloadAdapter) {
+	typ := reflect.TypeFor[T]()
+
+	payloadAdaptersMu.Lock()
+	defer payloadAdaptersMu.Unlock()
+
+	if payloadAdapters[typ] == nil {
+		payloadAdapters[typ] = map[string]PayloadAdapter{}
+	}
+	payloadAdapters[typ][strings.ToLower(mediaType)] = adapter
+}
+
+// adaptPayload converts the request body with the adapter registered for the DTO type and the request's
+// media type, if any. The request body is rewritten as JSON. It is called by Parse.
+func adaptPayload(c *core.Ctx, typ reflect.Type) *Error {
+	mediaType, _, err := mime.ParseMediaType(string(c.Root().Request.Header.ContentType()))
+	if err != nil {
+		return nil
+	}
+
+	payloadAdaptersMu.RLock()
+	adapter, ok := payloadAdapters[typ][mediaType]
+	if !ok {
+		adapter, ok = payloadAdapters[reflect.TypeFor[any]()][mediaType]
+	}
+	payloadAdaptersMu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	body, err := adapter(c, c.Root().PostBody())
+	if err != nil {
+		return &Error{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Invalid " + mediaType + " payload: " + err.Error(),
+		}
+	}
+
+	c.Root().Request.SetBody(body)
+	c.Root().Request.Header.SetContentType(core.MIMEApplicationJSON)
+
+	return nil
+}
+
+// yamlToJSON PayloadAdapter of YAML bodies, a YAML document is decoded then encoded as JSON. The DTO's `json`
+// tags and the rest of the JSON decoding (aliases, strict numbers, field masks) apply as is.
+func yamlToJSON(_ *core.Ctx, body []byte) ([]byte, error) {
+	var document any
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+
+	// Mappings with non-string keys can not be represented in JSON
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.New("mapping keys must be strings")
+	}
+
+	return encoded, nil
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ======================== Payload Encryption ========================
+// ====================================================================
+
+// Payload encryption headers: the client sends the ID of its registered public key, the response
+// tells the encryption applied to Data.
+const (
+	HeaderEncryptionKeyID   = "X-Encryption-Key-ID"
+	HeaderPayloadEncryption = "X-Payload-Encryption"
+)
+
+// PayloadEncryption JWE algorithms of encrypted payloads: RSA-OAEP-256 key encryption, A256GCM content encryption.
+const PayloadEncryption = "RSA-OAEP-256+A256GCM"
+
+// ErrUnknownClientKey returned by ClientKeyStore when no public key has the ID.
+var ErrUnknownClientKey = errors.New("unknown client key")
+
+// ClientKeyStore is an interface for the registry of client public keys, exchanged out of band.
+type ClientKeyStore interface {
+	// PublicKey returns the public key of the ID, ErrUnknownClientKey when there is none.
+	PublicKey(keyID string) (*rsa.PublicKey, error)
+}
+
+// ClientKeys in-memory ClientKeyStore by key ID.
+type ClientKeys map[string]*rsa.PublicKey
+
+// PublicKey returns the public key of the ID.
+func (keys ClientKeys) PublicKey(keyID string) (*rsa.PublicKey, error) {
+	if key, ok := keys[keyID]; ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownClientKey
+}
+
+// clientKeyStore registry of client public keys, payload encryption is unavailable when nil.
+var clientKeyStore ClientKeyStore
+
+// RegisterClientKeyStore registers the registry of the public keys clients receive encrypted payloads with.
+//
+// Example Usage:
+//
+//	http.RegisterClientKeyStore(http.ClientKeys{"partner-a-2024": partnerPublicKey})
+func RegisterClientKeyStore(store ClientKeyStore) {
+	clientKeyStore = store
+}
+
+// payloadKey public key of the request's encrypted payload.
+type payloadKey struct {
+	id  string
+	key *rsa.PublicKey
+}
+
+// EncryptSuccess struct to describe a success response whose data is encrypted.
+// @Description Success response with Data encrypted as a JWE compact serialization
+// @Message Message is a success message that describes the operation.
+// @Data Data is the JWE (RSA-OAEP-256, A256GCM) of the JSON data for the client's public key.
+// @Warnings Warnings is optional and contains non-fatal notices about the operation.
+// @Tags Success Responses
+type EncryptSuccess struct {
+	Message  string    `json:"message" example:"Operation completed successfully"`
+	Data     string    `json:"data" example:"eyJhbGciOiJSU0EtT0FFUC0yNTYi..." doc:"JWE of the JSON data"`
+	Warnings []Warning `json:"warnings,omitempty" doc:"Non-fatal notices about the operation"`
+}
+
+// EncryptList struct to describe a list response whose data is encrypted.
+// @Description List response with Data encrypted as a JWE compact serialization
+// @Meta Meta contains metadata information for pagination.
+// @Data Data is the JWE (RSA-OAEP-256, A256GCM) of the JSON list for the client's public key.
+// @Warnings Warnings is optional and contains non-fatal notices about the request.
+// @Tags Success Responses
+type EncryptList struct {
+	Meta     Meta      `json:"meta" doc:"Metadata information for pagination"`
+	Data     string    `json:"data" example:"eyJhbGciOiJSU0EtT0FFUC0yNTYi..." doc:"JWE of the JSON list"`
+	Warnings []Warning `json:"warnings,omitempty" doc:"Non-fatal notices about the request"`
+}
+
+// EncryptResponse negotiates the encryption of the response's Data for the public key named by the
+// X-Encryption-Key-ID header: WriteSuccess and WriteList then send Data as a JWE compact serialization
+// (RSA-OAEP-256, A256GCM) which only the client can decrypt. When required, requests without a known
+// key are rejected; otherwise they get plain responses.
+//
+// Example Usage:
+//
+//	func (h GetMedicalRecordApi) Validate(c *core.Ctx) error {
+//		if err := http.EncryptResponse(c, true); err != nil {
+//			return err
+//		}
+//		return http.ProcessPathID(c)
+//	}
+func EncryptResponse(c *core.Ctx, required bool) error {
+	keyID := c.GetHeader(HeaderEncryptionKeyID)
+	if keyID == "" || clientKeyStore == nil {
+		if !required {
+			return nil
+		}
+
+		return c.Error(&Error{
+			Code:    "ENCRYPTION_KEY_REQUIRED",
+			Message: "This endpoint requires the " + HeaderEncryptionKeyID + " header",
+		})
+	}
+
+	key, err := clientKeyStore.PublicKey(keyID)
+	if err != nil {
+		if !errors.Is(err, ErrUnknownClientKey) {
+			return WriteServerError(c, &Error{
+				Message: "Unable to encrypt response",
+			}, core.StatusInternalServerError, "Client key store error: %v", err)
+		}
+
+		return c.Error(&Error{
+			Code:    "ENCRYPTION_KEY_UNKNOWN",
+			Message: "Unknown encryption key " + keyID,
+		})
+	}
+
+	c.SetData(PayloadKeyKey, payloadKey{id: keyID, key: key})
+
+	return nil
+}
+
+// encryptPayload returns the JWE of the value for the request's negotiated key; ok is false when the
+// response is not encrypted.
+func encryptPayload(c *core.Ctx, value any) (jwe string, ok bool, err error) {
+	key, ok := c.GetData(PayloadKeyKey).(payloadKey)
+	if !ok {
+		return "", false, nil
+	}
+
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", true, err
+	}
+
+	jwe, err = EncryptJWE(plaintext, key.key, key.id)
+	if err != nil {
+		return "", true, err
+	}
+	setEncryptionHeaders(&c.Root().Response.Header)
+
+	return jwe, true, nil
+}
+
+// payloadKeyID returns the ID of the key negotiated by EncryptResponse, empty when the response is not encrypted.
+func payloadKeyID(c *core.Ctx) string {
+	key, _ := c.GetData(PayloadKeyKey).(payloadKey)
+
+	return key.id
+}
+
+// setEncryptionHeaders sets the headers of encrypted responses, on cached ones too.
+func setEncryptionHeaders(header *fasthttp.ResponseHeader) {
+	header.Set(HeaderPayloadEncryption, PayloadEncryption)
+	header.Add(core.HeaderVary, HeaderEncryptionKeyID)
+}
+
+// EncryptJWE encrypts the plaintext for the public key as a JWE compact serialization
+// (alg RSA-OAEP-256, enc A256GCM), readable with any JOSE library.
+func EncryptJWE(plaintext []byte, publicKey *rsa.PublicKey, keyID string) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RSA-OAEP-256",
+		"enc": "A256GCM",
+		"kid": keyID,
+		"cty": "application/json",
+	})
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(header)
+
+	contentKey := make([]byte, 32)
+	iv := make([]byte, 12)
+	if _, err := rand.Read(contentKey); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, contentKey, nil)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	// The protected header is authenticated as additional data
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// writeEncryptError reports a response which could not be encrypted.
+func writeEncryptError(c *core.Ctx, err error) error {
+	return WriteServerError(c, &Error{
+		Message: "Unable to encrypt response",
+	}, core.StatusInternalServerError, "Payload encryption error: %v", err)
+}
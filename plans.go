@@ -0,0 +1,119 @@
+package http
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ====================================================================
+// ========================= Validation Plans =========================
+// ====================================================================
+
+// dtoPlan reflection and tag analysis of a struct type, done once per type instead of on every request.
+// The validator caches its own parsing of `validate` tags.
+type dtoPlan struct {
+	name      string            // Type name, e.g. "dto.CreateUser"
+	labels    map[string]string // Labels of the type's validation metrics, read-only
+	walk      []int             // Exported fields which may hold strings to sanitize
+	tagged    []taggedField     // String fields with sanitize rules
+	sanitizer bool              // The type implements Sanitizer with a pointer receiver
+	defaults  []defaultField    // Fields with a `default` tag
+	nested    []int             // Struct fields (or pointers to structs) defaults are looked for in
+}
+
+// taggedField string field with the normalizers implied by its `validate` tag and its `sanitize` tag rules.
+type taggedField struct {
+	index       int
+	normalizers []func(string) string
+	rules       []sanitizeRule
+}
+
+// sanitizeRule rule of a `sanitize` tag, e.g. slug=title.
+type sanitizeRule struct {
+	name, param string
+}
+
+var (
+	// dtoPlans plans by struct type.
+	dtoPlans sync.Map
+
+	sanitizerType = reflect.TypeFor[Sanitizer]()
+)
+
+// planOf returns the plan of a struct type.
+func planOf(typ reflect.Type) *dtoPlan {
+	if plan, ok := dtoPlans.Load(typ); ok {
+		return plan.(*dtoPlan)
+	}
+
+	plan := &dtoPlan{
+		name:      typ.String(),
+		sanitizer: reflect.PointerTo(typ).Implements(sanitizerType),
+	}
+	plan.labels = map[string]string{"dto": plan.name}
+	plan.defaults, plan.nested = parseDefaults(typ)
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if mayHoldStrings(field.Type, map[reflect.Type]bool{typ: true}) {
+			plan.walk = append(plan.walk, i)
+		}
+
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		tagged := taggedField{index: i}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if normalize, ok := normalizers[strings.TrimSpace(rule)]; ok {
+				tagged.normalizers = append(tagged.normalizers, normalize)
+			}
+		}
+		if tag := field.Tag.Get("sanitize"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				tagged.rules = append(tagged.rules, sanitizeRule{name: name, param: param})
+			}
+		}
+		if len(tagged.normalizers) > 0 || len(tagged.rules) > 0 {
+			plan.tagged = append(plan.tagged, tagged)
+		}
+	}
+
+	actual, _ := dtoPlans.LoadOrStore(typ, plan)
+
+	return actual.(*dtoPlan)
+}
+
+// mayHoldStrings checks values of a type may hold strings or Sanitizer values reached by SanitizeStruct.
+// Types being analyzed (recursive types) are assumed to.
+func mayHoldStrings(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	switch typ.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return mayHoldStrings(typ.Elem(), seen)
+	case reflect.Map:
+		// Only string values of maps are sanitized
+		return typ.Elem().Kind() == reflect.String
+	case reflect.Struct:
+		if seen[typ] || reflect.PointerTo(typ).Implements(sanitizerType) {
+			return true
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		for i := 0; i < typ.NumField(); i++ {
+			if field := typ.Field(i); field.IsExported() && mayHoldStrings(field.Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
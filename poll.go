@@ -0,0 +1,73 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// =========================== Long Polling ===========================
+// ====================================================================
+
+var (
+	// PollInterval delay between two calls of the check function while a long-poll request is held.
+	PollInterval = 500 * time.Millisecond
+	// PollMaxTimeout upper bound of the timeout accepted by Poll.
+	PollMaxTimeout = 60 * time.Second
+)
+
+// PollCheckFunc checks whether data newer than the watermark is available.
+// It returns the data, the new watermark and whether anything changed.
+type PollCheckFunc[T any] func(watermark int64) (data T, newWatermark int64, changed bool, err error)
+
+// Poll holds the request until checkFn reports new data after the watermark or the timeout elapses.
+// New data is sent as a Success response with `watermark` and `items` in Data; a timeout without change
+// results in 304 Not Modified, letting clients simply reissue the request with the same watermark.
+//
+// Example Usage:
+//
+//	func (h NotificationsPollApi) Handle(c *core.Ctx) error {
+//		watermark, _ := c.QueryInt("watermark")
+//		return http.Poll(c, int64(watermark), 30*time.Second, func(w int64) ([]Notification, int64, bool, error) {
+//			return notificationRepository.After(userID, w)
+//		})
+//	}
+func Poll[T any](c *core.Ctx, watermark int64, timeout time.Duration, checkFn PollCheckFunc[T]) error {
+	config := RequestConfig(c)
+	if timeout <= 0 || timeout > config.PollMaxTimeout {
+		timeout = config.PollMaxTimeout
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		data, newWatermark, changed, err := checkFn(watermark)
+		if err != nil {
+			return WriteServerError(c, &Error{
+				Message: "Unable to check for new data",
+			}, core.StatusInternalServerError, "Poll check error: %v", err)
+		}
+
+		if changed {
+			return WriteSuccess(c, Success{
+				Message: "New data available",
+				Data: core.Data{
+					"watermark": newWatermark,
+					"items":     data,
+				},
+			})
+		}
+
+		select {
+		case <-deadline.C:
+			c.Status(core.StatusNotModified)
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ======================== Debug Serialization =======================
+// ====================================================================
+
+// PrettyParam query parameter requesting indented JSON, e.g. `?pretty=1`.
+const PrettyParam = "pretty"
+
+// DebugAnnotationField top-level field of the debug annotation added to annotated responses.
+const DebugAnnotationField = "_debug"
+
+// DebugOutputOptions configuration of PrettyPrinted.
+type DebugOutputOptions struct {
+	Enabled  bool   // Indents JSON responses of requests with `?pretty=1`
+	Always   bool   // Indents all JSON responses
+	Indent   string // Indentation, two spaces by default
+	Annotate bool   // Adds a "_debug" object (route, status, DTO, validation, latency) to indented responses
+}
+
+// debugOutput options used by PrettyPrinted, pretty-printing is available outside production by default.
+var debugOutput = DebugOutputOptions{
+	Enabled: core.AppEnv != "production",
+}
+
+// RegisterDebugOutput registers the options of PrettyPrinted.
+//
+// Example Usage:
+//
+//	http.RegisterDebugOutput(http.DebugOutputOptions{
+//		Enabled:  os.Getenv("APP_ENV") != "production",
+//		Annotate: true,
+//	})
+func RegisterDebugOutput(options DebugOutputOptions) {
+	debugOutput = options
+}
+
+// DebugAnnotation debug information added to annotated responses.
+type DebugAnnotation struct {
+	Route      string  `json:"route"`         // Method and route path template, e.g. "GET /users/{id}"
+	Status     int     `json:"status"`        // Response status
+	StatusText string  `json:"status_text"`   // Response status text
+	DTO        string  `json:"dto,omitempty"` // Type of the processed request DTO
+	Validation string  `json:"validation"`    // ValidationPassed, ValidationFailed or ValidationSkipped
+	LatencyMs  float64 `json:"latency_ms"`    // Time since the request start, in milliseconds
+}
+
+// prettyHandler handler wrapper indenting JSON responses.
+type prettyHandler struct {
+	core.IHandler
+}
+
+// PrettyPrinted wraps a handler so its JSON responses (List, Success and Error payloads alike) are indented
+// when the request asks with `?pretty=1`, or always, per the registered DebugOutputOptions. Indented
+// responses can be annotated with a "_debug" object for manual debugging.
+//
+// Example Usage:
+//
+//	router.GET("/users", http.PrettyPrinted(api.NewListUsersApi()))
+func PrettyPrinted(handler core.IHandler) core.IHandler {
+	return &prettyHandler{IHandler: handler}
+}
+
+// Validate runs the wrapped Validate, rejected requests are formatted as Handle is not called.
+func (h *prettyHandler) Validate(c *core.Ctx) error {
+	err := h.IHandler.Validate(c)
+	if err != nil {
+		formatResponse(c)
+	}
+
+	return err
+}
+
+// Handle runs the wrapped handler and formats its response.
+func (h *prettyHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	formatResponse(c)
+
+	return err
+}
+
+// WantsPretty checks the response of the request must be indented.
+func WantsPretty(c *core.Ctx) bool {
+	if debugOutput.Always {
+		return true
+	}
+	if !debugOutput.Enabled {
+		return false
+	}
+
+	switch strings.ToLower(c.QueryStr(PrettyParam)) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// formatResponse indents, and annotates when configured, the JSON response of the request.
+func formatResponse(c *core.Ctx) {
+	response := &c.Root().Response
+	if !WantsPretty(c) || !bytes.Contains(response.Header.ContentType(), []byte("json")) {
+		return
+	}
+
+	body := response.Body()
+	if debugOutput.Annotate {
+		body = annotateResponse(c, body)
+	}
+
+	indent := debugOutput.Indent
+	if indent == "" {
+		indent = "  "
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", indent); err != nil {
+		log.Debugf("Pretty print error: %v", err)
+
+		return
+	}
+	indented.WriteByte('\n')
+
+	response.SetBody(indented.Bytes())
+}
+
+// annotateResponse appends the DebugAnnotation to a JSON object body, keeping its field order; other
+// bodies are returned unchanged.
+func annotateResponse(c *core.Ctx, body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return body
+	}
+
+	entry := BuildAccessLog(c, nil)
+	annotation, err := json.Marshal(DebugAnnotation{
+		Route:      entry.Method + " " + entry.Route,
+		Status:     entry.Status,
+		StatusText: fasthttp.StatusMessage(entry.Status),
+		DTO:        entry.DTO,
+		Validation: entry.Validation,
+		LatencyMs:  entry.LatencyMs,
+	})
+	if err != nil {
+		return body
+	}
+
+	annotated := bytes.TrimSpace(trimmed[:len(trimmed)-1])
+	// Full slice so appending copies instead of writing into the response buffer
+	annotated = annotated[:len(annotated):len(annotated)]
+	if len(annotated) > 1 {
+		annotated = append(annotated, ',')
+	}
+	annotated = append(annotated, `"`+DebugAnnotationField+`":`...)
+	annotated = append(annotated, annotation...)
+
+	return append(annotated, '}')
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ======================= Query Normalization ========================
+// ====================================================================
+
+// QueryOptions normalization of the query parameters read by FilterData (ProcessFilter, ProcessFilterAs),
+// BindQuery and BindSources, so small mistakes of hand-written clients do not fail requests. The query string
+// itself is left unchanged: signed URLs and fingerprints keep seeing what the client sent.
+type QueryOptions struct {
+	// CaseInsensitiveKeys matches the parameters whatever the case of their key (`?Per_Page=20`,
+	// `?filter[Status]=new`).
+	CaseInsensitiveKeys bool
+	// TrimSpace ignores the whitespace around keys and values (`?page=2%20`, `?%20keyword=shoes`).
+	TrimSpace bool
+	// DecodeCommas decodes the `%2C` left in values by clients encoding lists twice (`?status=new%252Cpaid`).
+	DecodeCommas bool
+}
+
+// queryOptions options used to read query parameters.
+var queryOptions QueryOptions
+
+// RegisterQueryOptions registers the normalization of the query parameters.
+// Once a configuration snapshot is loaded (see ReloadConfig), the options of the snapshot are replaced.
+//
+// Example Usage:
+//
+//	http.RegisterQueryOptions(http.QueryOptions{
+//		CaseInsensitiveKeys: true,
+//		TrimSpace:           true,
+//		DecodeCommas:        true,
+//	})
+func RegisterQueryOptions(options QueryOptions) {
+	queryOptions = options
+	if configSnapshot.Load() != nil {
+		_ = UpdateConfig(func(config *Config) { config.Query = options })
+	}
+}
+
+// QueryValues returns the values of a query parameter, normalized with the QueryOptions of the request's
+// configuration (see RequestConfig).
+//
+// Example Usage:
+//
+//	// ?Tag=go&tag=%20sql%20
+//	tags := http.QueryValues(c, "tag") // ["go", "sql"] with CaseInsensitiveKeys and TrimSpace
+func QueryValues(c *core.Ctx, name string) []string {
+	options := RequestConfig(c).Query
+	if options == (QueryOptions{}) {
+		var values []string
+		for _, value := range c.Root().QueryArgs().PeekMulti(name) {
+			values = append(values, string(value))
+		}
+
+		return values
+	}
+
+	name = options.normalizeKey(name)
+
+	var values []string
+	c.Root().QueryArgs().VisitAll(func(key, value []byte) {
+		if options.normalizeKey(string(key)) == name {
+			values = append(values, options.normalizeValue(string(value)))
+		}
+	})
+
+	return values
+}
+
+// QueryValue returns the first value of a query parameter normalized as QueryValues does, empty when absent.
+//
+// Example Usage:
+//
+//	sort := http.QueryValue(c, "sort")
+func QueryValue(c *core.Ctx, name string) string {
+	return firstValue(QueryValues(c, name))
+}
+
+// visitQuery calls visitFn with the normalized key and value of each query parameter of the request.
+func visitQuery(c *core.Ctx, visitFn func(key, value string)) {
+	options := RequestConfig(c).Query
+
+	c.Root().QueryArgs().VisitAll(func(key, value []byte) {
+		visitFn(options.normalizeKey(string(key)), options.normalizeValue(string(value)))
+	})
+}
+
+// normalizeKey returns the key parameters are matched by.
+func (o QueryOptions) normalizeKey(key string) string {
+	if o.TrimSpace {
+		key = strings.TrimSpace(key)
+	}
+	if o.CaseInsensitiveKeys {
+		key = strings.ToLower(key)
+	}
+
+	return key
+}
+
+// normalizeValue returns the value of a parameter as read by the helpers.
+func (o QueryOptions) normalizeValue(value string) string {
+	if o.DecodeCommas {
+		value = strings.ReplaceAll(strings.ReplaceAll(value, "%2C", ","), "%2c", ",")
+	}
+	if o.TrimSpace {
+		value = strings.TrimSpace(value)
+	}
+
+	return value
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ============================== Quota ===============================
+// ====================================================================
+
+// Quota headers set by ConsumeQuota.
+const (
+	HeaderQuotaLimit     = "X-Quota-Limit"
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+	HeaderQuotaReset     = "X-Quota-Reset"
+	HeaderQuotaWarning   = "X-Quota-Warning" // Set from the warning threshold of the plan, see QuotaPlan.WarnRatio
+)
+
+// QuotaExceededStatus HTTP status of the Error returned when a quota is exhausted (429, or 402 for paid plans).
+var QuotaExceededStatus = core.StatusTooManyRequests
+
+// Quota struct to describe the quota of the caller for an endpoint class.
+// @Description Remaining quota of the caller for the endpoint class
+// @Class Class is the endpoint class the quota applies to
+// @Limit Limit is the number of requests allowed per period
+// @Remaining Remaining is the number of requests left in the current period
+// @ResetAt ResetAt is the Unix time the quota resets at
+// @Tags Info Responses
+type Quota struct {
+	Class     string `json:"class" example:"search"`
+	Limit     int64  `json:"limit" example:"1000"`
+	Remaining int64  `json:"remaining" example:"998"`
+	ResetAt   int64  `json:"reset_at" example:"1718000000"`
+}
+
+// QuotaPlan limit of an endpoint class.
+type QuotaPlan struct {
+	Limit  int64         // Requests allowed per period
+	Period time.Duration // Accounting period
+
+	// WarnRatio share of the limit (0.8 for 80%) from which responses carry a QUOTA_LOW warning and the
+	// X-Quota-Warning header, so clients slow down before being rejected. Disabled when zero.
+	WarnRatio float64
+	// WarnOnly reports exhausted quotas with a QUOTA_EXCEEDED warning instead of rejecting the requests, e.g.
+	// while a new limit is announced.
+	WarnOnly bool
+}
+
+// QuotaStore is an interface for storages of quota counters (memory, Redis, billing service, ...).
+type QuotaStore interface {
+	// Consume takes one request from the counter of the key for the current period. It returns the requests
+	// left and the end of the period; ok is false when the quota was already exhausted.
+	Consume(key string, plan QuotaPlan) (remaining int64, resetAt time.Time, ok bool, err error)
+}
+
+var (
+	quotaStore QuotaStore = NewMemoryQuotaStore()
+
+	quotaPlansMu sync.RWMutex
+	// quotaPlans plans of the endpoint classes, registered by RegisterQuota.
+	quotaPlans = map[string]QuotaPlan{}
+
+	// quotaCallerFunc identifies the caller of a request, CallerIdentity by default.
+	quotaCallerFunc = CallerIdentity
+)
+
+// RegisterQuotaStore registers the store of quota counters. A MemoryQuotaStore is used by default.
+func RegisterQuotaStore(store QuotaStore) {
+	quotaStore = store
+}
+
+// RegisterQuotaCaller registers the function identifying callers (API key, user or tenant ID, ...),
+// overriding CallerIdentity for quotas.
+func RegisterQuotaCaller(callerFn func(c *core.Ctx) string) {
+	quotaCallerFunc = callerFn
+}
+
+// RegisterQuota registers the plan of an endpoint class.
+//
+// Example Usage:
+//
+//	http.RegisterQuota("search", http.QuotaPlan{Limit: 1000, Period: 24 * time.Hour})
+func RegisterQuota(class string, plan QuotaPlan) {
+	quotaPlansMu.Lock()
+	defer quotaPlansMu.Unlock()
+
+	quotaPlans[class] = plan
+}
+
+// ConsumeQuota takes one request from the caller's quota of the endpoint class. The remaining quota is exposed
+// in X-Quota-* headers and in List responses' Meta; an exhausted quota is rejected with QUOTA_EXCEEDED.
+// The plans of the request's configuration (Config.QuotaPlans, see TenantConfigResolver) replace the registered
+// ones, so warning thresholds and warn-only plans can be set per tenant.
+//
+// Example Usage:
+//
+//	func (h SearchApi) Validate(c *core.Ctx) error {
+//		if err := http.ConsumeQuota(c, "search"); err != nil {
+//			return err
+//		}
+//		return http.ProcessFilter(c)
+//	}
+func ConsumeQuota(c *core.Ctx, class string) error {
+	config := RequestConfig(c)
+	plan, ok := config.QuotaPlans[class]
+	if !ok {
+		quotaPlansMu.RLock()
+		plan, ok = quotaPlans[class]
+		quotaPlansMu.RUnlock()
+	}
+	if !ok {
+		return WriteServerError(c, &Error{
+			Message: "Unable to check quota",
+		}, core.StatusInternalServerError, "Quota class %q is not registered", class)
+	}
+
+	remaining, resetAt, allowed, err := quotaStore.Consume(class+":"+quotaCallerFunc(c), plan)
+	if err != nil {
+		// Metering must not take the API down
+		log.Errorf("Quota store error: %v", err)
+
+		return nil
+	}
+
+	quota := Quota{
+		Class:     class,
+		Limit:     plan.Limit,
+		Remaining: remaining,
+		ResetAt:   resetAt.Unix(),
+	}
+	c.SetData(QuotaKey, quota)
+	c.SetHeader(HeaderQuotaLimit, strconv.FormatInt(quota.Limit, 10))
+	c.SetHeader(HeaderQuotaRemaining, strconv.FormatInt(quota.Remaining, 10))
+	c.SetHeader(HeaderQuotaReset, strconv.FormatInt(quota.ResetAt, 10))
+
+	switch {
+	case !allowed && plan.WarnOnly:
+		warnQuota(c, "QUOTA_EXCEEDED", fmt.Sprintf("Quota of %d requests exceeded for %s, requests will be "+
+			"rejected once the limit is enforced", plan.Limit, class))
+
+		return nil
+	case allowed && plan.WarnRatio > 0 && float64(plan.Limit-remaining) >= plan.WarnRatio*float64(plan.Limit):
+		warnQuota(c, WarningQuotaLow, fmt.Sprintf("%d of %d requests left for %s until %s", remaining, plan.Limit,
+			class, resetAt.UTC().Format(time.RFC3339)))
+	}
+
+	if !allowed {
+		c.SetHeader(core.HeaderRetryAfter, strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+
+		return c.Error(&Error{
+			Code:    "QUOTA_EXCEEDED",
+			Message: fmt.Sprintf("Quota of %d requests exceeded for %s", plan.Limit, class),
+			Data: core.Data{
+				"quota": quota,
+			},
+		}, config.QuotaExceededStatus)
+	}
+
+	return nil
+}
+
+// warnQuota adds a quota warning to the response and its X-Quota-Warning header.
+func warnQuota(c *core.Ctx, code, message string) {
+	c.SetHeader(HeaderQuotaWarning, message)
+	AddWarning(c, code, message)
+}
+
+// GetQuota returns the quota stored by ConsumeQuota, nil when the request is not metered.
+func GetQuota(c *core.Ctx) *Quota {
+	if quota, ok := c.GetData(QuotaKey).(Quota); ok {
+		return &quota
+	}
+
+	return nil
+}
+
+// ====================================================================
+// ======================= Memory Quota Store =========================
+// ====================================================================
+
+// memoryQuotaCounter a counter of MemoryQuotaStore.
+type memoryQuotaCounter struct {
+	used    int64
+	resetAt time.Time
+}
+
+// memoryQuotaPurgeSize number of stored counters from which the ones of past windows are purged.
+const memoryQuotaPurgeSize = 10000
+
+// MemoryQuotaStore in-process QuotaStore using fixed windows. Counters of past windows are purged once the store
+// is large, so callers seen once (e.g. scanners, keyed by IP) do not pile up.
+type MemoryQuotaStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryQuotaCounter
+}
+
+// NewMemoryQuotaStore creates an empty MemoryQuotaStore.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: map[string]*memoryQuotaCounter{}}
+}
+
+// Consume takes one request from the counter of the key for the current period.
+func (s *MemoryQuotaStore) Consume(key string, plan QuotaPlan) (int64, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.counters) >= memoryQuotaPurgeSize {
+		for stored, counter := range s.counters {
+			if !now.Before(counter.resetAt) {
+				delete(s.counters, stored)
+			}
+		}
+	}
+
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = &memoryQuotaCounter{resetAt: now.Add(plan.Period)}
+		s.counters[key] = counter
+	}
+
+	if counter.used >= plan.Limit {
+		return 0, counter.resetAt, false, nil
+	}
+	counter.used++
+
+	return plan.Limit - counter.used, counter.resetAt, true, nil
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ============================ Redirects =============================
+// ====================================================================
+
+// SafeRedirectFallback target of SafeRedirect when the requested target is not allowed.
+var SafeRedirectFallback = "/"
+
+// redirectAllowedHosts hosts accepted by the redirect_url validation rule.
+var redirectAllowedHosts []string
+
+// RegisterRedirectHosts registers the hosts absolute URLs of the `redirect_url` validation rule may point to.
+// A "*." prefix allows the subdomains of a domain.
+//
+// Example Usage:
+//
+//	http.RegisterRedirectHosts("example.com", "*.example.com")
+func RegisterRedirectHosts(hosts ...string) {
+	redirectAllowedHosts = hosts
+}
+
+// IsSafeRedirect checks a redirect target is a relative path on the same site ("/account", not
+// "//evil.com" nor "/\evil.com"), or an http(s) URL whose host is in allowedHosts.
+func IsSafeRedirect(target string, allowedHosts []string) bool {
+	if target == "" || strings.ContainsAny(target, "\\\x00\r\n\t") {
+		return false
+	}
+
+	// Relative path, protocol-relative URLs ("//host") leave the site
+	if strings.HasPrefix(target, "/") {
+		return !strings.HasPrefix(target, "//")
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.User != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return true
+		}
+		if suffix, wildcard := strings.CutPrefix(allowed, "*"); wildcard && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SafeRedirect redirects (302) to a client-supplied target such as a login `return_url`, only when it is a
+// relative path or an URL of an allowed host; other targets redirect to SafeRedirectFallback
+// (Config.SafeRedirectFallback of the request, see RequestConfig).
+//
+// Example Usage:
+//
+//	func (h LoginApi) Handle(c *core.Ctx) error {
+//		...
+//		return http.SafeRedirect(c, c.QueryStr("return_url"), []string{"app.example.com"})
+//	}
+func SafeRedirect(c *core.Ctx, target string, allowedHosts []string) error {
+	if !IsSafeRedirect(target, allowedHosts) {
+		log.Warnf("Unsafe redirect target %q from %s", target, c.ClientIP())
+		target = RequestConfig(c).SafeRedirectFallback
+	}
+
+	return redirect(c, target, core.StatusFound)
+}
+
+// RedirectFound redirects with 302 Found.
+func RedirectFound(c *core.Ctx, target string) error {
+	return redirect(c, target, core.StatusFound)
+}
+
+// RedirectSeeOther redirects with 303 See Other, the redirect to a page after a POST form submission.
+func RedirectSeeOther(c *core.Ctx, target string) error {
+	return redirect(c, target, core.StatusSeeOther)
+}
+
+// RedirectTemporary redirects with 307 Temporary Redirect, keeping the request method and body.
+func RedirectTemporary(c *core.Ctx, target string) error {
+	return redirect(c, target, core.StatusTemporaryRedirect)
+}
+
+// redirect sets the Location and status of a redirect. Like core.Ctx.Redirect, it returns errors.UnknownError
+// so it also ends a Validate.
+func redirect(c *core.Ctx, target string, status int) error {
+	c.Root().Redirect(target, status)
+
+	return errors.UnknownError
+}
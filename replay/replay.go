@@ -0,0 +1,119 @@
+// Package replay replays the requests captured by http.Dumped in tests, so production failures become
+// reproducible test cases.
+//
+// The gFly server is the only builder of core.Ctx: Ctx here sets its unexported fields with reflection, the way
+// the server does. The package is meant for tests, not to be imported by application code.
+//
+// Usage:
+//
+//	dump, err := http.LoadDump("testdata/dumps/checkout-500.json")
+//	...
+//	c, err := replay.Dump(api.NewCreateOrderApi(), dump)
+package replay
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/http"
+	"github.com/valyala/fasthttp"
+)
+
+// app application of the replayed Ctx, with its default configuration.
+var app = sync.OnceValue(func() *core.GFly {
+	return core.New().(*core.GFly)
+})
+
+// Ctx rebuilds the Ctx of a dumped request: method, URI, headers, body and path parameters. Its application and
+// router are a gFly application with the default configuration. Redacted headers keep http.RedactedValue, tests
+// set real credentials in dump.Headers before replaying.
+func Ctx(dump http.DebugDump) (*core.Ctx, error) {
+	root := &fasthttp.RequestCtx{}
+	root.Request.Header.SetMethod(dump.Method)
+	root.Request.SetRequestURI(dump.URI)
+	for header, values := range dump.Headers {
+		for _, value := range values {
+			root.Request.Header.Add(header, value)
+		}
+	}
+	root.Request.SetBodyString(dump.Body)
+	for key, value := range dump.PathParams {
+		root.SetUserValue(key, value)
+	}
+
+	c := &core.Ctx{}
+	for name, value := range map[string]any{
+		"app":    app(),
+		"router": app().Router(),
+		"root":   root,
+		"data":   core.Data{},
+	} {
+		if err := setField(c, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// setField sets an unexported field of a Ctx.
+func setField(c *core.Ctx, name string, value any) error {
+	field := reflect.ValueOf(c).Elem().FieldByName(name)
+	if !field.IsValid() || field.Type() != reflect.TypeOf(value) {
+		return errors.New("replay: unsupported core.Ctx layout, field " + name)
+	}
+
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(value))
+
+	return nil
+}
+
+// Dump replays a dumped request through a handler as the router does: Validate, then Handle when Validate
+// passes; errors of both set a 400 and a 500 status respectively, unless the handler set one. The returned Ctx
+// holds the response and the Ctx's Data, to be compared with the dump.
+//
+// Example Usage:
+//
+//	func TestOrderCheckoutFailure(t *testing.T) {
+//		dump, err := http.LoadDump("testdata/dumps/checkout-500.json")
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		dump.Headers["Authorization"] = []string{"Bearer " + testToken}
+//
+//		c, err := replay.Dump(api.NewCreateOrderApi(), dump)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		if status := c.Root().Response.StatusCode(); status != 201 {
+//			t.Errorf("status %d, body %s", status, c.Root().Response.Body())
+//		}
+//	}
+func Dump(handler core.IHandler, dump http.DebugDump) (*core.Ctx, error) {
+	c, err := Ctx(dump)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := handler.Validate(c); err != nil {
+		renderError(c, err, core.StatusBadRequest)
+	} else if err := handler.Handle(c); err != nil {
+		renderError(c, err, core.StatusInternalServerError)
+	}
+
+	return c, nil
+}
+
+// renderError renders a handler error as the router does.
+func renderError(c *core.Ctx, err error, status int) {
+	response := &c.Root().Response
+	if response.StatusCode() == core.StatusOK {
+		response.SetStatusCode(status)
+	}
+	if len(response.Body()) == 0 {
+		_ = c.JSON(core.Data{"error": err.Error()})
+	}
+}
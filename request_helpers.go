@@ -1,6 +1,8 @@
 package http
 
 import (
+	"reflect"
+
 	"github.com/gflydev/core"
 )
 
@@ -10,6 +12,7 @@ import (
 
 // ProcessPathID is a generic function that extracts a path ID parameter and stores it in the context.
 // It handles the common pattern of validating a path ID parameter for API endpoints and putting it in Ctx's Data.
+// The registered OwnershipPolicy is checked for the ID (see RegisterOwnershipPolicy).
 //
 // Parameters:
 //   - c: The context object containing the HTTP request/response data
@@ -26,7 +29,12 @@ func ProcessPathID(c *core.Ctx) error {
 	// Receive path parameter ID
 	itemID, errData := PathID(c)
 	if errData != nil {
-		return c.Error(errData)
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Check the caller may touch the row
+	if err := CheckOwnership(c, itemID); err != nil {
+		return err
 	}
 
 	// Store data into context
@@ -36,7 +44,8 @@ func ProcessPathID(c *core.Ctx) error {
 }
 
 // ProcessFilter validates and processes filter requests
-// It handles parsing the query parameters, converting to DTO, and validation and put to Ctx's Data
+// It handles parsing the query parameters, converting to DTO, and validation and put to Ctx's Data.
+// Offsets beyond MaxPageOffset are rejected with an OFFSET_TOO_LARGE error suggesting cursor pagination.
 //
 // Parameters:
 //   - c: The context object containing the HTTP request/response data
@@ -50,11 +59,21 @@ func ProcessPathID(c *core.Ctx) error {
 //		return http.ProcessFilter(c)
 //	}
 func ProcessFilter(c *core.Ctx) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
 	filterDto := FilterData(c)
 
 	// Validate DTO
-	if errData := Validate(filterDto); errData != nil {
-		return c.Error(errData)
+	if errData := ValidateRequest(c, filterDto); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Reject deep offsets
+	if errData := CheckOffset(c, filterDto); errData != nil && !relaxRule(c, "page", "max_offset") {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
 	}
 
 	// Store data into context.
@@ -79,7 +98,12 @@ type UpdateData interface {
 }
 
 // ProcessUpdateData validates and processes update requests.
-// It handles parsing the request body, setting the ID, converting to DTO, and validation and put to Ctx's Data.
+// It handles parsing the request body, setting the ID, converting to DTO, validation, field transforms and put to Ctx's Data.
+// The registered OwnershipPolicy is checked for the ID before the body is parsed (see RegisterOwnershipPolicy).
+// The fields present in the body, and the nullable ones explicitly set to null, are stored as a FieldMask
+// (see GetFieldMask and ClearedFields) so omitted fields can be told apart from fields to clear.
+// Deprecated fields (see DeprecateField) are accepted with a warning, and the event registered for T
+// (see RegisterEvent) is recorded.
 //
 // Type Parameters:
 //   - T: The type that implements the UpdateData interface.
@@ -90,32 +114,66 @@ type UpdateData interface {
 // Returns:
 //   - error: Returns nil if successful, otherwise returns an error response.
 func ProcessUpdateData[T UpdateData](c *core.Ctx) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	// Reject dry runs the handler would commit, see AllowDryRun
+	if err := checkDryRun(c); err != nil {
+		return err
+	}
+
 	// Receive path parameter ID
 	itemID, errData := PathID(c)
 	if errData != nil {
-		return c.Error(errData)
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Check the caller may touch the row
+	if err := CheckOwnership(c, itemID); err != nil {
+		return err
 	}
 
 	// Receive request data
 	var requestData T
 	if errData := Parse(c, &requestData); errData != nil {
-		return c.Error(errData)
+		return c.Error(errData, parseStatus(c, errData))
 	}
 
+	// Warn about deprecated fields
+	checkDeprecatedFields[T](c)
+
 	// Sanitize request data
-	SanitizeStruct(&requestData)
+	sanitizeRequest(c, &requestData)
+
+	// Fill zero-valued fields with their `default` tag
+	ApplyDefaults(&requestData)
 
 	// Set ID on the request body
 	requestData.SetID(itemID)
 
+	// Record present and cleared fields
+	c.SetData(FieldMaskKey, buildFieldMask(c.Root().PostBody(), requestData))
+
 	// Validate DTO
-	if errData := Validate(requestData); errData != nil {
-		return c.Error(errData)
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Transform validated data (e.g. hash passwords)
+	if err := TransformStruct(&requestData); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process request data",
+		}, core.StatusInternalServerError, "Transform request data error: %v", err)
 	}
 
 	// Store data into context
 	c.SetData(RequestKey, requestData)
 
+	// Record the DTO's event, emitted once the mutation succeeds
+	recordDataEvent(c, requestData)
+
 	return nil
 }
 
@@ -128,7 +186,12 @@ type AddData interface {
 }
 
 // ProcessData validates and processes create/add requests.
-// It handles parsing the request body, converting to DTO, and validation and put to Ctx's Data.
+// It handles parsing the request body, converting to DTO, validation, field transforms and put to Ctx's Data.
+// Deprecated fields (see DeprecateField) are accepted with a warning, and the event registered for T
+// (see RegisterEvent) is recorded.
+// UploadedFile, *UploadedFile and []UploadedFile fields take the files of their multipart field (named like
+// their `form` tag, else their JSON name), scanned and sanitized as by ProcessUpload; the files are removed
+// when the request is rejected.
 //
 // Type Parameters:
 //   - T: The type that implements the AddData interface.
@@ -139,22 +202,65 @@ type AddData interface {
 // Returns:
 //   - error: Returns nil if successful, otherwise returns an error response.
 func ProcessData[T AddData](c *core.Ctx) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	// Reject dry runs the handler would commit, see AllowDryRun
+	if err := checkDryRun(c); err != nil {
+		return err
+	}
+
+	// Receive the files of UploadedFile fields, before Parse rewrites multipart bodies
+	files, err := receiveFileFields(c, reflect.TypeFor[T]())
+	if err != nil {
+		return err
+	}
+	stored := false
+	defer func() {
+		// Removes the files of rejected requests
+		if !stored {
+			removeUploads(fileUploads(files))
+		}
+	}()
+
 	// Receive request data
 	var requestData T
 	if errData := Parse(c, &requestData); errData != nil {
-		return c.Error(errData)
+		return c.Error(errData, parseStatus(c, errData))
 	}
 
+	// Warn about deprecated fields
+	checkDeprecatedFields[T](c)
+
 	// Sanitize request data
-	SanitizeStruct(&requestData)
+	sanitizeRequest(c, &requestData)
+
+	// Fill zero-valued fields with their `default` tag
+	ApplyDefaults(&requestData)
+
+	// Bind uploaded files, overwriting values decoded from the body
+	bindFileFields(&requestData, files)
 
 	// Validate DTO
-	if errData := Validate(requestData); errData != nil {
-		return c.Error(errData)
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Transform validated data (e.g. hash passwords)
+	if err := TransformStruct(&requestData); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process request data",
+		}, core.StatusInternalServerError, "Transform request data error: %v", err)
 	}
 
 	// Store data into context
 	c.SetData(RequestKey, requestData)
+	stored = true
+
+	// Record the DTO's event, emitted once the mutation succeeds
+	recordDataEvent(c, requestData)
 
 	return nil
 }
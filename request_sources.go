@@ -0,0 +1,386 @@
+package http
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================= Multi-source Requests ======================
+// ====================================================================
+
+// Value sources of `from` tags. Fields without `from` tag are read from the body.
+const (
+	SourceQuery  = "query"
+	SourceHeader = "header"
+	SourcePath   = "path"
+	SourceBody   = "body"
+)
+
+// sourceField DTO field read from the query, a header or a path parameter.
+type sourceField struct {
+	index    []int
+	source   string         // SourceQuery, SourceHeader or SourcePath
+	name     string         // Parameter or header name
+	key      string         // JSON name, used in error data
+	layout   string         // Time layout of time fields, RFC 3339 when empty
+	location *time.Location // Location of times without offset
+	feature  string         // Feature required by the field, see RegisterFeatureFlagProvider
+}
+
+// sourceFieldsCache source fields by DTO type.
+var sourceFieldsCache sync.Map
+
+// sourceFields returns the field plan of BindSources, BindQuery and BindHeaders: the fields of a struct type,
+// those of embedded structs included, with a `from` tag other than body. The tag is `from:"source"`, the
+// parameter being named like the JSON field, or `from:"source:Name"`. The shorthand tags `path:"id"`,
+// `query:"page"` and `header:"X-Tenant-ID"` are accepted too, with the layout option of BindQuery for times
+// (`query:"since,layout=2006-01-02"`), or `timeFormat` and `timeZone` tags.
+func sourceFields(typ reflect.Type) []sourceField {
+	if cached, ok := sourceFieldsCache.Load(typ); ok {
+		return cached.([]sourceField)
+	}
+
+	var fields []sourceField
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			if !field.IsExported() {
+				continue
+			}
+
+			source, name, layout := sourceTag(field.Tag)
+			if source == "" || source == SourceBody {
+				continue
+			}
+			if source != SourceQuery && source != SourceHeader && source != SourcePath {
+				log.Errorf("Unknown source %q of field %s.%s", source, typ.Name(), field.Name)
+				continue
+			}
+
+			key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if key == "" || key == "-" {
+				key = field.Name
+			}
+			if name == "" {
+				name = key
+			}
+
+			layout, location := fieldTimeFormat(field, layout)
+
+			fields = append(fields, sourceField{
+				index:    field.Index,
+				source:   source,
+				name:     name,
+				key:      key,
+				layout:   layout,
+				location: location,
+				feature:  field.Tag.Get("feature"),
+			})
+		}
+	}
+
+	sourceFieldsCache.Store(typ, fields)
+
+	return fields
+}
+
+// sourceTag returns the source, the parameter name and the time layout declared by the tags of a field.
+func sourceTag(tag reflect.StructTag) (source, name, layout string) {
+	if from := tag.Get("from"); from != "" {
+		source, name, _ = strings.Cut(from, ":")
+
+		return source, name, ""
+	}
+
+	for _, candidate := range []string{SourcePath, SourceQuery, SourceHeader} {
+		if value := tag.Get(candidate); value != "" && value != "-" {
+			name, options, _ := strings.Cut(value, ",")
+			layout, _ = strings.CutPrefix(options, "layout=")
+
+			return candidate, name, layout
+		}
+	}
+
+	return "", "", ""
+}
+
+// BindSources sets the fields of the DTO tagged `from:"query"`, `from:"header"` or `from:"path"` (or the
+// shorthands `query:"name"`, `header:"Name"` and `path:"name"`) from their source, overwriting any value decoded
+// from the body so clients can not forge them. Integers read from the path are decoded with the registered
+// IDCodec (see RegisterIDCodec). Unconvertible values are returned as an error per field. Fields of disabled features (see RegisterFeatureFlagProvider) are left zero, with a
+// warning.
+//
+// Example Usage:
+//
+//	type UpdateMember struct {
+//		TenantID string `json:"tenant_id" from:"header:X-Tenant-ID" validate:"required"`
+//		ID       int    `json:"id" from:"path"`
+//		Notify   bool   `json:"notify" from:"query"`
+//		Role     string `json:"role" validate:"required"`
+//	}
+func BindSources[T any](c *core.Ctx, structData *T) *Error {
+	value := reflect.ValueOf(structData).Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errorData := core.Data{}
+	enabled := map[string]bool{}
+	for _, field := range sourceFields(value.Type()) {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			// Field of a nil embedded struct pointer
+			continue
+		}
+		fieldValue.SetZero()
+
+		var raws []string
+		switch field.source {
+		case SourceQuery:
+			raws = QueryValues(c, field.name)
+		case SourceHeader:
+			if raw := c.GetHeader(field.name); raw != "" {
+				raws = []string{raw}
+			}
+		case SourcePath:
+			if raw := c.PathVal(field.name); raw != "" {
+				raws = []string{raw}
+			}
+		}
+		if len(raws) == 0 {
+			continue
+		}
+		if field.feature != "" && !featureEnabled(c, field.feature, enabled) {
+			warnFeatureDisabled(c, droppedField{path: field.key, feature: field.feature})
+
+			continue
+		}
+
+		if field.layout != "" {
+			err = setTimeValue(fieldValue, raws[0], field.layout, field.location)
+		} else {
+			err = setSourceValue(fieldValue, raws, field.source == SourcePath)
+		}
+		if err != nil {
+			errorData[field.key] = []string{err.Error()}
+		}
+	}
+
+	if len(errorData) > 0 {
+		return &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	return nil
+}
+
+// bindsPathID reports whether a DTO type has a field bound to the `id` path parameter.
+func bindsPathID(typ reflect.Type) bool {
+	for _, field := range sourceFields(typ) {
+		if field.source == SourcePath && field.name == "id" {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// setSourceValue converts the raw values of a parameter to the field's type. Slices take each value, or
+// the comma-separated items of a single value.
+func setSourceValue(value reflect.Value, raws []string, isPath bool) error {
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+		if len(raws) == 1 {
+			raws = strings.Split(raws[0], ",")
+		}
+
+		items := reflect.MakeSlice(value.Type(), len(raws), len(raws))
+		for i, raw := range raws {
+			if err := setStringValue(items.Index(i), strings.TrimSpace(raw), isPath); err != nil {
+				return err
+			}
+		}
+		value.Set(items)
+
+		return nil
+	}
+
+	return setStringValue(value, raws[0], isPath)
+}
+
+// setStringValue converts a raw value to the type of the field.
+func setStringValue(value reflect.Value, raw string, isPath bool) error {
+	if value.Kind() == reflect.Pointer {
+		item := reflect.New(value.Type().Elem())
+		if err := setStringValue(item.Elem(), raw, isPath); err != nil {
+			return err
+		}
+		value.Set(item)
+
+		return nil
+	}
+
+	if value.Type() == timeType {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return errors.New("must be RFC 3339 date time")
+		}
+		value.Set(reflect.ValueOf(parsed))
+
+		return nil
+	}
+
+	if reflect.PointerTo(value.Type()).Implements(textUnmarshalerType) {
+		if err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return errors.New("is invalid")
+		}
+
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be boolean")
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isPath && value.Kind() == reflect.Int {
+			id, err := DecodeID(raw)
+			if err != nil {
+				return errors.New("must be valid identifier")
+			}
+			value.SetInt(int64(id))
+
+			return nil
+		}
+
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("must be integer")
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("must be positive integer")
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return errors.New("must be number")
+		}
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("has unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+// ProcessRequest validates and processes requests whose DTO is populated from several sources in one pass:
+// the JSON body (when sent) and the fields tagged `from:"query"`, `from:"header"` or `from:"path"`, or with
+// the shorthands `query:"page"`, `header:"X-Tenant-ID"` and `path:"id"` (see BindSources). The combined DTO is
+// then sanitized, validated, transformed and put to Ctx's Data, like ProcessData. When the DTO binds the `id`
+// path parameter, the registered OwnershipPolicy is checked for it before the body is parsed, like
+// ProcessUpdateData does.
+//
+// Type Parameters:
+//   - T: The DTO type.
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data.
+//
+// Returns:
+//   - error: Returns nil if successful, otherwise returns an error response.
+//
+// Example Usage:
+//
+//	func (h UpdateMemberApi) Validate(c *core.Ctx) error {
+//		return http.ProcessRequest[dto.UpdateMember](c)
+//	}
+func ProcessRequest[T any](c *core.Ctx) error {
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	// Reject dry runs the handler would commit, see AllowDryRun
+	if err := checkDryRun(c); err != nil {
+		return err
+	}
+
+	// Check the caller may touch the row of the bound path ID
+	if bindsPathID(reflect.TypeFor[T]()) {
+		itemID, errData := PathID(c)
+		if errData != nil {
+			return c.Error(errData, FailureStatus(c, FailureMalformed))
+		}
+		if err := CheckOwnership(c, itemID); err != nil {
+			return err
+		}
+	}
+
+	// Receive body data
+	var requestData T
+	if len(c.Root().PostBody()) > 0 {
+		if errData := Parse(c, &requestData); errData != nil {
+			return c.Error(errData, parseStatus(c, errData))
+		}
+
+		// Warn about deprecated fields
+		checkDeprecatedFields[T](c)
+	}
+
+	// Receive query, header and path data
+	if errData := BindSources(c, &requestData); errData != nil {
+		reportRequest(c, true)
+
+		return c.Error(errData, FailureStatus(c, FailureMalformed))
+	}
+
+	// Sanitize request data
+	sanitizeRequest(c, &requestData)
+
+	// Fill zero-valued fields with their `default` tag
+	ApplyDefaults(&requestData)
+
+	// Validate DTO
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Transform validated data (e.g. hash passwords)
+	if err := TransformStruct(&requestData); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process request data",
+		}, core.StatusInternalServerError, "Transform request data error: %v", err)
+	}
+
+	// Store data into context
+	c.SetData(RequestKey, requestData)
+
+	// Record the DTO's event, emitted once the mutation succeeds
+	recordDataEvent(c, requestData)
+
+	return nil
+}
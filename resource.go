@@ -0,0 +1,304 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ======================= Resource Descriptors =======================
+// ====================================================================
+
+// Types of filterable fields.
+const (
+	FieldTypeString = "string"
+	FieldTypeInt    = "int"
+	FieldTypeFloat  = "float"
+	FieldTypeBool   = "bool"
+	FieldTypeDate   = "date"
+)
+
+// FilterParam query parameter prefix of filter conditions: `filter[status]=active`, `filter[price][gte]=10`.
+const FilterParam = "filter"
+
+// FilterField describes a filterable field of a resource.
+type FilterField struct {
+	Type      string   // Value type: FieldTypeString, FieldTypeInt, FieldTypeFloat, FieldTypeBool or FieldTypeDate
+	Operators []string // Allowed operators (eq, ne, gt, gte, lt, lte, in, like), only eq when empty
+}
+
+// ResourceDescriptor describes the query capabilities of a list endpoint.
+type ResourceDescriptor struct {
+	Name         string                 // Resource name used by ProcessFilterAs
+	Filterable   map[string]FilterField // Filterable fields by name
+	Sortable     []string               // Fields accepted by order_by
+	DefaultOrder string                 // Order applied when order_by is not sent, e.g. "-created_at"
+	TieBreaker   string                 // Unique field appended to every order, "-id" by default, "-" disables it
+	Searchable   []string               // Fields searched by keyword; keyword is rejected when empty
+	MaxPerPage   int                    // Maximum per_page, unlimited when zero
+}
+
+var (
+	resourcesMu sync.RWMutex
+	resources   = map[string]ResourceDescriptor{}
+)
+
+// RegisterResource registers a resource descriptor, replacing any descriptor with the same name.
+//
+// Example Usage:
+//
+//	http.RegisterResource(http.ResourceDescriptor{
+//		Name: "products",
+//		Filterable: map[string]http.FilterField{
+//			"status": {Type: http.FieldTypeString, Operators: []string{"eq", "in"}},
+//			"price":  {Type: http.FieldTypeFloat, Operators: []string{"gte", "lte"}},
+//		},
+//		Sortable:     []string{"name", "price", "created_at"},
+//		DefaultOrder: "-created_at",
+//		Searchable:   []string{"name", "sku"},
+//		MaxPerPage:   100,
+//	})
+func RegisterResource(descriptor ResourceDescriptor) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+
+	resources[descriptor.Name] = descriptor
+}
+
+// GetResource returns the registered descriptor of a resource.
+func GetResource(name string) (ResourceDescriptor, bool) {
+	resourcesMu.RLock()
+	defer resourcesMu.RUnlock()
+
+	descriptor, ok := resources[name]
+
+	return descriptor, ok
+}
+
+// ProcessFilterAs works as ProcessFilter and also checks the parameters against the registered descriptor
+// of the resource: filter conditions, order_by, keyword and per_page. Unknown fields, operators and
+// invalid values are reported per parameter. Parsed conditions are stored in Filter.Conditions, and
+// Filter.OrderBy gets the descriptor's default order and unique tie-breaker (see ResourceDescriptor.Order).
+//
+// Example Usage:
+//
+//	func (h ListProductsApi) Validate(c *core.Ctx) error {
+//		return http.ProcessFilterAs(c, "products")
+//	}
+func ProcessFilterAs(c *core.Ctx, resource string) error {
+	descriptor, ok := GetResource(resource)
+	if !ok {
+		return WriteServerError(c, &Error{
+			Message: "Unable to process filter",
+		}, core.StatusInternalServerError, "Resource %q is not registered", resource)
+	}
+
+	// Abuse heuristics
+	if err := InspectRequest(c); err != nil {
+		return err
+	}
+
+	filterDto := FilterData(c)
+
+	// Validate DTO
+	if errData := ValidateRequest(c, filterDto); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Check against descriptor
+	conditions, errData := descriptor.Check(c, filterDto)
+	if errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+	filterDto.Conditions = conditions
+
+	// Apply default order and tie-breaker
+	filterDto.OrderBy = descriptor.Order(filterDto.OrderBy)
+
+	// Reject deep offsets
+	if errData = CheckOffset(c, filterDto); errData != nil && !relaxRule(c, "page", "max_offset") {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	// Store data into context.
+	c.SetData(FilterKey, filterDto)
+
+	return nil
+}
+
+// Check checks the filter and the `filter[...]` query parameters against the descriptor,
+// and returns the parsed conditions. The MaxPerPage cap can be relaxed by a RuleOverride ("per_page", "max").
+func (d ResourceDescriptor) Check(c *core.Ctx, filter Filter) ([]FilterCondition, *Error) {
+	errorData := core.Data{}
+	addError := func(field, message string) {
+		messages, _ := errorData[field].([]string)
+		errorData[field] = append(messages, message)
+	}
+
+	if d.MaxPerPage > 0 && filter.PerPage > d.MaxPerPage && !relaxRule(c, "per_page", "max") {
+		addError("per_page", fmt.Sprintf("must be %d or less", d.MaxPerPage))
+	}
+
+	if filter.Keyword != "" && len(d.Searchable) == 0 {
+		addError("keyword", "search is not supported")
+	}
+
+	for _, field := range strings.Split(filter.OrderBy, ",") {
+		field = strings.TrimPrefix(strings.TrimSpace(field), "-")
+		if field != "" && !slices.Contains(d.Sortable, field) {
+			addError("order_by", fmt.Sprintf("unknown sort field %q", field))
+		}
+	}
+
+	var conditions []FilterCondition
+	visitQuery(c, func(param, value string) {
+		field, operator, ok := parseFilterParam(param)
+		if !ok {
+			return
+		}
+
+		field, definition, known := d.filterField(c, field)
+		if !known {
+			addError(param, fmt.Sprintf("unknown filter field %q", field))
+
+			return
+		}
+
+		allowed := definition.Operators
+		if len(allowed) == 0 {
+			allowed = []string{"eq"}
+		}
+		if !slices.Contains(allowed, operator) {
+			addError(param, fmt.Sprintf("operator %q is not supported, use one of %s", operator, strings.Join(allowed, ", ")))
+
+			return
+		}
+
+		parsed, err := parseFilterValue(definition.Type, operator, value)
+		if err != nil {
+			addError(param, fmt.Sprintf("must be a valid %s", definition.Type))
+
+			return
+		}
+
+		conditions = append(conditions, FilterCondition{Field: field, Operator: operator, Value: parsed})
+	})
+
+	if len(errorData) > 0 {
+		return nil, &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+
+	return conditions, nil
+}
+
+// Order returns the order of a list query: the requested order, or DefaultOrder when none, followed by
+// the tie-breaker unless the order already contains its field.
+//
+// Example:
+//
+//	descriptor.Order("price")    // "price,-id"
+//	descriptor.Order("")         // "-created_at,-id"
+//	descriptor.Order("-id,name") // "-id,name"
+func (d ResourceDescriptor) Order(orderBy string) string {
+	if strings.TrimSpace(orderBy) == "" {
+		orderBy = d.DefaultOrder
+	}
+
+	tieBreaker := d.TieBreaker
+	if tieBreaker == "" {
+		tieBreaker = "-id"
+	}
+	if tieBreaker == "-" {
+		return orderBy
+	}
+
+	var fields []string
+	for _, field := range strings.Split(orderBy, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if strings.TrimPrefix(field, "-") == strings.TrimPrefix(tieBreaker, "-") {
+			// Already unique
+			return orderBy
+		}
+		fields = append(fields, field)
+	}
+
+	return strings.Join(append(fields, tieBreaker), ",")
+}
+
+// filterField returns the name and definition of a filterable field, matched whatever its case when the
+// QueryOptions of the request have CaseInsensitiveKeys.
+func (d ResourceDescriptor) filterField(c *core.Ctx, name string) (string, FilterField, bool) {
+	if definition, ok := d.Filterable[name]; ok {
+		return name, definition, true
+	}
+
+	if RequestConfig(c).Query.CaseInsensitiveKeys {
+		for field, definition := range d.Filterable {
+			if strings.EqualFold(field, name) {
+				return field, definition, true
+			}
+		}
+	}
+
+	return name, FilterField{}, false
+}
+
+// parseFilterParam splits `filter[field]` and `filter[field][op]` parameters.
+func parseFilterParam(param string) (field, operator string, ok bool) {
+	rest, found := strings.CutPrefix(param, FilterParam+"[")
+	if !found {
+		return "", "", false
+	}
+
+	field, rest, found = strings.Cut(rest, "]")
+	if !found || field == "" {
+		return "", "", false
+	}
+
+	operator = "eq"
+	if rest != "" {
+		operator = strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]")
+	}
+
+	return field, operator, true
+}
+
+// parseFilterValue converts a filter value to its field type; `in` values are comma-separated lists.
+func parseFilterValue(fieldType, operator, value string) (any, error) {
+	if operator == "in" {
+		var values []any
+		for _, item := range strings.Split(value, ",") {
+			parsed, err := parseFilterValue(fieldType, "eq", strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, parsed)
+		}
+
+		return values, nil
+	}
+
+	switch fieldType {
+	case FieldTypeInt:
+		return strconv.Atoi(value)
+	case FieldTypeFloat:
+		return strconv.ParseFloat(value, 64)
+	case FieldTypeBool:
+		return strconv.ParseBool(value)
+	case FieldTypeDate:
+		return ParseDate(value)
+	default:
+		return value, nil
+	}
+}
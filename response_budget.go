@@ -0,0 +1,208 @@
+package http
+
+import (
+	"fmt"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================= Response Size Budget =======================
+// ====================================================================
+
+// ResponseSizePolicy tells how list responses over the size budget are handled.
+type ResponseSizePolicy int
+
+// Response size policies.
+const (
+	SizePolicyReject   ResponseSizePolicy = iota // RESPONSE_TOO_LARGE error telling the per_page which fits (default)
+	SizePolicyTruncate                           // Records beyond the budget are dropped, Meta.Truncated is set
+	SizePolicyStream                             // The response is streamed instead of buffered
+)
+
+// ResponseBudget maximum serialized size of list responses and how larger responses are handled.
+type ResponseBudget struct {
+	MaxBytes int                // Maximum size of a list response body, the budget is disabled when zero
+	Policy   ResponseSizePolicy // Handling of larger responses
+}
+
+// responseBudget budget applied by WriteList.
+var responseBudget ResponseBudget
+
+// RegisterResponseBudget registers the size budget of list responses written by WriteList, protecting
+// gateways and clients from surprise multi-megabyte responses.
+//
+// Example Usage:
+//
+//	http.RegisterResponseBudget(http.ResponseBudget{
+//		MaxBytes: 5 << 20, // 5 MB
+//		Policy:   http.SizePolicyTruncate,
+//	})
+func RegisterResponseBudget(budget ResponseBudget) {
+	responseBudget = budget
+}
+
+// writeBudgetedList sends a List response within the registered ResponseBudget. Records are encoded one by
+// one, so the encoding stops as soon as the budget is exceeded.
+func writeBudgetedList[T any](c *core.Ctx, data List[T]) error {
+	if responseBudget.MaxBytes <= 0 || len(data.Data) == 0 {
+		return writeJSON(c, core.StatusOK, data)
+	}
+
+	prefix, suffix, err := listEnvelope(data.Meta, data.Warnings)
+	if err != nil {
+		return err
+	}
+
+	size := len(prefix) + len(suffix)
+	encoded := make([][]byte, 0, len(data.Data))
+	exceeded := false
+	for i, item := range data.Data {
+		encodedItem, err := codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			size++
+		}
+		size += len(encodedItem)
+		encoded = append(encoded, encodedItem)
+
+		if size > responseBudget.MaxBytes {
+			exceeded = true
+			break
+		}
+	}
+
+	if !exceeded {
+		return writeListBody(c, prefix, encoded, suffix)
+	}
+
+	// Records fitting in the budget
+	fitting := len(encoded) - 1
+
+	switch responseBudget.Policy {
+	case SizePolicyStream:
+		return streamList(c, prefix, encoded, data.Data[len(encoded):], suffix)
+	case SizePolicyTruncate:
+		data.Meta.Truncated = true
+		for count := fitting; count >= 0; count-- {
+			warnings := append(data.Warnings[:len(data.Warnings):len(data.Warnings)], Warning{
+				Code:    WarningTruncated,
+				Message: fmt.Sprintf("Response truncated to %d of %d records, request a smaller per_page", count, len(data.Data)),
+			})
+			prefix, suffix, err = listEnvelope(data.Meta, warnings)
+			if err != nil {
+				return err
+			}
+
+			if listBodySize(prefix, encoded[:count], suffix) <= responseBudget.MaxBytes || count == 0 {
+				return writeListBody(c, prefix, encoded[:count], suffix)
+			}
+		}
+	}
+
+	return c.Error(&Error{
+		Code:    "RESPONSE_TOO_LARGE",
+		Message: fmt.Sprintf("Response exceeds %d bytes, request a smaller per_page", responseBudget.MaxBytes),
+		Data: core.Data{
+			"per_page": max(fitting, 1),
+		},
+	})
+}
+
+// listEnvelope returns the JSON of a List before and after its records.
+func listEnvelope(meta Meta, warnings []Warning) (prefix, suffix []byte, err error) {
+	encodedMeta, err := codec.Marshal(meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefix = append(append([]byte(`{"meta":`), encodedMeta...), `,"data":[`...)
+
+	suffix = []byte("]")
+	if len(warnings) > 0 {
+		encodedWarnings, err := codec.Marshal(warnings)
+		if err != nil {
+			return nil, nil, err
+		}
+		suffix = append(append(suffix, `,"warnings":`...), encodedWarnings...)
+	}
+
+	return prefix, append(suffix, '}'), nil
+}
+
+// listBodySize returns the size of a List body.
+func listBodySize(prefix []byte, items [][]byte, suffix []byte) int {
+	size := len(prefix) + len(suffix) + max(len(items)-1, 0)
+	for _, item := range items {
+		size += len(item)
+	}
+
+	return size
+}
+
+// writeListBody sends a List body with HTTP 200 status.
+func writeListBody(c *core.Ctx, prefix []byte, items [][]byte, suffix []byte) error {
+	body := acquireBuffer(listBodySize(prefix, items, suffix))
+	defer releaseBuffer(body)
+
+	body.Write(prefix)
+	for i, item := range items {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(item)
+	}
+	body.Write(suffix)
+
+	return setResponseBody(c, core.StatusOK, body.Bytes())
+}
+
+// streamList streams a List response with HTTP 200 status: the encoded records, then the remaining ones
+// encoded while writing, paced by the client (see StreamFlowControl). MessagePack responses are not streamed, their body is transcoded as a whole.
+func streamList[T any](c *core.Ctx, prefix []byte, encoded [][]byte, remaining []T, suffix []byte) error {
+	if PrefersMsgpack(c) {
+		for _, item := range remaining {
+			encodedItem, err := codec.Marshal(item)
+			if err != nil {
+				return err
+			}
+			encoded = append(encoded, encodedItem)
+		}
+
+		return writeListBody(c, prefix, encoded, suffix)
+	}
+
+	setJSONResponse(c, core.StatusOK)
+
+	streamBody(c, "list", nil, func(flow *streamFlow) error {
+		chunk := append([]byte{}, prefix...)
+		for i, item := range encoded {
+			if i > 0 {
+				chunk = append(chunk, ',')
+			}
+			chunk = append(chunk, item...)
+		}
+		if err := flow.Send(chunk); err != nil {
+			return err
+		}
+
+		for _, item := range remaining {
+			encodedItem, err := codec.Marshal(item)
+			if err != nil {
+				// The status is sent, the truncated body tells the client the response failed
+				log.Errorf("Stream list encoding error: %v", err)
+
+				return err
+			}
+			if err := flow.Send(append([]byte{','}, encodedItem...)); err != nil {
+				return err
+			}
+		}
+
+		return flow.Send(suffix)
+	})
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================= Warning Helpers ==========================
+// ====================================================================
+
+// AddWarning appends a non-fatal warning to the request context.
+// Warnings are collected during the Validate phase (or by the handler itself) and are
+// attached automatically to the response by WriteSuccess and WriteList.
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data
+//   - code: Machine-readable warning code (e.g. WarningDeprecated)
+//   - message: Human-readable description of the warning
+//   - field: Optional request field the warning relates to
+//
+// Example Usage:
+//
+//	http.AddWarning(c, http.WarningDeprecated, "Use 'full_name' instead", "name")
+func AddWarning(c *core.Ctx, code, message string, field ...string) {
+	warning := Warning{
+		Code:    code,
+		Message: message,
+	}
+	if len(field) > 0 {
+		warning.Field = field[0]
+	}
+
+	c.SetData(WarningsKey, append(GetWarnings(c), warning))
+}
+
+// GetWarnings returns all warnings collected in the request context.
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data
+//
+// Returns:
+//   - []Warning: Collected warnings, nil if there are none
+func GetWarnings(c *core.Ctx) []Warning {
+	warnings, _ := c.GetData(WarningsKey).([]Warning)
+
+	return warnings
+}
+
+// ====================================================================
+// ======================== Response Writers ==========================
+// ====================================================================
+
+// WriteSuccess sends a Success response with HTTP 200 status.
+// Warnings collected in the request context are appended to the response's Warnings, and pending flash
+// messages (see AddFlash) to its Flashes.
+// When the request selects fields (`fields` query parameter), each value of Data is reduced to them,
+// and Data is encrypted when the request negotiated it (see EncryptResponse).
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data
+//   - data: The success response to send
+//
+// Returns:
+//   - error: An error if the response generation fails, otherwise nil
+//
+// Example Usage:
+//
+//	func (h CreateUserApi) Handle(c *core.Ctx) error {
+//		return http.WriteSuccess(c, http.Success{Message: "User created successfully"})
+//	}
+func WriteSuccess(c *core.Ctx, data Success) error {
+	data.Warnings = append(data.Warnings, GetWarnings(c)...)
+	data.Flashes = append(data.Flashes, ConsumeFlashes(c)...)
+
+	if fieldSet := SelectedFields(c); len(fieldSet) > 0 && data.Data != nil {
+		selected := make(core.Data, len(data.Data))
+		for key, value := range data.Data {
+			item, err := fieldSet.Select(value)
+			if err != nil {
+				return writeSelectError(c, err)
+			}
+			selected[key] = item
+		}
+		data.Data = selected
+	}
+
+	if jwe, encrypted, err := encryptPayload(c, data.Data); encrypted {
+		if err != nil {
+			return writeEncryptError(c, err)
+		}
+
+		return writeJSON(c, core.StatusOK, EncryptSuccess{
+			Message:  data.Message,
+			Data:     jwe,
+			Warnings: data.Warnings,
+		})
+	}
+
+	return writeJSON(c, core.StatusOK, data)
+}
+
+// WriteList sends a List response with HTTP 200 status.
+// Warnings collected in the request context are appended to the response's Warnings,
+// and the quota consumed by the request (see ConsumeQuota) and pending flash messages (see AddFlash) are set in Meta.
+// When the request selects fields (`fields` query parameter), each record of Data is reduced to them,
+// and Data is encrypted when the request negotiated it (see EncryptResponse). A nil Data is sent as [].
+// Responses larger than the registered ResponseBudget are rejected, truncated or streamed per its policy.
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data
+//   - data: The list response to send
+//
+// Returns:
+//   - error: An error if the response generation fails, otherwise nil
+func WriteList[T any](c *core.Ctx, data List[T]) error {
+	data.Warnings = append(data.Warnings, GetWarnings(c)...)
+	if data.Meta.Quota == nil {
+		data.Meta.Quota = GetQuota(c)
+	}
+	data.Meta.Flashes = append(data.Meta.Flashes, ConsumeFlashes(c)...)
+	if data.Data == nil {
+		data.Data = []T{}
+	}
+
+	var items any = data.Data
+	if fieldSet := SelectedFields(c); len(fieldSet) > 0 {
+		selected, err := fieldSet.Select(data.Data)
+		if err != nil {
+			return writeSelectError(c, err)
+		}
+
+		selectedItems, _ := selected.([]any)
+		items = selectedItems
+	}
+
+	if jwe, encrypted, err := encryptPayload(c, items); encrypted {
+		if err != nil {
+			return writeEncryptError(c, err)
+		}
+
+		return writeJSON(c, core.StatusOK, EncryptList{
+			Meta:     data.Meta,
+			Data:     jwe,
+			Warnings: data.Warnings,
+		})
+	}
+
+	if selectedItems, ok := items.([]any); ok {
+		return writeBudgetedList(c, List[any]{
+			Meta:     data.Meta,
+			Data:     selectedItems,
+			Warnings: data.Warnings,
+		})
+	}
+
+	return writeBudgetedList(c, data)
+}
+
+// WriteError sends an Error response, with HTTP 400 status unless another status is given.
+// When HTML error pages are registered (see RegisterErrorPages) and the client prefers text/html,
+// the status's error page is rendered instead of JSON. Server errors (5xx) are logged and carry the request's
+// support reference (see SupportReference) in their Data and the X-Support-Reference header.
+//
+// Parameters:
+//   - c: The context object containing the HTTP request/response data
+//   - data: The error response to send
+//   - status: Optional HTTP status, 400 by default
+//
+// Returns:
+//   - error: The error ending the request processing
+//
+// Example Usage:
+//
+//	func (h ShowPageApi) Handle(c *core.Ctx) error {
+//		...
+//		return http.WriteError(c, &http.Error{Code: "NOT_FOUND", Message: "Page not found"}, core.StatusNotFound)
+//	}
+func WriteError(c *core.Ctx, data *Error, status ...int) error {
+	httpStatus := core.StatusBadRequest
+	if len(status) > 0 {
+		httpStatus = status[0]
+	}
+
+	// Server errors carry the support reference of the request
+	if httpStatus >= core.StatusInternalServerError {
+		if data == nil {
+			data = &Error{}
+		}
+		data = withSupportReference(c, data)
+		log.Errorf("Server error %d %q [request %s, support reference %s]", httpStatus, data.Message, RequestID(c),
+			SupportReference(c))
+	}
+
+	return writeError(c, data, httpStatus)
+}
+
+// writeError writes the Error response, as an error page to browsers when error pages are registered.
+func writeError(c *core.Ctx, data *Error, httpStatus int) error {
+	if errorPages != nil && PrefersHTML(c) {
+		return writeErrorPage(c, data, httpStatus)
+	}
+
+	_ = writeJSON(c, httpStatus, data)
+
+	return errors.UnknownError
+}
+
+// writeSelectError reports a response which could not be reduced to the selected fields.
+func writeSelectError(c *core.Ctx, err error) error {
+	return WriteServerError(c, &Error{
+		Message: "Unable to select response fields",
+	}, core.StatusInternalServerError, "Field selection error: %v", err)
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Rule Overrides ==========================
+// ====================================================================
+
+// RuleOverride relaxes validation rules per request, typically depending on the principal stored in Ctx
+// (admins may exceed per_page caps, set restricted fields, ...) instead of duplicating DTOs per role.
+type RuleOverride interface {
+	// Relax reports whether the failed rule of the field may be ignored for the request.
+	// Field is the JSON path of the field without the struct name ("per_page", "address.zip"),
+	// rule is the failed tag ("max", "restricted", ...).
+	Relax(c *core.Ctx, field, rule string) bool
+}
+
+// RuleOverrideFunc adapter to use a function as a RuleOverride.
+type RuleOverrideFunc func(c *core.Ctx, field, rule string) bool
+
+// Relax calls f(c, field, rule).
+func (f RuleOverrideFunc) Relax(c *core.Ctx, field, rule string) bool {
+	return f(c, field, rule)
+}
+
+var (
+	ruleOverridesMu sync.RWMutex
+	// ruleOverrides overrides consulted by ValidateRequest.
+	ruleOverrides []RuleOverride
+)
+
+// RegisterRuleOverride registers an override consulted by ValidateRequest, ProcessFilter and ProcessFilterAs.
+// A failed rule is ignored when any registered override relaxes it.
+//
+// Example Usage:
+//
+//	http.RegisterRuleOverride(http.RuleOverrideFunc(func(c *core.Ctx, field, rule string) bool {
+//		user, ok := c.GetData(http.UserKey).(*models.User)
+//		return ok && user.IsAdmin() && (rule == "restricted" || field == "per_page")
+//	}))
+func RegisterRuleOverride(override RuleOverride) {
+	ruleOverridesMu.Lock()
+	defer ruleOverridesMu.Unlock()
+
+	ruleOverrides = append(ruleOverrides, override)
+}
+
+// relaxRule checks a registered override relaxes the failed rule of the field for the request.
+func relaxRule(c *core.Ctx, field, rule string) bool {
+	if c == nil {
+		return false
+	}
+
+	ruleOverridesMu.RLock()
+	overrides := ruleOverrides
+	ruleOverridesMu.RUnlock()
+
+	for _, override := range overrides {
+		if override.Relax(c, field, rule) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldPath removes the struct name from a validator namespace ("UpdateUser.address.zip" -> "address.zip").
+func fieldPath(namespace string) string {
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+
+	return namespace
+}
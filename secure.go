@@ -1,18 +1,63 @@
 package http
 
 import (
+	"github.com/gflydev/core"
 	"github.com/gflydev/core/log"
 	"github.com/gflydev/utils/str"
 	"html"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
+	"unicode"
 )
 
+// scriptTagPattern script elements removed by SanitizeString.
 var scriptTagPattern = regexp.MustCompile(`(?is)<script.*?>.*?</script>`)
 
-// SanitizeStruct recursively sanitizes string fields to mitigate XSS payloads.
+// Sanitizer is an interface for types that normalize their own value.
+// SanitizeStruct calls Sanitize on fields implementing it, after their string fields are sanitized.
+// Sanitize must be implemented with a pointer receiver.
+type Sanitizer interface {
+	Sanitize()
+}
+
+// SanitizePolicy tells how the Process* helpers sanitize request DTOs.
+type SanitizePolicy int
+
+// Sanitize policies.
+const (
+	SanitizeStrict   SanitizePolicy = iota // Strings are cleaned by SanitizeString, then `sanitize` tags apply (default)
+	SanitizeTagsOnly                       // Strings are kept as sent, only `sanitize` tags and Sanitizer apply
+)
+
+// sanitizePolicy the policy used by the Process* helpers.
+var sanitizePolicy = SanitizeStrict
+
+// RegisterSanitizePolicy registers the policy the Process* helpers sanitize request DTOs with. SanitizeTagsOnly
+// suits clients posting markup their views escape (rich text editors, templates); it is usually set per tenant
+// (see TenantConfigResolver). SanitizeStruct always applies SanitizeStrict.
+// Once a configuration snapshot is loaded (see ReloadConfig), the policy of the snapshot is replaced.
+func RegisterSanitizePolicy(policy SanitizePolicy) {
+	sanitizePolicy = policy
+	if configSnapshot.Load() != nil {
+		_ = UpdateConfig(func(config *Config) { config.Sanitize = policy })
+	}
+}
+
+// SanitizeStruct recursively sanitizes exported string fields to mitigate XSS payloads. Unexported fields are
+// left unchanged.
 func SanitizeStruct(target any) {
+	sanitizeWith(target, SanitizeStrict)
+}
+
+// sanitizeRequest sanitizes the DTO of a request with the policy of the request's configuration.
+func sanitizeRequest(c *core.Ctx, target any) {
+	sanitizeWith(target, RequestConfig(c).Sanitize)
+}
+
+// sanitizeWith sanitizes a struct with the policy.
+func sanitizeWith(target any, policy SanitizePolicy) {
 	if target == nil {
 		return
 	}
@@ -22,21 +67,82 @@ func SanitizeStruct(target any) {
 		return
 	}
 
-	sanitizeValue(val.Elem())
+	sanitizeValue(val.Elem(), policy)
 }
 
+// SanitizeString removes script tags, NUL bytes and invalid UTF-8 from a string, decodes its HTML entities
+// and trims it. Entities are decoded first, and script tags removed until none is left, so encoded or
+// nested tags (e.g. "&lt;script&gt;", "<scr<script></script>ipt>") do not survive. It runs in linear time.
 func SanitizeString(input string) string {
 	if input == "" {
 		return ""
 	}
-	clean := scriptTagPattern.ReplaceAllString(input, "")
-	clean = str.Trim(clean)
-	clean = html.UnescapeString(clean)
+	clean := html.UnescapeString(input)
 	clean = strings.ReplaceAll(clean, "\x00", "")
+	clean = strings.ToValidUTF8(clean, "\uFFFD")
+	clean = removeScriptTags(clean)
+	clean = str.Trim(clean)
 	return clean
 }
 
-func sanitizeValue(val reflect.Value) {
+// removeScriptTags removes the script elements of a string in a single pass. The output is checked as it is
+// built: an element is dropped as soon as its closing tag is appended, so elements formed by a removal
+// ("<scr<script></script>ipt>...</script>") are dropped too and nothing matching scriptTagPattern is left.
+func removeScriptTags(input string) string {
+	const openTag, closeTag = "<script", "</script>"
+
+	out := make([]rune, 0, len(input))
+	var opens, closings []int // Positions in out of the `<script` starts and of the `>`
+	for _, r := range input {
+		out = append(out, r)
+		size := len(out)
+
+		if size >= len(openTag) && out[size-len(openTag)] == '<' &&
+			strings.EqualFold(string(out[size-len(openTag):]), openTag) {
+			opens = append(opens, size-len(openTag))
+		}
+		if r != '>' {
+			continue
+		}
+
+		if size >= len(closeTag) && out[size-len(closeTag)] == '<' &&
+			strings.EqualFold(string(out[size-len(closeTag):]), closeTag) {
+			// The element needs a `>` ending its opening tag, before the closing tag
+			lastClosing := -1
+			if len(closings) > 0 {
+				lastClosing = closings[len(closings)-1]
+			}
+
+			if i := lastOpenBefore(opens, lastClosing-len(openTag)); i >= 0 {
+				size = opens[i]
+				out = out[:size]
+				opens = opens[:i]
+				for len(closings) > 0 && closings[len(closings)-1] >= size {
+					closings = closings[:len(closings)-1]
+				}
+
+				continue
+			}
+		}
+
+		closings = append(closings, size-1)
+	}
+
+	return string(out)
+}
+
+// lastOpenBefore returns the index of the last position of opens not after limit, -1 when none.
+func lastOpenBefore(opens []int, limit int) int {
+	for i := len(opens) - 1; i >= 0; i-- {
+		if opens[i] <= limit {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func sanitizeValue(val reflect.Value, policy SanitizePolicy) {
 	if !val.IsValid() {
 		return
 	}
@@ -44,25 +150,28 @@ func sanitizeValue(val reflect.Value) {
 	switch val.Kind() {
 	case reflect.Pointer:
 		if !val.IsNil() {
-			sanitizeValue(val.Elem())
+			sanitizeValue(val.Elem(), policy)
 		}
 	case reflect.Struct:
-		for i := 0; i < val.NumField(); i++ {
-			field := val.Field(i)
-			if field.CanSet() {
-				sanitizeValue(field)
-			} else if field.CanAddr() {
-				// Non-settable fields may still be pointers/structs we can sanitize through address.
-				sanitizeValue(field.Addr())
+		// Exported fields which may hold strings, see planOf
+		plan := planOf(val.Type())
+		for _, index := range plan.walk {
+			if field := val.Field(index); field.CanSet() {
+				sanitizeValue(field, policy)
 			}
 		}
+		applySanitizeTags(val, plan)
+
+		if plan.sanitizer && val.CanAddr() {
+			val.Addr().Interface().(Sanitizer).Sanitize()
+		}
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < val.Len(); i++ {
 			elem := val.Index(i)
 			if elem.CanAddr() {
-				sanitizeValue(elem.Addr())
+				sanitizeValue(elem.Addr(), policy)
 			} else {
-				sanitizeValue(elem)
+				sanitizeValue(elem, policy)
 			}
 		}
 	case reflect.Map:
@@ -70,16 +179,143 @@ func sanitizeValue(val reflect.Value) {
 			elem := val.MapIndex(key)
 			if elem.CanInterface() {
 				// Sanitize strings in map values.
-				if elem.Kind() == reflect.String {
+				if elem.Kind() == reflect.String && policy == SanitizeStrict {
 					clean := SanitizeString(elem.String())
 					val.SetMapIndex(key, reflect.ValueOf(clean))
 				}
 			}
 		}
 	case reflect.String:
-		clean := SanitizeString(val.String())
-		val.SetString(clean)
+		if policy == SanitizeStrict {
+			val.SetString(SanitizeString(val.String()))
+		}
 	default:
 		log.Tracef("unhandled default case for value type %v", val.Kind())
 	}
 }
+
+// normalizers string normalizations applied by `sanitize` tag rules of the same name.
+// They are also applied to fields whose `validate` tag uses a rule of the same name,
+// so the normalized value is checked and written back into the DTO.
+var normalizers = map[string]func(string) string{
+	"phone":    NormalizePhone,
+	"country":  strings.ToUpper,
+	"currency": strings.ToUpper,
+	"timezone": NormalizeTimezone,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// applySanitizeTags applies the rules declared by `sanitize` tags once all fields of the struct are sanitized.
+//
+// Supported rules:
+//   - slug: converts the field's value to a URL slug (see Slugify)
+//   - slug=title: same, derived from the `title` field (JSON or Go name) when the field is empty
+//   - phone, country, currency, timezone, upper, lower: see normalizers
+//   - rules registered with RegisterSanitizePattern
+func applySanitizeTags(val reflect.Value, plan *dtoPlan) {
+	for _, tagged := range plan.tagged {
+		field := val.Field(tagged.index)
+		if !field.CanSet() {
+			continue
+		}
+
+		// Normalizations implied by validation rules
+		for _, normalize := range tagged.normalizers {
+			field.SetString(normalize(field.String()))
+		}
+
+		for _, rule := range tagged.rules {
+			if normalize, ok := normalizers[rule.name]; ok {
+				field.SetString(normalize(field.String()))
+
+				continue
+			}
+
+			switch rule.name {
+			case "slug":
+				source := field.String()
+				if source == "" && rule.param != "" {
+					if sibling, ok := structFieldByName(val, rule.param); ok && sibling.Kind() == reflect.String {
+						source = sibling.String()
+					}
+				}
+				field.SetString(Slugify(source))
+			default:
+				if clean, ok := applySanitizePattern(rule.name, field.String()); ok {
+					field.SetString(clean)
+
+					continue
+				}
+				log.Tracef("unknown sanitize rule %s", rule.name)
+			}
+		}
+	}
+}
+
+// NormalizePhone removes formatting characters from a phone number so it can be checked as E.164,
+// e.g. "+84 (90) 123-4567" becomes "+84901234567" and the "00" international prefix becomes "+".
+func NormalizePhone(phone string) string {
+	var builder strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			builder.WriteRune(r)
+		} else if !strings.ContainsRune(" -.()/", r) {
+			return phone // Not a phone number, leave it to validation
+		}
+	}
+
+	normalized := builder.String()
+	if strings.HasPrefix(normalized, "00") {
+		normalized = "+" + normalized[2:]
+	}
+
+	return normalized
+}
+
+// NormalizeTimezone returns the canonical IANA name of a timezone matched case-insensitively,
+// e.g. "asia/ho_chi_minh" becomes "Asia/Ho_Chi_Minh". Unknown names are returned unchanged.
+func NormalizeTimezone(name string) string {
+	name = strings.TrimSpace(name)
+	if _, err := time.LoadLocation(name); err == nil {
+		return name
+	}
+
+	// IANA names capitalize each word separated by '/', '_' or '-'
+	var builder strings.Builder
+	upper := true
+	for _, r := range strings.ToLower(name) {
+		if upper {
+			builder.WriteRune(unicode.ToUpper(r))
+		} else {
+			builder.WriteRune(r)
+		}
+		upper = r == '/' || r == '_' || r == '-'
+	}
+
+	if candidate := builder.String(); candidate != name {
+		if _, err := time.LoadLocation(candidate); err == nil {
+			return candidate
+		}
+	}
+	if strings.EqualFold(name, "utc") {
+		return "UTC"
+	}
+
+	return name
+}
+
+// structFieldByName finds a struct field by its JSON name or Go name.
+func structFieldByName(val reflect.Value, name string) (reflect.Value, bool) {
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == name || strings.EqualFold(field.Name, name) {
+			return val.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
@@ -0,0 +1,415 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ============================= Sessions =============================
+// ====================================================================
+
+// ErrSessionNotFound returned by SessionStore when no session has the ID, or it expired.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore is an interface for the storage of encoded session data by session ID (memory, Redis, database, ...).
+type SessionStore interface {
+	// Load returns the data of a session, ErrSessionNotFound when there is none.
+	Load(id string) ([]byte, error)
+
+	// Save stores the data of a session for the TTL.
+	Save(id string, data []byte, ttl time.Duration) error
+
+	// Delete removes a session.
+	Delete(id string) error
+}
+
+// SessionOptions configuration of sessions.
+type SessionOptions struct {
+	CookieName string                  // Name of the session ID cookie, "session_id" by default
+	TTL        time.Duration           // Session lifetime from its last change, 24 hours by default
+	Insecure   bool                    // Sends the cookie over plain HTTP too (local development)
+	SameSite   fasthttp.CookieSameSite // SameSite of the cookie, Lax by default
+}
+
+var (
+	// sessionStore storage of sessions, sessions are unavailable when nil
+	sessionStore SessionStore
+	// sessionOptions options of sessions
+	sessionOptions SessionOptions
+)
+
+// RegisterSessionStore registers the storage of sessions and their options.
+//
+// Example Usage:
+//
+//	http.RegisterSessionStore(http.NewMemorySessionStore(), http.SessionOptions{TTL: 2 * time.Hour})
+func RegisterSessionStore(store SessionStore, options SessionOptions) {
+	if options.CookieName == "" {
+		options.CookieName = "session_id"
+	}
+	if options.TTL == 0 {
+		options.TTL = 24 * time.Hour
+	}
+	if options.SameSite == fasthttp.CookieSameSiteDisabled {
+		options.SameSite = fasthttp.CookieSameSiteLaxMode
+	}
+
+	sessionStore = store
+	sessionOptions = options
+}
+
+// Session data of a client session. Values are kept JSON encoded until read with a type.
+type Session struct {
+	mu      sync.Mutex
+	id      string
+	values  map[string]json.RawMessage
+	changed bool
+}
+
+// ID returns the session ID, empty for a new session not saved yet.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// ProcessSession loads the client's session, identified by its cookie, into Ctx's Data. Clients without a
+// valid session get an empty one, created when data is set. Sessions are saved by SaveSession (see WithSession).
+//
+// Example Usage:
+//
+//	func (h CheckoutStepApi) Validate(c *core.Ctx) error {
+//		if err := http.ProcessSession(c); err != nil {
+//			return err
+//		}
+//		return http.ProcessData[dto.CheckoutStep](c)
+//	}
+func ProcessSession(c *core.Ctx) error {
+	if _, err := loadSession(c); err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to load session",
+		}, core.StatusInternalServerError, "Session load error: %v", err)
+	}
+
+	return nil
+}
+
+// loadSession returns the session of the request, loading it from the store on first use.
+func loadSession(c *core.Ctx) (*Session, error) {
+	if session, ok := c.GetData(SessionKey).(*Session); ok {
+		return session, nil
+	}
+
+	if sessionStore == nil {
+		return nil, errors.New("session store is not registered")
+	}
+
+	session := &Session{values: map[string]json.RawMessage{}}
+
+	if id := c.GetCookie(sessionOptions.CookieName); id != "" {
+		data, err := sessionStore.Load(id)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &session.values); err != nil {
+				// Unreadable sessions are replaced by a new one
+				log.Errorf("Session decoding error: %v", err)
+				session.values = map[string]json.RawMessage{}
+			} else {
+				session.id = id
+			}
+		case !errors.Is(err, ErrSessionNotFound):
+			return nil, err
+		}
+	}
+
+	c.SetData(SessionKey, session)
+
+	return session, nil
+}
+
+// currentSession returns the session of the request, nil when it can not be loaded.
+func currentSession(c *core.Ctx) *Session {
+	session, err := loadSession(c)
+	if err != nil {
+		log.Errorf("Session load error: %v", err)
+
+		return nil
+	}
+
+	return session
+}
+
+// GetSession returns the session value of the key decoded as T; ok is false when the key is not set or its
+// value is not a T.
+//
+// Example Usage:
+//
+//	cart, ok := http.GetSession[dto.Cart](c, "cart")
+func GetSession[T any](c *core.Ctx, key string) (value T, ok bool) {
+	session := currentSession(c)
+	if session == nil {
+		return value, false
+	}
+
+	session.mu.Lock()
+	encoded, found := session.values[key]
+	session.mu.Unlock()
+	if !found {
+		return value, false
+	}
+
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return value, false
+	}
+
+	return value, true
+}
+
+// SetSession sets the session value of the key. The value must be JSON serializable.
+//
+// Example Usage:
+//
+//	http.SetSession(c, "cart", cart)
+func SetSession(c *core.Ctx, key string, value any) error {
+	session := currentSession(c)
+	if session == nil {
+		return errors.New("session is unavailable")
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	session.values[key] = encoded
+	session.changed = true
+	session.mu.Unlock()
+
+	return nil
+}
+
+// DeleteSession removes the session value of the key.
+func DeleteSession(c *core.Ctx, key string) {
+	session := currentSession(c)
+	if session == nil {
+		return
+	}
+
+	session.mu.Lock()
+	if _, ok := session.values[key]; ok {
+		delete(session.values, key)
+		session.changed = true
+	}
+	session.mu.Unlock()
+}
+
+// DestroySession removes the session from the store and expires its cookie, e.g. on logout.
+func DestroySession(c *core.Ctx) error {
+	session := currentSession(c)
+	if session == nil {
+		return nil
+	}
+
+	session.mu.Lock()
+	id := session.id
+	session.id = ""
+	session.values = map[string]json.RawMessage{}
+	session.changed = false
+	session.mu.Unlock()
+
+	if id == "" {
+		return nil
+	}
+
+	c.Root().Response.Header.DelClientCookie(sessionOptions.CookieName)
+
+	return sessionStore.Delete(id)
+}
+
+// SaveSession stores the changed session of the request and sets its cookie; new sessions get an ID.
+// Unchanged sessions are not written.
+func SaveSession(c *core.Ctx) error {
+	session, ok := c.GetData(SessionKey).(*Session)
+	if !ok || sessionStore == nil {
+		return nil
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !session.changed {
+		return nil
+	}
+
+	if session.id == "" {
+		session.id = newSessionID()
+	}
+
+	data, err := json.Marshal(session.values)
+	if err != nil {
+		return err
+	}
+	if err := sessionStore.Save(session.id, data, sessionOptions.TTL); err != nil {
+		return err
+	}
+	session.changed = false
+
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+	cookie.SetKey(sessionOptions.CookieName)
+	cookie.SetValue(session.id)
+	cookie.SetPath("/")
+	cookie.SetMaxAge(int(sessionOptions.TTL.Seconds()))
+	cookie.SetHTTPOnly(true)
+	cookie.SetSecure(!sessionOptions.Insecure)
+	cookie.SetSameSite(sessionOptions.SameSite)
+	c.Root().Response.Header.SetCookie(cookie)
+
+	return nil
+}
+
+// newSessionID returns a random session ID of 256 bits.
+func newSessionID() string {
+	id := make([]byte, 32)
+	_, _ = rand.Read(id)
+
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// sessionHandler handler wrapper loading and saving the session.
+type sessionHandler struct {
+	core.IHandler
+}
+
+// WithSession wraps a handler so the session is loaded before its Validate (see ProcessSession) and saved
+// once the response is rendered, rejected requests included.
+//
+// Example Usage:
+//
+//	router.POST("/checkout/{step}", http.WithSession(api.NewCheckoutStepApi()))
+func WithSession(handler core.IHandler) core.IHandler {
+	return &sessionHandler{IHandler: handler}
+}
+
+// Validate loads the session and runs the wrapped Validate.
+func (h *sessionHandler) Validate(c *core.Ctx) error {
+	if err := ProcessSession(c); err != nil {
+		return err
+	}
+
+	err := h.IHandler.Validate(c)
+	if err != nil {
+		h.save(c)
+	}
+
+	return err
+}
+
+// Handle runs the wrapped handler and saves the session.
+func (h *sessionHandler) Handle(c *core.Ctx) error {
+	err := h.IHandler.Handle(c)
+	h.save(c)
+
+	return err
+}
+
+// save saves the session, failures are logged as the response is already rendered.
+func (h *sessionHandler) save(c *core.Ctx) {
+	if err := SaveSession(c); err != nil {
+		log.Errorf("Session save error: %v", err)
+	}
+}
+
+// SessionBridge core.ISession implementation backed by the sessions of this package, so c.GetSession and
+// c.SetSession share data with GetSession and SetSession. Values read through it are decoded as JSON (maps,
+// float64 numbers, ...).
+//
+// Example Usage:
+//
+//	core.RegisterSession(http.SessionBridge{})
+type SessionBridge struct{}
+
+// Set sets the session value of the key.
+func (SessionBridge) Set(c *core.Ctx, key string, value any) {
+	if err := SetSession(c, key, value); err != nil {
+		log.Errorf("Session set error: %v", err)
+	}
+}
+
+// Get returns the session value of the key, nil when not set.
+func (SessionBridge) Get(c *core.Ctx, key string) any {
+	value, _ := GetSession[any](c, key)
+
+	return value
+}
+
+// memorySessionPurgeSize number of stored sessions from which expired ones are purged on save.
+const memorySessionPurgeSize = 10000
+
+// MemorySessionStore in-memory SessionStore for development and single-instance deployments.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+}
+
+// memorySession stored session with its expiration.
+type memorySession struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemorySessionStore creates an in-memory SessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[string]memorySession{}}
+}
+
+// Load returns the data of a session.
+func (s *MemorySessionStore) Load(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || time.Now().After(session.expiresAt) {
+		delete(s.sessions, id)
+
+		return nil, ErrSessionNotFound
+	}
+
+	return session.data, nil
+}
+
+// Save stores the data of a session for the TTL. Expired sessions are purged once the store is large.
+func (s *MemorySessionStore) Save(id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.sessions) >= memorySessionPurgeSize {
+		for key, session := range s.sessions {
+			if now.After(session.expiresAt) {
+				delete(s.sessions, key)
+			}
+		}
+	}
+	s.sessions[id] = memorySession{data: data, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// Delete removes a session.
+func (s *MemorySessionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ====================================================================
+// ============================== Slugs ===============================
+// ====================================================================
+
+// SlugMaxLength default maximum length of slugs generated by Slugify.
+const SlugMaxLength = 100
+
+// slugMaxAttempts maximum number of suffixes tried by UniqueSlug.
+const slugMaxAttempts = 100
+
+// slugTransliterations letters which are not decomposed into a base letter plus combining marks.
+var slugTransliterations = map[rune]string{
+	'đ': "d", 'Đ': "d", 'ß': "ss", 'æ': "ae", 'Æ': "ae", 'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l", 'þ': "th", 'Þ': "th", 'œ': "oe", 'Œ': "oe", 'ı': "i",
+}
+
+// SlugExistsFunc checks whether a slug is already taken, typically by querying the database.
+type SlugExistsFunc func(slug string) (bool, error)
+
+// Slugify converts a text to a URL slug: accents are transliterated to ASCII, letters are lowercased,
+// any other character sequence becomes a single '-' and the result is capped at maxLength
+// (SlugMaxLength by default) without cutting in the middle of a word when possible.
+//
+// Example Usage:
+//
+//	http.Slugify("Đường phố Hà Nội") // "duong-pho-ha-noi"
+func Slugify(text string, maxLength ...int) string {
+	limit := SlugMaxLength
+	if len(maxLength) > 0 && maxLength[0] > 0 {
+		limit = maxLength[0]
+	}
+
+	var builder strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // Combining mark left by the decomposition of an accented letter
+		}
+
+		if translit, ok := slugTransliterations[r]; ok {
+			builder.WriteString(translit)
+			dash = false
+
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			builder.WriteRune(unicode.ToLower(r))
+			dash = false
+		} else if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return truncateSlug(strings.TrimSuffix(builder.String(), "-"), limit)
+}
+
+// UniqueSlug generates a slug for the text which is not taken according to the exists function.
+// On collision, a numeric suffix is appended ("title", "title-2", "title-3", ...) while keeping the length cap.
+//
+// Example Usage:
+//
+//	slug, err := http.UniqueSlug(req.Title, func(slug string) (bool, error) {
+//		return postRepository.SlugExists(slug)
+//	})
+func UniqueSlug(text string, exists SlugExistsFunc, maxLength ...int) (string, error) {
+	limit := SlugMaxLength
+	if len(maxLength) > 0 && maxLength[0] > 0 {
+		limit = maxLength[0]
+	}
+
+	base := Slugify(text, limit)
+	slug := base
+	for attempt := 2; attempt <= slugMaxAttempts+1; attempt++ {
+		taken, err := exists(slug)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+
+		suffix := fmt.Sprintf("-%d", attempt)
+		slug = truncateSlug(base, limit-len(suffix)) + suffix
+	}
+
+	return "", fmt.Errorf("no unique slug found for %q after %d attempts", base, slugMaxAttempts)
+}
+
+// truncateSlug caps the slug length, preferring to cut at a '-' boundary.
+func truncateSlug(slug string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if len(slug) <= limit {
+		return slug
+	}
+
+	slug = slug[:limit]
+	if idx := strings.LastIndexByte(slug, '-'); idx > 0 {
+		slug = slug[:idx]
+	}
+
+	return strings.TrimSuffix(slug, "-")
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Context Snapshots ========================
+// ====================================================================
+
+// ContextSnapshot serializable request context handed to background jobs, so workers see the same
+// caller, tenant, locale and data as the request which enqueued them.
+type ContextSnapshot struct {
+	RequestID  string          `json:"request_id"`         // RequestID of the originating request
+	Principal  string          `json:"principal"`          // CallerIdentity of the originating request
+	Tenant     string          `json:"tenant,omitempty"`   // Tenant of the originating request (see RegisterTenant)
+	Locale     string          `json:"locale"`             // BCP 47 locale of the originating request
+	Route      string          `json:"route"`              // Method and route path template, e.g. "POST /reports"
+	DTOType    string          `json:"dto_type,omitempty"` // Type of the validated request DTO
+	DTO        json.RawMessage `json:"dto,omitempty"`      // JSON of the validated request DTO
+	CapturedAt time.Time       `json:"captured_at"`        // Snapshot time
+}
+
+// tenantFunc resolves the tenant of a request, requests have no tenant when nil.
+var tenantFunc func(c *core.Ctx) string
+
+// RegisterTenant registers the function resolving the tenant of a request (subdomain, header, principal, ...).
+//
+// Example Usage:
+//
+//	http.RegisterTenant(func(c *core.Ctx) string {
+//		return c.GetHeader("X-Tenant-ID")
+//	})
+func RegisterTenant(tenantFn func(c *core.Ctx) string) {
+	tenantFunc = tenantFn
+}
+
+// Tenant returns the tenant of the request, empty when no tenant function is registered.
+func Tenant(c *core.Ctx) string {
+	if tenantFunc == nil {
+		return ""
+	}
+
+	return tenantFunc(c)
+}
+
+// SnapshotCtx captures the request context for a background job: request ID, principal (CallerIdentity),
+// tenant, locale and the validated DTO stored by the Process* helpers. The snapshot is JSON serializable,
+// workers read it back with RestoreCtx and SnapshotDTO.
+//
+// Example Usage:
+//
+//	func (h CreateReportApi) Handle(c *core.Ctx) error {
+//		snapshot, err := http.SnapshotCtx(c)
+//		if err != nil {
+//			return err
+//		}
+//		queue.Enqueue("reports:generate", snapshot)
+//		...
+//	}
+func SnapshotCtx(c *core.Ctx) (ContextSnapshot, error) {
+	snapshot := ContextSnapshot{
+		RequestID:  RequestID(c),
+		Principal:  CallerIdentity(c),
+		Tenant:     Tenant(c),
+		Locale:     Locale(c).String(),
+		Route:      string(c.Root().Method()) + " " + RoutePath(c),
+		CapturedAt: time.Now(),
+	}
+
+	if requestData := c.GetData(RequestKey); requestData != nil {
+		encoded, err := json.Marshal(requestData)
+		if err != nil {
+			return snapshot, fmt.Errorf("snapshot request data: %w", err)
+		}
+		snapshot.DTOType = reflect.TypeOf(requestData).String()
+		snapshot.DTO = encoded
+	}
+
+	return snapshot, nil
+}
+
+// snapshotContextKey key of the ContextSnapshot in a context.Context.
+type snapshotContextKey struct{}
+
+// RestoreCtx returns a copy of the worker's context carrying the snapshot, read back with SnapshotFrom.
+//
+// Example Usage:
+//
+//	func generateReport(ctx context.Context, snapshot http.ContextSnapshot) error {
+//		ctx = http.RestoreCtx(ctx, snapshot)
+//		request, err := http.SnapshotDTO[dto.CreateReport](snapshot)
+//		...
+//	}
+func RestoreCtx(ctx context.Context, snapshot ContextSnapshot) context.Context {
+	return context.WithValue(ctx, snapshotContextKey{}, snapshot)
+}
+
+// SnapshotFrom returns the snapshot carried by a context restored with RestoreCtx.
+func SnapshotFrom(ctx context.Context) (ContextSnapshot, bool) {
+	snapshot, ok := ctx.Value(snapshotContextKey{}).(ContextSnapshot)
+
+	return snapshot, ok
+}
+
+// SnapshotDTO decodes the validated DTO of a snapshot. It fails when the snapshot holds a DTO of another type.
+func SnapshotDTO[T any](snapshot ContextSnapshot) (T, error) {
+	var requestData T
+
+	if typeName := reflect.TypeFor[T]().String(); snapshot.DTOType != typeName {
+		return requestData, fmt.Errorf("snapshot holds %q, not %q", snapshot.DTOType, typeName)
+	}
+
+	if err := json.Unmarshal(snapshot.DTO, &requestData); err != nil {
+		return requestData, fmt.Errorf("restore request data: %w", err)
+	}
+
+	return requestData, nil
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gflydev/core"
+	"github.com/valyala/fasthttp"
+)
+
+// ====================================================================
+// ========================= Static Assets & SPA ======================
+// ====================================================================
+
+// Cache-Control values of static responses.
+const (
+	ImmutableCacheControl  = "public, max-age=31536000, immutable"
+	RevalidateCacheControl = "no-cache"
+)
+
+// HashedAssetPattern matches file names carrying a content hash, served with ImmutableCacheControl:
+// hex hashes ("main.3f2a1b9c.js", webpack) and 8-character base64url hashes ("index-BfXk3a2Q.js", Vite/Rollup).
+// Its first group captures the hash, which must mix digits and letters, so words ("user-settings.json") and
+// dates ("backup-20240101.zip") are not taken for hashes.
+var HashedAssetPattern = regexp.MustCompile(`[.-]([0-9a-fA-F]{8,}|[A-Za-z0-9_]{8})\.[^./]+$`)
+
+// StaticCacheControl returns the Cache-Control of a static file: immutable for content-hashed assets,
+// revalidated for the others (index.html, favicon.ico, ...) so deployments are picked up.
+func StaticCacheControl(name string) string {
+	match := HashedAssetPattern.FindStringSubmatch(path.Base(name))
+	if match == nil {
+		return RevalidateCacheControl
+	}
+
+	hash := match[0]
+	if len(match) > 1 {
+		hash = match[1]
+	}
+	if !strings.ContainsAny(hash, "0123456789") || strings.Trim(hash, "0123456789_") == "" {
+		return RevalidateCacheControl
+	}
+
+	return ImmutableCacheControl
+}
+
+// SPAOptions configuration of SPAHandler.
+type SPAOptions struct {
+	Root        string   // Directory of the built front-end, e.g. "./public"
+	Index       string   // Index file served for client-side routes, "index.html" by default
+	APIPrefixes []string // Paths never falling back to the index, "/api/" by default
+}
+
+// SPAHandler handler serving the files of a built front-end, with the index file as fallback for
+// client-side routes. Unmatched API paths get a JSON 404 instead of the index.
+type SPAHandler struct {
+	core.Endpoint
+	options     SPAOptions
+	fileHandler fasthttp.RequestHandler
+}
+
+// NewSPAHandler creates the handler of a single-page application. It is meant as the router's NotFound
+// handler, so routes registered on the router (the API) keep precedence.
+//
+// Example Usage:
+//
+//	spa := http.NewSPAHandler(http.SPAOptions{Root: "./public"})
+//	app.Router().NotFound = spa.Handle
+func NewSPAHandler(options SPAOptions) *SPAHandler {
+	if options.Index == "" {
+		options.Index = "index.html"
+	}
+	if options.APIPrefixes == nil {
+		options.APIPrefixes = []string{"/api/"}
+	}
+
+	fs := &fasthttp.FS{
+		Root:            options.Root,
+		AcceptByteRange: true,
+		Compress:        true,
+	}
+
+	return &SPAHandler{
+		options:     options,
+		fileHandler: fs.NewRequestHandler(),
+	}
+}
+
+// Handle serves the requested file, or the index file for client-side routes.
+func (h *SPAHandler) Handle(c *core.Ctx) error {
+	requestPath := path.Clean("/" + string(c.Root().Path()))
+
+	for _, prefix := range h.options.APIPrefixes {
+		if strings.HasPrefix(requestPath+"/", prefix) {
+			return WriteError(c, &Error{
+				Code:    "NOT_FOUND",
+				Message: "Resource not found",
+			}, core.StatusNotFound)
+		}
+	}
+
+	if !c.Root().IsGet() && !c.Root().IsHead() {
+		return WriteError(c, &Error{
+			Code:    "NOT_FOUND",
+			Message: "Resource not found",
+		}, core.StatusNotFound)
+	}
+
+	if info, err := os.Stat(filepath.Join(h.options.Root, filepath.FromSlash(requestPath))); err == nil && info.Mode().IsRegular() {
+		c.SetHeader(core.HeaderCacheControl, StaticCacheControl(requestPath))
+		h.fileHandler(c.Root())
+
+		return nil
+	}
+
+	// Client-side route
+	c.SetHeader(core.HeaderCacheControl, RevalidateCacheControl)
+	c.Root().SendFile(filepath.Join(h.options.Root, h.options.Index))
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package http
+
+import "testing"
+
+func TestStaticCacheControl(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"/assets/index-BfXk3a2Q.js", ImmutableCacheControl},
+		{"/assets/main.3f2a1b9c.js", ImmutableCacheControl},
+		{"/static/css/main.3f2a1b9c4d5e6f70.css", ImmutableCacheControl},
+		{"/assets/vendor-a_B3c_D4.js", ImmutableCacheControl},
+		{"/index.html", RevalidateCacheControl},
+		{"/favicon.ico", RevalidateCacheControl},
+		{"/docs/user-settings.json", RevalidateCacheControl},
+		{"/terms-document.pdf", RevalidateCacheControl},
+		{"/backup-20240101.zip", RevalidateCacheControl},
+		{"/reports/report-deadbeef.csv", RevalidateCacheControl},
+		{"/assets/logo-12345678.png", RevalidateCacheControl},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StaticCacheControl(tc.name); got != tc.want {
+				t.Errorf("StaticCacheControl(%q) = %q, want %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
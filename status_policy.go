@@ -0,0 +1,134 @@
+package http
+
+import (
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Status Policy ===========================
+// ====================================================================
+
+// FailureCategory category of a rejected request, answered with the status of the StatusPolicy.
+type FailureCategory int
+
+// Failure categories.
+const (
+	FailureMalformed    FailureCategory = iota // Request that can not be decoded: syntax, types, layouts, path IDs
+	FailureUnknownField                        // Fields not declared by the DTO, see ParseOptions.DisallowUnknownFields
+	FailureValidation                          // DTO failing its validation rules
+	FailureBusinessRule                        // Well-formed request refused by the rules of the service
+)
+
+// StatusPolicy HTTP statuses of the failure categories, 400 Bad Request or 422 Unprocessable Entity as teams
+// prefer. It is applied by the Process helpers, ParseStatus and WriteFailure, so every endpoint answers the same
+// failure with the same status. Zero statuses keep their default.
+type StatusPolicy struct {
+	Malformed    int // Status of malformed requests, 400 by default
+	UnknownField int // Status of unknown fields, 400 by default
+	Validation   int // Status of validation failures, 400 by default
+	BusinessRule int // Status of business-rule violations (rejected files, upload policies, ...), 422 by default
+}
+
+// defaultStatusPolicy statuses of the failure categories when not registered.
+var defaultStatusPolicy = StatusPolicy{
+	Malformed:    core.StatusBadRequest,
+	UnknownField: core.StatusBadRequest,
+	Validation:   core.StatusBadRequest,
+	BusinessRule: core.StatusUnprocessableEntity,
+}
+
+// statusPolicy the registered status policy.
+var statusPolicy = defaultStatusPolicy
+
+// RegisterStatusPolicy registers the statuses of the failure categories.
+//
+// Example Usage:
+//
+//	// Syntax errors are 400, everything the server understood but refused is 422
+//	http.RegisterStatusPolicy(http.StatusPolicy{
+//		UnknownField: core.StatusUnprocessableEntity,
+//		Validation:   core.StatusUnprocessableEntity,
+//	})
+func RegisterStatusPolicy(policy StatusPolicy) {
+	statusPolicy = policy.withDefaults()
+	if configSnapshot.Load() != nil {
+		_ = UpdateConfig(func(config *Config) { config.Statuses = statusPolicy })
+	}
+}
+
+// withDefaults returns the policy with the default status of the categories it leaves zero.
+func (p StatusPolicy) withDefaults() StatusPolicy {
+	if p.Malformed == 0 {
+		p.Malformed = defaultStatusPolicy.Malformed
+	}
+	if p.UnknownField == 0 {
+		p.UnknownField = defaultStatusPolicy.UnknownField
+	}
+	if p.Validation == 0 {
+		p.Validation = defaultStatusPolicy.Validation
+	}
+	if p.BusinessRule == 0 {
+		p.BusinessRule = defaultStatusPolicy.BusinessRule
+	}
+
+	return p
+}
+
+// Status returns the status of a failure category.
+func (p StatusPolicy) Status(category FailureCategory) int {
+	p = p.withDefaults()
+
+	switch category {
+	case FailureUnknownField:
+		return p.UnknownField
+	case FailureValidation:
+		return p.Validation
+	case FailureBusinessRule:
+		return p.BusinessRule
+	default:
+		return p.Malformed
+	}
+}
+
+// FailureStatus returns the status of a failure category for the request, from the StatusPolicy of its
+// configuration (see RequestConfig).
+//
+// Example Usage:
+//
+//	if order.Total > customer.CreditLimit {
+//		return c.Error(&http.Error{Code: "CREDIT_LIMIT", Message: "Credit limit exceeded"},
+//			http.FailureStatus(c, http.FailureBusinessRule))
+//	}
+func FailureStatus(c *core.Ctx, category FailureCategory) int {
+	return RequestConfig(c).Statuses.Status(category)
+}
+
+// WriteFailure sends the Error of a rejected request with the status of its category (see WriteError).
+//
+// Example Usage:
+//
+//	if stock < requestData.Quantity {
+//		return http.WriteFailure(c, http.FailureBusinessRule, &http.Error{
+//			Code:    "OUT_OF_STOCK",
+//			Message: "Not enough items in stock",
+//		})
+//	}
+func WriteFailure(c *core.Ctx, category FailureCategory, errData *Error) error {
+	return WriteError(c, errData, FailureStatus(c, category))
+}
+
+// parseStatus returns the status of an Error returned by Parse for the request.
+func parseStatus(c *core.Ctx, errData *Error) int {
+	if errData == nil {
+		return FailureStatus(c, FailureMalformed)
+	}
+
+	switch errData.Code {
+	case ErrorCodeBodyTooLarge:
+		return core.StatusRequestEntityTooLarge
+	case ErrorCodeUnknownFields:
+		return FailureStatus(c, FailureUnknownField)
+	default:
+		return FailureStatus(c, FailureMalformed)
+	}
+}
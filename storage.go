@@ -0,0 +1,315 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================== Storage =============================
+// ====================================================================
+
+// ErrObjectNotFound returned by Storage when no object has the key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrInvalidKey returned by Storage for keys which are empty, absolute or escape their root ("..").
+var ErrInvalidKey = errors.New("invalid object key")
+
+// ObjectInfo metadata of a stored object.
+type ObjectInfo struct {
+	Key         string    // Object key, e.g. "avatars/7.png"
+	ContentType string    // MIME type
+	Size        int64     // Size in bytes
+	ModifiedAt  time.Time // Last modification time
+}
+
+// Storage is an interface for object storages of uploaded files (local disk, S3-compatible services, ...).
+type Storage interface {
+	// Put stores the content under the key, replacing any object with the same key. Size is -1 when unknown.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+
+	// Get opens the object of the key, ErrObjectNotFound when there is none. The caller closes the content.
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+
+	// Delete removes the object of the key; deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL downloading the object without credentials until expiresIn elapses.
+	SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error)
+}
+
+var (
+	storageMu sync.RWMutex
+	// registeredStorage storage used by StoreUpload and WriteStored.
+	registeredStorage Storage
+)
+
+// RegisterStorage registers the storage used by StoreUpload and WriteStored.
+//
+// Example Usage:
+//
+//	http.RegisterStorage(&http.S3Storage{
+//		Endpoint:        "https://s3.eu-west-1.amazonaws.com",
+//		Region:          "eu-west-1",
+//		Bucket:          "uploads",
+//		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+//		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+//	})
+func RegisterStorage(s Storage) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+
+	registeredStorage = s
+}
+
+// GetStorage returns the registered storage, nil when none is registered.
+func GetStorage() Storage {
+	storageMu.RLock()
+	defer storageMu.RUnlock()
+
+	return registeredStorage
+}
+
+// StoreUpload hands a file received by ProcessUpload to the registered storage under the key, and removes
+// its temporary file.
+//
+// Example Usage:
+//
+//	func (h UploadAvatarApi) Handle(c *core.Ctx) error {
+//		avatar := http.Uploads(c)[0]
+//		info, err := http.StoreUpload(c, avatar, fmt.Sprintf("avatars/%d%s", userID, filepath.Ext(avatar.Name)))
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func StoreUpload(c *core.Ctx, file core.UploadedFile, key string) (ObjectInfo, error) {
+	storage := GetStorage()
+	if storage == nil {
+		return ObjectInfo{}, errors.New("storage is not registered")
+	}
+
+	content, err := os.Open(file.Path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer content.Close()
+
+	info := ObjectInfo{
+		Key:         key,
+		ContentType: contentTypeOf(file.Name),
+		Size:        file.Size,
+		ModifiedAt:  time.Now(),
+	}
+
+	if err := storage.Put(c.Root(), key, content, file.Size, info.ContentType); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	removeUploads([]core.UploadedFile{file})
+
+	return info, nil
+}
+
+// WriteStored answers a download request with the object of the key from the registered storage, negotiated
+// like WriteMedia (metadata, thumbnail or original); 404 when there is no such object.
+//
+// Example Usage:
+//
+//	func (h DownloadFileApi) Handle(c *core.Ctx) error {
+//		return http.WriteStored(c, "files/"+c.PathVal("name"))
+//	}
+func WriteStored(c *core.Ctx, key string) error {
+	storage := GetStorage()
+	if storage == nil {
+		return writeMediaError(c, errors.New("storage is not registered"))
+	}
+
+	content, info, err := storage.Get(c.Root(), key)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) || errors.Is(err, ErrInvalidKey) {
+			err = os.ErrNotExist
+		}
+
+		return writeMediaError(c, err)
+	}
+
+	// The content fetched with the metadata is used by the first Open, later ones fetch it again
+	opened := false
+	file := MediaFile{
+		Name:        path.Base(info.Key),
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		ModifiedAt:  info.ModifiedAt,
+		Open: func() (io.ReadCloser, error) {
+			if !opened {
+				opened = true
+
+				return content, nil
+			}
+
+			reader, _, err := storage.Get(c.Root(), key)
+
+			return reader, err
+		},
+	}
+
+	err = WriteMedia(c, file)
+	if !opened {
+		_ = content.Close()
+	}
+
+	return err
+}
+
+// contentTypeOf returns the MIME type of a file name from its extension.
+func contentTypeOf(name string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
+
+// cleanKey checks an object key, so it stays inside the storage's root.
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(key, "\\", "/"))
+	if key == "" || cleaned == "." || strings.HasPrefix(cleaned, "/") || cleaned == ".." ||
+		strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
+
+// ====================================================================
+// =========================== Local Storage ==========================
+// ====================================================================
+
+// LocalStorage Storage of objects as files of a local directory. Signed URLs are minted with SignURL, the
+// download endpoint verifies them with VerifySignedURL.
+type LocalStorage struct {
+	Root    string // Directory of the objects
+	BaseURL string // URL of the download endpoint, the key is appended, e.g. "/api/v1/files"
+}
+
+// NewLocalStorage creates a Storage in a local directory, created when missing.
+//
+// Example Usage:
+//
+//	local, err := http.NewLocalStorage("storage/app", "/api/v1/files")
+//	http.RegisterStorage(local)
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{Root: root, BaseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Put writes the content to a temporary file renamed to the key's file, so readers never see partial objects.
+func (s *LocalStorage) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Removes the temporary file unless renamed
+		_ = os.Remove(temp.Name())
+	}()
+
+	if _, err := io.Copy(temp, content); err != nil {
+		_ = temp.Close()
+
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), filePath)
+}
+
+// Get opens the key's file.
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = ErrObjectNotFound
+		}
+
+		return nil, ObjectInfo{}, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil || !stat.Mode().IsRegular() {
+		_ = file.Close()
+
+		return nil, ObjectInfo{}, ErrObjectNotFound
+	}
+
+	return file, ObjectInfo{
+		Key:         key,
+		ContentType: contentTypeOf(key),
+		Size:        stat.Size(),
+		ModifiedAt:  stat.ModTime(),
+	}, nil
+}
+
+// Delete removes the key's file.
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// SignedURL returns the URL of the download endpoint for the key, signed with SignURL.
+func (s *LocalStorage) SignedURL(_ context.Context, key string, expiresIn time.Duration) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return SignURL(s.BaseURL+"/"+cleaned, expiresIn)
+}
+
+// path returns the file path of a key.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.Root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ====================================================================
+// ============================ S3 Storage ============================
+// ====================================================================
+
+// s3UnsignedPayload payload hash of requests whose body is not signed (streamed uploads, presigned URLs).
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// s3MaxPresignDuration maximum validity of presigned URLs accepted by S3.
+const s3MaxPresignDuration = 7 * 24 * time.Hour
+
+// S3Storage Storage of objects in a bucket of Amazon S3 or an S3-compatible service (MinIO, Cloudflare R2,
+// DigitalOcean Spaces, ...). Requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	Endpoint        string       // Service URL, e.g. "https://s3.eu-west-1.amazonaws.com" or "http://minio:9000"
+	Region          string       // Signing region, "us-east-1" by default ("auto" for R2)
+	Bucket          string       // Bucket name
+	AccessKeyID     string       // Access key ID
+	SecretAccessKey string       // Secret access key
+	PathStyle       bool         // Addresses the bucket in the path (endpoint/bucket/key) instead of the host name
+	Client          *http.Client // HTTP client, http.DefaultClient by default
+}
+
+// Put uploads the content with a PUT Object request. S3 requires the size of uploads; unknown sizes (-1) are
+// buffered to compute it.
+func (s *S3Storage) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		content, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	request, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+
+	return response.Body.Close()
+}
+
+// Get downloads the object with a GET Object request.
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	request, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	info := ObjectInfo{
+		Key:         key,
+		ContentType: response.Header.Get("Content-Type"),
+		Size:        response.ContentLength,
+	}
+	if info.ContentType == "" {
+		info.ContentType = contentTypeOf(key)
+	}
+	if modifiedAt, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		info.ModifiedAt = modifiedAt
+	}
+
+	return response.Body, info, nil
+}
+
+// Delete removes the object with a DELETE Object request.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	request, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+
+	return response.Body.Close()
+}
+
+// SignedURL returns a presigned GET URL of the object, valid up to 7 days.
+func (s *S3Storage) SignedURL(_ context.Context, key string, expiresIn time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, "", expiresIn, time.Now())
+}
+
+// SignedUploadURL returns a presigned PUT URL of the object, valid up to 7 days. The Content-Type header is
+// signed, so clients must upload with the given content type.
+func (s *S3Storage) SignedUploadURL(_ context.Context, key, contentType string, expiresIn time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, contentType, expiresIn, time.Now())
+}
+
+// presign returns the presigned URL of a request signed at a time, with the Content-Type header signed
+// when contentType is set.
+func (s *S3Storage) presign(method, key, contentType string, expiresIn time.Duration, now time.Time) (string, error) {
+	if expiresIn <= 0 || expiresIn > s3MaxPresignDuration {
+		return "", fmt.Errorf("presigned URLs expire within %s", s3MaxPresignDuration)
+	}
+
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	headers := http.Header{}
+	signedHeaders := []string{"host"}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+		signedHeaders = []string{"content-type", "host"}
+	}
+
+	now = now.UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiresIn.Seconds())))
+	query.Set("X-Amz-SignedHeaders", strings.Join(signedHeaders, ";"))
+	objectURL.RawQuery = s3CanonicalQuery(query)
+
+	signature := s.signature(method, objectURL, headers, signedHeaders, s3UnsignedPayload, now)
+	objectURL.RawQuery += "&X-Amz-Signature=" + signature
+
+	return objectURL.String(), nil
+}
+
+// newRequest creates a signed request on the object of the key.
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, objectURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	signature := s.signature(method, objectURL, request.Header, signedHeaders, s3UnsignedPayload, now)
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, s.scope(now), strings.Join(signedHeaders, ";"), signature))
+
+	return request, nil
+}
+
+// do sends a request, mapping 404 to ErrObjectNotFound and other failures to errors with S3's message.
+func (s *S3Storage) do(request *http.Request) (*http.Response, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 300 {
+		return response, nil
+	}
+
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	return nil, fmt.Errorf("s3 %s %s: %s %s", request.Method, request.URL.Path, response.Status,
+		strings.TrimSpace(string(message)))
+}
+
+// objectURL returns the URL of the object of the key.
+func (s *S3Storage) objectURL(key string) (*url.URL, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(s.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if s.PathStyle {
+		endpoint.Path += "/" + s.Bucket + "/" + cleaned
+	} else {
+		endpoint.Host = s.Bucket + "." + endpoint.Host
+		endpoint.Path += "/" + cleaned
+	}
+	endpoint.RawPath = s3EscapePath(endpoint.Path)
+
+	return endpoint, nil
+}
+
+// scope returns the credential scope of a signing time.
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region() + "/s3/aws4_request"
+}
+
+// region returns the signing region.
+func (s *S3Storage) region() string {
+	if s.Region == "" {
+		return "us-east-1"
+	}
+
+	return s.Region
+}
+
+// signature computes the Signature Version 4 of a request.
+func (s *S3Storage) signature(method string, requestURL *url.URL, headers http.Header, signedHeaders []string,
+	payloadHash string, now time.Time) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := headers.Get(name)
+		if name == "host" {
+			value = requestURL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		requestURL.EscapedPath(),
+		requestURL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		s.scope(now),
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + s.SecretAccessKey)
+	for _, part := range []string{now.Format("20060102"), s.region(), "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+
+	return hex.EncodeToString(key)
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name, escaped as Signature Version 4 requires.
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath escapes an object path, keeping its slashes.
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes every byte but the unreserved characters of RFC 3986 (and slashes unless
+// encodeSlash).
+func s3Escape(value string, encodeSlash bool) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+		switch {
+		case 'A' <= char && char <= 'Z', 'a' <= char && char <= 'z', '0' <= char && char <= '9',
+			char == '-', char == '_', char == '.', char == '~':
+			escaped.WriteByte(char)
+		case char == '/' && !encodeSlash:
+			escaped.WriteByte(char)
+		default:
+			escaped.WriteString(fmt.Sprintf("%%%02X", char))
+		}
+	}
+
+	return escaped.String()
+}
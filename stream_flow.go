@@ -0,0 +1,279 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================= Stream Flow Control ========================
+// ====================================================================
+
+// Stream metrics reported to the registered Metrics backend.
+const (
+	MetricStreamStalls  = "http_stream_stalls_total"  // Labels: stream
+	MetricStreamDropped = "http_stream_dropped_total" // Labels: stream
+	MetricStreamAborts  = "http_stream_aborts_total"  // Labels: stream
+)
+
+// SlowClientPolicy tells how records are handled while the client of a streamed response is stalled.
+type SlowClientPolicy int
+
+// Slow client policies.
+const (
+	SlowClientWait SlowClientPolicy = iota // The source is paused until the client catches up (default)
+	SlowClientDrop                         // Events are dropped, then a notice tells the client how many
+)
+
+// StreamFlowControl back-pressure of streamed responses (SizePolicyStream lists, access log exports). Records
+// are queued ahead of the client up to QueueSize; once the queue is full the source is paused instead of
+// buffering more. A stream is stalled when a record waits StallThreshold for a queue slot, or a write to the
+// client takes longer than StallThreshold.
+type StreamFlowControl struct {
+	QueueSize      int              // Records queued ahead of the client, 64 when zero
+	StallThreshold time.Duration    // Wait marking the stream as stalled, 1s when zero
+	MaxStall       time.Duration    // Wait after which a stalled stream is aborted, unlimited when zero
+	Policy         SlowClientPolicy // Handling of records while stalled, events streams only
+}
+
+// streamFlowControl flow control of streamed responses.
+var streamFlowControl StreamFlowControl
+
+// RegisterStreamFlowControl registers the back-pressure of streamed responses.
+// Once a configuration snapshot is loaded (see ReloadConfig), the flow control of the snapshot is replaced.
+//
+// Example Usage:
+//
+//	http.RegisterStreamFlowControl(http.StreamFlowControl{
+//		StallThreshold: 2 * time.Second,
+//		MaxStall:       time.Minute, // Release the source of clients gone silent
+//		Policy:         http.SlowClientDrop,
+//	})
+func RegisterStreamFlowControl(control StreamFlowControl) {
+	streamFlowControl = control
+	if configSnapshot.Load() != nil {
+		if err := UpdateConfig(func(config *Config) { config.Streams = control }); err != nil {
+			log.Errorf("Stream flow control not registered: %v", err)
+		}
+	}
+}
+
+// ErrStreamStalled returned to stream sources once the client stalled longer than StreamFlowControl.MaxStall.
+var ErrStreamStalled = errors.New("stream client stalled")
+
+// StreamFlowStats statistics of streamed responses.
+type StreamFlowStats struct {
+	Streams uint64 `json:"streams"` // Streamed responses
+	Stalled uint64 `json:"stalled"` // Streams whose client stalled
+	Dropped uint64 `json:"dropped"` // Records dropped while clients were stalled
+	Aborted uint64 `json:"aborted"` // Streams ended early, after MaxStall or a write error
+}
+
+var (
+	streamCount   atomic.Uint64
+	streamStalled atomic.Uint64
+	streamDropped atomic.Uint64
+	streamAborted atomic.Uint64
+)
+
+// StreamStats returns the statistics of streamed responses.
+func StreamStats() StreamFlowStats {
+	return StreamFlowStats{
+		Streams: streamCount.Load(),
+		Stalled: streamStalled.Load(),
+		Dropped: streamDropped.Load(),
+		Aborted: streamAborted.Load(),
+	}
+}
+
+// streamFlow bounded queue between the source of a streamed response and the client.
+type streamFlow struct {
+	w       *bufio.Writer
+	control StreamFlowControl
+	labels  map[string]string
+	notice  func(dropped int) []byte // Notice of dropped records, nil when records can not be dropped
+	queue   chan []byte
+	failed  chan struct{} // Closed when the stream is aborted
+	drained chan struct{} // Closed once the queue is drained
+	err     error         // Cause of the abort, set before failed is closed
+	aborted atomic.Bool
+	stalled atomic.Bool
+	dropped int // Records dropped since the last notice
+}
+
+// streamBody sets the streamed body of the response, written by source through a streamFlow. notice returns
+// the notice of dropped records, nil when records must not be dropped.
+func streamBody(c *core.Ctx, name string, notice func(dropped int) []byte, source func(flow *streamFlow) error) {
+	labels := map[string]string{"stream": name}
+	control := RequestConfig(c).Streams
+
+	c.Root().Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+		flow := newStreamFlow(w, control, labels, notice)
+		if err := source(flow); errors.Is(err, ErrStreamStalled) {
+			log.Warnf("Stream %s aborted, client stalled for %s", name, flow.control.MaxStall)
+		}
+		flow.close()
+	})
+}
+
+// newStreamFlow creates the flow of a stream and starts writing its queue to the client.
+func newStreamFlow(w *bufio.Writer, control StreamFlowControl, labels map[string]string,
+	notice func(dropped int) []byte) *streamFlow {
+	if control.QueueSize <= 0 {
+		control.QueueSize = 64
+	}
+	if control.StallThreshold <= 0 {
+		control.StallThreshold = time.Second
+	}
+	if control.Policy != SlowClientDrop {
+		notice = nil
+	}
+
+	flow := &streamFlow{
+		w:       w,
+		control: control,
+		labels:  labels,
+		notice:  notice,
+		queue:   make(chan []byte, control.QueueSize),
+		failed:  make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	streamCount.Add(1)
+	go flow.drain()
+
+	return flow
+}
+
+// Send queues a chunk, waiting for a slot while the client is slow. Chunks are dropped instead when the
+// policy allows it. An error is returned once the stream is aborted: the source must stop.
+func (f *streamFlow) Send(chunk []byte) error {
+	if f.dropped > 0 {
+		if !f.offer(f.notice(f.dropped)) {
+			f.drop()
+
+			return nil
+		}
+		f.dropped = 0
+	}
+
+	select {
+	case <-f.failed:
+		return f.err
+	default:
+	}
+
+	if f.offer(chunk) {
+		return nil
+	}
+
+	stall := time.NewTimer(f.control.StallThreshold)
+	defer stall.Stop()
+	select {
+	case f.queue <- chunk:
+		return nil
+	case <-f.failed:
+		return f.err
+	case <-stall.C:
+	}
+
+	f.markStalled()
+	if f.notice != nil {
+		f.drop()
+
+		return nil
+	}
+
+	var deadline <-chan time.Time
+	if f.control.MaxStall > 0 {
+		timer := time.NewTimer(f.control.MaxStall)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	select {
+	case f.queue <- chunk:
+		return nil
+	case <-f.failed:
+		return f.err
+	case <-deadline:
+		f.abort(ErrStreamStalled)
+
+		return ErrStreamStalled
+	}
+}
+
+// offer queues a chunk if a slot is free.
+func (f *streamFlow) offer(chunk []byte) bool {
+	select {
+	case f.queue <- chunk:
+		return true
+	default:
+		return false
+	}
+}
+
+// drop counts a dropped record.
+func (f *streamFlow) drop() {
+	f.dropped++
+	streamDropped.Add(1)
+	incCounter(MetricStreamDropped, f.labels)
+}
+
+// markStalled counts the stream as stalled, once.
+func (f *streamFlow) markStalled() {
+	if f.stalled.CompareAndSwap(false, true) {
+		streamStalled.Add(1)
+		incCounter(MetricStreamStalls, f.labels)
+	}
+}
+
+// abort ends the stream: queued chunks are discarded and Send returns err.
+func (f *streamFlow) abort(err error) {
+	if f.aborted.CompareAndSwap(false, true) {
+		f.err = err
+		close(f.failed)
+		streamAborted.Add(1)
+		incCounter(MetricStreamAborts, f.labels)
+	}
+}
+
+// drain writes the queued chunks to the client, flushing whenever the queue is empty.
+func (f *streamFlow) drain() {
+	defer close(f.drained)
+
+	for chunk := range f.queue {
+		if f.aborted.Load() {
+			continue
+		}
+
+		_, err := f.w.Write(chunk)
+		if err == nil && len(f.queue) == 0 {
+			start := time.Now()
+			err = f.w.Flush()
+			if time.Since(start) > f.control.StallThreshold {
+				f.markStalled()
+			}
+		}
+		if err != nil {
+			f.abort(err)
+		}
+	}
+}
+
+// close sends the notice of the last dropped records, then waits for the queue to be drained.
+func (f *streamFlow) close() {
+	if f.dropped > 0 && !f.aborted.Load() {
+		select {
+		case f.queue <- f.notice(f.dropped):
+		case <-f.failed:
+		}
+	}
+
+	close(f.queue)
+	<-f.drained
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Strict Numbers ==========================
+// ====================================================================
+
+// jsonUnmarshalerType type of json.Unmarshaler, values decoding themselves are not checked.
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// checkNumbers checks the JSON numbers of the body fit the fields of typ they are decoded into:
+// integers must be in the range of the field's type, and integer literals decoded into floats must be
+// exactly representable (e.g. int64 IDs above 2^53 sent to a float64 field).
+// It returns the errors by field path, empty when all numbers fit.
+func checkNumbers(body []byte, typ reflect.Type) core.Data {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		// Syntax errors are reported by the decoding itself
+		return nil
+	}
+
+	errorData := core.Data{}
+	checkNumberValue(document, typ, "", errorData)
+
+	return errorData
+}
+
+func checkNumberValue(value any, typ reflect.Type, path string, errorData core.Data) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for name, item := range typed {
+				if field, ok := jsonField(typ, name); ok {
+					checkNumberValue(item, field.Type, joinPath(path, name), errorData)
+				}
+			}
+		case reflect.Map:
+			for name, item := range typed {
+				checkNumberValue(item, typ.Elem(), joinPath(path, name), errorData)
+			}
+		default:
+		}
+	case []any:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, item := range typed {
+				checkNumberValue(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), errorData)
+			}
+		}
+	case json.Number:
+		if message := checkNumber(typed, typ); message != "" {
+			messages, _ := errorData[path].([]string)
+			errorData[path] = append(messages, message)
+		}
+	default:
+	}
+}
+
+// checkNumber returns the error message of a number which does not fit the type, empty when it fits.
+func checkNumber(number json.Number, typ reflect.Type) string {
+	literal := number.String()
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(literal, 10, typ.Bits()); err != nil {
+			if isIntegerLiteral(literal) {
+				return fmt.Sprintf("out of range for %s", typ.Kind())
+			}
+
+			return "must be an integer"
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(literal, 10, typ.Bits()); err != nil {
+			if isIntegerLiteral(literal) {
+				return fmt.Sprintf("out of range for %s", typ.Kind())
+			}
+
+			return "must be a non-negative integer"
+		}
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(literal, typ.Bits())
+		if err != nil {
+			return fmt.Sprintf("out of range for %s", typ.Kind())
+		}
+
+		if isIntegerLiteral(literal) {
+			exact, _ := new(big.Int).SetString(literal, 10)
+			converted, _ := big.NewFloat(parsed).Int(nil)
+			if exact.Cmp(converted) != 0 {
+				return "loses precision, send it as a string"
+			}
+		}
+	default:
+	}
+
+	return ""
+}
+
+// isIntegerLiteral checks a JSON number has no fraction nor exponent.
+func isIntegerLiteral(literal string) bool {
+	return !strings.ContainsAny(literal, ".eE")
+}
+
+// jsonField finds the struct field decoded from a JSON key, as encoding/json does (exact name first,
+// then case-insensitive), including fields of embedded structs.
+func jsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	var fallback *reflect.StructField
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+
+		fieldName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if fieldName == "-" {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		if fieldName == name {
+			return field, true
+		}
+		if fallback == nil && strings.EqualFold(fieldName, name) {
+			fallback = &field
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+
+	return reflect.StructField{}, false
+}
+
+// joinPath appends a field name to a dotted path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Request Summary ==========================
+// ====================================================================
+
+// summaryMaxFields number of fields named by a summary, the others are counted.
+const summaryMaxFields = 5
+
+// summaryActorFunc returns the actor named by summaries.
+var summaryActorFunc = CallerIdentity
+
+// RegisterSummaryActor registers the function naming the actor of summaries (user e-mail, API key name, ...),
+// CallerIdentity by default.
+//
+// Example Usage:
+//
+//	http.RegisterSummaryActor(func(c *core.Ctx) string {
+//		if user, ok := c.GetData(http.UserKey).(*models.User); ok {
+//			return user.Email
+//		}
+//		return "anonymous"
+//	})
+func RegisterSummaryActor(actorFn func(c *core.Ctx) string) {
+	summaryActorFunc = actorFn
+}
+
+// Summarize returns a short human-readable description of a mutation for audit logs, webhooks and chat
+// notifications: the actor (see RegisterSummaryActor), the action of the method, the resource named after the
+// DTO type (UpdateUserRequest is "User"), the path ID and the changed fields (see ChangedFields), or the fields
+// set by creations. Values are never included, and fields tagged `summary:"-"` are not named. Events recorded
+// for DTOs (see RegisterEvent) carry the summary of their request.
+//
+// Example Usage:
+//
+//	notifier.Send(http.Summarize(c, c.GetData(http.RequestKey)))
+//	// "admin@x.com updated User #42: email, role"
+func Summarize(c *core.Ctx, dto any) string {
+	var summary strings.Builder
+
+	summary.WriteString(summaryActorFunc(c))
+	summary.WriteString(" ")
+	summary.WriteString(summaryVerb(string(c.Root().Method())))
+
+	val := reflect.ValueOf(dto)
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			break
+		}
+		val = val.Elem()
+	}
+
+	if val.IsValid() && val.Kind() != reflect.Pointer {
+		summary.WriteString(" ")
+		summary.WriteString(resourceName(val.Type()))
+	}
+	if id := c.PathVal("id"); id != "" {
+		summary.WriteString(" #")
+		summary.WriteString(id)
+	}
+
+	if fields := summaryFields(c, val); len(fields) > 0 {
+		summary.WriteString(": ")
+		if len(fields) > summaryMaxFields {
+			summary.WriteString(strings.Join(fields[:summaryMaxFields], ", "))
+			summary.WriteString(fmt.Sprintf(" and %d more", len(fields)-summaryMaxFields))
+		} else {
+			summary.WriteString(strings.Join(fields, ", "))
+		}
+	}
+
+	if IsDryRun(c) {
+		summary.WriteString(" (dry run)")
+	}
+
+	return summary.String()
+}
+
+// summaryVerb returns the past tense action of a method.
+func summaryVerb(method string) string {
+	switch method {
+	case core.MethodPost:
+		return "created"
+	case core.MethodPut, core.MethodPatch:
+		return "updated"
+	case core.MethodDelete:
+		return "deleted"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// resourceName returns the resource of a DTO type, its name without action prefix nor DTO suffix.
+func resourceName(typ reflect.Type) string {
+	name, _, _ := strings.Cut(typ.Name(), "[")
+
+	resource := name
+	for _, prefix := range []string{"Create", "Update", "Patch", "Delete", "Add", "Upsert"} {
+		if trimmed, ok := strings.CutPrefix(resource, prefix); ok && trimmed != "" {
+			resource = trimmed
+
+			break
+		}
+	}
+	for _, suffix := range []string{"Request", "DTO", "Dto", "Data", "Form"} {
+		if trimmed, ok := strings.CutSuffix(resource, suffix); ok && trimmed != "" {
+			resource = trimmed
+
+			break
+		}
+	}
+
+	return resource
+}
+
+// summaryFields returns the JSON names of the changed fields of the request, else of the non-zero fields of the
+// DTO, without the fields tagged `summary:"-"`.
+func summaryFields(c *core.Ctx, val reflect.Value) []string {
+	var hidden map[string]bool
+	var present []string
+	if val.IsValid() && val.Kind() == reflect.Struct {
+		hidden = map[string]bool{}
+		for _, field := range reflect.VisibleFields(val.Type()) {
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || jsonName == "-" || field.Anonymous && jsonName == "" {
+				continue
+			}
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+
+			if field.Tag.Get("summary") == "-" {
+				hidden[jsonName] = true
+
+				continue
+			}
+
+			fieldValue, err := val.FieldByIndexErr(field.Index)
+			if err != nil {
+				// Field of a nil embedded struct pointer
+				continue
+			}
+			if !fieldValue.IsZero() {
+				present = append(present, jsonName)
+			}
+		}
+	}
+
+	changed := ChangedFields(c)
+	if len(changed) == 0 {
+		return present
+	}
+
+	fields := make([]string, 0, len(changed))
+	for _, field := range changed {
+		if !hidden[field] {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
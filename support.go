@@ -0,0 +1,157 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/errors"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ======================== Support References ========================
+// ====================================================================
+
+// SupportReferenceHeader response header carrying the support reference of 5xx responses.
+const SupportReferenceHeader = "X-Support-Reference"
+
+// SupportReferenceField key of the support reference in the Data of 5xx Error responses.
+const SupportReferenceField = "support_reference"
+
+// SupportReference returns the support reference of the request: a short code such as "7K3Q-M9XD", derived
+// from the request ID and start time, that users can read from a screenshot. 5xx responses written by WriteError
+// carry it, and it is logged with the request ID, so support teams find the matching server logs.
+//
+// Example Usage:
+//
+//	log.Errorf("Payment provider error: %v [%s]", err, http.SupportReference(c))
+func SupportReference(c *core.Ctx) string {
+	if reference, ok := c.GetData(SupportReferenceKey).(string); ok {
+		return reference
+	}
+
+	seed := fmt.Sprintf("%s|%d", RequestID(c), c.Root().Time().UnixNano())
+	sum := sha256.Sum256([]byte(seed))
+	bits := binary.BigEndian.Uint64(sum[:8]) >> 24 // 40 bits, 8 characters
+
+	reference := make([]byte, 9)
+	for i := 8; i >= 0; i-- {
+		if i == 4 {
+			reference[i] = '-'
+
+			continue
+		}
+		reference[i] = crockfordAlphabet[bits&0x1f]
+		bits >>= 5
+	}
+
+	c.SetData(SupportReferenceKey, string(reference))
+
+	return string(reference)
+}
+
+// WriteServerError logs the cause of a 5xx response, formatted like log.Errorf, with the request ID and the
+// support reference appended, then writes the Error with WriteError, which adds the reference to it.
+//
+// Example Usage:
+//
+//	if err := paymentClient.Charge(order); err != nil {
+//		return http.WriteServerError(c, &http.Error{
+//			Message: "Unable to charge the order",
+//		}, core.StatusBadGateway, "Payment provider error: %v", err)
+//	}
+func WriteServerError(c *core.Ctx, data *Error, status int, format string, args ...any) error {
+	log.Errorf("%s [request %s, support reference %s]", fmt.Sprintf(format, args...), RequestID(c),
+		SupportReference(c))
+
+	return writeError(c, withSupportReference(c, data), status)
+}
+
+// withSupportReference returns a copy of the Error with the support reference in its Data, and sets the
+// reference header. Errors are copied as they may be shared package variables.
+func withSupportReference(c *core.Ctx, data *Error) *Error {
+	reference := SupportReference(c)
+	c.Root().Response.Header.Set(SupportReferenceHeader, reference)
+
+	referenced := *data
+	referenced.Data = core.Data{}
+	for key, value := range data.Data {
+		referenced.Data[key] = value
+	}
+	referenced.Data[SupportReferenceField] = reference
+
+	return &referenced
+}
+
+// referencedHandler handler wrapper adding support references to the 5xx responses of plain errors and panics.
+type referencedHandler struct {
+	core.IHandler
+}
+
+// ReferenceServerErrors wraps a handler so the 5xx responses the router would render for it carry a support
+// reference too: a plain error returned by Validate or Handle, or a panic, is logged with WriteServerError and
+// answered with a generic Error instead of the error text. Responses already written with WriteError are only
+// given the reference header when they are 5xx and lack it.
+//
+// Example Usage:
+//
+//	router.POST("/orders", http.ReferenceServerErrors(api.NewCreateOrderApi()))
+func ReferenceServerErrors(handler core.IHandler) core.IHandler {
+	return &referencedHandler{IHandler: handler}
+}
+
+// Validate runs the wrapped Validate, referencing its server errors.
+func (h *referencedHandler) Validate(c *core.Ctx) (err error) {
+	defer recoverServerError(c, &err)
+
+	return referenceServerError(c, h.IHandler.Validate(c))
+}
+
+// Handle runs the wrapped Handle, referencing its server errors.
+func (h *referencedHandler) Handle(c *core.Ctx) (err error) {
+	defer recoverServerError(c, &err)
+
+	return referenceServerError(c, h.IHandler.Handle(c))
+}
+
+// referenceServerError writes the referenced 5xx response of a plain error, or adds the reference header to an
+// already written 5xx response.
+func referenceServerError(c *core.Ctx, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	response := &c.Root().Response
+	if errors.Is(err, errors.UnknownError) || len(response.Body()) > 0 {
+		if response.StatusCode() >= core.StatusInternalServerError &&
+			len(response.Header.Peek(SupportReferenceHeader)) == 0 {
+			response.Header.Set(SupportReferenceHeader, SupportReference(c))
+		}
+
+		return err
+	}
+
+	// The router answers 500 to plain errors returned without a status
+	status := response.StatusCode()
+	if status == core.StatusOK {
+		status = core.StatusInternalServerError
+	}
+	if status < core.StatusInternalServerError {
+		return err
+	}
+
+	return WriteServerError(c, &Error{Message: "Internal server error"}, status, "Handler error: %v", err)
+}
+
+// recoverServerError turns a panic of the wrapped handler into a referenced 500 response.
+func recoverServerError(c *core.Ctx, err *error) {
+	if r := recover(); r != nil {
+		c.Root().Response.ResetBody()
+		*err = WriteServerError(c, &Error{
+			Message: "Internal server error",
+		}, core.StatusInternalServerError, "Handler panic: %v\n%s", r, debug.Stack())
+	}
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Collection Sync ==========================
+// ====================================================================
+
+// DeltaTokenParam query parameter carrying the delta token returned in Meta by a previous sync.
+const DeltaTokenParam = "delta_token"
+
+// httpTimeFormat layout of HTTP date headers (Last-Modified, If-Modified-Since).
+const httpTimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// ErrInvalidDeltaToken returned when a delta token can not be decoded.
+var ErrInvalidDeltaToken = errors.New("invalid delta token")
+
+// deltaToken payload of delta tokens. Tokens are opaque for clients.
+type deltaToken struct {
+	Since time.Time `json:"s"`
+}
+
+// DeltaResult records returned by a DeltaFetchFunc.
+type DeltaResult[T any] struct {
+	Changed    []T       // Records created or updated after the since time
+	DeletedIDs []any     // IDs of records deleted after the since time
+	Watermark  time.Time // Latest modification time covered by the result, becomes the next delta token
+}
+
+// DeltaFetchFunc repository callback returning the records changed after since.
+// A zero since means a full sync: all records must be returned.
+type DeltaFetchFunc[T any] func(since time.Time) (DeltaResult[T], error)
+
+// EncodeDeltaToken encodes a watermark time as an opaque delta token.
+func EncodeDeltaToken(since time.Time) string {
+	data, _ := json.Marshal(deltaToken{Since: since.UTC()})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeDeltaToken decodes a token produced by EncodeDeltaToken.
+func DecodeDeltaToken(token string) (time.Time, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, ErrInvalidDeltaToken
+	}
+
+	var payload deltaToken
+	if err = json.Unmarshal(data, &payload); err != nil || payload.Since.IsZero() {
+		return time.Time{}, ErrInvalidDeltaToken
+	}
+
+	return payload.Since, nil
+}
+
+// ProcessDelta reads the changes-since time from the `delta_token` query parameter, or the If-Modified-Since
+// header when there is no token, and stores it in Ctx's Data. Without both, a full sync is performed.
+//
+// Example Usage:
+//
+//	func (h SyncProductsApi) Validate(c *core.Ctx) error {
+//		return http.ProcessDelta(c)
+//	}
+func ProcessDelta(c *core.Ctx) error {
+	var since time.Time
+
+	if token := c.QueryStr(DeltaTokenParam); token != "" {
+		decoded, err := DecodeDeltaToken(token)
+		if err != nil {
+			return c.Error(&Error{
+				Message: "delta_token is invalid, perform a full sync",
+			}, FailureStatus(c, FailureMalformed))
+		}
+		since = decoded
+	} else if header := c.GetHeader(core.HeaderIfModifiedSince); header != "" {
+		// Invalid dates are ignored as required by RFC 9110
+		if decoded, err := time.Parse(httpTimeFormat, header); err == nil {
+			since = decoded
+		}
+	}
+
+	c.SetData(DeltaSinceKey, since)
+
+	return nil
+}
+
+// DeltaSince returns the changes-since time stored by ProcessDelta; zero means full sync.
+func DeltaSince(c *core.Ctx) time.Time {
+	since, _ := c.GetData(DeltaSinceKey).(time.Time)
+
+	return since
+}
+
+// SyncList calls the repository callback with the time stored by ProcessDelta and writes the changed records,
+// the deleted IDs and the next delta token as a List response. A request using If-Modified-Since without
+// any change receives 304 Not Modified.
+//
+// Example Usage:
+//
+//	func (h SyncProductsApi) Handle(c *core.Ctx) error {
+//		return http.SyncList(c, productRepository.ChangedSince, transformers.ToProductResponse)
+//	}
+func SyncList[T any, R any](c *core.Ctx, fetchFn DeltaFetchFunc[T], transformerFn func(T) R) error {
+	since := DeltaSince(c)
+
+	result, err := fetchFn(since)
+	if err != nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to sync records",
+		}, core.StatusInternalServerError, "Sync list error: %v", err)
+	}
+
+	watermark := result.Watermark
+	if watermark.IsZero() {
+		watermark = since
+	}
+
+	if !watermark.IsZero() {
+		c.SetHeader(core.HeaderLastModified, watermark.UTC().Format(httpTimeFormat))
+	}
+
+	noChange := len(result.Changed) == 0 && len(result.DeletedIDs) == 0
+	if noChange && c.QueryStr(DeltaTokenParam) == "" && c.GetHeader(core.HeaderIfModifiedSince) != "" && !since.IsZero() {
+		c.Status(core.StatusNotModified)
+
+		return nil
+	}
+
+	meta := Meta{
+		Total:      len(result.Changed),
+		DeletedIDs: result.DeletedIDs,
+	}
+	if !watermark.IsZero() {
+		meta.DeltaToken = EncodeDeltaToken(watermark)
+	}
+
+	return WriteList(c, List[R]{
+		Meta: meta,
+		Data: ToListResponse(result.Changed, transformerFn),
+	})
+}
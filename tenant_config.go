@@ -0,0 +1,87 @@
+package http
+
+import (
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ====================== Tenant Configurations =======================
+// ====================================================================
+
+// TenantConfigResolver overrides the configuration of the requests of a tenant (see RegisterTenant), e.g. so
+// enterprise tenants get larger pages, uploads and quotas than free ones.
+type TenantConfigResolver interface {
+	// ResolveConfig adjusts config, a copy of the current configuration, for the requests of the tenant.
+	// An error keeps the current configuration for the request.
+	ResolveConfig(tenant string, config *Config) error
+}
+
+// TenantConfigResolverFunc adapter to use a function as a TenantConfigResolver.
+type TenantConfigResolverFunc func(tenant string, config *Config) error
+
+// ResolveConfig calls f(tenant, config).
+func (f TenantConfigResolverFunc) ResolveConfig(tenant string, config *Config) error {
+	return f(tenant, config)
+}
+
+// tenantConfigResolver resolver of RequestConfig, requests use the current configuration when nil.
+var tenantConfigResolver TenantConfigResolver
+
+// RegisterTenantConfigResolver registers the resolver of the tenants' configurations. Overrides apply to the
+// settings read while serving a request: per_page caps of filters, Parse options, upload limits, batch sizes,
+// WebSocket message sizes, long-poll timeouts, the sanitize and status policies and quota plans (Config.QuotaPlans).
+// The password policy is global, hashes are produced and verified outside of requests: overrides of
+// Config.PasswordPolicy are invalid.
+// Resolvers are called once per request, they should cache their tenants' settings.
+//
+// Example Usage:
+//
+//	http.RegisterTenantConfigResolver(http.TenantConfigResolverFunc(func(tenant string, config *http.Config) error {
+//		plan, err := billing.Plan(tenant)
+//		if err != nil {
+//			return err
+//		}
+//		if plan.Enterprise {
+//			config.MaxPerPage = 500
+//			config.Uploads.MaxTotalSize = 1 << 30
+//			config.QuotaPlans = map[string]http.QuotaPlan{"search": {Limit: 100000, Period: 24 * time.Hour}}
+//		}
+//		return nil
+//	}))
+func RegisterTenantConfigResolver(resolver TenantConfigResolver) {
+	tenantConfigResolver = resolver
+}
+
+// RequestConfig returns the configuration of the request: the current configuration with the overrides of the
+// request's tenant. Overrides failing to resolve or invalid are logged and ignored.
+//
+// Example Usage:
+//
+//	if len(ids) > http.RequestConfig(c).MaxPerPage { ... }
+func RequestConfig(c *core.Ctx) Config {
+	if tenantConfigResolver == nil || c == nil {
+		return CurrentConfig()
+	}
+	if config, ok := c.GetData(TenantConfigKey).(Config); ok {
+		return config
+	}
+
+	config := CurrentConfig()
+	if tenant := Tenant(c); tenant != "" {
+		resolved := config
+		if err := tenantConfigResolver.ResolveConfig(tenant, &resolved); err != nil {
+			log.Errorf("Tenant %s configuration error: %v", tenant, err)
+		} else if err := resolved.check(); err != nil {
+			log.Errorf("Tenant %s configuration is invalid: %v", tenant, err)
+		} else if resolved.PasswordPolicy != config.PasswordPolicy {
+			log.Errorf("Tenant %s configuration is invalid: the password policy can not be overridden", tenant)
+		} else {
+			config = resolved
+		}
+	}
+
+	c.SetData(TenantConfigKey, config)
+
+	return config
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// =========================== Time Formats ===========================
+// ====================================================================
+
+var (
+	// timeFormatCache whether struct types have time fields with a `timeFormat` tag, nested ones included.
+	timeFormatCache sync.Map
+	// timeZoneCache locations of `timeZone` tags by name.
+	timeZoneCache sync.Map
+)
+
+// fieldTimeFormat returns the time layout of a field, the given layout (`layout=` option of binding tags) or
+// its `timeFormat` tag, and the location of its `timeZone` tag, applied to values without offset (UTC when
+// absent). Unknown zones are logged and UTC is used.
+func fieldTimeFormat(field reflect.StructField, layout string) (string, *time.Location) {
+	if layout == "" {
+		layout = field.Tag.Get("timeFormat")
+	}
+
+	zone := field.Tag.Get("timeZone")
+	if zone == "" {
+		return layout, time.UTC
+	}
+	if cached, ok := timeZoneCache.Load(zone); ok {
+		return layout, cached.(*time.Location)
+	}
+
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		log.Errorf("Invalid time zone of field %s: %v", field.Name, err)
+		location = time.UTC
+	}
+	timeZoneCache.Store(zone, location)
+
+	return layout, location
+}
+
+// hasTimeFormats checks a type holds time fields with a `timeFormat` tag, so bodies of other DTOs are not
+// rewritten.
+func hasTimeFormats(typ reflect.Type) bool {
+	typ = derefType(typ)
+	if cached, ok := timeFormatCache.Load(typ); ok {
+		return cached.(bool)
+	}
+
+	found := findTimeFormats(typ, map[reflect.Type]bool{})
+	timeFormatCache.Store(typ, found)
+
+	return found
+}
+
+func findTimeFormats(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return findTimeFormats(typ.Elem(), seen)
+	case reflect.Struct:
+		if seen[typ] || typ == timeType {
+			return false
+		}
+		seen[typ] = true
+
+		for _, field := range reflect.VisibleFields(typ) {
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("timeFormat") != "" && derefType(field.Type) == timeType {
+				return true
+			}
+			if findTimeFormats(field.Type, seen) {
+				return true
+			}
+		}
+	default:
+	}
+
+	return false
+}
+
+// applyTimeFormats rewrites the times of the JSON body sent in the layouts declared by `timeFormat` tags as
+// RFC 3339, the format decoded by time.Time. Values not matching their layout are returned as an error
+// per field path instead of the opaque decoding error.
+//
+// Example:
+//
+//	type CreateShipmentRequest struct {
+//		ShipDate   time.Time  `json:"ship_date" timeFormat:"2006-01-02" validate:"required"`
+//		PickupTime *time.Time `json:"pickup_time" timeFormat:"02/01/2006 15:04" timeZone:"Asia/Ho_Chi_Minh"`
+//	}
+func applyTimeFormats(c *core.Ctx, typ reflect.Type) *Error {
+	body, errData := convertTimeFormats(c.Root().PostBody(), typ)
+	if errData != nil {
+		return errData
+	}
+	if body != nil {
+		c.Root().Request.SetBody(body)
+	}
+
+	return nil
+}
+
+// convertTimeFormats returns the JSON body with the times of `timeFormat` fields as RFC 3339, nil when the
+// body is unchanged.
+func convertTimeFormats(body []byte, typ reflect.Type) ([]byte, *Error) {
+	if !hasTimeFormats(typ) {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		// Syntax errors are reported by the decoding itself
+		return nil, nil
+	}
+
+	errorData := core.Data{}
+	changed := false
+	document = convertTimes(document, derefType(typ), "", errorData, &changed)
+	if len(errorData) > 0 {
+		return nil, &Error{
+			Message: "Invalid input",
+			Data:    errorData,
+		}
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil
+	}
+
+	return converted, nil
+}
+
+// convertTimes converts the times of a decoded JSON value of type typ.
+func convertTimes(value any, typ reflect.Type, path string, errorData core.Data, changed *bool) any {
+	typ = derefType(typ)
+	if typ == timeType || reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return value
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for name, item := range typed {
+				field, ok := jsonField(typ, name)
+				if !ok {
+					continue
+				}
+
+				if field.Tag.Get("timeFormat") != "" && derefType(field.Type) == timeType {
+					raw, isString := item.(string)
+					if !isString {
+						continue
+					}
+
+					layout, location := fieldTimeFormat(field, "")
+					parsed, err := time.ParseInLocation(layout, raw, location)
+					if err != nil {
+						errorData[joinPath(path, name)] = []string{"must be date time formatted as " + layout}
+
+						continue
+					}
+					typed[name] = parsed.Format(time.RFC3339Nano)
+					*changed = true
+
+					continue
+				}
+
+				typed[name] = convertTimes(item, field.Type, joinPath(path, name), errorData, changed)
+			}
+		case reflect.Map:
+			for name, item := range typed {
+				typed[name] = convertTimes(item, typ.Elem(), joinPath(path, name), errorData, changed)
+			}
+		default:
+		}
+	case []any:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, item := range typed {
+				typed[i] = convertTimes(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), errorData, changed)
+			}
+		}
+	default:
+	}
+
+	return value
+}
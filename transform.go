@@ -0,0 +1,186 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================= Field Transforms =========================
+// ====================================================================
+
+// TransformFunc converts the value of a field tagged with `transform`. It runs after validation,
+// so it receives validated input and may produce values which would not pass validation (hashes, ciphertexts).
+type TransformFunc func(value string) (string, error)
+
+// transforms registered transform functions by tag name.
+var transforms = map[string]TransformFunc{
+	"password": func(value string) (string, error) { return HashPassword(value) },
+	PasswordBcrypt: func(value string) (string, error) {
+		return HashPassword(value, PasswordBcrypt)
+	},
+	PasswordArgon2id: func(value string) (string, error) {
+		return HashPassword(value, PasswordArgon2id)
+	},
+	"encrypt": EncryptField,
+}
+
+// bcryptMaxBytes maximum length of the passwords hashed with bcrypt, which fails on longer ones.
+const bcryptMaxBytes = 72
+
+// AddTransform registers a transform function usable with `transform:"name"` tags.
+func AddTransform(name string, fn TransformFunc) {
+	transforms[name] = fn
+}
+
+// TransformStruct applies the `transform` tags of a struct's string fields, recursively.
+// Empty fields are left untouched so optional fields (e.g. a password in an update request) stay empty.
+// It is called by ProcessData and ProcessUpdateData once the request data is validated.
+//
+// Example:
+//
+//	type RegisterRequest struct {
+//		Email    string `json:"email" validate:"required,email"`
+//		Password string `json:"password" validate:"required,min=8" transform:"bcrypt"`
+//	}
+func TransformStruct(target any) error {
+	if target == nil {
+		return nil
+	}
+
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Pointer {
+		return nil
+	}
+
+	return transformValue(val.Elem())
+}
+
+func transformValue(val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.Pointer:
+		if !val.IsNil() {
+			return transformValue(val.Elem())
+		}
+	case reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			tag := typ.Field(i).Tag.Get("transform")
+			if tag == "" {
+				if err := transformValue(field); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := applyTransforms(field, tag); err != nil {
+				return fmt.Errorf("%s: %w", typ.Field(i).Name, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			if err := transformValue(val.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	return nil
+}
+
+// checkTransformInputs adds to errorData the fields whose value can not be transformed by their `transform` tag:
+// passwords longer than 72 bytes hashed with bcrypt ("bcrypt", or "password" with the bcrypt policy).
+func checkTransformInputs(val reflect.Value, errorData core.Data) {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !val.IsNil() {
+			checkTransformInputs(val.Elem(), errorData)
+		}
+	case reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag := field.Tag.Get("transform")
+			if tag == "" {
+				checkTransformInputs(val.Field(i), errorData)
+
+				continue
+			}
+
+			value := reflect.Indirect(val.Field(i))
+			if value.Kind() != reflect.String || len(value.String()) <= bcryptMaxBytes || !hashesWithBcrypt(tag) {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				name = field.Name
+			}
+			messages, _ := errorData[name].([]string)
+			errorData[name] = append(messages, fmt.Sprintf("must be at most %d bytes", bcryptMaxBytes))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			checkTransformInputs(val.Index(i), errorData)
+		}
+	default:
+	}
+}
+
+// hashesWithBcrypt reports whether the transforms of a tag hash the value with bcrypt.
+func hashesWithBcrypt(tag string) bool {
+	for _, name := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(name) {
+		case PasswordBcrypt:
+			return true
+		case "password":
+			if CurrentConfig().PasswordPolicy.Algorithm == PasswordBcrypt {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// applyTransforms runs the comma-separated transforms of the tag on a string or *string field.
+func applyTransforms(field reflect.Value, tag string) error {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.String || field.String() == "" {
+		return nil
+	}
+
+	for _, name := range strings.Split(tag, ",") {
+		fn, ok := transforms[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown transform %q", name)
+		}
+
+		value, err := fn(field.String())
+		if err != nil {
+			return err
+		}
+		field.SetString(value)
+	}
+
+	return nil
+}
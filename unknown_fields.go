@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== Unknown Fields ==========================
+// ====================================================================
+
+// ErrorCodeUnknownFields code of the Error returned for bodies with fields the DTO does not declare.
+const ErrorCodeUnknownFields = "UNKNOWN_FIELDS"
+
+// checkUnknownFields returns the UNKNOWN_FIELDS Error of a JSON body holding keys that no field of typ decodes,
+// nil when all keys are known. Keys are matched as encoding/json does, so they are reported by path
+// ("per_pages", "address.zip_cod", "items[1].qty"). Fields registered with DeprecateField are known, even once
+// removed from the DTO.
+func checkUnknownFields(body []byte, typ reflect.Type) *Error {
+	var document any
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&document); err != nil {
+		// Syntax errors are reported by the decoding itself
+		return nil
+	}
+
+	var unknown []string
+	collectUnknownFields(document, typ, "", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	errorData := core.Data{}
+	for _, path := range unknown {
+		errorData[path] = []string{"is not a known field"}
+	}
+
+	return &Error{
+		Code:    ErrorCodeUnknownFields,
+		Message: "Unknown fields: " + strings.Join(unknown, ", "),
+		Data:    errorData,
+	}
+}
+
+func collectUnknownFields(value any, typ reflect.Type, path string, unknown *[]string) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for name, item := range typed {
+				if field, ok := jsonField(typ, name); ok {
+					collectUnknownFields(item, field.Type, joinPath(path, name), unknown)
+				} else if !isDeprecatedField(typ, name) {
+					*unknown = append(*unknown, joinPath(path, name))
+				}
+			}
+		case reflect.Map:
+			for name, item := range typed {
+				collectUnknownFields(item, typ.Elem(), joinPath(path, name), unknown)
+			}
+		default:
+		}
+	case []any:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, item := range typed {
+				collectUnknownFields(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+			}
+		}
+	default:
+	}
+}
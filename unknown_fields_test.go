@@ -0,0 +1,42 @@
+package http
+
+import "testing"
+
+// deprecatedDTO request DTO whose "name" field was removed and deprecated.
+type deprecatedDTO struct {
+	FullName string `json:"full_name"`
+}
+
+func TestDisallowUnknownFieldsAcceptsDeprecatedFields(t *testing.T) {
+	DeprecateField[deprecatedDTO]("name", "Use 'full_name' instead")
+
+	for _, tc := range []struct {
+		name    string
+		body    string
+		unknown bool
+	}{
+		{"declared field", `{"full_name":"John Doe"}`, false},
+		{"deprecated field", `{"name":"John"}`, false},
+		{"unknown field", `{"name":"John","nickname":"JD"}`, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFuzzCtx(t, "POST", "/users", tc.body)
+
+			var requestData deprecatedDTO
+			errData := ParseWith(c, &requestData, ParseOptions{DisallowUnknownFields: true})
+			if tc.unknown {
+				if errData == nil || errData.Code != ErrorCodeUnknownFields {
+					t.Fatalf("ParseWith() = %v, want %s", errData, ErrorCodeUnknownFields)
+				}
+				if _, ok := errData.Data["name"]; ok {
+					t.Errorf("deprecated field reported as unknown: %v", errData.Data)
+				}
+
+				return
+			}
+			if errData != nil {
+				t.Fatalf("ParseWith() = %v, want nil", errData)
+			}
+		})
+	}
+}
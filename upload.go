@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ============================= Uploads ==============================
+// ====================================================================
+
+// ErrorCodeInfectedFile code of the Error returned when a scanner finds a threat in an uploaded file.
+const ErrorCodeInfectedFile = "INFECTED_FILE"
+
+// ScanResult verdict of a Scanner on a file.
+type ScanResult struct {
+	Infected bool   // A threat was found
+	Threat   string // Name of the threat, e.g. "Eicar-Test-Signature"
+}
+
+// Scanner is an interface for content scanners of uploaded files (ClamAV, SaaS scanners, ...).
+type Scanner interface {
+	// Scan checks the content of a file. Scanning errors (scanner unreachable, ...) are returned as error,
+	// not as a verdict.
+	Scan(ctx context.Context, name string, content io.Reader) (ScanResult, error)
+}
+
+// ScanFailurePolicy tells how uploads are handled when the scanner fails or times out. It sets the policy of
+// SubsystemScanner (see RegisterFailurePolicy).
+type ScanFailurePolicy int
+
+// Scan failure policies.
+const (
+	ScanFailClosed ScanFailurePolicy = iota // Uploads are rejected with 503 SCAN_UNAVAILABLE (default)
+	ScanFailOpen                            // Uploads are accepted unscanned, the failure is logged
+)
+
+// ScannerOptions configuration of upload scanning.
+type ScannerOptions struct {
+	Timeout time.Duration     // Maximum duration of the scan of one file, 30 seconds by default
+	Policy  ScanFailurePolicy // Handling of scanner failures
+}
+
+var (
+	// uploadScanner scanner of uploaded files, uploads are not scanned when nil
+	uploadScanner Scanner
+	// scannerOptions options of upload scanning
+	scannerOptions ScannerOptions
+)
+
+// RegisterScanner registers the scanner ProcessUpload runs on every uploaded file.
+//
+// Example Usage:
+//
+//	http.RegisterScanner(clamav.NewScanner("tcp://clamd:3310"), http.ScannerOptions{
+//		Timeout: 10 * time.Second,
+//		Policy:  http.ScanFailClosed,
+//	})
+func RegisterScanner(scanner Scanner, options ScannerOptions) {
+	if options.Timeout == 0 {
+		options.Timeout = 30 * time.Second
+	}
+
+	uploadScanner = scanner
+	scannerOptions = options
+
+	policy := FailClosed
+	if options.Policy == ScanFailOpen {
+		policy = FailOpen
+	}
+	RegisterFailurePolicy(SubsystemScanner, policy)
+}
+
+// InfectedFileError returns the 422 INFECTED_FILE Error of an uploaded file the scanner rejected.
+func InfectedFileError(field, threat string) *Error {
+	return &Error{
+		Code:    ErrorCodeInfectedFile,
+		Message: "Uploaded file is infected",
+		Data: core.Data{
+			field: []string{threat},
+		},
+	}
+}
+
+// ProcessUpload receives the files uploaded in the fields (all fields when none is given) into the temporary
+// directory, scans them with the registered Scanner, sanitizes images (see RegisterImageSanitize) and stores
+// them in Ctx's Data (see Uploads). Rejected uploads are removed.
+//
+// Example Usage:
+//
+//	func (h UploadAvatarApi) Validate(c *core.Ctx) error {
+//		return http.ProcessUpload(c, "avatar")
+//	}
+//
+//	func (h UploadAvatarApi) Handle(c *core.Ctx) error {
+//		avatar := http.Uploads(c)[0]
+//		...
+//	}
+func ProcessUpload(c *core.Ctx, fields ...string) error {
+	files, err := c.FormUpload(fields...)
+	if err != nil {
+		removeUploads(files)
+
+		return c.Error(&Error{
+			Code:    "INVALID_UPLOAD",
+			Message: "Invalid upload",
+		}, FailureStatus(c, FailureMalformed))
+	}
+
+	if err := scanUploads(c, files); err != nil {
+		removeUploads(files)
+
+		return err
+	}
+
+	// Strip image metadata (see RegisterImageSanitize)
+	if err := sanitizeUploads(c, files); err != nil {
+		removeUploads(files)
+
+		return err
+	}
+
+	c.SetData(UploadsKey, files)
+
+	return nil
+}
+
+// Uploads returns the files received by ProcessUpload.
+func Uploads(c *core.Ctx) []core.UploadedFile {
+	files, _ := c.GetData(UploadsKey).([]core.UploadedFile)
+
+	return files
+}
+
+// scanUploads runs the registered Scanner on the files and writes the error response of the first rejected one.
+func scanUploads(c *core.Ctx, files []core.UploadedFile) error {
+	if uploadScanner == nil {
+		return nil
+	}
+
+	for _, file := range files {
+		result, err := scanUpload(file)
+		if err := checkScan(c, file.Field, file.Name, result, err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkScan writes the error response of a file the scanner rejected, or could not scan under ScanFailClosed.
+// Infected verdicts are enforced even when returned with an error.
+func checkScan(c *core.Ctx, field, name string, result ScanResult, err error) error {
+	if result.Infected {
+		log.Warnf("Infected upload %s rejected: %s", name, result.Threat)
+
+		return c.Error(InfectedFileError(field, result.Threat), FailureStatus(c, FailureBusinessRule))
+	}
+
+	if err != nil {
+		if subsystemFailed(SubsystemScanner, fmt.Errorf("scan of %s: %w", name, err)) == FailOpen {
+			return nil
+		}
+
+		return WriteError(c, &Error{
+			Code:    "SCAN_UNAVAILABLE",
+			Message: "Uploaded files can not be scanned, try again later",
+		}, core.StatusServiceUnavailable)
+	}
+
+	return nil
+}
+
+// scanUpload scans a received file within the scan timeout.
+func scanUpload(file core.UploadedFile) (ScanResult, error) {
+	content, err := os.Open(file.Path)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	defer content.Close()
+
+	return scanContent(file.Name, content)
+}
+
+// scanContent scans the content of a file within the scan timeout.
+func scanContent(name string, content io.Reader) (ScanResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), scannerOptions.Timeout)
+	defer cancel()
+
+	result, err := uploadScanner.Scan(ctx, name, content)
+	// A verdict returned at the deadline is kept, an infected file must not go through FailOpen
+	if err == nil && !result.Infected && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errors.New("scan timed out")
+	}
+
+	return result, err
+}
+
+// removeUploads deletes the temporary files of rejected uploads.
+func removeUploads(files []core.UploadedFile) {
+	for _, file := range files {
+		if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Errorf("Upload removal error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================== Direct Uploads ==========================
+// ====================================================================
+
+// UploadSigner is implemented by storages accepting direct uploads from clients, e.g. S3Storage and LocalStorage.
+type UploadSigner interface {
+	// SignedUploadURL returns a URL accepting a PUT of the object with the content type until expiresIn elapses.
+	SignedUploadURL(ctx context.Context, key, contentType string, expiresIn time.Duration) (string, error)
+}
+
+// DirectUploadPolicy limits of direct uploads.
+type DirectUploadPolicy struct {
+	KeyPrefix    string        // Prefix of the generated object keys, e.g. "uploads/"
+	MaxBytes     int64         // Maximum size of an uploaded object, unlimited when zero
+	ContentTypes []string      // Allowed content types, "image/*" wildcards included; any type when empty
+	ExpiresIn    time.Duration // Validity of the upload URL, 15 minutes by default
+}
+
+// UploadTicketRequest struct to describe a request for a direct upload URL.
+// @Description Request for a direct upload URL
+// @Name Name is the name of the file to upload.
+// @ContentType ContentType is the MIME type of the file to upload.
+// @Size Size is the size of the file in bytes.
+// @Tags Uploads
+type UploadTicketRequest struct {
+	Name        string `json:"name" example:"report.pdf" validate:"required,max=255" doc:"Name of the file to upload"`
+	ContentType string `json:"content_type" example:"application/pdf" validate:"required,max=255" doc:"MIME type of the file"`
+	Size        int64  `json:"size" example:"482133" validate:"required,min=1" doc:"Size of the file in bytes"`
+}
+
+// UploadTicket struct to describe a direct upload URL.
+// @Description URL the client uploads a file to, bypassing the application server
+// @Key Key is the object key to confirm once uploaded.
+// @URL URL is the upload URL.
+// @Method Method is the HTTP method of the upload.
+// @Headers Headers are the headers the upload must send.
+// @ExpiresAt ExpiresAt is the expiration time of the URL.
+// @Tags Uploads
+type UploadTicket struct {
+	Key       string            `json:"key" example:"uploads/5f0c1e9b3a7d4c2e8f6a9b0c1d2e3f4a.pdf" doc:"Object key to confirm once uploaded"`
+	URL       string            `json:"url" example:"https://uploads.s3.amazonaws.com/uploads/5f0c...pdf?X-Amz-Signature=..." doc:"Upload URL"`
+	Method    string            `json:"method" example:"PUT" doc:"HTTP method of the upload"`
+	Headers   map[string]string `json:"headers" doc:"Headers the upload must send"`
+	ExpiresAt time.Time         `json:"expires_at" example:"2024-01-31T10:15:00Z" doc:"Expiration time of the URL"`
+}
+
+// uploadsUnavailable Error of direct upload requests when the storage can not sign upload URLs.
+var uploadsUnavailable = &Error{
+	Code:    "UPLOADS_UNAVAILABLE",
+	Message: "Direct uploads are not available",
+}
+
+// IssueUploadTicket checks a direct upload request against the policy and returns the signed URL the client
+// uploads the file to, under a generated key. Once uploaded, the client sends the key to the endpoint
+// creating the record, which calls ConfirmUpload.
+func IssueUploadTicket(c *core.Ctx, policy DirectUploadPolicy, request UploadTicketRequest) (UploadTicket, *Error) {
+	if errData := checkUploadPolicy(policy, request.ContentType, request.Size); errData != nil {
+		return UploadTicket{}, errData
+	}
+
+	storage := GetStorage()
+	signer, ok := storage.(UploadSigner)
+	if !ok {
+		log.Errorf("Direct uploads are not supported by the storage %T", storage)
+
+		return UploadTicket{}, uploadsUnavailable
+	}
+
+	expiresIn := policy.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = 15 * time.Minute
+	}
+
+	key := policy.KeyPrefix + newUploadKey() + strings.ToLower(filepath.Ext(request.Name))
+	signedURL, err := signer.SignedUploadURL(c.Root(), key, request.ContentType, expiresIn)
+	if err != nil {
+		log.Errorf("Upload URL signing error: %v", err)
+
+		return UploadTicket{}, uploadsUnavailable
+	}
+
+	return UploadTicket{
+		Key:       key,
+		URL:       signedURL,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{core.HeaderContentType: request.ContentType},
+		ExpiresAt: time.Now().Add(expiresIn).UTC(),
+	}, nil
+}
+
+// ConfirmUpload checks an object uploaded with a ticket of IssueUploadTicket: the key must belong to the
+// policy, the object must exist, fit the size limit and have an allowed content type (images are checked
+// against their content). Objects failing the checks are deleted.
+//
+// The object is copied under a new key, returned in the ObjectInfo, while being checked and the uploaded one is
+// deleted: the upload URL stays valid until it expires, so a client could otherwise replace the object after its
+// confirmation. Keys are random, so only the client the ticket was issued to knows them.
+//
+// Example Usage:
+//
+//	func (h CreateDocumentApi) Validate(c *core.Ctx) error {
+//		if err := http.ProcessData[dto.CreateDocument](c); err != nil {
+//			return err
+//		}
+//		info, errData := http.ConfirmUpload(c, documentUploads, c.GetData(http.DataKey).(dto.CreateDocument).FileKey)
+//		if errData != nil {
+//			return c.Error(errData, http.FailureStatus(c, http.FailureBusinessRule))
+//		}
+//		c.SetData("file", info) // info.Key is the key to store in the record
+//		return nil
+//	}
+func ConfirmUpload(c *core.Ctx, policy DirectUploadPolicy, key string) (ObjectInfo, *Error) {
+	storage := GetStorage()
+	notFound := &Error{
+		Code:    "UPLOAD_NOT_FOUND",
+		Message: "Uploaded file not found",
+		Data:    core.Data{"key": []string{"is not an uploaded file"}},
+	}
+
+	if storage == nil || !strings.HasPrefix(key, policy.KeyPrefix) {
+		return ObjectInfo{}, notFound
+	}
+
+	content, info, err := storage.Get(c.Root(), key)
+	if err != nil {
+		if !errors.Is(err, ErrObjectNotFound) && !errors.Is(err, ErrInvalidKey) {
+			log.Errorf("Upload confirmation error: %v", err)
+		}
+
+		return ObjectInfo{}, notFound
+	}
+
+	defer func() {
+		_ = content.Close()
+		removeObject(c, key)
+	}()
+
+	if errData := checkUploadPolicy(policy, info.ContentType, info.Size); errData != nil {
+		return ObjectInfo{}, errData
+	}
+
+	// The first 512 bytes are enough to detect the content type
+	head := make([]byte, 512)
+	read, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		log.Errorf("Upload confirmation error: %v", err)
+
+		return ObjectInfo{}, notFound
+	}
+	if strings.HasPrefix(info.ContentType, "image/") && http.DetectContentType(head[:read]) != info.ContentType {
+		return ObjectInfo{}, &Error{
+			Code:    "INVALID_UPLOAD",
+			Message: "Uploaded file does not match its content type",
+			Data:    core.Data{"key": []string{"is not a " + info.ContentType + " file"}},
+		}
+	}
+
+	// The copy is limited to the checked size, so the object can not grow while being copied
+	confirmed := ObjectInfo{
+		Key:         policy.KeyPrefix + newUploadKey() + strings.ToLower(filepath.Ext(key)),
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		ModifiedAt:  time.Now().UTC(),
+	}
+	copied := &maxBytesReader{reader: io.MultiReader(bytes.NewReader(head[:read]), content), remaining: info.Size}
+	if err := storage.Put(c.Root(), confirmed.Key, copied, info.Size, info.ContentType); err != nil {
+		removeObject(c, confirmed.Key)
+		if !errors.Is(err, errUploadTooLarge) {
+			log.Errorf("Upload confirmation error: %v", err)
+		}
+
+		return ObjectInfo{}, notFound
+	}
+
+	return confirmed, nil
+}
+
+// removeObject deletes an object of the storage, logging failures.
+func removeObject(c *core.Ctx, key string) {
+	storage := GetStorage()
+	if err := storage.Delete(c.Root(), key); err != nil {
+		log.Errorf("Upload removal error: %v", err)
+	}
+}
+
+// errUploadTooLarge returned by maxBytesReader once the content exceeds its limit.
+var errUploadTooLarge = errors.New("upload too large")
+
+// maxBytesReader reader failing with errUploadTooLarge when its content exceeds a number of bytes, so storages
+// abort the object instead of storing a truncated one.
+type maxBytesReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+// Read reads up to one byte past the limit to detect larger contents.
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, errUploadTooLarge
+	}
+
+	return n, err
+}
+
+// checkUploadPolicy checks the size and content type of an upload against the policy.
+func checkUploadPolicy(policy DirectUploadPolicy, contentType string, size int64) *Error {
+	if policy.MaxBytes > 0 && size > policy.MaxBytes {
+		return uploadTooLarge(policy)
+	}
+
+	if !allowsContentType(policy.ContentTypes, contentType) {
+		return &Error{
+			Code:    "UNSUPPORTED_FILE_TYPE",
+			Message: "File type is not allowed",
+			Data:    core.Data{"content_type": []string{"must be one of " + strings.Join(policy.ContentTypes, ", ")}},
+		}
+	}
+
+	return nil
+}
+
+// uploadTooLarge returns the Error of an upload exceeding the size limit of the policy.
+func uploadTooLarge(policy DirectUploadPolicy) *Error {
+	return &Error{
+		Code:    "FILE_TOO_LARGE",
+		Message: "File is too large",
+		Data:    core.Data{"size": []string{fmt.Sprintf("must be at most %d bytes", policy.MaxBytes)}},
+	}
+}
+
+// allowsContentType checks a content type against a list of allowed types, "type/*" wildcards included.
+func allowsContentType(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	mainType, _, _ := strings.Cut(mediaType, "/")
+
+	return slices.Contains(allowed, mediaType) || slices.Contains(allowed, mainType+"/*")
+}
+
+// newUploadKey returns a random object key name of 128 bits.
+func newUploadKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+
+	return hex.EncodeToString(key)
+}
+
+// UploadTicketApi handler issuing direct upload URLs (see IssueUploadTicket).
+type UploadTicketApi struct {
+	core.Endpoint
+	policy DirectUploadPolicy
+}
+
+// NewUploadTicketApi creates the handler issuing direct upload URLs with a policy.
+//
+// Example Usage:
+//
+//	documentUploads := http.DirectUploadPolicy{
+//		KeyPrefix:    "documents/",
+//		MaxBytes:     100 << 20, // 100 MB
+//		ContentTypes: []string{"application/pdf", "image/*"},
+//	}
+//	apiRouter.POST("/documents/uploads", http.NewUploadTicketApi(documentUploads))
+func NewUploadTicketApi(policy DirectUploadPolicy) *UploadTicketApi {
+	return &UploadTicketApi{policy: policy}
+}
+
+// Validate parses and checks the upload request.
+func (h *UploadTicketApi) Validate(c *core.Ctx) error {
+	var requestData UploadTicketRequest
+	if errData := Parse(c, &requestData); errData != nil {
+		return c.Error(errData, parseStatus(c, errData))
+	}
+
+	if errData := ValidateRequest(c, requestData); errData != nil {
+		return c.Error(errData, FailureStatus(c, FailureValidation))
+	}
+
+	c.SetData(RequestKey, requestData)
+
+	return nil
+}
+
+// Handle responds with the upload ticket.
+func (h *UploadTicketApi) Handle(c *core.Ctx) error {
+	ticket, errData := IssueUploadTicket(c, h.policy, c.GetData(RequestKey).(UploadTicketRequest))
+	if errData != nil {
+		if errData == uploadsUnavailable {
+			return WriteError(c, errData, core.StatusServiceUnavailable)
+		}
+
+		return c.Error(errData, FailureStatus(c, FailureBusinessRule))
+	}
+
+	return c.Success(ticket)
+}
+
+// ====================================================================
+// ======================= Local Direct Uploads =======================
+// ====================================================================
+
+// uploadContentTypeClaim signed URL claim of the content type of a local direct upload.
+const uploadContentTypeClaim = "content_type"
+
+// SignedUploadURL returns the URL of the upload endpoint for the key, signed with SignURL (see ReceiveUpload).
+func (s *LocalStorage) SignedUploadURL(_ context.Context, key, contentType string, expiresIn time.Duration) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return SignURL(s.BaseURL+"/"+cleaned, expiresIn, map[string]string{uploadContentTypeClaim: contentType})
+}
+
+// ReceiveUpload answers the PUT of a direct upload URL minted by LocalStorage for the policy: the body is stored
+// under the key in the registered storage, and the response is 204 No Content. Keys outside the policy are
+// rejected, and bodies larger than its MaxBytes are rejected with 413 Request Entity Too Large without being
+// stored. The signature is verified by VerifySignedURL.
+//
+// Example Usage:
+//
+//	func (h PutFileApi) Validate(c *core.Ctx) error {
+//		return http.VerifySignedURL(c)
+//	}
+//
+//	func (h PutFileApi) Handle(c *core.Ctx) error {
+//		return http.ReceiveUpload(c, documentUploads, c.PathVal("key"))
+//	}
+func ReceiveUpload(c *core.Ctx, policy DirectUploadPolicy, key string) error {
+	storage := GetStorage()
+	contentType := c.QueryStr(uploadContentTypeClaim)
+	if contentType == "" || c.GetHeader(core.HeaderContentType) != contentType {
+		return c.Error(&Error{
+			Code:    "INVALID_UPLOAD",
+			Message: "Content-Type does not match the upload URL",
+		}, FailureStatus(c, FailureBusinessRule))
+	}
+	if !strings.HasPrefix(key, policy.KeyPrefix) {
+		return c.Error(&Error{
+			Code:    "INVALID_UPLOAD",
+			Message: "Upload key does not belong to the upload policy",
+		}, FailureStatus(c, FailureBusinessRule))
+	}
+
+	if storage == nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to store upload",
+		}, core.StatusInternalServerError, "Upload store error: storage is not registered")
+	}
+
+	// Streamed bodies are handed to the storage as they arrive
+	body, size := requestBody(c)
+	if policy.MaxBytes > 0 {
+		if size > policy.MaxBytes {
+			return c.Error(uploadTooLarge(policy), core.StatusRequestEntityTooLarge)
+		}
+		body = &maxBytesReader{reader: body, remaining: policy.MaxBytes}
+	}
+
+	if err := storage.Put(c.Root(), key, body, size, contentType); err != nil {
+		if errors.Is(err, errUploadTooLarge) {
+			return c.Error(uploadTooLarge(policy), core.StatusRequestEntityTooLarge)
+		}
+
+		return WriteServerError(c, &Error{
+			Message: "Unable to store upload",
+		}, core.StatusInternalServerError, "Upload store error: %v", err)
+	}
+
+	return c.NoContent()
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ========================== File Fields =============================
+// ====================================================================
+
+// UploadedFile file of a multipart request bound to a DTO field by ProcessData, with the MIME type detected
+// from its content. Pass its core.UploadedFile to StoreUpload or StartImport.
+type UploadedFile struct {
+	core.UploadedFile
+	ContentType string // MIME type detected from the content, e.g. "image/png"
+}
+
+// uploadedFileType type of the UploadedFile fields.
+var uploadedFileType = reflect.TypeFor[UploadedFile]()
+
+// fileField struct field bound to the files of a multipart field.
+type fileField struct {
+	index []int
+	name  string // Multipart field name
+	slice bool   // []UploadedFile field, every file of the field
+}
+
+// fileFieldsCache file fields by struct type.
+var fileFieldsCache sync.Map
+
+// fileFields returns the UploadedFile, *UploadedFile and []UploadedFile fields of a struct type, named like the
+// `form` tag of the fields, else their JSON name.
+func fileFields(typ reflect.Type) []fileField {
+	if cached, ok := fileFieldsCache.Load(typ); ok {
+		return cached.([]fileField)
+	}
+
+	var fields []fileField
+	if typ.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(typ) {
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldType := field.Type
+			slice := fieldType.Kind() == reflect.Slice
+			if slice {
+				fieldType = fieldType.Elem()
+			}
+			if derefType(fieldType) != uploadedFileType {
+				continue
+			}
+
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+			if name == "" {
+				name = jsonName
+			}
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			fields = append(fields, fileField{index: field.Index, name: name, slice: slice})
+		}
+	}
+
+	fileFieldsCache.Store(typ, fields)
+
+	return fields
+}
+
+// receiveFileFields receives the files of the UploadedFile fields of typ into the temporary directory, before
+// Parse rewrites multipart bodies. Files are scanned with the registered Scanner and images sanitized, as by
+// ProcessUpload; the error response is written and the files removed when one is rejected.
+func receiveFileFields(c *core.Ctx, typ reflect.Type) (map[string][]UploadedFile, error) {
+	fields := fileFields(derefType(typ))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(string(c.Root().Request.Header.ContentType()))
+	if mediaType != core.MIMEMultipartForm {
+		return nil, nil
+	}
+
+	form, err := c.Root().MultipartForm()
+	if err != nil {
+		return nil, c.Error(&Error{
+			Code:    "INVALID_UPLOAD",
+			Message: "Invalid upload",
+		}, FailureStatus(c, FailureMalformed))
+	}
+
+	var received []core.UploadedFile
+	for _, field := range fields {
+		headers := form.File[field.name]
+		if field.slice {
+			headers = append(headers, form.File[field.name+"[]"]...)
+		} else if len(headers) > 1 {
+			headers = headers[:1]
+		}
+
+		for _, header := range headers {
+			file, err := receiveFile(field.name, header)
+			if err != nil {
+				removeUploads(received)
+
+				return nil, WriteServerError(c, &Error{
+					Message: "Unable to receive uploaded files",
+				}, core.StatusInternalServerError, "Upload receive error: %v", err)
+			}
+			received = append(received, file)
+		}
+	}
+
+	if err := scanUploads(c, received); err != nil {
+		removeUploads(received)
+
+		return nil, err
+	}
+	if err := sanitizeUploads(c, received); err != nil {
+		removeUploads(received)
+
+		return nil, err
+	}
+
+	files := map[string][]UploadedFile{}
+	for _, file := range received {
+		contentType, err := detectFileType(file.Path)
+		if err != nil {
+			removeUploads(received)
+
+			return nil, WriteServerError(c, &Error{
+				Message: "Unable to receive uploaded files",
+			}, core.StatusInternalServerError, "Upload type detection error: %v", err)
+		}
+
+		files[file.Field] = append(files[file.Field], UploadedFile{UploadedFile: file, ContentType: contentType})
+	}
+
+	return files, nil
+}
+
+// receiveFile saves a multipart file into the temporary directory.
+func receiveFile(field string, header *multipart.FileHeader) (core.UploadedFile, error) {
+	src, err := header.Open()
+	if err != nil {
+		return core.UploadedFile{}, err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(core.TempDir, "upload-*"+filepath.Ext(header.Filename))
+	if err != nil {
+		return core.UploadedFile{}, err
+	}
+	defer dst.Close()
+
+	file := core.UploadedFile{Field: field, Name: header.Filename, Path: dst.Name()}
+	if file.Size, err = io.Copy(dst, src); err != nil {
+		removeUploads([]core.UploadedFile{file})
+
+		return core.UploadedFile{}, err
+	}
+
+	return file, nil
+}
+
+// detectFileType returns the MIME type of a file sniffed from its first 512 bytes.
+func detectFileType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	read, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:read]), nil
+}
+
+// bindFileFields sets the UploadedFile fields of the DTO to their received files, overwriting any value decoded
+// from the body so clients can not forge file paths.
+func bindFileFields(structData any, files map[string][]UploadedFile) {
+	value := reflect.ValueOf(structData).Elem()
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range fileFields(value.Type()) {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			// Field of a nil embedded struct pointer
+			continue
+		}
+		fieldValue.SetZero()
+
+		received := files[field.name]
+		if len(received) == 0 {
+			continue
+		}
+
+		if !field.slice {
+			setFileValue(fieldValue, &received[0])
+
+			continue
+		}
+
+		items := reflect.MakeSlice(fieldValue.Type(), len(received), len(received))
+		for i := range received {
+			setFileValue(items.Index(i), &received[i])
+		}
+		fieldValue.Set(items)
+	}
+}
+
+// setFileValue sets an UploadedFile or *UploadedFile value.
+func setFileValue(value reflect.Value, file *UploadedFile) {
+	if value.Kind() == reflect.Pointer {
+		value.Set(reflect.ValueOf(file))
+	} else {
+		value.Set(reflect.ValueOf(*file))
+	}
+}
+
+// fileUploads returns the received files of the UploadedFile fields.
+func fileUploads(files map[string][]UploadedFile) []core.UploadedFile {
+	var uploads []core.UploadedFile
+	for _, received := range files {
+		for _, file := range received {
+			uploads = append(uploads, file.UploadedFile)
+		}
+	}
+
+	return uploads
+}
@@ -0,0 +1,416 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ======================= Upload Image Sanitize ======================
+// ====================================================================
+
+// ImageSanitizeOptions configuration of the image sanitization done by ProcessUpload.
+type ImageSanitizeOptions struct {
+	Enabled     bool  // Re-encodes uploaded JPEG, PNG and GIF images, dropping EXIF/GPS metadata and embedded payloads
+	MaxBytes    int64 // Maximum size of an uploaded image, unlimited when zero
+	MaxWidth    int   // Maximum width in pixels, unlimited when zero
+	MaxHeight   int   // Maximum height in pixels, unlimited when zero
+	MaxPixels   int64 // Maximum number of pixels decoded, all frames of a GIF together, 40 million by default
+	MaxFrames   int   // Maximum number of frames of a GIF, 100 by default
+	JPEGQuality int   // Quality of re-encoded JPEG images, 90 by default
+}
+
+// Default caps of the image sanitization, bounding the memory used to decode an image.
+const (
+	defaultImageMaxPixels = 40_000_000 // About 160 MB of decoded RGBA pixels
+	defaultImageMaxFrames = 100
+)
+
+// jpegHeaderSize bytes of a JPEG file searched for its EXIF orientation, the segments before the first scan
+// including the 64 KB EXIF segment.
+const jpegHeaderSize = 128 << 10
+
+// imageSanitizeOptions options of the image sanitization, disabled by default.
+var imageSanitizeOptions ImageSanitizeOptions
+
+// RegisterImageSanitize registers the sanitization of uploaded images. Images are decoded and re-encoded, so
+// only their pixels are kept: EXIF (GPS location, camera serial, ...), comments and data appended to the file
+// (polyglot payloads) are dropped. The EXIF orientation of JPEG photos is applied to the pixels first.
+// Caps are checked on the file size and the image header before decoding, and the pixel and frame caps always
+// apply, protecting the server from decompression bombs.
+//
+// Example Usage:
+//
+//	http.RegisterImageSanitize(http.ImageSanitizeOptions{
+//		Enabled:   true,
+//		MaxBytes:  10 << 20, // 10 MB
+//		MaxWidth:  8000,
+//		MaxHeight: 8000,
+//	})
+func RegisterImageSanitize(options ImageSanitizeOptions) {
+	if options.JPEGQuality == 0 {
+		options.JPEGQuality = 90
+	}
+	if options.MaxPixels == 0 {
+		options.MaxPixels = defaultImageMaxPixels
+	}
+	if options.MaxFrames == 0 {
+		options.MaxFrames = defaultImageMaxFrames
+	}
+
+	imageSanitizeOptions = options
+}
+
+// sanitizeUploads re-encodes the uploaded images and writes the error response of the first rejected one.
+func sanitizeUploads(c *core.Ctx, files []core.UploadedFile) error {
+	if !imageSanitizeOptions.Enabled {
+		return nil
+	}
+
+	for i := range files {
+		errData, err := sanitizeImage(&files[i])
+		if err != nil {
+			return WriteServerError(c, &Error{
+				Message: "Unable to process uploaded image",
+			}, core.StatusInternalServerError, "Image sanitize error: %v", err)
+		}
+
+		if errData != nil {
+			return c.Error(errData, FailureStatus(c, FailureBusinessRule))
+		}
+	}
+
+	return nil
+}
+
+// sanitizeImage re-encodes an uploaded image in place. Files which are not JPEG, PNG or GIF images are left
+// untouched. The file is read as a stream, only the decoded pixels are held in memory.
+func sanitizeImage(file *core.UploadedFile) (*Error, error) {
+	options := imageSanitizeOptions
+
+	source, err := os.Open(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = source.Close() }()
+
+	info, err := source.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReaderSize(source, 512)
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	format := http.DetectContentType(head)
+	switch format {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, nil
+	}
+
+	if options.MaxBytes > 0 && info.Size() > options.MaxBytes {
+		return imageError(file.Field, "IMAGE_TOO_LARGE", fmt.Sprintf("must be at most %d bytes", options.MaxBytes)), nil
+	}
+
+	// Dimensions are read from the header, before any pixel is decoded
+	config, _, err := image.DecodeConfig(reader)
+	if err != nil {
+		return imageError(file.Field, "INVALID_IMAGE", "is not a valid image"), nil
+	}
+	if options.MaxWidth > 0 && config.Width > options.MaxWidth {
+		return imageError(file.Field, "IMAGE_TOO_LARGE",
+			fmt.Sprintf("must be at most %d pixels wide", options.MaxWidth)), nil
+	}
+	if options.MaxHeight > 0 && config.Height > options.MaxHeight {
+		return imageError(file.Field, "IMAGE_TOO_LARGE",
+			fmt.Sprintf("must be at most %d pixels high", options.MaxHeight)), nil
+	}
+
+	frames := 1
+	if format == "image/gif" {
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if frames, err = gifFrameCount(bufio.NewReader(source)); err != nil {
+			return imageError(file.Field, "INVALID_IMAGE", "is not a valid image"), nil
+		}
+		if frames > options.MaxFrames {
+			return imageError(file.Field, "IMAGE_TOO_LARGE",
+				fmt.Sprintf("must have at most %d frames", options.MaxFrames)), nil
+		}
+	}
+	if int64(config.Width)*int64(config.Height)*int64(frames) > options.MaxPixels {
+		return imageError(file.Field, "IMAGE_TOO_LARGE",
+			fmt.Sprintf("must have at most %d pixels", options.MaxPixels)), nil
+	}
+
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader = bufio.NewReaderSize(source, jpegHeaderSize)
+
+	encoded, err := os.CreateTemp(filepath.Dir(file.Path), "sanitized-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = encoded.Close()
+		_ = os.Remove(encoded.Name())
+	}()
+	writer := bufio.NewWriter(encoded)
+
+	switch format {
+	case "image/jpeg":
+		header, err := reader.Peek(jpegHeaderSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		orientation := jpegOrientation(header)
+
+		img, err := jpeg.Decode(reader)
+		if err != nil {
+			return imageError(file.Field, "INVALID_IMAGE", "is not a valid image"), nil
+		}
+		err = jpeg.Encode(writer, orientImage(img, orientation), &jpeg.Options{Quality: options.JPEGQuality})
+		if err != nil {
+			return nil, err
+		}
+	case "image/png":
+		img, err := png.Decode(reader)
+		if err != nil {
+			return imageError(file.Field, "INVALID_IMAGE", "is not a valid image"), nil
+		}
+		if err := png.Encode(writer, img); err != nil {
+			return nil, err
+		}
+	case "image/gif":
+		// Frames are kept, animated GIFs stay animated
+		img, err := gif.DecodeAll(reader)
+		if err != nil {
+			return imageError(file.Field, "INVALID_IMAGE", "is not a valid image"), nil
+		}
+		if err := gif.EncodeAll(writer, img); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	size, err := encoded.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoded.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(encoded.Name(), file.Path); err != nil {
+		return nil, err
+	}
+	file.Size = size
+
+	return nil, nil
+}
+
+// gifFrameCount counts the frames of a GIF by walking its blocks, without decompressing them.
+func gifFrameCount(reader *bufio.Reader) (int, error) {
+	// Header and logical screen descriptor
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, err
+	}
+	if header[10]&0x80 != 0 {
+		if _, err := reader.Discard(3 << (header[10]&0x07 + 1)); err != nil {
+			return 0, err
+		}
+	}
+
+	frames := 0
+	for {
+		introducer, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch introducer {
+		case 0x21: // Extension: label, then sub-blocks
+			if _, err := reader.Discard(1); err != nil {
+				return 0, err
+			}
+		case 0x2C: // Image descriptor, local color table, LZW code size, then sub-blocks
+			descriptor := make([]byte, 9)
+			if _, err := io.ReadFull(reader, descriptor); err != nil {
+				return 0, err
+			}
+			skip := 1
+			if descriptor[8]&0x80 != 0 {
+				skip += 3 << (descriptor[8]&0x07 + 1)
+			}
+			if _, err := reader.Discard(skip); err != nil {
+				return 0, err
+			}
+			frames++
+		case 0x3B: // Trailer
+			return frames, nil
+		default:
+			return 0, fmt.Errorf("unknown GIF block 0x%02x", introducer)
+		}
+
+		if err := skipGIFSubBlocks(reader); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// skipGIFSubBlocks skips a sequence of GIF data sub-blocks, up to its empty terminator.
+func skipGIFSubBlocks(reader *bufio.Reader) error {
+	for {
+		size, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if _, err := reader.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
+
+// imageError returns the Error of a rejected uploaded image.
+func imageError(field, code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: "Invalid uploaded image",
+		Data: core.Data{
+			field: []string{message},
+		},
+	}
+}
+
+// jpegOrientation returns the EXIF orientation (1 to 8) of a JPEG image, 1 when it has none.
+func jpegOrientation(data []byte) int {
+	// Walk the JPEG segments up to the start of scan
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || length < 2 || offset+2+length > len(data) {
+			break
+		}
+
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		offset += 2 + length
+	}
+
+	return 1
+}
+
+// exifOrientation returns the orientation tag of the first IFD of a TIFF structure, 1 when it has none.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// orientImage returns the image transformed by an EXIF orientation, so it displays upright without metadata.
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Orientations 5 to 8 swap width and height
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dst := image.NewNRGBA(image.Rect(0, 0, outWidth, outHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3: // Rotated 180°
+				dx, dy = width-1-x, height-1-y
+			case 4: // Mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // Mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // Rotated 90° clockwise
+				dx, dy = height-1-y, x
+			case 7: // Mirrored along the top-right diagonal
+				dx, dy = height-1-y, width-1-x
+			case 8: // Rotated 90° counter-clockwise
+				dx, dy = y, width-1-x
+			}
+
+			dst.SetNRGBA(dx, dy, src.NRGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
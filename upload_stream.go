@@ -0,0 +1,361 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ========================= Streamed Uploads =========================
+// ====================================================================
+
+// ErrorCodeUploadTooLarge code of the Error returned when a streamed upload exceeds the UploadLimits.
+const ErrorCodeUploadTooLarge = "UPLOAD_TOO_LARGE"
+
+// UploadLimits limits of the multipart bodies read by ProcessStreamedUpload.
+type UploadLimits struct {
+	MaxPartSize     int64 // Maximum size of a file, unlimited when zero
+	MaxTotalSize    int64 // Maximum size of all the files of a request, unlimited when zero
+	MaxParts        int   // Maximum number of parts (files and values) of a request, unlimited when zero
+	MemoryThreshold int64 // Size up to which a file is buffered in memory, larger files spill to a temporary file
+}
+
+// DefaultUploadLimits files of 32 MB and 128 MB in total, 64 parts, files above 1 MB spill to disk.
+var DefaultUploadLimits = UploadLimits{
+	MaxPartSize:     32 << 20,
+	MaxTotalSize:    128 << 20,
+	MaxParts:        64,
+	MemoryThreshold: 1 << 20,
+}
+
+// uploadLimits the limits used by ProcessStreamedUpload.
+var uploadLimits = DefaultUploadLimits
+
+// RegisterUploadLimits registers the limits of ProcessStreamedUpload, the memory threshold of
+// DefaultUploadLimits is used when zero.
+// Once a configuration snapshot is loaded (see ReloadConfig), the limits of the snapshot are replaced.
+//
+// Example Usage:
+//
+//	http.RegisterUploadLimits(http.UploadLimits{
+//		MaxPartSize:  512 << 20,
+//		MaxTotalSize: 1 << 30,
+//		MaxParts:     16,
+//	})
+func RegisterUploadLimits(limits UploadLimits) {
+	if limits.MemoryThreshold == 0 {
+		limits.MemoryThreshold = DefaultUploadLimits.MemoryThreshold
+	}
+
+	uploadLimits = limits
+	if configSnapshot.Load() != nil {
+		if err := UpdateConfig(func(config *Config) { config.Uploads = limits }); err != nil {
+			log.Errorf("Upload limits not registered: %v", err)
+		}
+	}
+}
+
+// StreamedFile file of a multipart body stored by ProcessStreamedUpload.
+type StreamedFile struct {
+	Field  string     // Form field of the file
+	Name   string     // File name sent by the client
+	Object ObjectInfo // Stored object
+}
+
+// StreamedForm multipart body received by ProcessStreamedUpload.
+type StreamedForm struct {
+	Values map[string][]string // Form values by field
+	Files  []StreamedFile      // Stored files, in the order of the body
+}
+
+// ProcessStreamedUpload reads a multipart body part by part and hands each file to the registered storage under
+// the key returned by keyFn (files with an empty key are skipped), so neither the body nor whole files are held
+// in memory: files up to the memory threshold of the UploadLimits are buffered, larger ones spill to a temporary
+// file, and the storage always receives their size. Files are scanned by the registered Scanner before being
+// stored; images are not re-encoded (see RegisterImageSanitize).
+//
+// Bodies exceeding the limits are answered with 413 UPLOAD_TOO_LARGE, and the files already stored are deleted.
+// The body is read as it arrives when the server streams request bodies (StreamRequestBody) and the body is
+// larger than the server's MaxRequestBodySize; smaller bodies have already been read.
+//
+// Example Usage:
+//
+//	func (h UploadDocumentsApi) Validate(c *core.Ctx) error {
+//		return http.ProcessStreamedUpload(c, func(field, name string) string {
+//			if field != "documents" {
+//				return ""
+//			}
+//			return fmt.Sprintf("documents/%s%s", uuid.NewString(), filepath.Ext(name))
+//		})
+//	}
+//
+//	func (h UploadDocumentsApi) Handle(c *core.Ctx) error {
+//		form := http.StreamedUploads(c)
+//		...
+//	}
+func ProcessStreamedUpload(c *core.Ctx, keyFn func(field, name string) string) error {
+	storage := GetStorage()
+	invalidUpload := &Error{
+		Code:    "INVALID_UPLOAD",
+		Message: "Invalid upload",
+	}
+
+	boundary := string(c.Root().Request.Header.MultipartFormBoundary())
+	if boundary == "" {
+		return c.Error(invalidUpload, FailureStatus(c, FailureMalformed))
+	}
+
+	if storage == nil {
+		return WriteServerError(c, &Error{
+			Message: "Unable to store upload",
+		}, core.StatusInternalServerError, "Upload store error: storage is not registered")
+	}
+
+	body, _ := requestBody(c)
+	receiver := &streamReceiver{
+		c:       c,
+		storage: storage,
+		limits:  RequestConfig(c).Uploads,
+		keyFn:   keyFn,
+		form:    StreamedForm{Values: map[string][]string{}},
+	}
+
+	if err := receiver.receive(multipart.NewReader(body, boundary)); err != nil {
+		receiver.rollback()
+
+		if errors.Is(err, errStreamResponded) {
+			return receiver.response
+		}
+
+		log.Warnf("Streamed upload rejected: %v", err)
+
+		return c.Error(invalidUpload, FailureStatus(c, FailureMalformed))
+	}
+
+	c.SetData(StreamedUploadsKey, receiver.form)
+
+	return nil
+}
+
+// StreamedUploads returns the form received by ProcessStreamedUpload.
+func StreamedUploads(c *core.Ctx) StreamedForm {
+	form, _ := c.GetData(StreamedUploadsKey).(StreamedForm)
+
+	return form
+}
+
+// errStreamResponded returned by streamReceiver once the error response is written.
+var errStreamResponded = errors.New("upload response written")
+
+// streamReceiver state of the reception of a multipart body.
+type streamReceiver struct {
+	c        *core.Ctx
+	storage  Storage
+	limits   UploadLimits
+	keyFn    func(field, name string) string
+	form     StreamedForm
+	total    int64 // Size of the files received
+	response error // Error response, set with errStreamResponded
+}
+
+// receive reads the parts of a multipart body.
+func (r *streamReceiver) receive(reader *multipart.Reader) error {
+	for parts := 1; ; parts++ {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if r.limits.MaxParts > 0 && parts > r.limits.MaxParts {
+			return r.tooLarge("parts", fmt.Sprintf("must be at most %d", r.limits.MaxParts))
+		}
+
+		if part.FileName() == "" {
+			err = r.receiveValue(part)
+		} else {
+			err = r.receiveFile(part)
+		}
+		_ = part.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// receiveValue reads a form value, values are limited to the memory threshold.
+func (r *streamReceiver) receiveValue(part *multipart.Part) error {
+	value, err := io.ReadAll(io.LimitReader(part, r.limits.MemoryThreshold+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(value)) > r.limits.MemoryThreshold {
+		return r.tooLarge(part.FormName(), fmt.Sprintf("must be at most %d bytes", r.limits.MemoryThreshold))
+	}
+
+	r.form.Values[part.FormName()] = append(r.form.Values[part.FormName()], string(value))
+
+	return nil
+}
+
+// receiveFile stores a file: buffered in memory up to the memory threshold, spilled to a temporary file above.
+func (r *streamReceiver) receiveFile(part *multipart.Part) error {
+	field, name := part.FormName(), part.FileName()
+
+	key := r.keyFn(field, name)
+	if key == "" {
+		_, err := io.Copy(io.Discard, part)
+
+		return err
+	}
+
+	// Bytes the file may hold: the smallest of the part and remaining total limits
+	limit := int64(math.MaxInt64 - 1)
+	if r.limits.MaxPartSize > 0 {
+		limit = r.limits.MaxPartSize
+	}
+	if r.limits.MaxTotalSize > 0 {
+		limit = min(limit, r.limits.MaxTotalSize-r.total)
+	}
+	content := io.LimitReader(part, limit+1)
+
+	buffer := acquireBuffer(int(r.limits.MemoryThreshold))
+	defer releaseBuffer(buffer)
+
+	size, err := io.CopyN(buffer, content, r.limits.MemoryThreshold+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var file io.ReadSeeker = bytes.NewReader(buffer.Bytes())
+	if size > r.limits.MemoryThreshold {
+		spilled, err := os.CreateTemp(core.TempDir, "upload-*")
+		if err != nil {
+			return r.storeError(err)
+		}
+		defer func() {
+			_ = spilled.Close()
+			_ = os.Remove(spilled.Name())
+		}()
+
+		if size, err = io.Copy(spilled, io.MultiReader(buffer, content)); err != nil {
+			return err
+		}
+		if _, err := spilled.Seek(0, io.SeekStart); err != nil {
+			return r.storeError(err)
+		}
+		file = spilled
+	}
+
+	if size > limit {
+		if r.limits.MaxPartSize > 0 && size > r.limits.MaxPartSize {
+			return r.tooLarge(field, fmt.Sprintf("must be at most %d bytes", r.limits.MaxPartSize))
+		}
+
+		return r.tooLarge(field, fmt.Sprintf("files must be at most %d bytes in total", r.limits.MaxTotalSize))
+	}
+	r.total += size
+
+	if uploadScanner != nil {
+		result, err := scanContent(name, file)
+		if err := checkScan(r.c, field, name, result, err); err != nil {
+			return r.respond(err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return r.storeError(err)
+		}
+	}
+
+	// The type is sniffed from the content, the part header and the file name are set by the client
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		return r.storeError(err)
+	}
+
+	info := ObjectInfo{
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		ModifiedAt:  time.Now(),
+	}
+
+	if err := r.storage.Put(r.c.Root(), key, file, size, info.ContentType); err != nil {
+		return r.storeError(err)
+	}
+
+	r.form.Files = append(r.form.Files, StreamedFile{Field: field, Name: name, Object: info})
+
+	return nil
+}
+
+// sniffContentType returns the MIME type of a file sniffed from its first 512 bytes, and rewinds it.
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	read, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:read]), nil
+}
+
+// tooLarge writes the 413 UPLOAD_TOO_LARGE response of a field.
+func (r *streamReceiver) tooLarge(field, message string) error {
+	return r.respond(r.c.Error(&Error{
+		Code:    ErrorCodeUploadTooLarge,
+		Message: "Upload is too large",
+		Data:    core.Data{field: []string{message}},
+	}, core.StatusRequestEntityTooLarge))
+}
+
+// storeError logs an error of the server while storing a file and writes the 500 response.
+func (r *streamReceiver) storeError(err error) error {
+	return r.respond(WriteServerError(r.c, &Error{
+		Message: "Unable to store upload",
+	}, core.StatusInternalServerError, "Upload store error: %v", err))
+}
+
+// respond records the written error response.
+func (r *streamReceiver) respond(response error) error {
+	r.response = response
+
+	return errStreamResponded
+}
+
+// rollback deletes the files stored before the reception failed.
+func (r *streamReceiver) rollback() {
+	for _, file := range r.form.Files {
+		if err := r.storage.Delete(r.c.Root(), file.Object.Key); err != nil {
+			log.Errorf("Upload removal error: %v", err)
+		}
+	}
+}
+
+// requestBody returns the request body and its size (-1 when unknown): the body stream while the server
+// streams it, else the body read in memory.
+func requestBody(c *core.Ctx) (io.Reader, int64) {
+	root := c.Root()
+	if stream := root.RequestBodyStream(); stream != nil {
+		return stream, max(int64(root.Request.Header.ContentLength()), -1)
+	}
+
+	body := root.PostBody()
+
+	return bytes.NewReader(body), int64(len(body))
+}
@@ -0,0 +1,274 @@
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gflydev/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+// ====================================================================
+// ========================= Validation Rules =========================
+// ====================================================================
+
+// Register the package's custom validation rules
+func init() {
+	validation.AddRule(UUIDv7Rule("uuid7"))
+	validation.AddRule(MoneyRule("money"))
+	validation.AddRule(MoneyRule("money_min"))
+	validation.AddRule(MoneyRule("money_max"))
+	validation.AddRule(MoneyRule("money_currency"))
+	validation.AddRule(DateRule("date_before"))
+	validation.AddRule(DateRule("date_after"))
+	validation.AddRule(DateRule("date_today"))
+	validation.AddRule(DateRule("time_before"))
+	validation.AddRule(DateRule("time_after"))
+	validation.AddRule(CodeRule("phone"))
+	validation.AddRule(CodeRule("country"))
+	validation.AddRule(CodeRule("currency"))
+	validation.AddRule(RestrictedRule("restricted"))
+	validation.AddRule(RedirectURLRule("redirect_url"))
+	validation.AddRule(PatternRule("pattern"))
+}
+
+// UUIDv7Rule custom validation rule checking a string field is a UUID version 7.
+//
+//	ID string `json:"id" validate:"required,uuid7"`
+type UUIDv7Rule string
+
+func (v UUIDv7Rule) GetTag() string {
+	return string(v)
+}
+
+func (v UUIDv7Rule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return IsUUIDv7(fl.Field().String())
+	}
+}
+
+// MoneyRule custom validation rules for Money fields. The rule depends on its tag:
+//
+//   - money: the currency is a valid ISO 4217 code
+//   - money_min=0.01: the amount is greater than or equal to the decimal parameter
+//   - money_max=1000: the amount is less than or equal to the decimal parameter
+//   - money_currency=USD EUR: the currency is one of the space-separated codes
+//
+// Example:
+//
+//	Price http.Money `json:"price" validate:"money,money_min=0.01,money_currency=USD EUR"`
+type MoneyRule string
+
+func (v MoneyRule) GetTag() string {
+	return string(v)
+}
+
+func (v MoneyRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		var money Money
+		switch value := fl.Field().Interface().(type) {
+		case Money:
+			money = value
+		case *Money:
+			money = *value
+		default:
+			return false
+		}
+
+		switch string(v) {
+		case "money":
+			return validation.ValidatorInstance().Var(money.Currency, "iso4217") == nil
+		case "money_min", "money_max":
+			limit, err := ParseAmount(fl.Param(), CurrencyExponent(money.Currency))
+			if err != nil {
+				return false
+			}
+			if string(v) == "money_min" {
+				return money.Amount >= limit
+			}
+
+			return money.Amount <= limit
+		case "money_currency":
+			for _, currency := range strings.Fields(fl.Param()) {
+				if strings.EqualFold(currency, money.Currency) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+// DateRule custom validation rules for Date and TimeOfDay fields. The rule depends on its tag:
+//
+//   - date_before=2024-12-31: the date is strictly before the parameter ("today" is accepted)
+//   - date_after=today: the date is strictly after the parameter ("today" is accepted)
+//   - date_today: the date is today
+//   - time_before=18:00: the time of day is strictly before the parameter
+//   - time_after=08:00: the time of day is strictly after the parameter
+//
+// Zero dates pass date_before and date_after so optional fields can be left empty.
+// "today" is evaluated in time.Local.
+//
+// Example:
+//
+//	Birthday http.Date `json:"birthday" validate:"date_before=today"`
+type DateRule string
+
+func (v DateRule) GetTag() string {
+	return string(v)
+}
+
+func (v DateRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		switch value := reflect.Indirect(fl.Field()).Interface().(type) {
+		case Date:
+			if string(v) == "date_today" {
+				return value == Today(nil)
+			}
+			if value.IsZero() {
+				return true
+			}
+
+			limit := Today(nil)
+			if fl.Param() != "today" {
+				parsed, err := ParseDate(fl.Param())
+				if err != nil {
+					return false
+				}
+				limit = parsed
+			}
+
+			switch string(v) {
+			case "date_before":
+				return value.Before(limit)
+			case "date_after":
+				return value.After(limit)
+			}
+		case TimeOfDay:
+			limit, err := ParseTimeOfDay(fl.Param())
+			if err != nil {
+				return false
+			}
+
+			switch string(v) {
+			case "time_before":
+				return value.Before(limit)
+			case "time_after":
+				return value.After(limit)
+			}
+		}
+
+		return false
+	}
+}
+
+// codeRuleTags validator's built-in tags checked by each CodeRule.
+var codeRuleTags = map[string]string{
+	"phone":    "e164",
+	"country":  "iso3166_1_alpha2",
+	"currency": "iso4217",
+}
+
+// CodeRule custom validation rules for standard codes. The rule depends on its tag:
+//
+//   - phone: E.164 phone number such as "+84901234567"
+//   - country: ISO 3166-1 alpha-2 country code such as "VN"
+//   - currency: ISO 4217 currency code such as "USD"
+//
+// Values are normalized by SanitizeStruct before validation (see NormalizePhone),
+// IANA timezones use the validator's built-in "timezone" tag and are normalized as well.
+//
+// Example:
+//
+//	Phone string `json:"phone" validate:"required,phone"`
+type CodeRule string
+
+func (v CodeRule) GetTag() string {
+	return string(v)
+}
+
+func (v CodeRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return validation.ValidatorInstance().Var(fl.Field().String(), codeRuleTags[string(v)]) == nil
+	}
+}
+
+// RestrictedRule custom validation rule rejecting any value for the field. It marks fields only
+// privileged callers may set, a RuleOverride relaxing "restricted" grants them access.
+//
+//	Role string `json:"role" validate:"omitempty,oneof=user admin,restricted"`
+type RestrictedRule string
+
+func (v RestrictedRule) GetTag() string {
+	return string(v)
+}
+
+func (v RestrictedRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return fl.Field().IsZero()
+	}
+}
+
+// RedirectURLRule custom validation rule checking a string field is a safe redirect target: a relative path
+// or an URL of a host registered with RegisterRedirectHosts.
+//
+//	ReturnURL string `json:"return_url" validate:"omitempty,redirect_url"`
+type RedirectURLRule string
+
+func (v RedirectURLRule) GetTag() string {
+	return string(v)
+}
+
+func (v RedirectURLRule) Handler() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return IsSafeRedirect(fl.Field().String(), redirectAllowedHosts)
+	}
+}
+
+// ====================================================================
+// ======================== Validation Messages =======================
+// ====================================================================
+
+// MsgForTag is a validation.MsgForTagFunc which builds messages for the package's custom
+// validation rules and falls back to validation.MsgForTag for the others.
+func MsgForTag(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "uuid7":
+		return "invalid UUIDv7"
+	case "ulid":
+		return "invalid ULID"
+	case "money":
+		return "invalid currency"
+	case "money_min":
+		return fmt.Sprintf("amount greater than or equal %s", fe.Param())
+	case "money_max":
+		return fmt.Sprintf("amount less than or equal %s", fe.Param())
+	case "money_currency":
+		return fmt.Sprintf("currency one of %s", fe.Param())
+	case "date_before", "time_before":
+		return fmt.Sprintf("before %s", fe.Param())
+	case "date_after", "time_after":
+		return fmt.Sprintf("after %s", fe.Param())
+	case "date_today":
+		return "must be today"
+	case "phone":
+		return "invalid phone number, E.164 format expected"
+	case "country":
+		return "invalid country code, ISO 3166-1 alpha-2 expected"
+	case "currency":
+		return "invalid currency code, ISO 4217 expected"
+	case "timezone":
+		return "invalid timezone, IANA name expected"
+	case "restricted":
+		return "can not be set"
+	case "redirect_url":
+		return "invalid redirect URL, relative path or allowed host expected"
+	case "pattern":
+		return fmt.Sprintf("invalid format, %s expected", fe.Param())
+	}
+
+	return validation.MsgForTag(fe)
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gflydev/core"
+	"github.com/go-playground/validator/v10"
+)
+
+// ====================================================================
+// ========================= Validation Stats =========================
+// ====================================================================
+
+// Counters reported to the registered Metrics backend.
+const (
+	MetricValidationRequests = "http_validation_requests_total" // Labels: dto
+	MetricValidationFailures = "http_validation_failures_total" // Labels: dto, field, rule
+)
+
+// ValidationRuleStat failure counter of a validation rule of a DTO field.
+// @Description Failure rate of a validation rule of a DTO field
+// @DTO DTO is the request DTO type
+// @Field Field is the field path
+// @Rule Rule is the validation rule tag
+// @Failures Failures is the number of validations failing the rule
+// @Requests Requests is the number of validations of the DTO
+// @Rate Rate is Failures / Requests
+// @Tags Info Responses
+type ValidationRuleStat struct {
+	DTO      string  `json:"dto" example:"dto.CreateUser"`
+	Field    string  `json:"field" example:"email"`
+	Rule     string  `json:"rule" example:"email"`
+	Failures uint64  `json:"failures" example:"400"`
+	Requests uint64  `json:"requests" example:"1000"`
+	Rate     float64 `json:"rate" example:"0.4"`
+}
+
+// validationRuleKey key of a rule failure counter.
+type validationRuleKey struct {
+	dto, field, rule string
+}
+
+var (
+	validationStatsMu sync.Mutex
+	// validationRequests number of validations by DTO.
+	validationRequests = map[string]uint64{}
+	// validationFailures number of failures by DTO, field and rule.
+	validationFailures = map[validationRuleKey]uint64{}
+)
+
+// recordValidation counts a validation of the DTO and its failed rules. It is called by ValidateRequest.
+func recordValidation(structData any, failed []validator.FieldError) {
+	dto := dtoName(structData)
+
+	// Labels of structs are allocated once, by their plan
+	var labels map[string]string
+	if typ := reflect.TypeOf(structData); typ != nil && typ.Kind() == reflect.Struct {
+		labels = planOf(typ).labels
+	} else {
+		labels = map[string]string{"dto": dto}
+	}
+
+	// Items of slices and maps share the counters of their field, counted once per validation
+	keys := make([]validationRuleKey, 0, len(failed))
+	for _, fe := range failed {
+		key := validationRuleKey{dto: dto, field: statsFieldPath(fe.Namespace()), rule: fe.Tag()}
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	validationStatsMu.Lock()
+	validationRequests[dto]++
+	for _, key := range keys {
+		validationFailures[key]++
+	}
+	validationStatsMu.Unlock()
+
+	incCounter(MetricValidationRequests, labels)
+	for _, key := range keys {
+		incCounter(MetricValidationFailures, map[string]string{
+			"dto":   dto,
+			"field": key.field,
+			"rule":  key.rule,
+		})
+	}
+}
+
+// statsFieldPath returns the field path of a validator namespace with the indexes and keys of items removed
+// ("CreateOrder.items[3].sku" -> "items[].sku"), bounding the number of counters and metric labels.
+func statsFieldPath(namespace string) string {
+	path := fieldPath(namespace)
+	if !strings.Contains(path, "[") {
+		return path
+	}
+
+	var normalized strings.Builder
+	for {
+		open := strings.IndexByte(path, '[')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(path[open:], ']')
+		if end < 0 {
+			break
+		}
+		normalized.WriteString(path[:open])
+		normalized.WriteString("[]")
+		path = path[open+end+1:]
+	}
+	normalized.WriteString(path)
+
+	return normalized.String()
+}
+
+// ValidationStats returns a snapshot of the validation failure counters, highest failure rates first,
+// e.g. to spot that 40% of requests fail the "email" rule after a client release.
+func ValidationStats() []ValidationRuleStat {
+	validationStatsMu.Lock()
+	defer validationStatsMu.Unlock()
+
+	stats := make([]ValidationRuleStat, 0, len(validationFailures))
+	for key, failures := range validationFailures {
+		requests := validationRequests[key.dto]
+		stats = append(stats, ValidationRuleStat{
+			DTO:      key.dto,
+			Field:    key.field,
+			Rule:     key.rule,
+			Failures: failures,
+			Requests: requests,
+			Rate:     float64(failures) / float64(requests),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Rate != stats[j].Rate {
+			return stats[i].Rate > stats[j].Rate
+		}
+
+		return stats[i].DTO+stats[i].Field+stats[i].Rule < stats[j].DTO+stats[j].Field+stats[j].Rule
+	})
+
+	return stats
+}
+
+// ResetValidationStats clears the validation counters, e.g. after a client release.
+func ResetValidationStats() {
+	validationStatsMu.Lock()
+	defer validationStatsMu.Unlock()
+
+	validationRequests = map[string]uint64{}
+	validationFailures = map[validationRuleKey]uint64{}
+}
+
+// dtoName returns the type name of a DTO, e.g. "dto.CreateUser".
+func dtoName(structData any) string {
+	typ := reflect.TypeOf(structData)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return "<nil>"
+	}
+	if typ.Kind() == reflect.Struct {
+		return planOf(typ).name
+	}
+
+	return typ.String()
+}
+
+// ValidationStatsApi debug handler listing the validation failure counters. It exposes DTO internals:
+// register it behind an admin middleware.
+type ValidationStatsApi struct {
+	core.Api
+}
+
+// NewValidationStatsApi creates the validation stats debug handler.
+//
+// Example Usage:
+//
+//	admin.GET("/_debug/validation", http.NewValidationStatsApi())
+func NewValidationStatsApi() *ValidationStatsApi {
+	return &ValidationStatsApi{}
+}
+
+// Handle sends the snapshot of the validation failure counters.
+func (h *ValidationStatsApi) Handle(c *core.Ctx) error {
+	stats := ValidationStats()
+
+	return WriteList(c, List[ValidationRuleStat]{
+		Meta: Meta{Total: len(stats)},
+		Data: stats,
+	})
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gflydev/core"
+	"github.com/gflydev/core/log"
+)
+
+// ====================================================================
+// ============================ WebSocket =============================
+// ====================================================================
+
+// WSMaxMessageSize maximum size in bytes of inbound WebSocket messages.
+var WSMaxMessageSize int64 = 1 << 20
+
+// WSUpgrader upgrader used by Upgrade. Set CheckOrigin to accept cross-origin clients.
+var WSUpgrader = websocket.FastHTTPUpgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// ErrMalformedWSMessage returned by WSConn.Receive when a message is not a valid WSMessage envelope.
+var ErrMalformedWSMessage = errors.New("malformed websocket message")
+
+// WSMessage struct to describe an inbound WebSocket message.
+// @Description Inbound WebSocket message envelope
+// @Type Type is the message type used for routing, e.g. "chat.send"
+// @ID ID is an optional client correlation ID echoed in the response
+// @Data Data is the message payload decoded into a DTO by ParseWSMessage
+// @Tags WebSocket
+type WSMessage struct {
+	Type string          `json:"type" example:"chat.send"`
+	ID   string          `json:"id,omitempty" example:"c1"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// WSResponse struct to describe an outbound WebSocket message reusing the Success and Error envelopes.
+// @Description Outbound WebSocket message envelope, exactly one of Success and Error is set
+// @Tags WebSocket
+type WSResponse struct {
+	Type    string   `json:"type" example:"chat.send"`
+	ID      string   `json:"id,omitempty" example:"c1"`
+	Success *Success `json:"success,omitempty"`
+	Error   *Error   `json:"error,omitempty"`
+}
+
+// WSConn WebSocket connection with typed send/receive helpers. Sends are safe for concurrent use.
+type WSConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+// Upgrade upgrades the request to a WebSocket connection and runs the handler with it.
+// The connection is closed when the handler returns.
+//
+// Example Usage:
+//
+//	func (h ChatApi) Handle(c *core.Ctx) error {
+//		return http.Upgrade(c, func(conn *http.WSConn) {
+//			for {
+//				msg, err := conn.Receive()
+//				if err != nil {
+//					return
+//				}
+//				req, errData := http.ParseWSMessage[SendMessageRequest](msg)
+//				if errData != nil {
+//					_ = conn.SendError(msg, errData)
+//					continue
+//				}
+//				_ = conn.SendSuccess(msg, http.Success{Message: "Sent", Data: core.Data{"text": req.Text}})
+//			}
+//		})
+//	}
+func Upgrade(c *core.Ctx, handler func(conn *WSConn)) error {
+	maxMessageSize := RequestConfig(c).WSMaxMessageSize
+	err := WSUpgrader.Upgrade(c.Root(), func(conn *websocket.Conn) {
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		conn.SetReadLimit(maxMessageSize)
+		handler(&WSConn{Conn: conn})
+	})
+	if err != nil {
+		log.Debugf("WebSocket upgrade error: %v", err)
+	}
+
+	return err
+}
+
+// IsWebSocketUpgrade checks the request asks for a WebSocket upgrade.
+func IsWebSocketUpgrade(c *core.Ctx) bool {
+	return websocket.FastHTTPIsWebSocketUpgrade(c.Root())
+}
+
+// Receive reads the next message. Connection errors are returned as-is;
+// messages which are not valid envelopes return ErrMalformedWSMessage.
+func (w *WSConn) Receive() (WSMessage, error) {
+	var msg WSMessage
+
+	_, data, err := w.ReadMessage()
+	if err != nil {
+		return msg, err
+	}
+
+	if err = json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+		return msg, ErrMalformedWSMessage
+	}
+
+	return msg, nil
+}
+
+// SendSuccess sends a Success envelope in reply to the message (type and ID are echoed).
+func (w *WSConn) SendSuccess(msg WSMessage, data Success) error {
+	return w.send(WSResponse{Type: msg.Type, ID: msg.ID, Success: &data})
+}
+
+// SendError sends an Error envelope in reply to the message (type and ID are echoed).
+func (w *WSConn) SendError(msg WSMessage, data *Error) error {
+	return w.send(WSResponse{Type: msg.Type, ID: msg.ID, Error: data})
+}
+
+func (w *WSConn) send(response WSResponse) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.WriteJSON(response)
+}
+
+// ParseWSMessage decodes the message payload into T and runs the same pipeline as ProcessData:
+// sanitization, validation and field transforms.
+func ParseWSMessage[T any](msg WSMessage) (T, *Error) {
+	var requestData T
+
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &requestData); err != nil {
+			return requestData, &Error{
+				Message: err.Error(),
+			}
+		}
+	}
+
+	// Sanitize request data
+	SanitizeStruct(&requestData)
+
+	// Validate DTO
+	if errData := Validate(requestData); errData != nil {
+		return requestData, errData
+	}
+
+	// Transform validated data
+	if err := TransformStruct(&requestData); err != nil {
+		log.Errorf("Transform websocket data error: %v", err)
+
+		return requestData, &Error{
+			Message: "Unable to process message data",
+		}
+	}
+
+	return requestData, nil
+}
@@ -0,0 +1,440 @@
+package http
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ====================================================================
+// ============================ XLSX Sheets ===========================
+// ====================================================================
+
+const (
+	// xlsxMaxPartSize maximum uncompressed size of a workbook part, protecting the server from zip bombs.
+	xlsxMaxPartSize = 256 << 20
+	// xlsxMaxColumns number of columns of a sheet.
+	xlsxMaxColumns = 16384
+	// xlsxMaxRows number of rows of a sheet.
+	xlsxMaxRows = 1048576
+)
+
+// xlsxRow row element of a sheet.
+type xlsxRow struct {
+	Index int `xml:"r,attr"`
+	Cells []struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline struct {
+			Text string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"is"`
+	} `xml:"c"`
+}
+
+// xlsxSheetReader importRowReader streaming the rows of the first sheet of an XLSX workbook as strings, one row
+// element decoded at a time. Cells are read as displayed by their raw value: numbers and dates (serial numbers)
+// are not formatted.
+type xlsxSheetReader struct {
+	workbook      *zip.ReadCloser
+	sharedStrings []string
+	part          io.ReadCloser
+	decoder       *xml.Decoder
+	count         int64
+	line          int      // Number of rows returned
+	skipped       int      // Rows without cells still to return before next
+	next          []string // Row read ahead of the skipped ones
+	pending       bool     // Next holds a row
+}
+
+// openXLSXSheet opens the first sheet of an XLSX workbook. Its rows are counted first, so the progress has a
+// total.
+func openXLSXSheet(filePath string) (*xlsxSheetReader, error) {
+	workbook, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newXLSXSheetReader(workbook)
+	if err != nil {
+		_ = workbook.Close()
+
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// newXLSXSheetReader reads the shared strings of a workbook, counts the rows of its first sheet and opens it.
+func newXLSXSheetReader(workbook *zip.ReadCloser) (*xlsxSheetReader, error) {
+	files := map[string]*zip.File{}
+	for _, file := range workbook.File {
+		files[file.Name] = file
+	}
+
+	sharedStrings, err := readXLSXSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	sheetPath, err := firstXLSXSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("xlsx: sheet not found")
+	}
+
+	count, err := countXLSXRows(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	part, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxSheetReader{
+		workbook:      workbook,
+		sharedStrings: sharedStrings,
+		part:          part,
+		decoder:       xml.NewDecoder(io.LimitReader(part, xlsxMaxPartSize)),
+		count:         count,
+	}, nil
+}
+
+// countXLSXRows returns the number of rows of a sheet, rows without cells included, without decoding them.
+func countXLSXRows(sheet *zip.File) (int64, error) {
+	part, err := sheet.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer part.Close()
+
+	var count int64
+	decoder := xml.NewDecoder(io.LimitReader(part, xlsxMaxPartSize))
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		count++
+		for _, attr := range start.Attr {
+			if index, err := strconv.ParseInt(attr.Value, 10, 64); attr.Name.Local == "r" && err == nil {
+				count = max(count, index)
+			}
+		}
+	}
+}
+
+// Next returns the next row, nil for rows without cells, io.EOF after the last one.
+func (r *xlsxSheetReader) Next() ([]string, error) {
+	if r.skipped > 0 {
+		r.skipped--
+		r.line++
+
+		return nil, nil
+	}
+	if r.pending {
+		values := r.next
+		r.next, r.pending = nil, false
+		r.line++
+
+		return values, nil
+	}
+
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := r.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+		if row.Index > xlsxMaxRows {
+			return nil, errors.New("xlsx: invalid row number")
+		}
+
+		values, err := r.values(row)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows without cells are not written
+		if gap := row.Index - r.line - 1; gap > 0 {
+			r.skipped = gap - 1
+			r.next, r.pending = values, true
+			r.line++
+
+			return nil, nil
+		}
+		r.line++
+
+		return values, nil
+	}
+}
+
+// values returns the values of the cells of a row.
+func (r *xlsxSheetReader) values(row xlsxRow) ([]string, error) {
+	var values []string
+	for i, cell := range row.Cells {
+		column := i
+		if cell.Ref != "" {
+			column = xlsxColumnIndex(cell.Ref)
+		}
+		if column < 0 || column >= xlsxMaxColumns {
+			return nil, errors.New("xlsx: invalid cell reference")
+		}
+		for len(values) <= column {
+			values = append(values, "")
+		}
+
+		switch cell.Type {
+		case "s":
+			index, err := strconv.Atoi(cell.Value)
+			if err != nil || index < 0 || index >= len(r.sharedStrings) {
+				return nil, errors.New("xlsx: invalid shared string")
+			}
+			values[column] = r.sharedStrings[index]
+		case "inlineStr":
+			values[column] = cell.Inline.Text + strings.Join(cell.Inline.Runs, "")
+		case "b":
+			values[column] = strconv.FormatBool(cell.Value == "1")
+		default:
+			values[column] = cell.Value
+		}
+	}
+
+	return values, nil
+}
+
+// Count returns the number of data rows, the header excluded.
+func (r *xlsxSheetReader) Count() int64 {
+	return max(r.count-1, 0)
+}
+
+// Close closes the sheet and the workbook.
+func (r *xlsxSheetReader) Close() error {
+	return errors.Join(r.part.Close(), r.workbook.Close())
+}
+
+// readXLSXSharedStrings returns the shared strings of a workbook.
+func readXLSXSharedStrings(file *zip.File) ([]string, error) {
+	if file == nil {
+		return nil, nil
+	}
+
+	var data struct {
+		Items []struct {
+			Text string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"si"`
+	}
+	if err := decodeXLSXPart(file, &data); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(data.Items))
+	for i, item := range data.Items {
+		values[i] = item.Text + strings.Join(item.Runs, "")
+	}
+
+	return values, nil
+}
+
+// firstXLSXSheet returns the path of the first sheet of a workbook.
+func firstXLSXSheet(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXLSXPart(files["xl/workbook.xml"], &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx: workbook has no sheet")
+	}
+
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXLSXPart(files["xl/_rels/workbook.xml.rels"], &relationships); err != nil {
+		return "", err
+	}
+
+	for _, item := range relationships.Items {
+		if item.ID == workbook.Sheets[0].ID {
+			if strings.HasPrefix(item.Target, "/") {
+				return strings.TrimPrefix(item.Target, "/"), nil
+			}
+
+			return path.Join("xl", item.Target), nil
+		}
+	}
+
+	return "", errors.New("xlsx: sheet not found")
+}
+
+// decodeXLSXPart decodes an XML part of a workbook.
+func decodeXLSXPart(file *zip.File, data any) error {
+	if file == nil {
+		return errors.New("xlsx: invalid workbook")
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return xml.NewDecoder(io.LimitReader(reader, xlsxMaxPartSize)).Decode(data)
+}
+
+// xlsxColumnIndex returns the zero-based column of a cell reference, e.g. 27 for "AB12".
+func xlsxColumnIndex(ref string) int {
+	column := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' || column > xlsxMaxColumns {
+			break
+		}
+		column = column*26 + int(char-'A'+1)
+	}
+
+	return column - 1
+}
+
+// xlsxColumnName returns the letters of a zero-based column, e.g. "AB" for 27.
+func xlsxColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+
+	return name
+}
+
+// xlsxWriter streaming writer of a single-sheet XLSX workbook. Rows are written to the sheet as they come,
+// strings inline, so the workbook never sits in memory.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	row     int
+}
+
+// xlsxStaticParts parts of a single-sheet workbook, besides the sheet.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// newXLSXWriter starts a workbook written to w.
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(writer, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, xml.Header+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+// WriteRow writes a row; cells flagged numeric are written as numbers, others as strings.
+func (w *xlsxWriter) WriteRow(values []string, numeric []bool) error {
+	w.row++
+	if w.row > xlsxMaxRows {
+		return errors.New("xlsx: too many rows")
+	}
+
+	var row strings.Builder
+	row.WriteString(`<row r="` + strconv.Itoa(w.row) + `">`)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+
+		ref := xlsxColumnName(i) + strconv.Itoa(w.row)
+		if i < len(numeric) && numeric[i] {
+			row.WriteString(`<c r="` + ref + `"><v>` + value + `</v></c>`)
+			continue
+		}
+
+		row.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&row, []byte(value)); err != nil {
+			return err
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+
+	_, err := io.WriteString(w.sheet, row.String())
+
+	return err
+}
+
+// Close ends the sheet and the workbook.
+func (w *xlsxWriter) Close() error {
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+
+	return w.archive.Close()
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/gflydev/core"
+)
+
+// ====================================================================
+// ============================ XML Bodies ============================
+// ====================================================================
+
+// isXMLBody checks the request body is XML: application/xml, text/xml or a +xml media type
+// (e.g. application/soap+xml).
+func isXMLBody(c *core.Ctx) bool {
+	mediaType, _, err := mime.ParseMediaType(string(c.Root().Request.Header.ContentType()))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == core.MIMEApplicationXML || mediaType == core.MIMETextXML || strings.HasSuffix(mediaType, "+xml")
+}
+
+// parseXML decodes an XML body into target, a pointer to typ. Elements and attributes are matched by the `xml`
+// tags of the DTO's fields; elements of fields without `xml` tag are matched by their JSON name, or by their Go
+// name. The name of the root element is free unless the DTO has an XMLName field.
+func parseXML(c *core.Ctx, typ reflect.Type, target any) *Error {
+	decoder := xml.NewTokenDecoder(&xmlFieldNames{
+		decoder: xml.NewDecoder(bytes.NewReader(c.Root().PostBody())),
+		root:    typ,
+	})
+	if err := decoder.Decode(target); err != nil {
+		return &Error{
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+var (
+	xmlUnmarshalerType     = reflect.TypeFor[xml.Unmarshaler]()
+	xmlNameType            = reflect.TypeFor[xml.Name]()
+	xmlUnmarshalerAttrType = reflect.TypeFor[xml.UnmarshalerAttr]()
+)
+
+// xmlElement element opened in an XML body.
+type xmlElement struct {
+	name    xml.Name     // Name in the body
+	renamed xml.Name     // Name handed to encoding/xml
+	typ     reflect.Type // Struct type decoded from the element, nil when its children are not renamed
+}
+
+// xmlFieldNames xml.TokenReader renaming the elements of DTO fields without `xml` tag from their JSON name to
+// their Go name, the name encoding/xml matches them by.
+type xmlFieldNames struct {
+	decoder  *xml.Decoder
+	root     reflect.Type
+	elements []xmlElement
+}
+
+// Token returns the next token of the body, with renamed elements.
+func (r *xmlFieldNames) Token() (xml.Token, error) {
+	token, err := r.decoder.RawToken()
+	if err != nil {
+		return nil, err
+	}
+
+	switch typed := token.(type) {
+	case xml.StartElement:
+		element := xmlElement{name: typed.Name, renamed: typed.Name}
+		if len(r.elements) == 0 {
+			element.typ = xmlStructType(r.root)
+		} else if parent := r.elements[len(r.elements)-1].typ; parent != nil {
+			element.typ = xmlChildType(parent, &element.renamed)
+		}
+		r.elements = append(r.elements, element)
+		typed.Name = element.renamed
+
+		return typed, nil
+	case xml.EndElement:
+		// Mismatched end elements are left to encoding/xml to report
+		if last := len(r.elements) - 1; last >= 0 && r.elements[last].name == typed.Name {
+			typed.Name = r.elements[last].renamed
+			r.elements = r.elements[:last]
+		}
+
+		return typed, nil
+	default:
+		return token, nil
+	}
+}
+
+// xmlChildType renames a child element of a struct type when it is a field without `xml` tag named by its JSON
+// name, and returns the struct type decoded from it, nil when there is none.
+func xmlChildType(typ reflect.Type, name *xml.Name) reflect.Type {
+	if field, ok := jsonField(typ, name.Local); ok {
+		if _, tagged := field.Tag.Lookup("xml"); !tagged {
+			name.Local = field.Name
+
+			return xmlStructType(field.Type)
+		}
+	}
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("xml")
+		tagName, _, _ := strings.Cut(tag, ",")
+		if (tagged && tagName == name.Local) || (!tagged && field.Name == name.Local) {
+			return xmlStructType(field.Type)
+		}
+	}
+
+	return nil
+}
+
+// xmlStructType returns the struct type decoded from the elements of a field's type, nil when it is not a struct
+// or decodes itself (time.Time, xml.Unmarshaler, encoding.TextUnmarshaler).
+func xmlStructType(typ reflect.Type) reflect.Type {
+	typ = derefType(typ)
+	if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() != reflect.Uint8 {
+		typ = derefType(typ.Elem())
+	}
+
+	if typ.Kind() != reflect.Struct || typ == xmlNameType {
+		return nil
+	}
+	for _, candidate := range []reflect.Type{typ, reflect.PointerTo(typ)} {
+		if candidate.Implements(xmlUnmarshalerType) || candidate.Implements(xmlUnmarshalerAttrType) ||
+			candidate.Implements(textUnmarshalerType) {
+			return nil
+		}
+	}
+
+	return typ
+}